@@ -2,20 +2,32 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"Puff/i18n"
 	"Puff/storage"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	SMTP     SMTPConfig     `json:"smtp"`
-	Telegram TelegramConfig `json:"telegram"`
-	Monitor  MonitorConfig  `json:"monitor"`
-	Log      LogConfig      `json:"log"`
+	Server       ServerConfig       `json:"server"`
+	Webhook      WebhookConfig      `json:"webhook"`
+	DingTalk     RobotConfig        `json:"dingtalk"`
+	Feishu       RobotConfig        `json:"feishu"`
+	WeCom        RobotConfig        `json:"wecom"`
+	Bark         PushConfig         `json:"bark"`
+	Ntfy         PushConfig         `json:"ntfy"`
+	Gotify       PushConfig         `json:"gotify"`
+	ServerChan   PushConfig         `json:"serverchan"`
+	Notification NotificationConfig `json:"notification"`
+	Digest       DigestConfig       `json:"digest"`
+	Health       HealthConfig       `json:"health"`
+	Events       EventsConfig       `json:"events"`
+	Monitor      MonitorConfig      `json:"monitor"`
+	Log          LogConfig          `json:"log"`
 }
 
 // ServerConfig 服务器配置结构
@@ -23,32 +35,167 @@ type ServerConfig struct {
 	Port     string `json:"port"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"` // 默认界面语言（用户未选择时使用），为空时按浏览器语言
 }
 
-// SMTPConfig 邮件配置
+// SMTPConfig 邮件配置（作为通知渠道实例的配置，见 notifier_instances 表）
 type SMTPConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Enabled  bool   `json:"enabled"`
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	User       string `json:"user"`
+	Password   string `json:"password"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Security   string `json:"security"`    // 连接安全：none / starttls / tls，为空时 465 端口用 TLS，其余在服务器支持时 STARTTLS
+	Auth       string `json:"auth"`        // 认证方式：none / plain / login / cram-md5，为空时有用户名则用 plain
+	SkipVerify bool   `json:"skip_verify"` // 跳过证书校验（自签名内网服务器）
+	CACert     string `json:"ca_cert"`     // 自定义CA证书（PEM）
+	HeloName   string `json:"helo_name"`   // EHLO/HELO 主机名，为空时使用 localhost
+	Timeout    int    `json:"timeout"`     // 连接与发送超时（秒），为空时 30 秒
+	Enabled    bool   `json:"-"`           // 由通知渠道实例的 enabled 列控制
 }
 
-// TelegramConfig Telegram配置
+// TelegramConfig Telegram配置（作为通知渠道实例的配置，见 notifier_instances 表）
 type TelegramConfig struct {
 	BotToken string `json:"bot_token"`
 	ChatID   string `json:"chat_id"`
-	Enabled  bool   `json:"enabled"`
+	Commands bool   `json:"commands"`           // 启用交互命令与告警按钮（同一 Bot 只应有一个渠道启用）
+	APIBase  string `json:"api_base,omitempty"` // Bot API 地址，为空时使用 https://api.telegram.org
+
+	ParseMode    string `json:"parse_mode,omitempty"`    // 自定义模板消息的解析模式：MarkdownV2、Markdown 或 HTML，为空时为纯文本
+	CSVThreshold int    `json:"csv_threshold,omitempty"` // 批量通知的事件数达到该值时改为发送 CSV 附件，0 表示不启用
+	Enabled      bool   `json:"-"`                       // 由通知渠道实例的 enabled 列控制
+}
+
+// ExecConfig 外部命令通知渠道配置
+type ExecConfig struct {
+	Command  string   `json:"command"`             // 可执行文件路径（不经过 shell）
+	Args     []string `json:"args,omitempty"`      // 命令参数
+	WorkDir  string   `json:"work_dir,omitempty"`  // 工作目录，为空时使用程序当前目录
+	Timeout  int      `json:"timeout,omitempty"`   // 超时时间（秒），默认 30
+	PerEvent bool     `json:"per_event,omitempty"` // 批量通知时每个事件单独执行一次
+	Enabled  bool     `json:"-"`                   // 由通知渠道实例的 enabled 列控制
+}
+
+// MQTTConfig MQTT 发布渠道配置
+type MQTTConfig struct {
+	Broker          string `json:"broker"`                     // 代理地址，如 mqtt://host:1883、mqtts://host:8883
+	Username        string `json:"username,omitempty"`         // 用户名
+	Password        string `json:"password,omitempty"`         // 密码
+	ClientID        string `json:"client_id,omitempty"`        // 客户端ID，为空时自动生成
+	TopicPrefix     string `json:"topic_prefix,omitempty"`     // 主题前缀，默认 puff
+	QoS             int    `json:"qos,omitempty"`              // 0、1 或 2
+	SkipVerify      bool   `json:"skip_verify,omitempty"`      // TLS 跳过证书校验
+	CACert          string `json:"ca_cert,omitempty"`          // TLS 自定义CA证书（PEM）
+	Discovery       bool   `json:"discovery,omitempty"`        // 发布 Home Assistant 自动发现配置
+	DiscoveryPrefix string `json:"discovery_prefix,omitempty"` // 自动发现主题前缀，默认 homeassistant
+	Timeout         int    `json:"timeout,omitempty"`          // 连接与发布超时（秒），默认 10
+	Enabled         bool   `json:"-"`                          // 由通知渠道实例的 enabled 列控制
+}
+
+// WebhookConfig Webhook配置
+type WebhookConfig struct {
+	URL        string `json:"url"`         // 目标地址，多个地址以换行或逗号分隔
+	Secret     string `json:"secret"`      // HMAC-SHA256 签名密钥（为空时不签名）
+	Headers    string `json:"headers"`     // 自定义请求头，每行一个 "Key: Value"
+	Template   string `json:"template"`    // 请求体模板（text/template），为空时使用默认JSON
+	MaxRetries int    `json:"max_retries"` // 失败重试次数
+	Enabled    bool   `json:"enabled"`
+}
+
+// RobotConfig 群机器人配置（钉钉、飞书、企业微信）
+type RobotConfig struct {
+	Webhook string `json:"webhook"` // 机器人Webhook地址
+	Secret  string `json:"secret"`  // 加签密钥（企业微信无需填写）
+	Enabled bool   `json:"enabled"`
+}
+
+// Robots 按设置键前缀返回群机器人配置
+func (cfg *Config) Robots() map[string]*RobotConfig {
+	return map[string]*RobotConfig{
+		"dingtalk": &cfg.DingTalk,
+		"feishu":   &cfg.Feishu,
+		"wecom":    &cfg.WeCom,
+	}
+}
+
+// PushConfig 手机推送配置（Bark、ntfy、Gotify、Server酱）
+type PushConfig struct {
+	Server      string `json:"server"`       // 服务地址，为空时使用官方服务（Gotify 必填）
+	Token       string `json:"token"`        // Bark 设备Key / ntfy 主题 / Gotify 应用Token / Server酱 SendKey
+	AccessToken string `json:"access_token"` // ntfy 访问令牌（可选）
+	Priorities  string `json:"priorities"`   // 优先级映射，如 "critical=5,high=4"，为空时使用平台默认
+	Enabled     bool   `json:"enabled"`
+}
+
+// PushServices 按设置键前缀返回手机推送配置
+func (cfg *Config) PushServices() map[string]*PushConfig {
+	return map[string]*PushConfig{
+		"bark":       &cfg.Bark,
+		"ntfy":       &cfg.Ntfy,
+		"gotify":     &cfg.Gotify,
+		"serverchan": &cfg.ServerChan,
+	}
+}
+
+// NotificationConfig 通知投递配置
+type NotificationConfig struct {
+	MaxAttempts int    `json:"max_attempts"` // 单个通知器的最大投递次数，超过后进入死信
+	PublicURL   string `json:"public_url"`   // 对外访问地址（如 https://puff.example.com），用于通知中的确认链接
+
+	AggregateWindow  int    `json:"aggregate_window"`  // 状态变化的合并窗口（秒）
+	QuietTimeout     int    `json:"quiet_timeout"`     // 无新域名查询超过该时间（秒）时提前发送合并通知
+	MaxBatchSize     int    `json:"max_batch_size"`    // 单条合并通知最多包含的域名数，达到后立即发送
+	CriticalStatuses string `json:"critical_statuses"` // 不参与合并、立即发送给所有渠道的目标状态（逗号分隔，如 available）
+	ChannelModes     string `json:"channel_modes"`     // 各通知器的发送方式（如 bark=immediate,email=hourly），未列出的为 batched
+
+	Locale  string `json:"locale"`  // 默认通知语言（如 zh-CN、en）
+	Locales string `json:"locales"` // 各通知器的通知语言（如 telegram:3=en,email=zh-CN），未列出的使用默认通知语言
+}
+
+// DigestConfig 定期摘要配置
+type DigestConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Frequency  string `json:"frequency"`   // daily 或 weekly
+	Hour       int    `json:"hour"`        // 发送时刻（0-23，本地时间）
+	Weekday    int    `json:"weekday"`     // 每周发送的星期（0=周日），仅 weekly 使用
+	ExpiryDays int    `json:"expiry_days"` // 列出未来 N 天内过期的域名
+	Targets    string `json:"targets"`     // 接收的通知器标识（逗号分隔），为空时发送给全部已启用的通知器
+}
+
+// EventsConfig 事件流导出配置（状态变化、字段变化、查询失败与管理操作，供 SIEM 采集）
+type EventsConfig struct {
+	SyslogEnabled    bool   `json:"syslog_enabled"`
+	SyslogNetwork    string `json:"syslog_network"`     // udp、tcp 或 tls
+	SyslogAddress    string `json:"syslog_address"`     // host:port
+	SyslogFacility   string `json:"syslog_facility"`    // 如 local0、daemon，默认 local0
+	SyslogAppName    string `json:"syslog_app_name"`    // APP-NAME 字段，默认 puff
+	SyslogSkipVerify bool   `json:"syslog_skip_verify"` // TLS 跳过证书校验
+	SyslogCACert     string `json:"syslog_ca_cert"`     // TLS 自定义CA证书（PEM）
+
+	FileEnabled    bool   `json:"file_enabled"`
+	FilePath       string `json:"file_path"`        // JSONL 文件路径，默认 data/events.jsonl
+	FileMaxSize    int    `json:"file_max_size"`    // 单个文件的最大大小（MB），超过后轮转
+	FileMaxBackups int    `json:"file_max_backups"` // 保留的轮转文件数
+}
+
+// HealthConfig 查询健康告警配置（发送到单独的管理员通道）
+type HealthConfig struct {
+	Enabled          bool   `json:"enabled"`
+	FailureThreshold int    `json:"failure_threshold"`  // 域名连续查询失败 N 次后告警
+	ServerWindow     int    `json:"server_window"`      // 统计 WHOIS/RDAP 服务器失败率的时间窗口（分钟）
+	ServerFailurePct int    `json:"server_failure_pct"` // 窗口内失败域名占比达到该百分比时判定服务器不可用
+	ServerMinDomains int    `json:"server_min_domains"` // 判定服务器不可用所需的最少域名数
+	Targets          string `json:"targets"`            // 管理员通道的通知器标识（逗号分隔），为空时发送给全部已启用的通知器
 }
 
 // MonitorConfig 监控配置
 type MonitorConfig struct {
-	CheckInterval   time.Duration `json:"check_interval"`   // 检查间隔
-	ConcurrentLimit int           `json:"concurrent_limit"` // 并发限制
-	Timeout         time.Duration `json:"timeout"`          // 查询超时
-	CacheDuration   time.Duration `json:"cache_duration"`   // 缓存时间
+	CheckInterval   time.Duration `json:"check_interval"`    // 检查间隔
+	ConcurrentLimit int           `json:"concurrent_limit"`  // 并发限制
+	Timeout         time.Duration `json:"timeout"`           // 查询超时
+	CacheDuration   time.Duration `json:"cache_duration"`    // 缓存时间
+	RecordAllChecks bool          `json:"record_all_checks"` // 是否为每次查询记录状态历史（默认仅记录状态变化）
 }
 
 // LogConfig 日志配置
@@ -78,6 +225,33 @@ func setDefaults(cfg *Config) {
 	cfg.Server.Username = "puff"
 	cfg.Server.Password = "puff123"
 
+	cfg.Webhook.MaxRetries = 3
+
+	cfg.Notification.MaxAttempts = 5
+	cfg.Notification.AggregateWindow = 10
+	cfg.Notification.QuietTimeout = 8
+	cfg.Notification.MaxBatchSize = 50
+	cfg.Notification.CriticalStatuses = "available"
+	cfg.Notification.Locale = i18n.Default
+
+	cfg.Digest.Frequency = "daily"
+	cfg.Digest.Hour = 9
+	cfg.Digest.Weekday = 1
+	cfg.Digest.ExpiryDays = 30
+
+	cfg.Health.Enabled = true
+	cfg.Health.FailureThreshold = 5
+	cfg.Health.ServerWindow = 360
+	cfg.Health.ServerFailurePct = 80
+	cfg.Health.ServerMinDomains = 3
+
+	cfg.Events.SyslogNetwork = "udp"
+	cfg.Events.SyslogFacility = "local0"
+	cfg.Events.SyslogAppName = "puff"
+	cfg.Events.FilePath = filepath.Join("data", "events.jsonl")
+	cfg.Events.FileMaxSize = 100
+	cfg.Events.FileMaxBackups = 5
+
 	cfg.Monitor.CheckInterval = 5 * time.Minute
 	cfg.Monitor.ConcurrentLimit = 50
 	cfg.Monitor.Timeout = 30 * time.Second
@@ -94,6 +268,11 @@ func loadFromDatabase(cfg *Config) error {
 		return err
 	}
 
+	// 将旧版单一邮件/Telegram设置迁移为通知渠道实例
+	if err := migrateLegacyNotifiers(settings); err != nil {
+		return err
+	}
+
 	// 如果缺失则写入默认值
 	if err := backfillDefaults(cfg, settings); err != nil {
 		return err
@@ -115,21 +294,140 @@ func loadFromDatabase(cfg *Config) error {
 	applySetting("server_username", func(v string) { cfg.Server.Username = v })
 	applySetting("server_password", func(v string) { cfg.Server.Password = v })
 
-	applySetting("smtp_host", func(v string) { cfg.SMTP.Host = v })
-	applySetting("smtp_port", func(v string) {
-		if n, err := strconv.Atoi(v); err == nil {
-			cfg.SMTP.Port = n
+	applySetting("webhook_url", func(v string) { cfg.Webhook.URL = v })
+	applySetting("webhook_secret", func(v string) { cfg.Webhook.Secret = v })
+	applySetting("webhook_headers", func(v string) { cfg.Webhook.Headers = v })
+	applySetting("webhook_template", func(v string) { cfg.Webhook.Template = v })
+	applySetting("webhook_max_retries", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Webhook.MaxRetries = n
 		}
 	})
-	applySetting("smtp_user", func(v string) { cfg.SMTP.User = v })
-	applySetting("smtp_pass", func(v string) { cfg.SMTP.Password = v })
-	applySetting("smtp_from", func(v string) { cfg.SMTP.From = v })
-	applySetting("smtp_to", func(v string) { cfg.SMTP.To = v })
-	applySetting("smtp_enabled", func(v string) { cfg.SMTP.Enabled = parseBool(v) })
+	applySetting("webhook_enabled", func(v string) { cfg.Webhook.Enabled = parseBool(v) })
+
+	for prefix, robot := range cfg.Robots() {
+		robot := robot
+		applySetting(prefix+"_webhook", func(v string) { robot.Webhook = v })
+		applySetting(prefix+"_secret", func(v string) { robot.Secret = v })
+		applySetting(prefix+"_enabled", func(v string) { robot.Enabled = parseBool(v) })
+	}
 
-	applySetting("telegram_bot_token", func(v string) { cfg.Telegram.BotToken = v })
-	applySetting("telegram_chat_id", func(v string) { cfg.Telegram.ChatID = v })
-	applySetting("telegram_enabled", func(v string) { cfg.Telegram.Enabled = parseBool(v) })
+	for prefix, push := range cfg.PushServices() {
+		push := push
+		applySetting(prefix+"_server", func(v string) { push.Server = v })
+		applySetting(prefix+"_token", func(v string) { push.Token = v })
+		applySetting(prefix+"_access_token", func(v string) { push.AccessToken = v })
+		applySetting(prefix+"_priorities", func(v string) { push.Priorities = v })
+		applySetting(prefix+"_enabled", func(v string) { push.Enabled = parseBool(v) })
+	}
+
+	applySetting("notification_max_attempts", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Notification.MaxAttempts = n
+		}
+	})
+	applySetting("notification_public_url", func(v string) { cfg.Notification.PublicURL = strings.TrimRight(strings.TrimSpace(v), "/") })
+	applySetting("notification_aggregate_window", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Notification.AggregateWindow = n
+		}
+	})
+	applySetting("notification_quiet_timeout", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Notification.QuietTimeout = n
+		}
+	})
+	applySetting("notification_max_batch_size", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Notification.MaxBatchSize = n
+		}
+	})
+	applySetting("notification_critical_statuses", func(v string) { cfg.Notification.CriticalStatuses = v })
+	applySetting("notification_channel_modes", func(v string) { cfg.Notification.ChannelModes = v })
+	applySetting("notification_locale", func(v string) { cfg.Notification.Locale = v })
+	applySetting("notification_locales", func(v string) { cfg.Notification.Locales = v })
+
+	applySetting("digest_enabled", func(v string) { cfg.Digest.Enabled = parseBool(v) })
+	applySetting("digest_frequency", func(v string) {
+		if v == "daily" || v == "weekly" {
+			cfg.Digest.Frequency = v
+		}
+	})
+	applySetting("digest_hour", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < 24 {
+			cfg.Digest.Hour = n
+		}
+	})
+	applySetting("digest_weekday", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < 7 {
+			cfg.Digest.Weekday = n
+		}
+	})
+	applySetting("digest_expiry_days", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Digest.ExpiryDays = n
+		}
+	})
+	applySetting("digest_targets", func(v string) { cfg.Digest.Targets = v })
+
+	applySetting("health_enabled", func(v string) { cfg.Health.Enabled = parseBool(v) })
+	applySetting("health_failure_threshold", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Health.FailureThreshold = n
+		}
+	})
+	applySetting("health_server_window", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Health.ServerWindow = n
+		}
+	})
+	applySetting("health_server_failure_pct", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			cfg.Health.ServerFailurePct = n
+		}
+	})
+	applySetting("health_server_min_domains", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Health.ServerMinDomains = n
+		}
+	})
+	applySetting("health_targets", func(v string) { cfg.Health.Targets = v })
+
+	applySetting("events_syslog_enabled", func(v string) { cfg.Events.SyslogEnabled = parseBool(v) })
+	applySetting("events_syslog_network", func(v string) {
+		if v == "udp" || v == "tcp" || v == "tls" {
+			cfg.Events.SyslogNetwork = v
+		}
+	})
+	applySetting("events_syslog_address", func(v string) { cfg.Events.SyslogAddress = v })
+	applySetting("events_syslog_facility", func(v string) {
+		if v != "" {
+			cfg.Events.SyslogFacility = v
+		}
+	})
+	applySetting("events_syslog_app_name", func(v string) {
+		if v != "" {
+			cfg.Events.SyslogAppName = v
+		}
+	})
+	applySetting("events_syslog_skip_verify", func(v string) { cfg.Events.SyslogSkipVerify = parseBool(v) })
+	applySetting("events_syslog_ca_cert", func(v string) { cfg.Events.SyslogCACert = v })
+	applySetting("events_file_enabled", func(v string) { cfg.Events.FileEnabled = parseBool(v) })
+	applySetting("events_file_path", func(v string) {
+		if v != "" {
+			cfg.Events.FilePath = v
+		}
+	})
+	applySetting("events_file_max_size", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Events.FileMaxSize = n
+		}
+	})
+	applySetting("events_file_max_backups", func(v string) {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			cfg.Events.FileMaxBackups = n
+		}
+	})
 
 	applySetting("monitor_check_interval", func(v string) {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -152,6 +450,8 @@ func loadFromDatabase(cfg *Config) error {
 		}
 	})
 
+	applySetting("monitor_record_all_checks", func(v string) { cfg.Monitor.RecordAllChecks = parseBool(v) })
+
 	applySetting("log_level", func(v string) { cfg.Log.Level = v })
 
 	// 如果存在空值，回落到默认并写回数据库
@@ -165,24 +465,63 @@ func loadFromDatabase(cfg *Config) error {
 // backfillDefaults 将缺失的键写入数据库
 func backfillDefaults(cfg *Config, settings map[string]string) error {
 	defaults := map[string]string{
-		"server_port":              cfg.Server.Port,
-		"server_username":          cfg.Server.Username,
-		"server_password":          cfg.Server.Password,
-		"smtp_host":                cfg.SMTP.Host,
-		"smtp_port":                fmt.Sprintf("%d", cfg.SMTP.Port),
-		"smtp_user":                cfg.SMTP.User,
-		"smtp_pass":                cfg.SMTP.Password,
-		"smtp_from":                cfg.SMTP.From,
-		"smtp_to":                  cfg.SMTP.To,
-		"smtp_enabled":             fmt.Sprintf("%t", cfg.SMTP.Enabled),
-		"telegram_bot_token":       cfg.Telegram.BotToken,
-		"telegram_chat_id":         cfg.Telegram.ChatID,
-		"telegram_enabled":         fmt.Sprintf("%t", cfg.Telegram.Enabled),
-		"monitor_check_interval":   fmt.Sprintf("%d", int(cfg.Monitor.CheckInterval.Seconds())),
-		"monitor_concurrent_limit": fmt.Sprintf("%d", cfg.Monitor.ConcurrentLimit),
-		"monitor_timeout":          fmt.Sprintf("%d", int(cfg.Monitor.Timeout.Seconds())),
-		"monitor_cache_duration":   fmt.Sprintf("%d", int(cfg.Monitor.CacheDuration.Seconds())),
-		"log_level":                cfg.Log.Level,
+		"server_port":                    cfg.Server.Port,
+		"server_username":                cfg.Server.Username,
+		"server_password":                cfg.Server.Password,
+		"webhook_url":                    cfg.Webhook.URL,
+		"webhook_secret":                 cfg.Webhook.Secret,
+		"webhook_headers":                cfg.Webhook.Headers,
+		"webhook_template":               cfg.Webhook.Template,
+		"webhook_max_retries":            fmt.Sprintf("%d", cfg.Webhook.MaxRetries),
+		"webhook_enabled":                fmt.Sprintf("%t", cfg.Webhook.Enabled),
+		"notification_max_attempts":      fmt.Sprintf("%d", cfg.Notification.MaxAttempts),
+		"notification_public_url":        cfg.Notification.PublicURL,
+		"notification_aggregate_window":  fmt.Sprintf("%d", cfg.Notification.AggregateWindow),
+		"notification_quiet_timeout":     fmt.Sprintf("%d", cfg.Notification.QuietTimeout),
+		"notification_max_batch_size":    fmt.Sprintf("%d", cfg.Notification.MaxBatchSize),
+		"notification_critical_statuses": cfg.Notification.CriticalStatuses,
+		"notification_channel_modes":     cfg.Notification.ChannelModes,
+		"notification_locale":            cfg.Notification.Locale,
+		"notification_locales":           cfg.Notification.Locales,
+		"digest_enabled":                 fmt.Sprintf("%t", cfg.Digest.Enabled),
+		"digest_frequency":               cfg.Digest.Frequency,
+		"digest_hour":                    fmt.Sprintf("%d", cfg.Digest.Hour),
+		"digest_weekday":                 fmt.Sprintf("%d", cfg.Digest.Weekday),
+		"digest_expiry_days":             fmt.Sprintf("%d", cfg.Digest.ExpiryDays),
+		"digest_targets":                 cfg.Digest.Targets,
+		"health_enabled":                 fmt.Sprintf("%t", cfg.Health.Enabled),
+		"health_failure_threshold":       fmt.Sprintf("%d", cfg.Health.FailureThreshold),
+		"health_server_window":           fmt.Sprintf("%d", cfg.Health.ServerWindow),
+		"health_server_failure_pct":      fmt.Sprintf("%d", cfg.Health.ServerFailurePct),
+		"health_server_min_domains":      fmt.Sprintf("%d", cfg.Health.ServerMinDomains),
+		"health_targets":                 cfg.Health.Targets,
+		"events_syslog_enabled":          fmt.Sprintf("%t", cfg.Events.SyslogEnabled),
+		"events_syslog_network":          cfg.Events.SyslogNetwork,
+		"events_syslog_address":          cfg.Events.SyslogAddress,
+		"events_syslog_facility":         cfg.Events.SyslogFacility,
+		"events_syslog_app_name":         cfg.Events.SyslogAppName,
+		"events_file_enabled":            fmt.Sprintf("%t", cfg.Events.FileEnabled),
+		"events_file_path":               cfg.Events.FilePath,
+		"events_file_max_size":           fmt.Sprintf("%d", cfg.Events.FileMaxSize),
+		"events_file_max_backups":        fmt.Sprintf("%d", cfg.Events.FileMaxBackups),
+		"monitor_check_interval":         fmt.Sprintf("%d", int(cfg.Monitor.CheckInterval.Seconds())),
+		"monitor_concurrent_limit":       fmt.Sprintf("%d", cfg.Monitor.ConcurrentLimit),
+		"monitor_timeout":                fmt.Sprintf("%d", int(cfg.Monitor.Timeout.Seconds())),
+		"monitor_cache_duration":         fmt.Sprintf("%d", int(cfg.Monitor.CacheDuration.Seconds())),
+		"monitor_record_all_checks":      fmt.Sprintf("%t", cfg.Monitor.RecordAllChecks),
+		"log_level":                      cfg.Log.Level,
+	}
+	for prefix, robot := range cfg.Robots() {
+		defaults[prefix+"_webhook"] = robot.Webhook
+		defaults[prefix+"_secret"] = robot.Secret
+		defaults[prefix+"_enabled"] = fmt.Sprintf("%t", robot.Enabled)
+	}
+	for prefix, push := range cfg.PushServices() {
+		defaults[prefix+"_server"] = push.Server
+		defaults[prefix+"_token"] = push.Token
+		defaults[prefix+"_access_token"] = push.AccessToken
+		defaults[prefix+"_priorities"] = push.Priorities
+		defaults[prefix+"_enabled"] = fmt.Sprintf("%t", push.Enabled)
 	}
 
 	missing := map[string]string{}
@@ -216,10 +555,6 @@ func ensureDefaultsIfEmpty(cfg *Config, settings map[string]string) error {
 		updates["server_port"] = cfg.Server.Port
 	}
 
-	if cfg.SMTP.Port == 0 {
-		cfg.SMTP.Port = 587
-		updates["smtp_port"] = fmt.Sprintf("%d", cfg.SMTP.Port)
-	}
 	if cfg.Monitor.CheckInterval == 0 {
 		cfg.Monitor.CheckInterval = 5 * time.Minute
 		updates["monitor_check_interval"] = fmt.Sprintf("%d", int(cfg.Monitor.CheckInterval.Seconds()))
@@ -283,5 +618,7 @@ func (cfg *Config) Validate() error {
 
 // GetNotificationEnabled 获取通知是否启用
 func (cfg *Config) GetNotificationEnabled() bool {
-	return cfg.SMTP.Enabled || cfg.Telegram.Enabled
+	return cfg.Webhook.Enabled ||
+		cfg.DingTalk.Enabled || cfg.Feishu.Enabled || cfg.WeCom.Enabled ||
+		cfg.Bark.Enabled || cfg.Ntfy.Enabled || cfg.Gotify.Enabled || cfg.ServerChan.Enabled
 }
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"Puff/storage"
+)
+
+// notifierMigrationKey 旧版通知设置迁移完成标记
+const notifierMigrationKey = "notifier_instances_migrated"
+
+// legacyNotifierKeys 旧版单一邮件/Telegram通知的设置键
+var legacyNotifierKeys = []string{
+	"smtp_host", "smtp_port", "smtp_user", "smtp_pass", "smtp_from", "smtp_to", "smtp_enabled",
+	"telegram_bot_token", "telegram_chat_id", "telegram_enabled",
+}
+
+// migrateLegacyNotifiers 将 app_settings 中的旧版邮件/Telegram配置迁移为 notifier_instances 记录（仅执行一次）
+func migrateLegacyNotifiers(settings map[string]string) error {
+	if parseBool(settings[notifierMigrationKey]) {
+		return nil
+	}
+
+	get := func(key string) string { return strings.TrimSpace(settings[key]) }
+	var instances []storage.NotifierInstance
+
+	if get("smtp_host") != "" {
+		smtp := SMTPConfig{
+			Host:     get("smtp_host"),
+			User:     get("smtp_user"),
+			Password: get("smtp_pass"),
+			From:     get("smtp_from"),
+			To:       get("smtp_to"),
+		}
+		if n, err := strconv.Atoi(get("smtp_port")); err == nil {
+			smtp.Port = n
+		}
+		inst, err := legacyInstance("email", "默认邮箱", smtp, parseBool(get("smtp_enabled")))
+		if err != nil {
+			return err
+		}
+		instances = append(instances, inst)
+	}
+
+	if get("telegram_bot_token") != "" {
+		telegram := TelegramConfig{
+			BotToken: get("telegram_bot_token"),
+			ChatID:   get("telegram_chat_id"),
+		}
+		inst, err := legacyInstance("telegram", "默认Telegram", telegram, parseBool(get("telegram_enabled")))
+		if err != nil {
+			return err
+		}
+		instances = append(instances, inst)
+	}
+
+	if err := storage.MigrateSettingsToNotifiers(instances, legacyNotifierKeys, notifierMigrationKey); err != nil {
+		return fmt.Errorf("迁移通知设置失败: %v", err)
+	}
+	return nil
+}
+
+// legacyInstance 将旧版配置序列化为通知渠道实例
+func legacyInstance(notifierType, name string, cfg interface{}, enabled bool) (storage.NotifierInstance, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return storage.NotifierInstance{}, fmt.Errorf("序列化%s配置失败: %v", notifierType, err)
+	}
+	return storage.NotifierInstance{
+		Type:    notifierType,
+		Name:    name,
+		Config:  string(data),
+		Enabled: enabled,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"Puff/config"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+// TLDLifecycle 域名过期后各阶段的时长（天）
+type TLDLifecycle struct {
+	GraceDays         int `json:"grace_days"`          // 续费宽限期
+	RedemptionDays    int `json:"redemption_days"`     // 赎回期
+	PendingDeleteDays int `json:"pending_delete_days"` // 待删除期
+}
+
+// defaultLifecycle 通用顶级域（ICANN gTLD）标准生命周期
+var defaultLifecycle = TLDLifecycle{GraceDays: 45, RedemptionDays: 30, PendingDeleteDays: 5}
+
+// tldLifecycles 与通用规则不同的后缀（估算值，以注册局政策为准）
+var tldLifecycles = map[string]TLDLifecycle{
+	"cn": {GraceDays: 30, RedemptionDays: 15, PendingDeleteDays: 5},
+	"de": {GraceDays: 0, RedemptionDays: 30, PendingDeleteDays: 0},
+	"eu": {GraceDays: 0, RedemptionDays: 40, PendingDeleteDays: 0},
+	"uk": {GraceDays: 90, RedemptionDays: 0, PendingDeleteDays: 0},
+	"fr": {GraceDays: 0, RedemptionDays: 30, PendingDeleteDays: 0},
+	"nl": {GraceDays: 0, RedemptionDays: 40, PendingDeleteDays: 0},
+}
+
+// GetTLDLifecycle 获取域名后缀对应的生命周期
+func GetTLDLifecycle(domain string) TLDLifecycle {
+	tld := config.FindBestTLD(domain)
+	if tld == "" {
+		if idx := strings.LastIndex(domain, "."); idx >= 0 {
+			tld = domain[idx+1:]
+		}
+	}
+	// 多级后缀（如 co.uk）按最后一级匹配
+	if idx := strings.LastIndex(tld, "."); idx >= 0 {
+		tld = tld[idx+1:]
+	}
+	if lc, ok := tldLifecycles[strings.ToLower(tld)]; ok {
+		return lc
+	}
+	return defaultLifecycle
+}
+
+// CalendarFilter 日历订阅过滤条件
+type CalendarFilter struct {
+	TLDs     []string       // 后缀，如 com、co.uk
+	Statuses []DomainStatus // 域名状态
+}
+
+// match 判断域名是否满足过滤条件
+func (f CalendarFilter) match(domain string, status DomainStatus) bool {
+	if len(f.TLDs) > 0 {
+		matched := false
+		for _, tld := range f.TLDs {
+			if strings.HasSuffix(domain, "."+strings.TrimPrefix(tld, ".")) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.Statuses) > 0 {
+		for _, s := range f.Statuses {
+			if s == status {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// calendarEvent 日历中的全天事件
+type calendarEvent struct {
+	uid         string
+	date        time.Time
+	summary     string
+	description string
+}
+
+// BuildCalendar 根据查询结果生成指定语言的 iCalendar 文本（过期日期及宽限期、赎回期、待删除期的预计结束日期）
+func BuildCalendar(locale string, results map[string]storage.DomainResult, filter CalendarFilter, now time.Time) string {
+	domains := make([]string, 0, len(results))
+	for domain := range results {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var events []calendarEvent
+	for _, domain := range domains {
+		res := results[domain]
+		if res.ExpiryAt == nil || !filter.match(domain, DomainStatus(res.Status)) {
+			continue
+		}
+
+		expiry := res.ExpiryAt.UTC()
+		lc := GetTLDLifecycle(domain)
+		status := GetStatusInfoFor(locale, DomainStatus(res.Status)).Description
+
+		events = append(events, calendarEvent{
+			uid:         fmt.Sprintf("%s-expiry@puff", domain),
+			date:        expiry,
+			summary:     i18n.T(locale, "calendar.expiry", domain),
+			description: i18n.T(locale, "calendar.expiry_desc", domain, status),
+		})
+
+		phases := []struct {
+			uid   string
+			label string
+			days  int
+		}{
+			{"grace", i18n.T(locale, "calendar.grace"), lc.GraceDays},
+			{"redemption", i18n.T(locale, "calendar.redemption"), lc.RedemptionDays},
+			{"pending-delete", i18n.T(locale, "calendar.pending_delete"), lc.PendingDeleteDays},
+		}
+		offset := 0
+		for _, phase := range phases {
+			offset += phase.days
+			// 时长为 0 的阶段跳过，但始终保留预计删除日期
+			if phase.days == 0 && phase.uid != "pending-delete" {
+				continue
+			}
+			events = append(events, calendarEvent{
+				uid:         fmt.Sprintf("%s-%s@puff", domain, phase.uid),
+				date:        expiry.AddDate(0, 0, offset),
+				summary:     i18n.T(locale, "calendar.phase", domain, phase.label),
+				description: i18n.T(locale, "calendar.phase_desc", domain, offset, phase.label, status),
+			})
+		}
+	}
+
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Puff//Domain Monitor//ZH")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeICSText(i18n.T(locale, "calendar.name")))
+	for _, e := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + e.uid)
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART;VALUE=DATE:" + e.date.Format("20060102"))
+		writeLine("DTEND;VALUE=DATE:" + e.date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine("SUMMARY:" + escapeICSText(e.summary))
+		writeLine("DESCRIPTION:" + escapeICSText(e.description))
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+
+	return b.String()
+}
+
+// escapeICSText 转义 iCalendar 文本值（RFC 5545 3.3.11）
+func escapeICSText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICSLine 按 75 字节折行，不拆分多字节字符（RFC 5545 3.1）
+func foldICSLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	// 续行以一个空格开头，空格计入该行长度
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+func testCalendarResults() map[string]storage.DomainResult {
+	expiry := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return map[string]storage.DomainResult{
+		"example.com":  {Domain: "example.com", Status: string(StatusRegistered), ExpiryAt: &expiry},
+		"example.de":   {Domain: "example.de", Status: string(StatusGrace), ExpiryAt: &expiry},
+		"noexpiry.com": {Domain: "noexpiry.com", Status: string(StatusRegistered)},
+	}
+}
+
+func TestBuildCalendarPhases(t *testing.T) {
+	ics := BuildCalendar(i18n.ZhCN, testCalendarResults(), CalendarFilter{}, time.Now())
+
+	for _, want := range []string{
+		"UID:example.com-expiry@puff",
+		"UID:example.com-grace@puff\r\nDTSTAMP",
+		"DTSTART;VALUE=DATE:20260615", // 45 天宽限期
+		"DTSTART;VALUE=DATE:20260715", // 再加 30 天赎回期
+		"DTSTART;VALUE=DATE:20260720", // 再加 5 天待删除期
+		"UID:example.de-redemption@puff",
+		"UID:example.de-pending-delete@puff",
+		"X-WR-CALNAME:Puff 域名日历",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("日历缺少 %q", want)
+		}
+	}
+	if strings.Contains(ics, "example.de-grace@puff") {
+		t.Error("时长为 0 的宽限期不应生成事件")
+	}
+	if strings.Contains(ics, "noexpiry.com") {
+		t.Error("没有过期日期的域名不应出现在日历中")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("行超过 75 字节未折叠: %q", line)
+		}
+	}
+}
+
+func TestBuildCalendarLocalized(t *testing.T) {
+	ics := BuildCalendar(i18n.En, testCalendarResults(), CalendarFilter{}, time.Now())
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	for _, want := range []string{
+		"X-WR-CALNAME:Puff domain calendar",
+		"SUMMARY:example.com expires",
+		"SUMMARY:example.com redemption ends (estimated)",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("英文日历缺少 %q", want)
+		}
+	}
+	if strings.Contains(unfolded, "过期") || strings.Contains(unfolded, "预计") {
+		t.Errorf("英文日历含有中文文案:\n%s", unfolded)
+	}
+}
+
+func TestBuildCalendarFilter(t *testing.T) {
+	ics := BuildCalendar(i18n.ZhCN, testCalendarResults(), CalendarFilter{TLDs: []string{".de"}}, time.Now())
+	if strings.Contains(ics, "example.com") || !strings.Contains(ics, "example.de") {
+		t.Errorf("按后缀过滤结果错误:\n%s", ics)
+	}
+
+	ics = BuildCalendar(i18n.ZhCN, testCalendarResults(), CalendarFilter{Statuses: []DomainStatus{StatusRegistered}}, time.Now())
+	if !strings.Contains(ics, "example.com") || strings.Contains(ics, "example.de") {
+		t.Errorf("按状态过滤结果错误:\n%s", ics)
+	}
+}
+
+func TestEscapeICSText(t *testing.T) {
+	if got := escapeICSText("a,b;c\\d\ne"); got != `a\,b\;c\\d\ne` {
+		t.Errorf("escapeICSText = %q", got)
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	if short := strings.Repeat("a", 75); foldICSLine(short) != short {
+		t.Error("75 字节以内不应折行")
+	}
+
+	// 多字节字符不被拆分，续行以空格开头且计入长度
+	line := "SUMMARY:" + strings.Repeat("域", 40)
+	folded := foldICSLine(line)
+	parts := strings.Split(folded, "\r\n")
+	if len(parts) < 2 {
+		t.Fatalf("超长行应折行: %q", folded)
+	}
+	for i, part := range parts {
+		if len(part) > 75 || !utf8.ValidString(part) {
+			t.Errorf("第 %d 行长度 %d 或编码无效: %q", i+1, len(part), part)
+		}
+		if i > 0 && !strings.HasPrefix(part, " ") {
+			t.Errorf("续行应以空格开头: %q", part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+		t.Errorf("展开后应与原行一致: %q", unfolded)
+	}
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffMaxCells LCS 计算的最大表格规模，超出时退化为整体替换
+const diffMaxCells = 25_000_000
+
+// diffOp 单行差异操作：' ' 不变，'-' 删除，'+' 新增
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff 生成两段文本之间的统一格式（unified）差异，context 为上下文行数
+// 两段文本相同时返回空字符串
+func UnifiedDiff(fromName, toName, from, to string, context int) string {
+	if from == to {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	// 预先计算每个操作之前的旧/新行号
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1] = oldPos[i]
+		newPos[i+1] = newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
+		}
+		if op.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for i := 0; i < len(changes); {
+		// 合并间隔不超过 2*context 的变化为同一个 hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + context + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		oldCount := oldPos[end] - oldPos[start]
+		newCount := newPos[end] - newPos[start]
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldCount), hunkRange(newPos[start], newCount)))
+
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+
+		i = j + 1
+	}
+
+	return out.String()
+}
+
+// hunkRange 格式化 hunk 行号范围
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines 按行切分文本（统一换行符）
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+func diffLines(a, b []string) []diffOp {
+	// 去掉公共前后缀，缩小计算规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	if len(midA)*len(midB) > diffMaxCells {
+		// 规模过大，整体替换
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		ops = append(ops, lcsDiff(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+// lcsDiff 动态规划求解 LCS 并回溯生成差异
+func lcsDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	width := m + 1
+	table := make([]int32, (n+1)*width)
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i*width+j] = table[(i+1)*width+j+1] + 1
+			} else if table[(i+1)*width+j] >= table[i*width+j+1] {
+				table[i*width+j] = table[(i+1)*width+j]
+			} else {
+				table[i*width+j] = table[i*width+j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case table[(i+1)*width+j] >= table[i*width+j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
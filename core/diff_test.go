@@ -0,0 +1,55 @@
+package core
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		context  int
+		want     string
+	}{
+		{"相同文本", "a\nb\n", "a\nb", 3, ""},
+		{"换行符差异不算变化", "a\r\nb\r\n", "a\nb\n", 3, ""},
+		{
+			"单行修改",
+			"Domain: a.com\nRegistrar: Old\nStatus: ok\n",
+			"Domain: a.com\nRegistrar: New\nStatus: ok\n",
+			1,
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n Domain: a.com\n-Registrar: Old\n+Registrar: New\n Status: ok\n",
+		},
+		{
+			"新增到空文本",
+			"",
+			"a\nb\n",
+			3,
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"相距较远的变化分为两个 hunk",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"x\n2\n3\n4\n5\n6\n7\ny\n",
+			1,
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y\n",
+		},
+		{
+			"相近的变化合并为一个 hunk",
+			"1\n2\n3\n4\n",
+			"x\n2\n3\ny\n",
+			1,
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+		{
+			"删除行",
+			"a\nb\nc\n",
+			"a\nc\n",
+			0,
+			"--- old\n+++ new\n@@ -2 +1,0 @@\n-b\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := UnifiedDiff("old", "new", tt.from, tt.to, tt.context); got != tt.want {
+			t.Errorf("%s:\n得到:\n%s\n期望:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"Puff/config"
+	"Puff/eventsink"
 	"Puff/logger"
 	"Puff/storage"
 )
@@ -23,6 +24,10 @@ type DomainWorker struct {
 	cancel        context.CancelFunc
 	lastStatus    DomainStatus
 	statusChange  chan<- StatusChangeEvent // 状态变化通知通道
+	fieldChange   chan<- FieldChangeEvent  // 字段变化通知通道
+	checked       chan<- *DomainInfo       // 每次查询的结果
+	health        *HealthTracker           // 查询健康跟踪
+	events        *eventsink.Sink          // 事件流导出
 	notify        bool                     // 是否启用通知
 	queryRecorder func(string)             // 查询记录函数
 	isFirstQuery  bool                     // 是否为首次查询
@@ -35,6 +40,9 @@ func NewDomainWorker(
 	cfg *config.Config,
 	semaphore chan struct{},
 	statusChange chan<- StatusChangeEvent,
+	fieldChange chan<- FieldChangeEvent,
+	checked chan<- *DomainInfo,
+	health *HealthTracker,
 	notify bool,
 	queryRecorder func(string),
 ) *DomainWorker {
@@ -57,6 +65,9 @@ func NewDomainWorker(
 		cancel:        cancel,
 		lastStatus:    StatusUnknown,
 		statusChange:  statusChange,
+		fieldChange:   fieldChange,
+		checked:       checked,
+		health:        health,
 		notify:        notify,
 		queryRecorder: queryRecorder,
 		isFirstQuery:  isFirstQuery,
@@ -190,10 +201,15 @@ func (w *DomainWorker) executeQuery() {
 	logger.Debug("域名 %s 准备获取信号量 (容量: %d)", w.domain, cap(sem))
 	sem <- struct{}{}
 
-	// 查询完成后释放信号量（使用同一个信号量引用）
-	defer func() {
-		<-sem
-	}()
+	// 查询完成后立即释放信号量（使用同一个信号量引用），保存与通知不占用并发槽位
+	released := false
+	release := func() {
+		if !released {
+			released = true
+			<-sem
+		}
+	}
+	defer release()
 
 	startTime := time.Now()
 	// 计算等待时间
@@ -213,10 +229,26 @@ func (w *DomainWorker) executeQuery() {
 
 	// 执行查询（带重试）
 	info := w.queryWithRetry()
+	// 通知通道已满时发送会等待，不能占用信号量阻塞其他域名的查询
+	release()
 
 	// 保存到数据库
 	w.saveToDatabase(info)
 
+	// 记录状态历史
+	recordStatusHistory(w.config.Monitor.RecordAllChecks, previousStatus, info)
+
+	// 原始响应变化时保存快照
+	recordWhoisSnapshot(info)
+
+	// 统计查询健康（连续失败与服务器失败率）
+	w.health.Record(w.config.Health, previousResult, info)
+
+	// 导出状态与字段变化（在通知过滤之前）
+	changes := DetectFieldChanges(previousResult, info)
+	publishChanges(w.events, previousStatus, info, changes)
+	emitChecked(w.checked, info)
+
 	// 检查状态变化并发送通知
 	// 只有当有明确的前一个状态，且状态发生变化时才通知
 	if w.isFirstQuery {
@@ -228,6 +260,9 @@ func (w *DomainWorker) executeQuery() {
 		w.notifyStatusChange(previousStatus, info.Status, info)
 	}
 
+	// 检查注册商、名称服务器、过期时间、EPP状态码等字段变化
+	w.notifyFieldChanges(changes)
+
 	// 更新最后状态
 	w.lastStatus = info.Status
 
@@ -342,6 +377,7 @@ func (w *DomainWorker) saveToDatabase(info *DomainInfo) {
 		ExpiryAt:     toCST(info.ExpiryDate),
 		UpdatedAt:    toCST(info.UpdatedDate),
 		NameServers:  info.NameServers,
+		StatusCodes:  info.StatusCodes,
 		WhoisRaw:     info.WhoisRaw,
 		ErrorMessage: info.ErrorMessage,
 	}
@@ -456,15 +492,40 @@ func (w *DomainWorker) notifyStatusChange(oldStatus, newStatus DomainStatus, inf
 		DomainInfo: info,
 	}
 
-	// 非阻塞发送
-	select {
-	case w.statusChange <- event:
+	if sendStatusChange(w.ctx, w.statusChange, event) {
 		logger.Info("域名 %s 状态变化通知已发送: %s -> %s", w.domain, oldStatus, newStatus)
-	default:
-		logger.Warn("通知队列已满，丢弃域名 %s 的状态变化通知", w.domain)
+	} else {
+		logger.Error("通知队列持续已满，丢弃域名 %s 的状态变化通知", w.domain)
+	}
+}
+
+// statusChangeSendTimeout 通知通道已满时等待消费的最长时间
+const statusChangeSendTimeout = 30 * time.Second
+
+// sendStatusChange 发送状态变化事件；通道已满时等待消费（事件随后写入持久化发件箱），超时或取消才丢弃
+func sendStatusChange(ctx context.Context, ch chan<- StatusChangeEvent, event StatusChangeEvent) bool {
+	timer := time.NewTimer(statusChangeSendTimeout)
+	defer timer.Stop()
+
+	select {
+	case ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return false
 	}
 }
 
+// notifyFieldChanges 发送字段变化通知（仅发送已订阅的变化类型）
+func (w *DomainWorker) notifyFieldChanges(changes []FieldChangeEvent) {
+	if !w.notify {
+		return
+	}
+
+	sendFieldChanges(w.fieldChange, changes)
+}
+
 // uniqueStrings 字符串去重
 func uniqueStrings(strs []string) []string {
 	seen := make(map[string]bool)
@@ -488,11 +549,15 @@ type WorkerManager struct {
 	config        *config.Config
 	semaphore     chan struct{} // 并发控制信号量
 	statusCh      chan StatusChangeEvent
-	queryRecorder func(string) // 查询记录函数
+	fieldCh       chan FieldChangeEvent
+	checkedCh     chan *DomainInfo
+	health        *HealthTracker
+	events        *eventsink.Sink // 事件流导出
+	queryRecorder func(string)    // 查询记录函数
 }
 
 // NewWorkerManager 创建worker管理器
-func NewWorkerManager(checker *DomainChecker, cfg *config.Config, statusCh chan StatusChangeEvent, queryRecorder func(string)) *WorkerManager {
+func NewWorkerManager(checker *DomainChecker, cfg *config.Config, statusCh chan StatusChangeEvent, fieldCh chan FieldChangeEvent, checkedCh chan *DomainInfo, health *HealthTracker, queryRecorder func(string)) *WorkerManager {
 	// 创建信号量，容量为并发限制
 	semaphore := make(chan struct{}, cfg.Monitor.ConcurrentLimit)
 
@@ -502,6 +567,9 @@ func NewWorkerManager(checker *DomainChecker, cfg *config.Config, statusCh chan
 		config:        cfg,
 		semaphore:     semaphore,
 		statusCh:      statusCh,
+		fieldCh:       fieldCh,
+		checkedCh:     checkedCh,
+		health:        health,
 		queryRecorder: queryRecorder,
 	}
 }
@@ -520,7 +588,8 @@ func (m *WorkerManager) AddWorker(domain string, notify bool) {
 	}
 
 	// 创建新worker
-	worker := NewDomainWorker(domain, m.checker, m.config, m.semaphore, m.statusCh, notify, m.queryRecorder)
+	worker := NewDomainWorker(domain, m.checker, m.config, m.semaphore, m.statusCh, m.fieldCh, m.checkedCh, m.health, notify, m.queryRecorder)
+	worker.events = m.events
 	m.workers[domain] = worker
 
 	// 启动worker
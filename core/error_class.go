@@ -0,0 +1,64 @@
+package core
+
+import "strings"
+
+// 查询错误分类（稳定的错误码，用于历史记录、告警与路由）
+const (
+	ErrorClassNone           = ""
+	ErrorClassTimeout        = "timeout"
+	ErrorClassRateLimited    = "rate_limited"
+	ErrorClassConnection     = "connection"
+	ErrorClassDNS            = "dns"
+	ErrorClassUnsupportedTLD = "unsupported_tld"
+	ErrorClassEmptyResponse  = "empty_response"
+	ErrorClassHTTP           = "http_error"
+	ErrorClassParse          = "parse_error"
+	ErrorClassCancelled      = "cancelled"
+	ErrorClassUnknown        = "unknown"
+)
+
+// ErrorClasses 可用于路由规则的错误分类
+var ErrorClasses = []string{
+	ErrorClassTimeout, ErrorClassRateLimited, ErrorClassConnection, ErrorClassDNS, ErrorClassUnsupportedTLD,
+	ErrorClassEmptyResponse, ErrorClassHTTP, ErrorClassParse, ErrorClassUnknown,
+}
+
+// ClassifyError 根据错误信息归类查询错误
+func ClassifyError(message string) string {
+	if strings.TrimSpace(message) == "" {
+		return ErrorClassNone
+	}
+
+	msg := strings.ToLower(message)
+	contains := func(keywords ...string) bool {
+		for _, k := range keywords {
+			if strings.Contains(msg, k) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("查询被取消", "context canceled"):
+		return ErrorClassCancelled
+	case contains("429", "限流", "rate limit", "too many", "quota", "limit exceeded"):
+		return ErrorClassRateLimited
+	case contains("timeout", "deadline exceeded", "超时"):
+		return ErrorClassTimeout
+	case contains("no such host", "dns"):
+		return ErrorClassDNS
+	case contains("不支持的tld", "缺少rdap服务器"):
+		return ErrorClassUnsupportedTLD
+	case contains("空响应", "响应过短"):
+		return ErrorClassEmptyResponse
+	case contains("connection refused", "connection reset", "连接", "eof", "network is unreachable"):
+		return ErrorClassConnection
+	case contains("状态码"):
+		return ErrorClassHTTP
+	case contains("解析"):
+		return ErrorClassParse
+	default:
+		return ErrorClassUnknown
+	}
+}
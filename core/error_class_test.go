@@ -0,0 +1,25 @@
+package core
+
+import "testing"
+
+func TestClassifyError(t *testing.T) {
+	tests := map[string]string{
+		"":                                   ErrorClassNone,
+		"查询被取消":                              ErrorClassCancelled,
+		"RDAP请求失败: 状态码 429":                  ErrorClassRateLimited,
+		"whois: i/o timeout":                 ErrorClassTimeout,
+		"context deadline exceeded":          ErrorClassTimeout,
+		"dial tcp: lookup x: no such host":   ErrorClassDNS,
+		"不支持的TLD: .zz":                       ErrorClassUnsupportedTLD,
+		"WHOIS空响应":                           ErrorClassEmptyResponse,
+		"read tcp: connection reset by peer": ErrorClassConnection,
+		"RDAP请求失败: 状态码 503":                  ErrorClassHTTP,
+		"解析RDAP响应失败":                         ErrorClassParse,
+		"something odd":                      ErrorClassUnknown,
+	}
+	for message, want := range tests {
+		if got := ClassifyError(message); got != want {
+			t.Errorf("ClassifyError(%q) = %q，期望 %q", message, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"Puff/eventsink"
+	"Puff/logger"
+)
+
+// SetEventSink 设置事件流导出：每次查询后在通知过滤之前导出状态与字段变化
+func (m *Monitor) SetEventSink(sink *eventsink.Sink) {
+	m.events = sink
+	m.workerManager.SetEventSink(sink)
+}
+
+// SetEventSink 设置各 worker 使用的事件流导出
+func (m *WorkerManager) SetEventSink(sink *eventsink.Sink) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = sink
+	for _, worker := range m.workers {
+		worker.events = sink
+	}
+}
+
+// publishChanges 导出本次查询的状态变化与全部字段变化；
+// 与通知无关：不受域名通知开关、变化订阅、状态是否需要通知等过滤影响
+func publishChanges(sink *eventsink.Sink, previousStatus DomainStatus, info *DomainInfo, changes []FieldChangeEvent) {
+	if !sink.Enabled() || info == nil {
+		return
+	}
+
+	if previousStatus != StatusUnknown && previousStatus != info.Status {
+		sink.Publish(eventsink.Event{
+			Time:       info.LastChecked,
+			Type:       eventsink.TypeStatusChange,
+			Domain:     info.Name,
+			Status:     string(info.Status),
+			OldStatus:  string(previousStatus),
+			ErrorClass: ClassifyError(info.ErrorMessage),
+			Message:    GetStatusChangeMessage(info.Name, previousStatus, info.Status),
+		})
+	}
+
+	for _, change := range changes {
+		sink.Publish(eventsink.Event{
+			Time:     change.Timestamp,
+			Type:     eventsink.TypeFieldChange,
+			Domain:   change.Domain,
+			Status:   string(info.Status),
+			Change:   change.Type,
+			Field:    change.Field,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+			Message:  change.Message,
+		})
+	}
+}
+
+// emitChecked 发送本次查询结果，队列已满时丢弃（下次查询会再次同步）
+func emitChecked(ch chan<- *DomainInfo, info *DomainInfo) {
+	if ch == nil || info == nil {
+		return
+	}
+	select {
+	case ch <- info:
+	default:
+		logger.Debug("查询结果队列已满，跳过同步: %s", info.Name)
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/eventsink"
+	"Puff/storage"
+)
+
+// fileSink 写入临时 JSONL 文件的事件流导出
+func fileSink(t *testing.T) (*eventsink.Sink, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := eventsink.New()
+	if err := sink.Configure(config.EventsConfig{FileEnabled: true, FilePath: path, FileMaxSize: 1, FileMaxBackups: 1}); err != nil {
+		t.Fatal(err)
+	}
+	return sink, path
+}
+
+// readSinkEvents 停止导出并读取已写入的事件
+func readSinkEvents(t *testing.T, sink *eventsink.Sink, path string) []eventsink.Event {
+	t.Helper()
+	sink.Stop()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var events []eventsink.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event eventsink.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestPublishChangesIgnoresNotificationFilters(t *testing.T) {
+	sink, path := fileSink(t)
+	expiry := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := &storage.DomainResult{
+		Domain:      "example.com",
+		Status:      string(StatusRegistered),
+		QueryMethod: "rdap",
+		Registrar:   "Old Registrar",
+		NameServers: []string{"ns1.old.net"},
+	}
+	info := &DomainInfo{
+		Name:        "example.com",
+		Status:      StatusRegistered,
+		QueryMethod: "rdap",
+		Registrar:   "New Registrar",
+		NameServers: []string{"ns1.old.net"},
+		ExpiryDate:  &expiry,
+		LastChecked: time.Now(),
+	}
+
+	// 从查询失败恢复：通知会跳过，事件流仍需记录
+	publishChanges(sink, StatusError, info, DetectFieldChanges(previous, info))
+	// 首次查询（无前一状态）不产生状态变化
+	publishChanges(sink, StatusUnknown, &DomainInfo{Name: "new.com", Status: StatusAvailable}, nil)
+
+	events := readSinkEvents(t, sink, path)
+	if len(events) != 2 {
+		t.Fatalf("期望 2 条事件，实际 %d 条: %+v", len(events), events)
+	}
+	if e := events[0]; e.Type != eventsink.TypeStatusChange || e.OldStatus != "error" || e.Status != "registered" {
+		t.Errorf("状态变化事件不正确: %+v", e)
+	}
+	if e := events[1]; e.Type != eventsink.TypeFieldChange || e.Change != ChangeRegistrar || e.NewValue != "New Registrar" {
+		t.Errorf("字段变化事件不正确: %+v", e)
+	}
+}
+
+func TestPublishChangesDisabledSink(t *testing.T) {
+	// 未配置导出时不应出错
+	publishChanges(nil, StatusRegistered, &DomainInfo{Name: "example.com", Status: StatusAvailable}, nil)
+	publishChanges(eventsink.New(), StatusRegistered, &DomainInfo{Name: "example.com", Status: StatusAvailable}, nil)
+}
+
+func TestDetectFieldChanges(t *testing.T) {
+	oldExpiry := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	newExpiry := oldExpiry.AddDate(1, 0, 0)
+	previous := &storage.DomainResult{
+		Status:      string(StatusRegistered),
+		QueryMethod: "whois",
+		Registrar:   "Example Registrar",
+		NameServers: []string{"NS1.EXAMPLE.NET.", "ns2.example.net"},
+		StatusCodes: []string{"clientTransferProhibited"},
+		ExpiryAt:    &oldExpiry,
+	}
+
+	tests := []struct {
+		name string
+		info DomainInfo
+		want []string
+	}{
+		{"无变化（大小写、顺序与末尾点不同）", DomainInfo{Status: StatusRegistered, QueryMethod: "whois", Registrar: "example registrar",
+			NameServers: []string{"ns2.example.net", "ns1.example.net"}, StatusCodes: []string{"clientTransferProhibited"}, ExpiryDate: &oldExpiry}, nil},
+		{"续费与换 DNS", DomainInfo{Status: StatusRegistered, QueryMethod: "whois", Registrar: "Example Registrar",
+			NameServers: []string{"a.dns.com"}, StatusCodes: []string{"clientTransferProhibited"}, ExpiryDate: &newExpiry}, []string{ChangeNameServers, ChangeExpiry}},
+		{"EPP 状态码变化", DomainInfo{Status: StatusRegistered, QueryMethod: "whois", StatusCodes: []string{"ok"}}, []string{ChangeEPPStatus}},
+		{"查询方式不同不比较", DomainInfo{Status: StatusRegistered, QueryMethod: "rdap", Registrar: "Other"}, nil},
+		{"查询失败不比较", DomainInfo{Status: StatusError, QueryMethod: "whois", Registrar: "Other"}, nil},
+		{"不支持的注册商提示不算变化", DomainInfo{Status: StatusRegistered, QueryMethod: "whois", Registrar: "该后缀不支持注册商信息"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.info.Name = "example.com"
+			var got []string
+			for _, change := range DetectFieldChanges(previous, &tt.info) {
+				got = append(got, change.Type)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("变化 = %v, 期望 %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("变化 = %v, 期望 %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// 字段变化事件类型
+const (
+	ChangeRegistrar   = "registrar_change"
+	ChangeNameServers = "nameserver_change"
+	ChangeExpiry      = "expiry_change"
+	ChangeEPPStatus   = "epp_status_change"
+)
+
+// FieldChangeTypes 所有支持订阅的字段变化类型
+var FieldChangeTypes = []string{ChangeRegistrar, ChangeNameServers, ChangeExpiry, ChangeEPPStatus}
+
+// IsFieldChangeType 判断是否为有效的字段变化类型
+func IsFieldChangeType(changeType string) bool {
+	for _, t := range FieldChangeTypes {
+		if t == changeType {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldChangeEvent 字段变化事件（注册商、名称服务器、过期时间、EPP状态码）
+type FieldChangeEvent struct {
+	Domain     string      `json:"domain"`
+	Type       string      `json:"type"`
+	Field      string      `json:"field"`
+	OldValue   string      `json:"old_value"`
+	NewValue   string      `json:"new_value"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Message    string      `json:"message"`
+	DomainInfo *DomainInfo `json:"domain_info,omitempty"`
+}
+
+// GetFieldLabel 获取字段变化类型的显示名称（默认语言）
+func GetFieldLabel(changeType string) string {
+	return GetFieldLabelFor(i18n.Default, changeType)
+}
+
+// GetFieldLabelFor 获取字段变化类型在指定语言下的显示名称
+func GetFieldLabelFor(locale, changeType string) string {
+	if !IsFieldChangeType(changeType) {
+		return changeType
+	}
+	return i18n.T(locale, "field."+changeType)
+}
+
+// GetFieldChangeMessage 获取字段变化消息（默认语言）
+func GetFieldChangeMessage(domain, changeType, oldValue, newValue string) string {
+	return GetFieldChangeMessageFor(i18n.Default, domain, changeType, oldValue, newValue)
+}
+
+// GetFieldChangeMessageFor 获取指定语言的字段变化消息
+func GetFieldChangeMessageFor(locale, domain, changeType, oldValue, newValue string) string {
+	return i18n.T(locale, "field.change_message", domain, GetFieldLabelFor(locale, changeType), oldValue, newValue)
+}
+
+// DetectFieldChanges 逐字段比较上次保存的结果与本次查询结果
+// 仅在两次查询均成功获取注册信息且查询方式相同时比较，避免 RDAP/WHOIS 格式差异造成误报
+func DetectFieldChanges(prev *storage.DomainResult, info *DomainInfo) []FieldChangeEvent {
+	if prev == nil || info == nil {
+		return nil
+	}
+	if !hasRegistrationData(DomainStatus(prev.Status)) || !hasRegistrationData(info.Status) {
+		return nil
+	}
+	if prev.QueryMethod != info.QueryMethod {
+		return nil
+	}
+
+	now := time.Now()
+	var events []FieldChangeEvent
+	add := func(changeType, oldValue, newValue string) {
+		events = append(events, FieldChangeEvent{
+			Domain:     info.Name,
+			Type:       changeType,
+			Field:      GetFieldLabel(changeType),
+			OldValue:   oldValue,
+			NewValue:   newValue,
+			Timestamp:  now,
+			Message:    GetFieldChangeMessage(info.Name, changeType, oldValue, newValue),
+			DomainInfo: info,
+		})
+	}
+
+	// 注册商
+	oldRegistrar := normalizeRegistrar(prev.Registrar)
+	newRegistrar := normalizeRegistrar(info.Registrar)
+	if oldRegistrar != "" && newRegistrar != "" && !strings.EqualFold(oldRegistrar, newRegistrar) {
+		add(ChangeRegistrar, oldRegistrar, newRegistrar)
+	}
+
+	// 名称服务器
+	oldNS := normalizeSet(prev.NameServers, true)
+	newNS := normalizeSet(info.NameServers, true)
+	if oldNS != "" && newNS != "" && oldNS != newNS {
+		add(ChangeNameServers, oldNS, newNS)
+	}
+
+	// 过期时间
+	if prev.ExpiryAt != nil && info.ExpiryDate != nil &&
+		!prev.ExpiryAt.Truncate(time.Second).Equal(info.ExpiryDate.Truncate(time.Second)) {
+		add(ChangeExpiry, prev.ExpiryAt.Format("2006-01-02"), info.ExpiryDate.Format("2006-01-02"))
+	}
+
+	// EPP状态码
+	oldCodes := normalizeSet(prev.StatusCodes, false)
+	newCodes := normalizeSet(info.StatusCodes, false)
+	if oldCodes != "" && newCodes != "" && oldCodes != newCodes {
+		add(ChangeEPPStatus, oldCodes, newCodes)
+	}
+
+	return events
+}
+
+// sendFieldChanges 将已订阅的字段变化事件非阻塞地发送到通知通道
+func sendFieldChanges(ch chan<- FieldChangeEvent, changes []FieldChangeEvent) {
+	for _, event := range changes {
+		logger.Info("检测到%s", event.Message)
+
+		subscribed, err := storage.IsChangeSubscribed(event.Domain, event.Type)
+		if err != nil {
+			logger.Error("读取域名 %s 的变化订阅失败: %v", event.Domain, err)
+			continue
+		}
+		if !subscribed {
+			continue
+		}
+
+		select {
+		case ch <- event:
+			logger.Info("域名 %s 字段变化通知已发送: %s", event.Domain, event.Type)
+		default:
+			logger.Warn("通知队列已满，丢弃域名 %s 的字段变化通知", event.Domain)
+		}
+	}
+}
+
+// hasRegistrationData 判断该状态下查询结果是否包含注册信息
+func hasRegistrationData(status DomainStatus) bool {
+	switch status {
+	case StatusError, StatusUnknown, StatusAvailable:
+		return false
+	default:
+		return true
+	}
+}
+
+// normalizeRegistrar 清理注册商名称（忽略“不支持”提示）
+func normalizeRegistrar(registrar string) string {
+	registrar = strings.TrimSpace(registrar)
+	if strings.Contains(registrar, "不支持") {
+		return ""
+	}
+	return registrar
+}
+
+// normalizeSet 将列表去重排序后拼接，用于无序比较
+func normalizeSet(values []string, lower bool) string {
+	seen := make(map[string]bool)
+	var items []string
+	for _, v := range values {
+		v = strings.TrimSuffix(strings.TrimSpace(v), ".")
+		if lower {
+			v = strings.ToLower(v)
+		}
+		if v != "" && !seen[v] {
+			seen[v] = true
+			items = append(items, v)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+func TestSendFieldChangesSubscriptions(t *testing.T) {
+	if err := storage.AddChangeSubscription("sub-a.com", ChangeRegistrar); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AddChangeSubscription("sub-b.com", storage.GlobalScope); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []FieldChangeEvent{
+		{Domain: "sub-a.com", Type: ChangeRegistrar},
+		{Domain: "sub-a.com", Type: ChangeExpiry},      // 未订阅该类型
+		{Domain: "sub-b.com", Type: ChangeNameServers}, // 订阅了全部类型
+		{Domain: "sub-c.com", Type: ChangeRegistrar},   // 未订阅
+	}
+	ch := make(chan FieldChangeEvent, 10)
+	sendFieldChanges(ch, changes)
+	close(ch)
+
+	var got []string
+	for event := range ch {
+		got = append(got, event.Domain+"|"+event.Type)
+	}
+	want := []string{"sub-a.com|" + ChangeRegistrar, "sub-b.com|" + ChangeNameServers}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("发送的变化 = %v，期望 %v", got, want)
+	}
+
+	// 队列已满时丢弃而不阻塞
+	full := make(chan FieldChangeEvent)
+	sendFieldChanges(full, changes[:1])
+}
+
+func TestFieldChangeMessageLocalized(t *testing.T) {
+	if got := GetFieldChangeMessageFor(i18n.En, "a.com", ChangeRegistrar, "Old", "New"); got != "Domain a.com: Registrar changed from [Old] to [New]" {
+		t.Errorf("英文消息 = %q", got)
+	}
+	if got := GetFieldChangeMessage("a.com", ChangeExpiry, "2026-01-01", "2027-01-01"); got != "域名 a.com 的过期时间发生变化：从 [2026-01-01] 变为 [2027-01-01]" {
+		t.Errorf("默认语言消息 = %q", got)
+	}
+	if got := GetFieldLabelFor(i18n.En, "unknown_change"); got != "unknown_change" {
+		t.Errorf("未知类型应原样返回: %q", got)
+	}
+}
+
+func TestDomainNotify(t *testing.T) {
+	for domain, notify := range map[string]bool{"notify-on.com": true, "notify-off.com": false} {
+		if err := storage.AddDomain(domain, true, notify); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { storage.RemoveDomain(domain) })
+	}
+
+	// 手动检查与 worker 一致：静音或不在监控列表中的域名不发送通知
+	for domain, want := range map[string]bool{"notify-on.com": true, "notify-off.com": false, "notify-none.com": false} {
+		if got := domainNotify(domain); got != want {
+			t.Errorf("domainNotify(%s) = %v，期望 %v", domain, got, want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// 查询健康事件类型
+const (
+	HealthLookupFailure   = "health_lookup_failure"   // 域名连续查询失败
+	HealthLookupRecovered = "health_lookup_recovered" // 域名查询恢复
+	HealthServerDown      = "health_server_down"      // WHOIS/RDAP 服务器大面积失败
+	HealthServerRecovered = "health_server_recovered" // 服务器恢复
+)
+
+// HealthEventTypes 所有查询健康事件类型（可用于通知路由规则）
+var HealthEventTypes = []string{HealthLookupFailure, HealthLookupRecovered, HealthServerDown, HealthServerRecovered}
+
+// HealthEvent 查询健康事件（发送到管理员通道）
+type HealthEvent struct {
+	Type       string    `json:"type"`
+	Domain     string    `json:"domain,omitempty"`
+	Server     string    `json:"server,omitempty"`
+	Failures   int       `json:"failures,omitempty"`    // 域名连续失败次数
+	Failed     int       `json:"failed,omitempty"`      // 窗口内失败的域名数
+	Total      int       `json:"total,omitempty"`       // 窗口内查询的域名数
+	Window     int       `json:"window,omitempty"`      // 统计窗口（分钟）
+	Error      string    `json:"error,omitempty"`       // 最近一次查询错误
+	ErrorClass string    `json:"error_class,omitempty"` // 最近一次查询错误的分类
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// CheckFailureEvent 单次查询失败事件（供事件流导出）
+type CheckFailureEvent struct {
+	Domain     string    `json:"domain"`
+	Server     string    `json:"server,omitempty"`
+	Error      string    `json:"error"`
+	ErrorClass string    `json:"error_class"`
+	Failures   int       `json:"failures"` // 含本次在内的连续失败次数
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// healthCheck 域名在窗口内的最近一次查询结果
+type healthCheck struct {
+	failed bool
+	at     time.Time
+}
+
+// serverHealth 单个查询服务器的统计
+type serverHealth struct {
+	checks map[string]healthCheck
+	down   bool
+}
+
+// HealthTracker 跟踪域名连续失败与查询服务器失败率
+type HealthTracker struct {
+	mu       sync.Mutex
+	servers  map[string]*serverHealth
+	events   chan HealthEvent
+	failures chan CheckFailureEvent
+}
+
+// NewHealthTracker 创建健康跟踪器
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		servers:  make(map[string]*serverHealth),
+		events:   make(chan HealthEvent, 100),
+		failures: make(chan CheckFailureEvent, 1000),
+	}
+}
+
+// LookupServer 返回域名使用的查询服务器标识（RDAP 与 WHOIS 主机），不支持的后缀返回空
+func LookupServer(domain string) string {
+	var parts []string
+	if srv, ok := config.GetRDAPServerByTLD(domain); ok {
+		host := srv.Server
+		if u, err := url.Parse(srv.Server); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		parts = append(parts, host)
+	}
+	if srv, ok := config.GetWhoisServerByTLD(domain); ok {
+		parts = append(parts, srv.Server)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// Record 记录一次查询结果；previous 为查询前的数据库记录
+func (h *HealthTracker) Record(cfg config.HealthConfig, previous *storage.DomainResult, info *DomainInfo) {
+	if h == nil || info == nil {
+		return
+	}
+	failed := info.Status == StatusError
+	if failed && ClassifyError(info.ErrorMessage) == ErrorClassCancelled {
+		return
+	}
+
+	now := time.Now()
+	// 查询失败事件与健康告警是否启用无关
+	if failed {
+		h.emitFailure(previous, info, now)
+	}
+	if !cfg.Enabled {
+		return
+	}
+	h.recordDomain(cfg, previous, info, failed, now)
+	h.recordServer(cfg, info.Name, failed, now)
+}
+
+// recordDomain 连续失败次数达到阈值时告警，恢复时通知
+func (h *HealthTracker) recordDomain(cfg config.HealthConfig, previous *storage.DomainResult, info *DomainInfo, failed bool, now time.Time) {
+	before := 0
+	if previous != nil {
+		before = previous.FailureCount
+	}
+	threshold := cfg.FailureThreshold
+
+	switch {
+	case failed && before+1 >= threshold && before < threshold:
+		h.emit(HealthEvent{
+			Type:       HealthLookupFailure,
+			Domain:     info.Name,
+			Server:     LookupServer(info.Name),
+			Failures:   before + 1,
+			Error:      info.ErrorMessage,
+			ErrorClass: ClassifyError(info.ErrorMessage),
+			Message:    fmt.Sprintf("域名 %s 已连续 %d 次查询失败，当前未被有效监控: %s", info.Name, before+1, info.ErrorMessage),
+			Timestamp:  now,
+		})
+	case !failed && before >= threshold:
+		h.emit(HealthEvent{
+			Type:      HealthLookupRecovered,
+			Domain:    info.Name,
+			Server:    LookupServer(info.Name),
+			Failures:  before,
+			Message:   fmt.Sprintf("域名 %s 查询已恢复（此前连续失败 %d 次）", info.Name, before),
+			Timestamp: now,
+		})
+	}
+}
+
+// recordServer 统计窗口内服务器的失败占比，超过阈值时告警，回落时通知
+func (h *HealthTracker) recordServer(cfg config.HealthConfig, domain string, failed bool, now time.Time) {
+	server := LookupServer(domain)
+	if server == "" {
+		return
+	}
+
+	h.mu.Lock()
+	stats, ok := h.servers[server]
+	if !ok {
+		stats = &serverHealth{checks: make(map[string]healthCheck)}
+		h.servers[server] = stats
+	}
+	stats.checks[domain] = healthCheck{failed: failed, at: now}
+
+	cutoff := now.Add(-time.Duration(cfg.ServerWindow) * time.Minute)
+	total, failures := 0, 0
+	for d, c := range stats.checks {
+		if c.at.Before(cutoff) {
+			delete(stats.checks, d)
+			continue
+		}
+		total++
+		if c.failed {
+			failures++
+		}
+	}
+
+	unhealthy := total >= cfg.ServerMinDomains && failures*100 >= total*cfg.ServerFailurePct
+	var event *HealthEvent
+	switch {
+	case unhealthy && !stats.down:
+		stats.down = true
+		event = &HealthEvent{
+			Type:    HealthServerDown,
+			Message: fmt.Sprintf("查询服务器 %s 最近 %d 分钟内 %d/%d 个域名查询失败", server, cfg.ServerWindow, failures, total),
+		}
+	case !unhealthy && stats.down && failures*100 < total*cfg.ServerFailurePct:
+		stats.down = false
+		event = &HealthEvent{
+			Type:    HealthServerRecovered,
+			Message: fmt.Sprintf("查询服务器 %s 已恢复，最近 %d 分钟内 %d/%d 个域名查询失败", server, cfg.ServerWindow, failures, total),
+		}
+	}
+	h.mu.Unlock()
+
+	if event != nil {
+		event.Server = server
+		event.Failed = failures
+		event.Total = total
+		event.Window = cfg.ServerWindow
+		event.Timestamp = now
+		h.emit(*event)
+	}
+}
+
+// emitFailure 发送查询失败事件，队列已满时丢弃
+func (h *HealthTracker) emitFailure(previous *storage.DomainResult, info *DomainInfo, now time.Time) {
+	failures := 1
+	if previous != nil {
+		failures = previous.FailureCount + 1
+	}
+	event := CheckFailureEvent{
+		Domain:     info.Name,
+		Server:     LookupServer(info.Name),
+		Error:      info.ErrorMessage,
+		ErrorClass: ClassifyError(info.ErrorMessage),
+		Failures:   failures,
+		Timestamp:  now,
+	}
+	select {
+	case h.failures <- event:
+	default:
+		logger.Warn("查询失败事件队列已满，丢弃: %s", info.Name)
+	}
+}
+
+// emit 发送健康事件，队列已满时丢弃
+func (h *HealthTracker) emit(event HealthEvent) {
+	select {
+	case h.events <- event:
+		logger.Warn("%s", event.Message)
+	default:
+		logger.Warn("通知队列已满，丢弃健康告警: %s", event.Message)
+	}
+}
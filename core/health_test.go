@@ -0,0 +1,164 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/storage"
+)
+
+// drainHealth 取出跟踪器中已产生的健康事件
+func drainHealth(h *HealthTracker) []HealthEvent {
+	var events []HealthEvent
+	for {
+		select {
+		case event := <-h.events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// failedInfo 查询失败的域名信息
+func failedInfo(domain, message string) *DomainInfo {
+	return &DomainInfo{Name: domain, Status: StatusError, ErrorMessage: message}
+}
+
+func TestLookupServer(t *testing.T) {
+	tests := []struct {
+		domain, want string
+	}{
+		{"example.com", "rdap.verisign.com / whois.verisign-grs.com"},
+		{"EXAMPLE.NET", "rdap.verisign.com / whois.verisign-grs.com"},
+		{"example.io", "whois.nic.io"},
+		{"example.notatld", ""},
+	}
+	for _, tt := range tests {
+		if got := LookupServer(tt.domain); got != tt.want {
+			t.Errorf("LookupServer(%s) = %q, 期望 %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestHealthDomainThreshold(t *testing.T) {
+	h := NewHealthTracker()
+	cfg := config.HealthConfig{Enabled: true, FailureThreshold: 3, ServerWindow: 30, ServerFailurePct: 50, ServerMinDomains: 100}
+	previous := func(failures int) *storage.DomainResult {
+		return &storage.DomainResult{Domain: "flaky.io", FailureCount: failures}
+	}
+
+	// 未达到阈值不告警，达到时告警一次，之后继续失败不重复
+	for _, before := range []int{0, 1} {
+		h.Record(cfg, previous(before), failedInfo("flaky.io", "i/o timeout"))
+	}
+	if events := drainHealth(h); len(events) != 0 {
+		t.Fatalf("未达到阈值不应告警: %+v", events)
+	}
+	h.Record(cfg, previous(2), failedInfo("flaky.io", "i/o timeout"))
+	events := drainHealth(h)
+	if len(events) != 1 {
+		t.Fatalf("达到阈值应告警一次: %+v", events)
+	}
+	if e := events[0]; e.Type != HealthLookupFailure || e.Domain != "flaky.io" || e.Server != "whois.nic.io" || e.Failures != 3 ||
+		e.ErrorClass != ClassifyError("i/o timeout") || !strings.Contains(e.Message, "已连续 3 次查询失败") {
+		t.Errorf("告警内容错误: %+v", e)
+	}
+	h.Record(cfg, previous(3), failedInfo("flaky.io", "i/o timeout"))
+	if events := drainHealth(h); len(events) != 0 {
+		t.Errorf("超过阈值后不应重复告警: %+v", events)
+	}
+
+	// 恢复时通知，未曾告警的恢复不通知
+	h.Record(cfg, previous(4), &DomainInfo{Name: "flaky.io", Status: StatusRegistered})
+	if events := drainHealth(h); len(events) != 1 || events[0].Type != HealthLookupRecovered || events[0].Failures != 4 {
+		t.Errorf("恢复时应通知: %+v", events)
+	}
+	h.Record(cfg, previous(2), &DomainInfo{Name: "flaky.io", Status: StatusRegistered})
+	if events := drainHealth(h); len(events) != 0 {
+		t.Errorf("未达到阈值的恢复不应通知: %+v", events)
+	}
+}
+
+func TestHealthServerFailureRate(t *testing.T) {
+	h := NewHealthTracker()
+	cfg := config.HealthConfig{Enabled: true, FailureThreshold: 100, ServerWindow: 30, ServerFailurePct: 50, ServerMinDomains: 4}
+	now := time.Now()
+
+	// 域名数不足时不判定
+	h.recordServer(cfg, "a.net", true, now)
+	h.recordServer(cfg, "b.net", true, now)
+	h.recordServer(cfg, "c.net", false, now)
+	if events := drainHealth(h); len(events) != 0 {
+		t.Fatalf("域名数不足时不应告警: %+v", events)
+	}
+
+	// 失败占比达到阈值时告警一次
+	h.recordServer(cfg, "d.net", false, now)
+	events := drainHealth(h)
+	if len(events) != 1 {
+		t.Fatalf("失败占比达到阈值应告警: %+v", events)
+	}
+	if e := events[0]; e.Type != HealthServerDown || e.Server != "rdap.verisign.com / whois.verisign-grs.com" || e.Failed != 2 || e.Total != 4 || e.Window != 30 {
+		t.Errorf("服务器告警内容错误: %+v", e)
+	}
+	h.recordServer(cfg, "e.net", true, now)
+	if events := drainHealth(h); len(events) != 0 {
+		t.Errorf("已告警的服务器不应重复告警: %+v", events)
+	}
+
+	// 同一域名只计最近一次结果，失败占比回落后通知恢复
+	h.recordServer(cfg, "a.net", false, now)
+	if events := drainHealth(h); len(events) != 1 || events[0].Type != HealthServerRecovered || events[0].Failed != 2 || events[0].Total != 5 {
+		t.Errorf("失败占比回落应通知恢复: %+v", events)
+	}
+
+	// 窗口外的结果不再计入
+	later := now.Add(31 * time.Minute)
+	for _, domain := range []string{"f.net", "g.net", "h.net"} {
+		h.recordServer(cfg, domain, true, later)
+	}
+	if events := drainHealth(h); len(events) != 0 {
+		t.Errorf("窗口外的结果不应计入: %+v", events)
+	}
+
+	// 不支持的后缀不统计
+	h.recordServer(config.HealthConfig{ServerWindow: 30, ServerFailurePct: 1}, "x.notatld", true, now)
+	if len(h.servers) != 1 {
+		t.Errorf("不支持的后缀不应统计: %v", h.servers)
+	}
+}
+
+func TestHealthRecordFailureEvents(t *testing.T) {
+	h := NewHealthTracker()
+
+	// 健康告警未启用时仍产生查询失败事件
+	h.Record(config.HealthConfig{}, &storage.DomainResult{FailureCount: 4}, failedInfo("fail.com", "connection refused"))
+	select {
+	case event := <-h.failures:
+		if event.Domain != "fail.com" || event.Failures != 5 || event.Server == "" || event.ErrorClass != ClassifyError("connection refused") {
+			t.Errorf("查询失败事件错误: %+v", event)
+		}
+	default:
+		t.Fatal("应产生查询失败事件")
+	}
+	if events := drainHealth(h); len(events) != 0 || len(h.servers) != 0 {
+		t.Errorf("未启用时不应统计健康状态: %+v", events)
+	}
+
+	// 被取消的查询不计入
+	h.Record(config.HealthConfig{Enabled: true, FailureThreshold: 1}, nil, failedInfo("fail.com", "context canceled"))
+	select {
+	case event := <-h.failures:
+		t.Errorf("被取消的查询不应产生事件: %+v", event)
+	default:
+	}
+	if events := drainHealth(h); len(events) != 0 {
+		t.Errorf("被取消的查询不应告警: %+v", events)
+	}
+
+	var nilTracker *HealthTracker
+	nilTracker.Record(config.HealthConfig{Enabled: true}, nil, failedInfo("fail.com", "x"))
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// StatusHistoryEvent 状态历史事件
+type StatusHistoryEvent struct {
+	Status       DomainStatus `json:"status"`
+	OldStatus    DomainStatus `json:"old_status"`
+	Changed      bool         `json:"changed"`
+	QueryMethod  string       `json:"query_method"`
+	ErrorCode    string       `json:"error_code,omitempty"`
+	ErrorMessage string       `json:"error_message,omitempty"`
+	CheckedAt    time.Time    `json:"checked_at"`
+}
+
+// TimelineSegment 时间线区间（域名处于某一状态的一段时间）
+type TimelineSegment struct {
+	Status          DomainStatus `json:"status"`
+	Start           time.Time    `json:"start"`
+	End             *time.Time   `json:"end"` // 为空表示当前仍处于该状态
+	DurationSeconds int64        `json:"duration_seconds"`
+	Duration        string       `json:"duration"`
+}
+
+// DomainTimeline 域名生命周期时间线
+type DomainTimeline struct {
+	Domain        string                 `json:"domain"`
+	CurrentStatus DomainStatus           `json:"current_status"`
+	Segments      []TimelineSegment      `json:"segments"`
+	TimeInStatus  map[DomainStatus]int64 `json:"time_in_status"` // 各状态累计时长（秒）
+	Events        []StatusHistoryEvent   `json:"events"`
+}
+
+// recordStatusHistory 记录状态历史：状态变化时必定写入，开启全量记录时每次查询都写入
+func recordStatusHistory(recordAll bool, previousStatus DomainStatus, info *DomainInfo) {
+	if info == nil {
+		return
+	}
+
+	changed := previousStatus != info.Status
+	if !changed && !recordAll {
+		return
+	}
+
+	entry := storage.StatusHistoryEntry{
+		Domain:       info.Name,
+		Status:       string(info.Status),
+		OldStatus:    string(previousStatus),
+		Changed:      changed,
+		QueryMethod:  info.QueryMethod,
+		ErrorCode:    ClassifyError(info.ErrorMessage),
+		ErrorMessage: info.ErrorMessage,
+		CheckedAt:    info.LastChecked,
+	}
+
+	if err := storage.SaveStatusHistory(entry); err != nil {
+		logger.Error("保存域名 %s 状态历史失败: %v", info.Name, err)
+	}
+}
+
+// GetDomainHistory 获取域名状态历史与时间线
+// limit 限制返回的事件条数，includeChecks 为 true 时事件中包含未发生状态变化的查询记录
+func (m *Monitor) GetDomainHistory(domain string, limit int, includeChecks bool) (*DomainTimeline, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	// 时间线只依赖状态变化记录，需要完整读取
+	changes, err := storage.ListStatusHistory(domain, true, 0)
+	if err != nil {
+		return nil, fmt.Errorf("读取状态历史失败: %v", err)
+	}
+
+	events := changes
+	if includeChecks {
+		events, err = storage.ListStatusHistory(domain, false, limit)
+		if err != nil {
+			return nil, fmt.Errorf("读取状态历史失败: %v", err)
+		}
+	} else if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+
+	timeline := BuildTimeline(domain, changes, time.Now())
+	timeline.Events = make([]StatusHistoryEvent, 0, len(events))
+	for _, e := range events {
+		timeline.Events = append(timeline.Events, StatusHistoryEvent{
+			Status:       DomainStatus(e.Status),
+			OldStatus:    DomainStatus(e.OldStatus),
+			Changed:      e.Changed,
+			QueryMethod:  e.QueryMethod,
+			ErrorCode:    e.ErrorCode,
+			ErrorMessage: e.ErrorMessage,
+			CheckedAt:    e.CheckedAt,
+		})
+	}
+
+	return timeline, nil
+}
+
+// BuildTimeline 根据状态变化记录（时间升序）构建时间线
+func BuildTimeline(domain string, changes []storage.StatusHistoryEntry, now time.Time) *DomainTimeline {
+	timeline := &DomainTimeline{
+		Domain:        domain,
+		CurrentStatus: StatusUnknown,
+		Segments:      make([]TimelineSegment, 0, len(changes)),
+		TimeInStatus:  make(map[DomainStatus]int64),
+	}
+
+	for i, c := range changes {
+		segment := TimelineSegment{
+			Status: DomainStatus(c.Status),
+			Start:  c.CheckedAt,
+		}
+
+		end := now
+		if i+1 < len(changes) {
+			end = changes[i+1].CheckedAt
+			segment.End = &end
+		}
+
+		duration := end.Sub(c.CheckedAt)
+		if duration < 0 {
+			duration = 0
+		}
+		segment.DurationSeconds = int64(duration.Seconds())
+		segment.Duration = duration.Truncate(time.Second).String()
+
+		timeline.Segments = append(timeline.Segments, segment)
+		timeline.TimeInStatus[segment.Status] += segment.DurationSeconds
+		timeline.CurrentStatus = segment.Status
+	}
+
+	return timeline
+}
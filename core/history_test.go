@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"Puff/storage"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	changes := []storage.StatusHistoryEntry{
+		{Status: string(StatusRegistered), CheckedAt: start},
+		{Status: string(StatusGrace), CheckedAt: start.Add(48 * time.Hour)},
+		{Status: string(StatusRegistered), CheckedAt: start.Add(72 * time.Hour)},
+	}
+	now := start.Add(96 * time.Hour)
+
+	timeline := BuildTimeline("a.com", changes, now)
+	if timeline.CurrentStatus != StatusRegistered || len(timeline.Segments) != 3 {
+		t.Fatalf("时间线错误: %+v", timeline)
+	}
+	if end := timeline.Segments[0].End; end == nil || !end.Equal(start.Add(48*time.Hour)) {
+		t.Errorf("第一段应在下一次变化时结束: %v", end)
+	}
+	if last := timeline.Segments[2]; last.End != nil || last.DurationSeconds != 24*3600 || last.Duration != "24h0m0s" {
+		t.Errorf("最后一段应持续到当前: %+v", last)
+	}
+	if got := timeline.TimeInStatus[StatusRegistered]; got != 72*3600 {
+		t.Errorf("已注册累计时长 = %d，期望 %d", got, 72*3600)
+	}
+	if got := timeline.TimeInStatus[StatusGrace]; got != 24*3600 {
+		t.Errorf("宽限期累计时长 = %d，期望 %d", got, 24*3600)
+	}
+
+	// 时钟回拨时时长不为负
+	skewed := BuildTimeline("a.com", changes[:1], start.Add(-time.Hour))
+	if skewed.Segments[0].DurationSeconds != 0 {
+		t.Errorf("时长不应为负: %d", skewed.Segments[0].DurationSeconds)
+	}
+
+	empty := BuildTimeline("a.com", nil, now)
+	if empty.CurrentStatus != StatusUnknown || len(empty.Segments) != 0 {
+		t.Errorf("没有记录时应为未知状态: %+v", empty)
+	}
+}
+
+func TestRecordStatusHistory(t *testing.T) {
+	const domain = "history-test.com"
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	check := func(status DomainStatus, offset time.Duration, errMsg string) *DomainInfo {
+		return &DomainInfo{Name: domain, Status: status, QueryMethod: "rdap", ErrorMessage: errMsg, LastChecked: base.Add(offset)}
+	}
+
+	recordStatusHistory(false, StatusUnknown, check(StatusRegistered, 0, ""))
+	recordStatusHistory(false, StatusRegistered, check(StatusRegistered, time.Minute, ""))          // 未变化，不记录
+	recordStatusHistory(true, StatusRegistered, check(StatusRegistered, 2*time.Minute, ""))         // 全量记录
+	recordStatusHistory(false, StatusRegistered, check(StatusError, 3*time.Minute, "请求超时 timeout")) // 变化
+
+	timeline, err := (&Monitor{}).GetDomainHistory(" History-Test.com ", 0, true)
+	if err != nil {
+		t.Fatalf("读取状态历史失败: %v", err)
+	}
+	if len(timeline.Events) != 3 {
+		t.Fatalf("应记录 3 条，实际 %d 条: %+v", len(timeline.Events), timeline.Events)
+	}
+	if len(timeline.Segments) != 2 || timeline.CurrentStatus != StatusError {
+		t.Errorf("时间线只应包含状态变化: %+v", timeline.Segments)
+	}
+
+	var last StatusHistoryEvent
+	for _, e := range timeline.Events {
+		if e.CheckedAt.After(last.CheckedAt) {
+			last = e
+		}
+	}
+	if !last.Changed || last.OldStatus != StatusRegistered || last.ErrorCode != ErrorClassTimeout {
+		t.Errorf("最近一条记录错误: %+v", last)
+	}
+
+	changesOnly, err := (&Monitor{}).GetDomainHistory(domain, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changesOnly.Events) != 1 || changesOnly.Events[0].Status != StatusError {
+		t.Errorf("只返回状态变化时应按 limit 保留最近的记录: %+v", changesOnly.Events)
+	}
+}
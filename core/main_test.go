@@ -0,0 +1,23 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain 在临时目录中运行测试，数据库（data/puff.db）不写入源码目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "puff-core-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
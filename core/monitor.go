@@ -1,12 +1,14 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"Puff/config"
+	"Puff/eventsink"
 	"Puff/logger"
 	"Puff/storage"
 )
@@ -18,21 +20,35 @@ type Monitor struct {
 	isRunning     bool
 	mu            sync.RWMutex
 	notifications chan StatusChangeEvent
-	startTime     time.Time      // 启动时间
-	workerManager *WorkerManager // worker管理器
-	queryRecorder func(string)   // 查询记录函数
+	fieldChanges  chan FieldChangeEvent
+	checked       chan *DomainInfo // 每次查询完成的结果
+	reminders     chan ExpiryReminderEvent
+	health        *HealthTracker  // 查询健康跟踪
+	events        *eventsink.Sink // 事件流导出
+	reminderStop  chan struct{}   // 停止续费提醒任务
+	reminderMu    sync.Mutex      // 避免并发检查重复发送提醒
+	startTime     time.Time       // 启动时间
+	workerManager *WorkerManager  // worker管理器
+	queryRecorder func(string)    // 查询记录函数
 }
 
 // NewMonitor 创建新的监控器
 func NewMonitor(cfg *config.Config, queryRecorder func(string)) *Monitor {
 	notifications := make(chan StatusChangeEvent, 1000)
+	fieldChanges := make(chan FieldChangeEvent, 1000)
+	checked := make(chan *DomainInfo, 1000)
 	checker := NewDomainChecker(cfg)
-	workerManager := NewWorkerManager(checker, cfg, notifications, queryRecorder)
+	health := NewHealthTracker()
+	workerManager := NewWorkerManager(checker, cfg, notifications, fieldChanges, checked, health, queryRecorder)
 
 	return &Monitor{
 		checker:       checker,
 		config:        cfg,
 		notifications: notifications,
+		fieldChanges:  fieldChanges,
+		checked:       checked,
+		reminders:     make(chan ExpiryReminderEvent, 100),
+		health:        health,
 		startTime:     time.Now(),
 		workerManager: workerManager,
 		isRunning:     false,
@@ -75,6 +91,7 @@ func (m *Monitor) Start() error {
 	}
 
 	m.isRunning = true
+	m.reminderStop = make(chan struct{})
 	m.mu.Unlock()
 
 	// 仅在启动时加载域名并启动workers
@@ -82,6 +99,9 @@ func (m *Monitor) Start() error {
 		logger.Warn("加载域名失败: %v", err)
 	}
 
+	// 启动续费提醒任务
+	go m.runReminderLoop(m.reminderStop)
+
 	workerCount := m.workerManager.GetWorkerCount()
 	logger.Info("域名监控已启动，并发限制: %d, worker数量: %d", m.config.Monitor.ConcurrentLimit, workerCount)
 
@@ -97,6 +117,7 @@ func (m *Monitor) Stop() {
 	}
 
 	m.isRunning = false
+	close(m.reminderStop)
 	m.mu.Unlock()
 
 	// 停止所有workers
@@ -174,20 +195,25 @@ func (m *Monitor) GetDomainInfo(domain string) (*DomainInfo, error) {
 		}, nil
 	}
 
-	// 转换为DomainInfo
+	return DomainInfoFromResult(result), nil
+}
+
+// DomainInfoFromResult 将数据库记录转换为DomainInfo
+func DomainInfoFromResult(res *storage.DomainResult) *DomainInfo {
 	return &DomainInfo{
-		Name:         result.Domain,
-		Status:       DomainStatus(result.Status),
-		Registrar:    result.Registrar,
-		CreatedDate:  result.CreatedAt,
-		ExpiryDate:   result.ExpiryAt,
-		UpdatedDate:  result.UpdatedAt,
-		NameServers:  result.NameServers,
-		LastChecked:  result.LastChecked,
-		QueryMethod:  result.QueryMethod,
-		WhoisRaw:     result.WhoisRaw,
-		ErrorMessage: result.ErrorMessage,
-	}, nil
+		Name:         res.Domain,
+		Status:       DomainStatus(res.Status),
+		Registrar:    res.Registrar,
+		CreatedDate:  res.CreatedAt,
+		ExpiryDate:   res.ExpiryAt,
+		UpdatedDate:  res.UpdatedAt,
+		NameServers:  res.NameServers,
+		StatusCodes:  res.StatusCodes,
+		LastChecked:  res.LastChecked,
+		QueryMethod:  res.QueryMethod,
+		WhoisRaw:     res.WhoisRaw,
+		ErrorMessage: res.ErrorMessage,
+	}
 }
 
 // GetAllDomainInfo 获取所有域名信息（从数据库）
@@ -202,19 +228,7 @@ func (m *Monitor) GetAllDomainInfo() []*DomainInfo {
 	// 转换为DomainInfo列表
 	infos := make([]*DomainInfo, 0, len(results))
 	for _, res := range results {
-		infos = append(infos, &DomainInfo{
-			Name:         res.Domain,
-			Status:       DomainStatus(res.Status),
-			Registrar:    res.Registrar,
-			LastChecked:  res.LastChecked,
-			QueryMethod:  res.QueryMethod,
-			CreatedDate:  res.CreatedAt,
-			ExpiryDate:   res.ExpiryAt,
-			UpdatedDate:  res.UpdatedAt,
-			NameServers:  res.NameServers,
-			WhoisRaw:     res.WhoisRaw,
-			ErrorMessage: res.ErrorMessage,
-		})
+		infos = append(infos, DomainInfoFromResult(&res))
 	}
 
 	return infos
@@ -244,6 +258,20 @@ func (m *Monitor) ForceCheck(domain string) (*DomainInfo, error) {
 	// 保存到数据库
 	m.saveResultToDB(info)
 
+	// 记录状态历史
+	recordStatusHistory(m.config.Monitor.RecordAllChecks, previousStatus, info)
+
+	// 原始响应变化时保存快照
+	recordWhoisSnapshot(info)
+
+	// 统计查询健康
+	m.health.Record(m.config.Health, previousResult, info)
+
+	// 导出状态与字段变化（在通知过滤之前）
+	changes := DetectFieldChanges(previousResult, info)
+	publishChanges(m.events, previousStatus, info, changes)
+	emitChecked(m.checked, info)
+
 	endTime := time.Now()
 	duration := endTime.Sub(startTime)
 	logger.Info("域名 %s 查询完成，状态: %s，开始: %s，结束: %s，耗时: %v",
@@ -252,8 +280,11 @@ func (m *Monitor) ForceCheck(domain string) (*DomainInfo, error) {
 		endTime.Format("2006-01-02 15:04:05"),
 		duration)
 
+	// 与 worker 一致，按域名保存的通知开关决定是否发送通知
+	notify := domainNotify(domain)
+
 	// 检查状态变化并发送通知
-	if previousStatus != StatusUnknown && previousStatus != info.Status && previousStatus != StatusError {
+	if notify && previousStatus != StatusUnknown && previousStatus != info.Status && previousStatus != StatusError {
 		logger.Info("检测到域名 %s 状态变化: %s -> %s", domain, previousStatus, info.Status)
 
 		// 检查是否需要通知
@@ -270,15 +301,19 @@ func (m *Monitor) ForceCheck(domain string) (*DomainInfo, error) {
 			}
 
 			// 发送到通知通道
-			select {
-			case m.notifications <- event:
+			if sendStatusChange(context.Background(), m.notifications, event) {
 				logger.Info("域名 %s 状态变化通知已发送", domain)
-			default:
-				logger.Warn("通知队列已满，丢弃域名 %s 的状态变化通知", domain)
+			} else {
+				logger.Error("通知队列持续已满，丢弃域名 %s 的状态变化通知", domain)
 			}
 		}
 	}
 
+	// 检查字段变化并发送通知
+	if notify {
+		sendFieldChanges(m.fieldChanges, changes)
+	}
+
 	return info, nil
 }
 
@@ -376,11 +411,87 @@ func (m *Monitor) RemoveDomain(domain string) {
 	logger.Info("域名 %s 的worker已停止", domain)
 }
 
+// SetDomainNotify 设置域名是否发送通知，并以新设置重启其worker
+func (m *Monitor) SetDomainNotify(domain string, notify bool) error {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	entry, err := findDomainEntry(domain)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("域名 %s 不在监控列表中", domain)
+	}
+
+	if err := storage.AddDomain(domain, entry.Enabled, notify); err != nil {
+		return fmt.Errorf("保存域名通知设置失败: %v", err)
+	}
+
+	m.mu.RLock()
+	isRunning := m.isRunning
+	m.mu.RUnlock()
+	if isRunning && entry.Enabled {
+		m.workerManager.RemoveWorker(domain)
+		m.workerManager.AddWorker(domain, notify)
+	}
+
+	if notify {
+		logger.Info("域名 %s 已开启通知", domain)
+	} else {
+		logger.Info("域名 %s 已静音", domain)
+	}
+	return nil
+}
+
+// findDomainEntry 查找监控列表中的域名，不存在时返回 nil
+func findDomainEntry(domain string) (*storage.DomainEntry, error) {
+	entries, err := storage.ListDomains(false)
+	if err != nil {
+		return nil, fmt.Errorf("读取域名列表失败: %v", err)
+	}
+	for i := range entries {
+		if entries[i].Name == domain {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// domainNotify 域名保存的通知开关（不在监控列表中或读取失败时不通知）
+func domainNotify(domain string) bool {
+	entry, err := findDomainEntry(domain)
+	if err != nil {
+		logger.Warn("读取域名 %s 的通知设置失败: %v", domain, err)
+		return false
+	}
+	return entry != nil && entry.Notify
+}
+
 // GetNotifications 获取通知通道
 func (m *Monitor) GetNotifications() <-chan StatusChangeEvent {
 	return m.notifications
 }
 
+// GetCheckResults 获取每次查询完成的结果通道（用于同步 MQTT 保留状态）
+func (m *Monitor) GetCheckResults() <-chan *DomainInfo {
+	return m.checked
+}
+
+// GetFieldChanges 获取字段变化通知通道
+func (m *Monitor) GetFieldChanges() <-chan FieldChangeEvent {
+	return m.fieldChanges
+}
+
+// GetHealthEvents 获取查询健康告警通道
+func (m *Monitor) GetHealthEvents() <-chan HealthEvent {
+	return m.health.events
+}
+
+// GetCheckFailures 获取单次查询失败事件通道
+func (m *Monitor) GetCheckFailures() <-chan CheckFailureEvent {
+	return m.health.failures
+}
+
 // saveResultToDB 将单条结果写入数据库
 func (m *Monitor) saveResultToDB(info *DomainInfo) {
 	if info == nil {
@@ -413,6 +524,7 @@ func (m *Monitor) saveResultToDB(info *DomainInfo) {
 		ExpiryAt:     toCST(info.ExpiryDate),
 		UpdatedAt:    toCST(info.UpdatedDate),
 		NameServers:  info.NameServers,
+		StatusCodes:  info.StatusCodes,
 		WhoisRaw:     info.WhoisRaw,
 		ErrorMessage: info.ErrorMessage,
 	}
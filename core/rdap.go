@@ -209,6 +209,7 @@ func (r *RDAPClient) ParseRDAPResponse(domain string, rdapResp *RDAPResponse, ra
 
 	// 解析域名状态
 	info.Status = r.parseRDAPStatus(rdapResp.Status)
+	info.StatusCodes = normalizeEPPStatusCodes(rdapResp.Status)
 
 	// 解析注册商
 	info.Registrar = r.parseRDAPRegistrar(rdapResp.Entities)
@@ -298,6 +299,27 @@ func (r *RDAPClient) parseRDAPStatus(statuses []string) DomainStatus {
 	return StatusRegistered
 }
 
+// normalizeEPPStatusCodes 将RDAP状态（如 "client transfer prohibited"）转换为EPP格式（clientTransferProhibited）
+func normalizeEPPStatusCodes(statuses []string) []string {
+	codes := make([]string, 0, len(statuses))
+	seen := make(map[string]bool)
+	for _, status := range statuses {
+		words := strings.Fields(strings.ToLower(status))
+		if len(words) == 0 {
+			continue
+		}
+		code := words[0]
+		for _, word := range words[1:] {
+			code += strings.ToUpper(word[:1]) + word[1:]
+		}
+		if !seen[code] {
+			codes = append(codes, code)
+			seen[code] = true
+		}
+	}
+	return codes
+}
+
 // parseRDAPRegistrar 解析注册商信息
 func (r *RDAPClient) parseRDAPRegistrar(entities []RDAPEntity) string {
 	for _, entity := range entities {
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// reminderCheckInterval 续费提醒检查间隔
+const reminderCheckInterval = 24 * time.Hour
+
+// DefaultReminderDays 默认的到期前提醒天数
+var DefaultReminderDays = []int{90, 30, 7, 1}
+
+// ExpiryReminderEvent 自有域名续费提醒事件
+type ExpiryReminderEvent struct {
+	Domain     string    `json:"domain"`
+	ExpiryDate time.Time `json:"expiry_date"`
+	DaysLeft   int       `json:"days_left"`   // 剩余天数，负数表示已过期
+	OffsetDays int       `json:"offset_days"` // 触发的提醒档位
+	Timestamp  time.Time `json:"timestamp"`
+	Message    string    `json:"message"`
+}
+
+// OwnedDomainStatus 自有域名及其到期情况
+type OwnedDomainStatus struct {
+	storage.OwnedDomain
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	DaysLeft   *int       `json:"days_left,omitempty"`
+}
+
+// GetReminderMessage 获取续费提醒消息（默认语言）
+func GetReminderMessage(domain string, expiry time.Time, daysLeft int) string {
+	return GetReminderMessageFor(i18n.Default, domain, expiry, daysLeft)
+}
+
+// GetReminderMessageFor 获取指定语言的续费提醒消息
+func GetReminderMessageFor(locale, domain string, expiry time.Time, daysLeft int) string {
+	if daysLeft < 0 {
+		return i18n.T(locale, "reminder.expired", domain, expiry.Format("2006-01-02"), -daysLeft)
+	}
+	if daysLeft == 0 {
+		return i18n.T(locale, "reminder.today", domain, expiry.Format("2006-01-02"))
+	}
+	return i18n.T(locale, "reminder.days", domain, expiry.Format("2006-01-02"), daysLeft)
+}
+
+// GetExpiryReminderMessage 按提醒时刻计算剩余天数，返回指定语言的续费提醒消息
+func GetExpiryReminderMessage(locale, domain string, expiry, now time.Time) string {
+	return GetReminderMessageFor(locale, domain, expiry, DaysUntil(expiry, now))
+}
+
+// DaysUntil 计算距离过期的天数（向上取整）
+func DaysUntil(expiry, now time.Time) int {
+	return int(math.Ceil(expiry.Sub(now).Hours() / 24))
+}
+
+// runReminderLoop 启动时立即检查一次，之后每天检查一次
+func (m *Monitor) runReminderLoop(stop <-chan struct{}) {
+	m.CheckExpiryReminders(time.Now())
+
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.CheckExpiryReminders(now)
+		}
+	}
+}
+
+// CheckExpiryReminders 检查所有自有域名，对到达提醒档位的域名发送续费提醒
+// 提醒记录按过期日期保存，过期日期后移（已续费）后自动重新计算
+func (m *Monitor) CheckExpiryReminders(now time.Time) {
+	m.reminderMu.Lock()
+	defer m.reminderMu.Unlock()
+
+	owned, err := storage.ListOwnedDomains()
+	if err != nil {
+		logger.Error("读取自有域名失败: %v", err)
+		return
+	}
+
+	for _, od := range owned {
+		result, err := storage.GetDomainResult(od.Domain)
+		if err != nil {
+			logger.Error("读取域名 %s 的查询结果失败: %v", od.Domain, err)
+			continue
+		}
+		if result == nil || result.ExpiryAt == nil {
+			continue
+		}
+
+		expiry := *result.ExpiryAt
+		expiryKey := expiry.UTC().Format("2006-01-02")
+
+		if n, err := storage.ResetReminders(od.Domain, expiryKey); err != nil {
+			logger.Error("重置域名 %s 的提醒记录失败: %v", od.Domain, err)
+			continue
+		} else if n > 0 {
+			logger.Info("域名 %s 的过期时间已更新为 %s，重置续费提醒", od.Domain, expiryKey)
+		}
+
+		sent, err := storage.ListSentReminders(od.Domain, expiryKey)
+		if err != nil {
+			logger.Error("读取域名 %s 的提醒记录失败: %v", od.Domain, err)
+			continue
+		}
+
+		// 已到达的提醒档位（0 表示到期当天及之后）
+		daysLeft := DaysUntil(expiry, now)
+		offsets := od.ReminderDays
+		if len(offsets) == 0 {
+			offsets = DefaultReminderDays
+		}
+		var due []int
+		pending := false
+		for _, offset := range append(append([]int{}, offsets...), 0) {
+			if daysLeft <= offset {
+				due = append(due, offset)
+				if !sent[offset] {
+					pending = true
+				}
+			}
+		}
+		if !pending {
+			continue
+		}
+
+		// 同时到达多个档位时只发送最紧急的一条
+		sort.Ints(due)
+		event := ExpiryReminderEvent{
+			Domain:     od.Domain,
+			ExpiryDate: expiry,
+			DaysLeft:   daysLeft,
+			OffsetDays: due[0],
+			Timestamp:  now,
+			Message:    GetReminderMessage(od.Domain, expiry, daysLeft),
+		}
+
+		select {
+		case m.reminders <- event:
+			logger.Info("%s", event.Message)
+		default:
+			logger.Warn("通知队列已满，丢弃域名 %s 的续费提醒", od.Domain)
+			continue
+		}
+
+		if err := storage.MarkRemindersSent(od.Domain, expiryKey, due); err != nil {
+			logger.Error("保存域名 %s 的提醒记录失败: %v", od.Domain, err)
+		}
+	}
+}
+
+// GetOwnedDomains 获取自有域名及其剩余天数
+func (m *Monitor) GetOwnedDomains() ([]OwnedDomainStatus, error) {
+	owned, err := storage.ListOwnedDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]OwnedDomainStatus, 0, len(owned))
+	for _, od := range owned {
+		if len(od.ReminderDays) == 0 {
+			od.ReminderDays = DefaultReminderDays
+		}
+		status := OwnedDomainStatus{OwnedDomain: od}
+		if result, err := storage.GetDomainResult(od.Domain); err == nil && result != nil && result.ExpiryAt != nil {
+			daysLeft := DaysUntil(*result.ExpiryAt, now)
+			status.ExpiryDate = result.ExpiryAt
+			status.DaysLeft = &daysLeft
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// GetReminders 获取续费提醒通道
+func (m *Monitor) GetReminders() <-chan ExpiryReminderEvent {
+	return m.reminders
+}
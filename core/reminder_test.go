@@ -0,0 +1,96 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+// ownTestDomain 保存带过期时间的查询结果并标记为自有域名
+func ownTestDomain(t *testing.T, domain string, expiry time.Time, days []int) {
+	t.Helper()
+	if err := storage.SaveDomainResult(storage.DomainResult{Domain: domain, Status: string(StatusRegistered), LastChecked: time.Now(), ExpiryAt: &expiry}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SetDomainOwned(domain, days); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// drainReminders 取出已发送的续费提醒
+func drainReminders(m *Monitor) map[string]ExpiryReminderEvent {
+	events := make(map[string]ExpiryReminderEvent)
+	for {
+		select {
+		case e := <-m.reminders:
+			events[e.Domain] = e
+		default:
+			return events
+		}
+	}
+}
+
+func TestCheckExpiryReminders(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ownTestDomain(t, "owned-a.com", now.Add(20*24*time.Hour), []int{30, 7})
+	ownTestDomain(t, "owned-b.com", now.Add(5*24*time.Hour), nil) // 默认档位，同时到达 90/30/7
+	ownTestDomain(t, "owned-c.com", now.Add(-2*24*time.Hour), []int{7})
+
+	m := &Monitor{reminders: make(chan ExpiryReminderEvent, 10)}
+	m.CheckExpiryReminders(now)
+	events := drainReminders(m)
+	if e := events["owned-a.com"]; e.OffsetDays != 30 || e.DaysLeft != 20 {
+		t.Errorf("owned-a.com 提醒错误: %+v", e)
+	}
+	if e := events["owned-b.com"]; e.OffsetDays != 7 || e.DaysLeft != 5 {
+		t.Errorf("同时到达多个档位时只发送最紧急的一条: %+v", e)
+	}
+	if e := events["owned-c.com"]; e.OffsetDays != 0 || e.DaysLeft != -2 {
+		t.Errorf("已过期域名提醒错误: %+v", e)
+	}
+
+	// 同一档位不重复提醒
+	m.CheckExpiryReminders(now.Add(time.Hour))
+	if events := drainReminders(m); len(events) != 0 {
+		t.Errorf("不应重复提醒: %v", events)
+	}
+
+	// 到达下一档位
+	m.CheckExpiryReminders(now.Add(14 * 24 * time.Hour))
+	events = drainReminders(m)
+	if e, ok := events["owned-a.com"]; !ok || e.OffsetDays != 7 || e.DaysLeft != 6 {
+		t.Errorf("应发送 7 天档位提醒: %+v", e)
+	}
+
+	// 续费后过期时间后移，提醒记录重置，重新从远档位开始计算
+	ownTestDomain(t, "owned-a.com", now.Add(40*24*time.Hour), []int{30, 7})
+	m.CheckExpiryReminders(now.Add(14 * 24 * time.Hour))
+	events = drainReminders(m)
+	if e, ok := events["owned-a.com"]; !ok || e.OffsetDays != 30 || e.DaysLeft != 26 {
+		t.Errorf("续费后应重新提醒: %+v", e)
+	}
+}
+
+func TestReminderMessage(t *testing.T) {
+	expiry := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale   string
+		daysLeft int
+		want     string
+	}{
+		{i18n.ZhCN, 30, "域名 a.com 将于 2026-05-01 过期，剩余 30 天"},
+		{i18n.ZhCN, 0, "域名 a.com 将于今天（2026-05-01）过期，请尽快续费"},
+		{i18n.En, -3, "Domain a.com expired on 2026-05-01, 3 days ago; please renew it as soon as possible"},
+	}
+	for _, tt := range tests {
+		if got := GetReminderMessageFor(tt.locale, "a.com", expiry, tt.daysLeft); got != tt.want {
+			t.Errorf("GetReminderMessageFor(%s, %d) = %q，期望 %q", tt.locale, tt.daysLeft, got, tt.want)
+		}
+	}
+
+	if got := DaysUntil(expiry, expiry.Add(-36*time.Hour)); got != 2 {
+		t.Errorf("剩余天数应向上取整: %d", got)
+	}
+}
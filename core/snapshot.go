@@ -0,0 +1,178 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// volatileLinePatterns WHOIS 文本中每次查询都会变化的行（如数据库更新时间、查询时间）
+var volatileLinePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)last update of (whois|rdap) database`),
+	regexp.MustCompile(`(?i)^[%#\s]*(timestamp|query time|queried at|whois lookup made at|this whois was generated at|response generated|record maintained by|database last updated)\b`),
+	regexp.MustCompile(`^\s*>>>.*<<<\s*$`),
+}
+
+// SnapshotDiff 两个快照之间的差异
+type SnapshotDiff struct {
+	Domain  string                 `json:"domain"`
+	From    *storage.WhoisSnapshot `json:"from"`
+	To      *storage.WhoisSnapshot `json:"to"`
+	Changed bool                   `json:"changed"`
+	Diff    string                 `json:"diff"`
+}
+
+// NormalizeWhoisRaw 规范化原始响应：去除易变的时间戳，便于判断内容是否真正变化
+func NormalizeWhoisRaw(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+
+	// RDAP JSON：删除“最后更新数据库”事件，并以有序键重新编码
+	if strings.HasPrefix(trimmed, "{") {
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &doc); err == nil {
+			stripVolatileRDAPEvents(doc)
+			if data, err := json.Marshal(doc); err == nil {
+				return string(data)
+			}
+		}
+	}
+
+	// WHOIS 文本：删除易变行并统一换行与行尾空白
+	var lines []string
+	for _, line := range splitLines(trimmed) {
+		line = strings.TrimRight(line, " \t")
+		volatile := false
+		for _, re := range volatileLinePatterns {
+			if re.MatchString(line) {
+				volatile = true
+				break
+			}
+		}
+		if !volatile {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// stripVolatileRDAPEvents 递归删除 RDAP 中的 "last update of RDAP database" 事件
+func stripVolatileRDAPEvents(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if events, ok := v["events"].([]interface{}); ok {
+			kept := make([]interface{}, 0, len(events))
+			for _, e := range events {
+				if em, ok := e.(map[string]interface{}); ok {
+					if action, _ := em["eventAction"].(string); strings.EqualFold(action, "last update of RDAP database") {
+						continue
+					}
+				}
+				kept = append(kept, e)
+			}
+			v["events"] = kept
+		}
+		for _, child := range v {
+			stripVolatileRDAPEvents(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			stripVolatileRDAPEvents(child)
+		}
+	}
+}
+
+// SnapshotHash 计算规范化内容的 SHA-256 哈希
+func SnapshotHash(raw string) string {
+	sum := sha256.Sum256([]byte(NormalizeWhoisRaw(raw)))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordWhoisSnapshot 在原始响应内容发生实质变化时保存快照
+func recordWhoisSnapshot(info *DomainInfo) {
+	if info == nil || strings.TrimSpace(info.WhoisRaw) == "" {
+		return
+	}
+
+	created, err := storage.SaveWhoisSnapshot(info.Name, info.QueryMethod, SnapshotHash(info.WhoisRaw), info.WhoisRaw)
+	if err != nil {
+		logger.Error("保存域名 %s 的WHOIS快照失败: %v", info.Name, err)
+		return
+	}
+	if created {
+		logger.Debug("域名 %s 的WHOIS/RDAP响应发生变化，已保存新快照", info.Name)
+	}
+}
+
+// GetSnapshotDiff 比较域名的两个快照；fromID/toID 为 0 时默认比较最近两个快照
+func (m *Monitor) GetSnapshotDiff(domain string, fromID, toID int64) (*SnapshotDiff, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	if fromID == 0 || toID == 0 {
+		snapshots, err := storage.ListWhoisSnapshots(domain)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, fmt.Errorf("域名 %s 暂无快照", domain)
+		}
+		if toID == 0 {
+			toID = snapshots[0].ID
+		}
+		if fromID == 0 {
+			// 取 toID 之前的一个快照，没有则与自身比较
+			fromID = toID
+			for _, s := range snapshots {
+				if s.ID < toID {
+					fromID = s.ID
+					break
+				}
+			}
+		}
+	}
+
+	from, err := storage.GetWhoisSnapshot(domain, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := storage.GetWhoisSnapshot(domain, toID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("快照不存在")
+	}
+
+	diff := UnifiedDiff(
+		fmt.Sprintf("%s@%d (%s)", domain, from.ID, from.CapturedAt.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("%s@%d (%s)", domain, to.ID, to.CapturedAt.Format("2006-01-02 15:04:05")),
+		prettyRaw(from.Raw), prettyRaw(to.Raw), 3,
+	)
+
+	return &SnapshotDiff{
+		Domain:  domain,
+		From:    from,
+		To:      to,
+		Changed: diff != "",
+		Diff:    diff,
+	}, nil
+}
+
+// prettyRaw 将 RDAP JSON 格式化为多行，便于逐行比较
+func prettyRaw(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "{") {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(trimmed), "", "  "); err == nil {
+			return buf.String()
+		}
+	}
+	return raw
+}
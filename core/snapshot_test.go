@@ -0,0 +1,93 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"Puff/storage"
+)
+
+func TestSnapshotHashIgnoresVolatileContent(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{
+			"WHOIS 查询时间",
+			"Domain Name: A.COM\nRegistrar: X\n>>> Last update of whois database: 2026-01-01T00:00:00Z <<<\n",
+			"Domain Name: A.COM   \r\nRegistrar: X\r\n>>> Last update of whois database: 2026-02-01T00:00:00Z <<<\r\n",
+			true,
+		},
+		{
+			"WHOIS 注释中的时间戳",
+			"% Timestamp: 1\nDomain Name: A.COM\n",
+			"% Timestamp: 2\nDomain Name: A.COM\n",
+			true,
+		},
+		{
+			"RDAP 键顺序与数据库更新事件",
+			`{"ldhName":"a.com","events":[{"eventAction":"expiration","eventDate":"2027-01-01"},{"eventAction":"last update of RDAP database","eventDate":"2026-01-01"}]}`,
+			`{"events":[{"eventAction":"expiration","eventDate":"2027-01-01"},{"eventAction":"last update of RDAP database","eventDate":"2026-03-01"}],"ldhName":"a.com"}`,
+			true,
+		},
+		{
+			"注册商变化",
+			"Domain Name: A.COM\nRegistrar: X\n",
+			"Domain Name: A.COM\nRegistrar: Y\n",
+			false,
+		},
+		{
+			"RDAP 过期时间变化",
+			`{"events":[{"eventAction":"expiration","eventDate":"2027-01-01"}]}`,
+			`{"events":[{"eventAction":"expiration","eventDate":"2028-01-01"}]}`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		if same := SnapshotHash(tt.a) == SnapshotHash(tt.b); same != tt.same {
+			t.Errorf("%s: 哈希相同 = %v，期望 %v\n%q\n%q", tt.name, same, tt.same, NormalizeWhoisRaw(tt.a), NormalizeWhoisRaw(tt.b))
+		}
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	const domain = "snapshot-test.com"
+	record := func(raw string) {
+		recordWhoisSnapshot(&DomainInfo{Name: domain, QueryMethod: "whois", WhoisRaw: raw})
+	}
+	record("Domain Name: SNAPSHOT-TEST.COM\nRegistrar: X\n% Timestamp: 1\n")
+	record("Domain Name: SNAPSHOT-TEST.COM\nRegistrar: X\n% Timestamp: 2\n") // 仅时间戳变化，不保存
+	record("Domain Name: SNAPSHOT-TEST.COM\nRegistrar: Y\n% Timestamp: 3\n")
+	record("")
+
+	snapshots, err := storage.ListWhoisSnapshots(domain)
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("应保存 2 个快照: %v %v", snapshots, err)
+	}
+
+	m := &Monitor{}
+	diff, err := m.GetSnapshotDiff(domain, 0, 0)
+	if err != nil {
+		t.Fatalf("比较快照失败: %v", err)
+	}
+	if !diff.Changed || diff.From.ID != snapshots[1].ID || diff.To.ID != snapshots[0].ID {
+		t.Fatalf("默认应比较最近两个快照: %+v", diff)
+	}
+	if !strings.Contains(diff.Diff, "\n-Registrar: X\n") || !strings.Contains(diff.Diff, "\n+Registrar: Y\n") {
+		t.Errorf("差异内容错误:\n%s", diff.Diff)
+	}
+
+	// 只有一个参照快照时与自身比较
+	same, err := m.GetSnapshotDiff(domain, 0, snapshots[1].ID)
+	if err != nil || same.Changed || same.Diff != "" {
+		t.Errorf("最早的快照应与自身比较: %+v %v", same, err)
+	}
+
+	if _, err := m.GetSnapshotDiff(domain, snapshots[1].ID, snapshots[0].ID+100); err == nil {
+		t.Error("快照不存在时应返回错误")
+	}
+	if _, err := m.GetSnapshotDiff("no-snapshots.com", 0, 0); err == nil {
+		t.Error("没有快照时应返回错误")
+	}
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 	"time"
+
+	"Puff/i18n"
 )
 
 // DomainStatus 域名状态枚举
@@ -49,6 +51,7 @@ type DomainInfo struct {
 	ExpiryDate   *time.Time   `json:"expiry_date"`   // 过期日期
 	UpdatedDate  *time.Time   `json:"updated_date"`  // 更新日期
 	NameServers  []string     `json:"name_servers"`  // 名称服务器
+	StatusCodes  []string     `json:"status_codes"`  // EPP状态码（如 clientTransferProhibited）
 	LastChecked  time.Time    `json:"last_checked"`  // 最后检查时间
 	QueryMethod  string       `json:"query_method"`  // 查询方法 (whois/rdap)
 	ErrorMessage string       `json:"error_message"` // 错误信息
@@ -65,71 +68,78 @@ type StatusInfo struct {
 	ShouldNotify bool         `json:"should_notify"` // 是否发送通知
 }
 
-// GetAllStatusInfo 获取所有状态信息
+// GetAllStatusInfo 获取所有状态信息（默认语言）
 func GetAllStatusInfo() map[DomainStatus]StatusInfo {
-	return map[DomainStatus]StatusInfo{
+	return GetAllStatusInfoFor(i18n.Default)
+}
+
+// GetAllStatusInfoFor 获取所有状态信息，描述使用指定语言
+func GetAllStatusInfoFor(locale string) map[DomainStatus]StatusInfo {
+	statusMap := map[DomainStatus]StatusInfo{
 		StatusAvailable: {
 			Status:       StatusAvailable,
-			Description:  "域名可注册",
 			Color:        "#28a745", // 绿色
 			Priority:     1,
 			ShouldNotify: true,
 		},
 		StatusGrace: {
 			Status:       StatusGrace,
-			Description:  "宽限期",
 			Color:        "#ffc107",
 			Priority:     2,
 			ShouldNotify: true,
 		},
 		StatusRedemption: {
 			Status:       StatusRedemption,
-			Description:  "域名在赎回期",
 			Color:        "#fd7e14", // 橙色
 			Priority:     3,
 			ShouldNotify: true,
 		},
 		StatusPendingDelete: {
 			Status:       StatusPendingDelete,
-			Description:  "域名待删除/抢注期",
 			Color:        "#dc3545", // 红色
 			Priority:     4,
 			ShouldNotify: true,
 		},
 		StatusRegistered: {
 			Status:       StatusRegistered,
-			Description:  "域名已注册",
 			Color:        "#6c757d", // 灰色
 			Priority:     5,
 			ShouldNotify: true, // 改为true，状态变化也需要通知
 		},
 		StatusTransferLocked: {
 			Status:       StatusTransferLocked,
-			Description:  "域名转移锁定",
 			Color:        "#17a2b8", // 青色
 			Priority:     6,
 			ShouldNotify: false,
 		},
 		StatusUnknown: {
 			Status:       StatusUnknown,
-			Description:  "未知状态",
 			Color:        "#343a40", // 深灰色
 			Priority:     8,
 			ShouldNotify: false,
 		},
 		StatusError: {
 			Status:       StatusError,
-			Description:  "查询错误",
 			Color:        "#dc3545", // 红色
 			Priority:     9,
 			ShouldNotify: false,
 		},
 	}
+	for status, info := range statusMap {
+		info.Description = i18n.T(locale, "status."+string(status)+".desc")
+		statusMap[status] = info
+	}
+	return statusMap
 }
 
-// GetStatusInfo 获取指定状态的信息
+// GetStatusInfo 获取指定状态的信息（默认语言）
 func GetStatusInfo(status DomainStatus) StatusInfo {
-	statusMap := GetAllStatusInfo()
+	return GetStatusInfoFor(i18n.Default, status)
+}
+
+// GetStatusInfoFor 获取指定状态的信息，描述使用指定语言
+func GetStatusInfoFor(locale string, status DomainStatus) StatusInfo {
+	statusMap := GetAllStatusInfoFor(locale)
 	if info, exists := statusMap[status]; exists {
 		return info
 	}
@@ -179,13 +189,17 @@ type StatusChangeEvent struct {
 	DomainInfo *DomainInfo  `json:"domain_info,omitempty"` // 包含详细信息
 }
 
-// GetStatusChangeMessage 获取状态变化消息
+// GetStatusChangeMessage 获取状态变化消息（默认语言）
 func GetStatusChangeMessage(domain string, oldStatus, newStatus DomainStatus) string {
-	oldInfo := GetStatusInfo(oldStatus)
-	newInfo := GetStatusInfo(newStatus)
+	return GetStatusChangeMessageFor(i18n.Default, domain, oldStatus, newStatus)
+}
+
+// GetStatusChangeMessageFor 获取指定语言的状态变化消息
+func GetStatusChangeMessageFor(locale, domain string, oldStatus, newStatus DomainStatus) string {
+	oldInfo := GetStatusInfoFor(locale, oldStatus)
+	newInfo := GetStatusInfoFor(locale, newStatus)
 
-	return fmt.Sprintf("域名 %s 状态发生变化：从 [%s] 变为 [%s]",
-		domain, oldInfo.Description, newInfo.Description)
+	return i18n.T(locale, "status.change_message", domain, oldInfo.Description, newInfo.Description)
 }
 
 // GetSmartCacheDuration 根据域名状态和过期时间计算智能缓存时间
@@ -139,6 +139,9 @@ func (w *WhoisClient) ParseWhoisResponse(domain, response string) *DomainInfo {
 	// 解析名称服务器
 	info.NameServers = w.parseNameServers(response)
 
+	// 解析EPP状态码
+	info.StatusCodes = w.parseStatusCodes(response)
+
 	// 额外校验：如果判定为可注册，但存在关键注册信息，则认为是误报
 	if info.Status == StatusAvailable {
 		hasValidRegistrar := info.Registrar != "" && !strings.Contains(info.Registrar, "不支持")
@@ -735,6 +738,25 @@ func (w *WhoisClient) parseNameServers(response string) []string {
 	return nameServers
 }
 
+// whoisStatusCodePattern 匹配 "Domain Status: clientTransferProhibited https://icann.org/epp#..." 行
+var whoisStatusCodePattern = regexp.MustCompile(`(?im)^\s*(?:domain )?status:\s*([a-z][a-z]+)`)
+
+// parseStatusCodes 解析EPP状态码
+func (w *WhoisClient) parseStatusCodes(response string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+
+	for _, match := range whoisStatusCodePattern.FindAllStringSubmatch(response, -1) {
+		code := strings.TrimSpace(match[1])
+		if code != "" && !seen[code] {
+			codes = append(codes, code)
+			seen[code] = true
+		}
+	}
+
+	return codes
+}
+
 // isExpired 检查域名是否已过期
 func (w *WhoisClient) isExpired(response string) bool {
 	expiryPatterns := []string{
@@ -0,0 +1,67 @@
+package eventsink
+
+import "time"
+
+// 事件类型
+const (
+	TypeStatusChange = "status_change" // 域名状态变化
+	TypeFieldChange  = "field_change"  // 注册商、DNS、到期时间等字段变化
+	TypeCheckFailure = "check_failure" // 单次查询失败
+	TypeAdminAction  = "admin_action"  // 管理操作（Web 接口、登录登出、Telegram 命令）
+)
+
+// syslog 严重级别（RFC 5424）
+const (
+	SeverityError   = 3
+	SeverityWarning = 4
+	SeverityNotice  = 5
+	SeverityInfo    = 6
+)
+
+// Event 导出到事件流的事件，JSONL 每行一条，syslog 的 MSG 部分为同样的 JSON
+type Event struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Severity int       `json:"severity"` // syslog 严重级别，为 0 时按类型取默认值
+
+	// 域名事件
+	Domain     string `json:"domain,omitempty"`
+	Status     string `json:"status,omitempty"`
+	OldStatus  string `json:"old_status,omitempty"`
+	Change     string `json:"change,omitempty"` // 字段变化类型，如 registrar_change
+	Field      string `json:"field,omitempty"`
+	OldValue   string `json:"old_value,omitempty"`
+	NewValue   string `json:"new_value,omitempty"`
+	Server     string `json:"server,omitempty"`      // 查询服务器
+	Error      string `json:"error,omitempty"`       // 查询错误信息
+	ErrorClass string `json:"error_class,omitempty"` // 查询错误分类
+	Failures   int    `json:"failures,omitempty"`    // 连续失败次数
+
+	// 管理操作
+	Actor      string `json:"actor,omitempty"`       // 操作者（用户名或 Telegram 聊天）
+	Source     string `json:"source,omitempty"`      // 来源：web、telegram
+	RemoteAddr string `json:"remote_addr,omitempty"` // 客户端地址
+	Action     string `json:"action,omitempty"`      // 操作，如 POST /api/domain/add
+	Result     string `json:"result,omitempty"`      // success 或 failure
+	StatusCode int    `json:"status_code,omitempty"` // HTTP 状态码
+
+	Message string `json:"message,omitempty"`
+}
+
+// severity 事件的 syslog 严重级别
+func (e Event) severity() int {
+	if e.Severity > 0 {
+		return e.Severity
+	}
+	switch e.Type {
+	case TypeCheckFailure:
+		return SeverityWarning
+	case TypeStatusChange, TypeAdminAction:
+		if e.Result == "failure" {
+			return SeverityWarning
+		}
+		return SeverityNotice
+	default:
+		return SeverityInfo
+	}
+}
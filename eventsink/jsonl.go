@@ -0,0 +1,110 @@
+package eventsink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"Puff/config"
+)
+
+// fileWriter 追加写入 JSONL 文件，超过大小上限时轮转为 .1、.2 ...
+type fileWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newFileWriter 创建 JSONL 文件写入器并打开文件
+func newFileWriter(cfg config.EventsConfig) (*fileWriter, error) {
+	if cfg.FilePath == "" {
+		return nil, fmt.Errorf("未配置事件文件路径")
+	}
+	if cfg.FileMaxBackups < 1 {
+		return nil, fmt.Errorf("事件文件保留数量至少为1（轮转时不删除当前文件）")
+	}
+	w := &fileWriter{
+		path:       cfg.FilePath,
+		maxSize:    int64(cfg.FileMaxSize) * 1024 * 1024,
+		maxBackups: cfg.FileMaxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open 以追加方式打开文件
+func (w *fileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return fmt.Errorf("创建事件文件目录失败: %w", err)
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("打开事件文件失败: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("读取事件文件信息失败: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// Write 写入一行事件，写入前按需轮转
+func (w *fileWriter) Write(_ Event, payload []byte) error {
+	if w.file == nil {
+		// 上次轮转后重新打开失败
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	line := append(payload, '\n')
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(line)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("写入事件文件失败: %w", err)
+	}
+	return nil
+}
+
+// rotate 将当前文件重命名为 .1（已有的依次后移，超出保留数的删除）并重新打开
+func (w *fileWriter) rotate() error {
+	w.file.Close()
+	w.file = nil
+
+	os.Remove(backupName(w.path, w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(w.path, i), backupName(w.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("轮转事件文件失败: %w", err)
+		}
+	}
+	if err := os.Rename(w.path, backupName(w.path, 1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("轮转事件文件失败: %w", err)
+	}
+	return w.open()
+}
+
+// Close 关闭文件
+func (w *fileWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// backupName 第 n 个轮转文件的路径
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,96 @@
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Puff/config"
+)
+
+// readEvents 读取 JSONL 文件中的全部事件
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("打开 %s 失败: %v", path, err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("解析事件行失败: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestFileWriterRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := newFileWriter(config.EventsConfig{FilePath: path, FileMaxSize: 1, FileMaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.maxSize = 200 // 便于测试，约两条事件轮转一次
+
+	for i := 0; i < 10; i++ {
+		if err := write(w, Event{Time: time.Now(), Type: TypeCheckFailure, Domain: "example.com", Failures: i + 1}); err != nil {
+			t.Fatalf("写入第 %d 条事件失败: %v", i+1, err)
+		}
+	}
+
+	live := readEvents(t, path)
+	if len(live) == 0 {
+		t.Fatal("轮转后当前文件不应为空")
+	}
+	if last := live[len(live)-1]; last.Failures != 10 {
+		t.Errorf("当前文件最后一条应为最新事件，实际 failures=%d", last.Failures)
+	}
+	for _, n := range []int{1, 2} {
+		if len(readEvents(t, backupName(path, n))) == 0 {
+			t.Errorf("轮转文件 .%d 为空", n)
+		}
+	}
+	if _, err := os.Stat(backupName(path, 3)); !os.IsNotExist(err) {
+		t.Errorf("超出保留数的轮转文件 .3 应被删除")
+	}
+}
+
+func TestFileWriterRejectsZeroBackups(t *testing.T) {
+	cfg := config.EventsConfig{FileEnabled: true, FilePath: filepath.Join(t.TempDir(), "events.jsonl"), FileMaxSize: 1}
+	if _, err := newFileWriter(cfg); err == nil {
+		t.Error("保留数量为 0 时应拒绝创建（轮转会丢弃当前文件）")
+	}
+	if err := Validate(cfg); err == nil {
+		t.Error("Validate 应拒绝保留数量为 0 的配置")
+	}
+}
+
+func TestSinkPublishToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := New()
+	if err := sink.Configure(config.EventsConfig{FileEnabled: true, FilePath: path, FileMaxSize: 1, FileMaxBackups: 1}); err != nil {
+		t.Fatal(err)
+	}
+	sink.Publish(Event{Type: TypeAdminAction, Actor: "admin", Action: "POST /api/domain/add", Result: "failure"})
+	sink.Stop()
+
+	events := readEvents(t, path)
+	if len(events) != 1 {
+		t.Fatalf("期望 1 条事件，实际 %d 条", len(events))
+	}
+	if events[0].Time.IsZero() || events[0].Severity != SeverityWarning {
+		t.Errorf("事件应补全时间与严重级别: %+v", events[0])
+	}
+	if sink.Enabled() {
+		t.Error("停止后不应再接收事件")
+	}
+}
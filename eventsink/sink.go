@@ -0,0 +1,191 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Puff/config"
+	"Puff/logger"
+)
+
+const queueSize = 1000
+
+// writer 事件输出（syslog 或 JSONL 文件）
+type writer interface {
+	Write(event Event, payload []byte) error
+	Close() error
+}
+
+// Sink 事件流导出：事件进入队列后由单独的协程依次写入各输出，不阻塞监控与通知流程。
+// 与通知渠道的启用状态无关，未配置任何输出时丢弃事件
+type Sink struct {
+	mu      sync.Mutex // 保护 writers（写入期间持有，Publish 不使用）
+	writers map[string]writer
+	enabled atomic.Bool
+	queue   chan Event
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New 创建事件流导出并启动写入协程
+func New() *Sink {
+	s := &Sink{
+		writers: map[string]writer{},
+		queue:   make(chan Event, queueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Validate 校验配置（不建立连接）
+func Validate(cfg config.EventsConfig) error {
+	if cfg.SyslogEnabled {
+		if _, err := newSyslogWriter(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.FileEnabled {
+		if cfg.FilePath == "" {
+			return fmt.Errorf("未配置事件文件路径")
+		}
+		if cfg.FileMaxBackups < 1 {
+			return fmt.Errorf("事件文件保留数量至少为1")
+		}
+	}
+	return nil
+}
+
+// Configure 按配置替换输出；某个输出配置错误时其余输出仍然生效
+func (s *Sink) Configure(cfg config.EventsConfig) error {
+	writers := map[string]writer{}
+	var errs []error
+	if cfg.SyslogEnabled {
+		if w, err := newSyslogWriter(cfg); err != nil {
+			errs = append(errs, err)
+		} else {
+			writers["syslog"] = w
+		}
+	}
+	if cfg.FileEnabled {
+		if w, err := newFileWriter(cfg); err != nil {
+			errs = append(errs, err)
+		} else {
+			writers["file"] = w
+		}
+	}
+
+	s.mu.Lock()
+	old := s.writers
+	s.writers = writers
+	s.enabled.Store(len(writers) > 0)
+	s.mu.Unlock()
+	for _, w := range old {
+		w.Close()
+	}
+
+	if len(writers) > 0 {
+		logger.Info("事件流导出已启用: syslog=%t 文件=%t", writers["syslog"] != nil, writers["file"] != nil)
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled 是否配置了任何输出
+func (s *Sink) Enabled() bool {
+	return s != nil && s.enabled.Load()
+}
+
+// Publish 将事件加入队列；未配置输出时忽略，队列已满时丢弃
+func (s *Sink) Publish(event Event) {
+	if !s.Enabled() {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case s.queue <- event:
+	default:
+		logger.Warn("事件流队列已满，丢弃事件: %s %s", event.Type, event.Domain)
+	}
+}
+
+// Test 立即向各输出写入一条测试事件，返回各输出的结果
+func (s *Sink) Test() map[string]error {
+	event := Event{
+		Time:     time.Now(),
+		Type:     "test",
+		Severity: SeverityInfo,
+		Domain:   "example.com",
+		Message:  "这是一条测试事件，用于验证事件流导出是否正常工作",
+	}
+	results := map[string]error{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, w := range s.writers {
+		results[name] = write(w, event)
+	}
+	return results
+}
+
+// Stop 写完队列中剩余的事件后关闭各输出
+func (s *Sink) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// run 依次写入队列中的事件
+func (s *Sink) run() {
+	defer close(s.done)
+	for {
+		select {
+		case event := <-s.queue:
+			s.dispatch(event)
+		case <-s.stop:
+			for {
+				select {
+				case event := <-s.queue:
+					s.dispatch(event)
+				default:
+					s.mu.Lock()
+					for _, w := range s.writers {
+						w.Close()
+					}
+					s.writers = map[string]writer{}
+					s.enabled.Store(false)
+					s.mu.Unlock()
+					return
+				}
+			}
+		}
+	}
+}
+
+// dispatch 将事件写入各输出，失败只记录日志
+func (s *Sink) dispatch(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, w := range s.writers {
+		if err := write(w, event); err != nil {
+			logger.Warn("事件写入 %s 失败: %v", name, err)
+		}
+	}
+}
+
+// write 序列化并写入一条事件
+func write(w writer, event Event) error {
+	event.Severity = event.severity()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %w", err)
+	}
+	return w.Write(event, payload)
+}
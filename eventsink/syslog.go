@@ -0,0 +1,228 @@
+package eventsink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"Puff/config"
+)
+
+const (
+	syslogDialTimeout  = 10 * time.Second
+	syslogWriteTimeout = 10 * time.Second
+	syslogMaxUDPSize   = 8192 // 超过时截断消息（多数接收端的 UDP 上限）
+	syslogSDID         = "puff@32473"
+)
+
+// syslogFacilities syslog 设施名称与编号
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogWriter 以 RFC 5424 格式发送事件；TCP 与 TLS 使用 RFC 6587 的长度前缀分帧
+type syslogWriter struct {
+	network   string
+	address   string
+	facility  int
+	appName   string
+	hostname  string
+	tlsConfig *tls.Config
+	conn      net.Conn
+}
+
+// ParseFacility 解析 syslog 设施（名称或 0-23 的编号）
+func ParseFacility(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return syslogFacilities["local0"], nil
+	}
+	if n, ok := syslogFacilities[value]; ok {
+		return n, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 23 {
+		return n, nil
+	}
+	return 0, fmt.Errorf("无效的 syslog 设施: %s", value)
+}
+
+// newSyslogWriter 创建 syslog 发送器（首次写入时连接）
+func newSyslogWriter(cfg config.EventsConfig) (*syslogWriter, error) {
+	network := cfg.SyslogNetwork
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" && network != "tls" {
+		return nil, fmt.Errorf("不支持的 syslog 传输方式: %s（可选 udp、tcp、tls）", network)
+	}
+	if _, _, err := net.SplitHostPort(cfg.SyslogAddress); err != nil {
+		return nil, fmt.Errorf("syslog 地址格式错误（应为 host:port）: %w", err)
+	}
+	facility, err := ParseFacility(cfg.SyslogFacility)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &syslogWriter{
+		network:  network,
+		address:  cfg.SyslogAddress,
+		facility: facility,
+		appName:  syslogField(cfg.SyslogAppName, 48),
+		hostname: "-",
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		w.hostname = syslogField(hostname, 255)
+	}
+	if network == "tls" {
+		host, _, _ := net.SplitHostPort(cfg.SyslogAddress)
+		w.tlsConfig = &tls.Config{ServerName: host, InsecureSkipVerify: cfg.SyslogSkipVerify}
+		if strings.TrimSpace(cfg.SyslogCACert) != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(cfg.SyslogCACert)) {
+				return nil, fmt.Errorf("解析 syslog CA 证书失败")
+			}
+			w.tlsConfig.RootCAs = pool
+		}
+	}
+	return w, nil
+}
+
+// connect 建立连接
+func (w *syslogWriter) connect() error {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if w.network == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.address, w.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(w.network, w.address)
+	}
+	if err != nil {
+		return fmt.Errorf("连接 syslog 服务器 %s 失败: %w", w.address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write 发送一条事件；流式连接写入失败时重连并重试一次
+func (w *syslogWriter) Write(event Event, payload []byte) error {
+	msg := w.format(event, payload)
+	if w.network == "udp" {
+		msg = truncateUTF8(msg, syslogMaxUDPSize)
+	}
+	if w.network != "udp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return err
+			}
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+		if _, err = w.conn.Write(msg); err == nil {
+			return nil
+		}
+		w.Close()
+	}
+	return fmt.Errorf("发送 syslog 消息失败: %w", err)
+}
+
+// format 生成 RFC 5424 消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (w *syslogWriter) format(event Event, payload []byte) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		w.facility*8+event.severity(),
+		event.Time.Format(time.RFC3339Nano),
+		w.hostname,
+		w.appName,
+		os.Getpid(),
+		syslogField(event.Type, 32),
+	)
+	b.WriteString(structuredData(event))
+	b.WriteByte(' ')
+	b.Write(payload)
+	return []byte(b.String())
+}
+
+// Close 关闭连接
+func (w *syslogWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// structuredData 关键字段作为结构化数据，便于接收端无需解析 JSON 即可过滤
+func structuredData(event Event) string {
+	params := [][2]string{
+		{"domain", event.Domain},
+		{"status", event.Status},
+		{"old_status", event.OldStatus},
+		{"change", event.Change},
+		{"error_class", event.ErrorClass},
+		{"actor", event.Actor},
+		{"action", event.Action},
+		{"result", event.Result},
+	}
+	var b strings.Builder
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=\"%s\"", p[0], escapeParam(p[1]))
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return "[" + syslogSDID + b.String() + "]"
+}
+
+// escapeParam 转义结构化数据参数值中的 "、\ 与 ]
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// truncateUTF8 截断到不超过 max 字节，不切断多字节字符
+func truncateUTF8(msg []byte, max int) []byte {
+	if len(msg) <= max {
+		return msg
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n]
+}
+
+// syslogField 头部字段只允许可打印 ASCII，空值为 "-"
+func syslogField(value string, max int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+		if b.Len() >= max {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package eventsink
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Puff/config"
+)
+
+func testSyslogWriter(t *testing.T, network, address string) *syslogWriter {
+	t.Helper()
+	w, err := newSyslogWriter(config.EventsConfig{
+		SyslogNetwork:  network,
+		SyslogAddress:  address,
+		SyslogFacility: "local3",
+		SyslogAppName:  "puff",
+	})
+	if err != nil {
+		t.Fatalf("创建 syslog 发送器失败: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func TestSyslogFormat(t *testing.T) {
+	w := testSyslogWriter(t, "udp", "127.0.0.1:514")
+	w.hostname = "host1"
+	event := Event{
+		Time:      time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC),
+		Type:      TypeStatusChange,
+		Domain:    "example.com",
+		Status:    "available",
+		OldStatus: "pending_delete",
+		Actor:     `a"b]c\d`,
+	}
+	msg := string(w.format(event, []byte(`{"type":"status_change"}`)))
+
+	// local3(19)*8 + notice(5) = 157
+	want := regexp.MustCompile(`^<157>1 2026-03-01T08:30:00Z host1 puff \d+ status_change \[puff@32473 (.*)\] \{"type":"status_change"\}$`)
+	m := want.FindStringSubmatch(msg)
+	if m == nil {
+		t.Fatalf("消息格式不符合 RFC 5424: %q", msg)
+	}
+	if !strings.Contains(m[1], `domain="example.com" status="available" old_status="pending_delete"`) {
+		t.Errorf("结构化数据缺少域名字段: %q", m[1])
+	}
+	if !strings.Contains(m[1], `actor="a\"b\]c\\d"`) {
+		t.Errorf("结构化数据未正确转义: %q", m[1])
+	}
+	if !strings.Contains(msg, " "+strconv.Itoa(os.Getpid())+" ") {
+		t.Errorf("PROCID 不是进程号: %q", msg)
+	}
+}
+
+func TestSyslogFormatNoStructuredData(t *testing.T) {
+	w := testSyslogWriter(t, "udp", "127.0.0.1:514")
+	msg := string(w.format(Event{Time: time.Now(), Type: "test", Severity: SeverityInfo}, []byte("{}")))
+	if !strings.HasPrefix(msg, "<158>1 ") || !strings.HasSuffix(msg, " test - {}") {
+		t.Errorf("无结构化数据时应为 -: %q", msg)
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+			if err != nil {
+				frames <- "长度前缀无效: " + prefix
+				return
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			frames <- string(buf)
+		}
+	}()
+
+	w := testSyslogWriter(t, "tcp", ln.Addr().String())
+	for _, domain := range []string{"a.com", "域名.中国"} {
+		event := Event{Time: time.Now(), Type: TypeFieldChange, Domain: domain}
+		if err := write(w, event); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	for _, domain := range []string{"a.com", "域名.中国"} {
+		select {
+		case frame := <-frames:
+			if !strings.HasPrefix(frame, "<") || !strings.Contains(frame, `domain="`+domain+`"`) {
+				t.Errorf("帧内容不完整: %q", frame)
+			}
+			if !strings.HasSuffix(frame, "}") {
+				t.Errorf("帧未以 JSON 结尾（长度前缀错误）: %q", frame)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("等待 syslog 帧超时")
+		}
+	}
+}
+
+func TestSyslogUDPTruncation(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w := testSyslogWriter(t, "udp", pc.LocalAddr().String())
+	event := Event{Time: time.Now(), Type: TypeCheckFailure, Domain: "example.com", Message: strings.Repeat("查询失败", 2000)}
+	if err := write(w, event); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	buf := make([]byte, 65536)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("接收 UDP 消息失败: %v", err)
+	}
+	if n > syslogMaxUDPSize {
+		t.Errorf("UDP 消息 %d 字节，超过上限 %d", n, syslogMaxUDPSize)
+	}
+	if n < syslogMaxUDPSize-utf8.UTFMax {
+		t.Errorf("UDP 消息被过度截断: %d 字节", n)
+	}
+	if !utf8.Valid(buf[:n]) {
+		t.Error("UDP 消息截断后不是有效的 UTF-8")
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"a中文", 2, "a"},
+		{"a中文", 4, "a中"},
+		{"a中文", 5, "a中"},
+		{"a中文", 7, "a中文"},
+		{"中", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := string(truncateUTF8([]byte(tt.in), tt.max)); got != tt.want {
+			t.Errorf("truncateUTF8(%q, %d) = %q, 期望 %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestParseFacility(t *testing.T) {
+	tests := map[string]int{"": 16, "daemon": 3, "LOCAL7": 23, "4": 4}
+	for value, want := range tests {
+		if got, err := ParseFacility(value); err != nil || got != want {
+			t.Errorf("ParseFacility(%q) = %d, %v, 期望 %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"local8", "24", "-1"} {
+		if _, err := ParseFacility(value); err == nil {
+			t.Errorf("ParseFacility(%q) 应返回错误", value)
+		}
+	}
+}
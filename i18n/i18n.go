@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	En   = "en"
+
+	// Default 默认语言（未指定或不支持时使用）
+	Default = ZhCN
+)
+
+// Names 各语言的显示名称（以该语言书写）
+var Names = map[string]string{
+	ZhCN: "简体中文",
+	En:   "English",
+}
+
+// catalogs 各语言的消息目录：键 -> 文本（可含 fmt 占位符）
+var catalogs = map[string]map[string]string{
+	ZhCN: zhCN,
+	En:   en,
+}
+
+// Supported 返回支持的语言列表（默认语言在前）
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		if locale != Default {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{Default}, locales...)
+}
+
+// Normalize 将语言标签规范为支持的语言，如 "en-US" -> "en"、"zh" -> "zh-CN"；不支持时返回空
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, "_", "-")))
+	if tag == "" {
+		return ""
+	}
+	for locale := range catalogs {
+		if strings.ToLower(locale) == tag {
+			return locale
+		}
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	switch primary {
+	case "zh":
+		return ZhCN
+	case "en":
+		return En
+	}
+	return ""
+}
+
+// Resolve 返回候选中第一个支持的语言，都不支持时返回默认语言
+func Resolve(candidates ...string) string {
+	for _, c := range candidates {
+		if locale := Normalize(c); locale != "" {
+			return locale
+		}
+	}
+	return Default
+}
+
+// FromAcceptLanguage 按 Accept-Language 的权重选择支持的语言，都不支持时返回空
+func FromAcceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if locale := Normalize(tag); locale != "" && q > bestQ {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+// T 返回指定语言的消息，缺失时依次回退到默认语言与键本身
+func T(locale, key string, args ...interface{}) string {
+	text, ok := catalogs[Resolve(locale)][key]
+	if !ok {
+		if text, ok = catalogs[Default][key]; !ok {
+			text = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Has 判断默认语言目录中是否存在该键
+func Has(key string) bool {
+	_, ok := catalogs[Default][key]
+	return ok
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// verbPattern fmt 占位符（不含 %%）
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z]`)
+
+// sampleArgs 按默认语言文本的占位符生成示例参数
+func sampleArgs(text string) []interface{} {
+	var args []interface{}
+	for _, verb := range verbPattern.FindAllString(strings.ReplaceAll(text, "%%", ""), -1) {
+		switch verb[len(verb)-1] {
+		case 'd':
+			args = append(args, 1)
+		default:
+			args = append(args, "x")
+		}
+	}
+	return args
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key := range zhCN {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s 缺少键 %s", locale, key)
+			}
+		}
+		for key := range catalog {
+			if _, ok := zhCN[key]; !ok {
+				t.Errorf("%s 含有默认语言未定义的键 %s", locale, key)
+			}
+		}
+	}
+}
+
+func TestCatalogPlaceholders(t *testing.T) {
+	for key, text := range zhCN {
+		args := sampleArgs(text)
+		for _, locale := range Supported() {
+			if got := T(locale, key, args...); strings.Contains(got, "%!") {
+				t.Errorf("%s 的 %s 占位符与默认语言不一致: %q", locale, key, got)
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"en":      En,
+		"en-US":   En,
+		"EN_gb":   En,
+		"zh":      ZhCN,
+		"zh-cn":   ZhCN,
+		"zh-Hant": ZhCN,
+		" zh-CN ": ZhCN,
+		"fr":      "",
+		"":        "",
+	}
+	for tag, want := range tests {
+		if got := Normalize(tag); got != want {
+			t.Errorf("Normalize(%q) = %q，期望 %q", tag, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if got := Resolve("", "fr", "en-US"); got != En {
+		t.Errorf("Resolve 应返回第一个支持的语言，实际 %q", got)
+	}
+	if got := Resolve("fr"); got != Default {
+		t.Errorf("都不支持时应返回默认语言，实际 %q", got)
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"en-US,en;q=0.9":             En,
+		"fr-FR,fr;q=0.9,zh-CN;q=0.8": ZhCN,
+		"zh-CN;q=0.5,en;q=0.8":       En,
+		"de,fr":                      "",
+		"":                           "",
+		"en;q=abc":                   En,
+	}
+	for header, want := range tests {
+		if got := FromAcceptLanguage(header); got != want {
+			t.Errorf("FromAcceptLanguage(%q) = %q，期望 %q", header, got, want)
+		}
+	}
+}
+
+func TestTFallback(t *testing.T) {
+	if got := T("fr", "status.available"); got != zhCN["status.available"] {
+		t.Errorf("不支持的语言应回退到默认语言，实际 %q", got)
+	}
+	if got := T(En, "no.such.key"); got != "no.such.key" {
+		t.Errorf("缺失的键应原样返回，实际 %q", got)
+	}
+	if got := T(En, "digest.days_left", 3); got != "3 days left" {
+		t.Errorf("T 格式化结果错误: %q", got)
+	}
+}
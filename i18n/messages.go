@@ -0,0 +1,660 @@
+package i18n
+
+// zhCN 简体中文消息目录（默认语言，所有键都应在此定义）
+var zhCN = map[string]string{
+	// 域名状态（简称）
+	"status.available":      "可注册",
+	"status.registered":     "已注册",
+	"status.grace":          "宽限期",
+	"status.redemption":     "赎回期",
+	"status.pending_delete": "待删除",
+	"status.expired":        "已过期",
+	"status.error":          "查询失败",
+	"status.unknown":        "未知",
+
+	// 域名状态（描述）
+	"status.available.desc":       "域名可注册",
+	"status.grace.desc":           "宽限期",
+	"status.redemption.desc":      "域名在赎回期",
+	"status.pending_delete.desc":  "域名待删除/抢注期",
+	"status.registered.desc":      "域名已注册",
+	"status.transfer_locked.desc": "域名转移锁定",
+	"status.unknown.desc":         "未知状态",
+	"status.error.desc":           "查询错误",
+	"status.change_message":       "域名 %s 状态发生变化：从 [%s] 变为 [%s]",
+
+	// 字段变化
+	"field.registrar_change":  "注册商",
+	"field.nameserver_change": "名称服务器",
+	"field.expiry_change":     "过期时间",
+	"field.epp_status_change": "EPP状态码",
+	"field.change_message":    "域名 %s 的%s发生变化：从 [%s] 变为 [%s]",
+
+	// 续费提醒
+	"reminder.expired": "域名 %s 已于 %s 过期 %d 天，请尽快续费",
+	"reminder.today":   "域名 %s 将于今天（%s）过期，请尽快续费",
+	"reminder.days":    "域名 %s 将于 %s 过期，剩余 %d 天",
+
+	// 通知主题
+	"notify.subject.status_change":   "%s 状态变化",
+	"notify.subject.available":       "%s 可注册！",
+	"notify.subject.redemption":      "%s 进入赎回期",
+	"notify.subject.pending_delete":  "%s 进入待删除期",
+	"notify.subject.error":           "%s 查询失败",
+	"notify.subject.field_change":    "%s %s变更",
+	"notify.subject.expiry_reminder": "%s 续费提醒",
+	"notify.subject.default":         "%s 通知",
+	"notify.subject.batch":           "域名状态变化通知 (%d个域名)",
+
+	// 通知正文的字段标签（各渠道据此解析通知文本）
+	"notify.label.domain":         "域名",
+	"notify.label.time":           "时间",
+	"notify.label.status_change":  "状态变化",
+	"notify.label.status":         "状态",
+	"notify.label.old_value":      "原值",
+	"notify.label.new_value":      "新值",
+	"notify.label.detail":         "详细信息",
+	"notify.label.error":          "错误信息",
+	"notify.label.first_notified": "首次通知",
+	"notify.label.old_status":     "旧状态",
+	"notify.label.new_status":     "新状态",
+	"notify.label.change":         "变化",
+
+	// 通知正文
+	"notify.status.field_change":  "%s变更",
+	"notify.status.expiring":      "即将过期",
+	"notify.hint.available":       "此域名现在可以注册！",
+	"notify.hint.redemption":      "此域名现在处于赎回期，可以尝试赎回。",
+	"notify.hint.pending_delete":  "此域名即将删除，进入抢注阶段！",
+	"notify.hint.expiry_reminder": "此域名为自有域名，请及时续费。",
+	"notify.whois_header":         "=== WHOIS/RDAP 信息 ===",
+	"notify.truncated":            "...(已截断)",
+	"notify.footer":               "此消息由 Puff 自动发送",
+	"notify.batch_header":         "检测到 %d 个域名状态发生变化",
+	"notify.title":                "域名状态变化通知",
+	"notify.batch_title":          "域名状态变化通知 (%d个)",
+	"notify.from":                 "来自 Puff",
+	"notify.alert_required":       "⚠️ %s 需要确认（告警 #%d）",
+	"notify.ack":                  "确认",
+	"notify.snooze":               "暂停1小时",
+	"notify.reminder.subject":     "【第%d次提醒】域名 %s %s",
+	"notify.reminder.message":     "域名 %s 仍处于%s状态，尚未确认",
+	"notify.reminder.count":       "已提醒: %d 次",
+	"notify.reminder.max":         "已达到最大提醒次数，此后不再重复",
+	"notify.reminder.batch":       "【提醒】%d 个告警尚未确认",
+	"notify.csv.header":           "域名,事件,原状态,新状态,时间,说明",
+	"notify.csv.caption":          "%s\n共 %d 条变化，详见附件\n时间: %s",
+
+	// 查询健康告警与测试通知
+	"notify.health.subject.health_lookup_failure":   "【监控异常】域名 %s 持续查询失败",
+	"notify.health.subject.health_lookup_recovered": "【监控恢复】域名 %s 查询已恢复",
+	"notify.health.subject.health_server_down":      "【监控异常】查询服务器 %s 不可用",
+	"notify.health.subject.health_server_recovered": "【监控恢复】查询服务器 %s 已恢复",
+	"notify.health.subject.default":                 "【监控健康】%s",
+	"notify.health.health_lookup_failure":           "域名 %s 已连续 %d 次查询失败，当前未被有效监控: %s",
+	"notify.health.health_lookup_recovered":         "域名 %s 查询已恢复（此前连续失败 %d 次）",
+	"notify.health.health_server_down":              "查询服务器 %s 最近 %d 分钟内 %d/%d 个域名查询失败",
+	"notify.health.health_server_recovered":         "查询服务器 %s 已恢复，最近 %d 分钟内 %d/%d 个域名查询失败",
+	"notify.health.server":                          "查询服务器",
+	"notify.test.subject":                           "Puff 通知测试",
+	"notify.test.exec":                              "这是一条测试通知，用于验证外部命令是否正常工作",
+	"notify.test.mqtt":                              "这是一条测试通知，用于验证MQTT发布是否正常工作",
+	"notify.test.mqtt_result":                       "已发布测试事件到 %s，并同步 %d 个域名的状态",
+
+	// 日历订阅
+	"calendar.name":           "Puff 域名日历",
+	"calendar.expiry":         "%s 过期",
+	"calendar.expiry_desc":    "域名 %s 过期日期\n当前状态: %s",
+	"calendar.grace":          "宽限期结束",
+	"calendar.redemption":     "赎回期结束",
+	"calendar.pending_delete": "预计删除",
+	"calendar.phase":          "%s %s（预计）",
+	"calendar.phase_desc":     "域名 %s 过期 %d 天后%s（根据后缀标准生命周期估算）\n当前状态: %s",
+
+	// 定期摘要
+	"digest.daily":         "每日摘要",
+	"digest.weekly":        "每周摘要",
+	"digest.period":        "统计区间",
+	"digest.total":         "监控域名",
+	"digest.expiring":      "%d 天内过期",
+	"digest.changes":       "状态变化",
+	"digest.errors":        "持续查询失败",
+	"digest.more":          "… 另有 %d 个",
+	"digest.none":          "无",
+	"digest.footer":        "此邮件由 Puff 自动发送",
+	"digest.days_left":     "剩余 %d 天",
+	"digest.days_expired":  "已过期 %d 天",
+	"digest.expired_today": "今天已过期",
+
+	// 通知渠道类型
+	"channel.email":      "邮件",
+	"channel.telegram":   "Telegram",
+	"channel.exec":       "外部命令",
+	"channel.mqtt":       "MQTT",
+	"channel.url":        "通知URL",
+	"channel.webhook":    "Webhook",
+	"channel.dingtalk":   "钉钉",
+	"channel.feishu":     "飞书",
+	"channel.wecom":      "企业微信",
+	"channel.bark":       "Bark",
+	"channel.ntfy":       "ntfy",
+	"channel.gotify":     "Gotify",
+	"channel.serverchan": "Server酱",
+
+	// 告警确认页面
+	"alert.title":   "Puff 告警",
+	"alert.heading": "告警 #%d",
+	"alert.domain":  "域名",
+	"alert.status":  "状态",
+	"alert.snooze":  "暂停提醒 %d 分钟",
+	"alert.ack":     "确认告警",
+
+	// 登录页
+	"login.title":         "登录",
+	"login.username":      "用户名",
+	"login.password":      "密码",
+	"login.submit":        "登录",
+	"login.failed":        "登录失败",
+	"login.network_error": "网络错误，请重试",
+
+	// 接口提示
+	"msg.domain_added":         "域名添加成功",
+	"msg.domain_removed":       "域名删除成功",
+	"msg.password_changed":     "密码修改成功",
+	"msg.username_updated":     "用户名更新成功",
+	"msg.webhook_saved":        "Webhook设置保存成功",
+	"msg.webhook_test_ok":      "测试Webhook请求发送成功",
+	"msg.monitor_saved":        "监控参数保存成功并已热重载",
+	"msg.cleanup_done":         "孤立数据清理完成",
+	"msg.no_notifiers":         "未启用任何通知方式，请先在设置中添加并启用通知渠道",
+	"msg.test_done":            "测试通知发送完成",
+	"msg.test_all_failed":      "所有通知方式发送失败",
+	"msg.test_partial_failed":  "部分通知方式发送失败",
+	"msg.send_ok":              "发送成功",
+	"msg.send_failed":          "发送失败: %s",
+	"msg.locales_saved":        "通知语言设置已保存",
+	"msg.events_saved":         "事件流导出设置已保存",
+	"msg.events_test_done":     "测试事件已写入",
+	"msg.events_disabled":      "未启用任何事件输出",
+	"msg.alert_acked":          "告警已确认，不再重复提醒",
+	"msg.alert_snoozed":        "已暂停提醒至 %s",
+	"msg.alert_handled":        "该告警已处理，无需操作",
+	"msg.escalation_added":     "提醒策略已添加",
+	"msg.escalation_updated":   "提醒策略已更新",
+	"msg.escalation_deleted":   "提醒策略已删除",
+	"msg.batching_saved":       "通知合并设置已保存",
+	"msg.digest_saved":         "摘要设置已保存",
+	"msg.digest_queued":        "摘要已加入发件箱",
+	"msg.health_saved":         "健康告警设置已保存",
+	"msg.notifier_added":       "通知渠道已添加",
+	"msg.notifier_updated":     "通知渠道已更新",
+	"msg.notifier_deleted":     "通知渠道已删除",
+	"msg.test_sent":            "测试%s发送成功",
+	"msg.push_test_sent":       "测试%s推送发送成功，请检查手机",
+	"msg.robot_test_sent":      "测试%s消息发送成功，请检查群聊",
+	"msg.settings_saved":       "%s设置保存成功",
+	"msg.outbox_replayed":      "已重新投递 %d 条通知",
+	"msg.owned_set":            "已标记为自有域名",
+	"msg.owned_unset":          "已取消自有域名标记",
+	"msg.rule_added":           "路由规则已添加",
+	"msg.rule_updated":         "路由规则已更新",
+	"msg.rule_deleted":         "路由规则已删除",
+	"msg.subscription_saved":   "订阅已保存",
+	"msg.subscription_deleted": "订阅已删除",
+	"msg.template_added":       "通知模板已添加",
+	"msg.template_updated":     "通知模板已更新",
+	"msg.template_deleted":     "通知模板已删除，恢复内置排版",
+	"msg.all_targets":          "全部通知器",
+
+	// 接口错误（键为 error.<错误码>）
+	"error.bad_request":                      "请求无效",
+	"error.unauthorized":                     "未登录或会话已过期",
+	"error.forbidden":                        "无权访问",
+	"error.not_found":                        "资源不存在",
+	"error.method_not_allowed":               "不允许的请求方法",
+	"error.conflict":                         "资源冲突",
+	"error.rate_limited":                     "请求过于频繁，请稍后再试",
+	"error.internal_error":                   "服务器内部错误",
+	"error.invalid_json":                     "请求体不是有效的JSON",
+	"error.login_rate_limited":               "登录尝试过于频繁，请5分钟后再试",
+	"error.login_failed":                     "用户名或密码错误",
+	"error.domain_required":                  "域名不能为空",
+	"error.domain_invalid":                   "域名格式无效: %s",
+	"error.tld_unsupported":                  "该后缀目前不支持进行监控",
+	"error.domain_exists":                    "域名已存在",
+	"error.domain_check_failed":              "检查域名失败: %s",
+	"error.domain_add_failed":                "添加域名失败: %s",
+	"error.domain_reload_failed":             "重新加载域名失败: %s",
+	"error.domain_list_failed":               "获取域名列表失败: %s",
+	"error.domain_remove_failed":             "删除域名失败: %s",
+	"error.domain_info_failed":               "获取域名信息失败: %s",
+	"error.domain_query_failed":              "查询域名失败: %s",
+	"error.monitor_start_failed":             "启动监控失败: %s",
+	"error.monitor_reload_failed":            "重新加载域名列表失败: %s",
+	"error.batch_rate_limited":               "批量添加过于频繁，请1分钟后再试",
+	"error.batch_empty":                      "未提供任何域名",
+	"error.batch_too_many":                   "一次最多添加%d个域名",
+	"error.password_incorrect":               "当前密码错误",
+	"error.password_too_short":               "新密码长度至少%d位",
+	"error.password_update_failed":           "更新密码失败",
+	"error.username_too_short":               "用户名长度至少%d位",
+	"error.username_update_failed":           "更新用户名失败",
+	"error.retries_out_of_range":             "重试次数必须在0-10之间",
+	"error.invalid_config":                   "%s",
+	"error.settings_save_failed":             "保存设置失败: %s",
+	"error.interval_too_small":               "检查间隔不能小于%d秒",
+	"error.concurrency_out_of_range":         "并发限制必须在1-1000之间",
+	"error.timeout_out_of_range":             "超时时间必须在1-120秒之间",
+	"error.max_attempts_out_of_range":        "最大投递次数必须在1-50之间",
+	"error.public_url_invalid":               "对外访问地址必须以 http:// 或 https:// 开头",
+	"error.whois_not_found":                  "暂无WHOIS数据，请等待查询完成",
+	"error.cleanup_failed":                   "清理失败: %s",
+	"error.webhook_disabled":                 "Webhook通知未启用，请先在设置中启用并配置Webhook地址",
+	"error.webhook_not_found":                "未找到Webhook通知器",
+	"error.webhook_test_failed":              "测试Webhook发送失败: %s",
+	"error.update_check_failed":              "无法检查更新",
+	"error.update_parse_failed":              "解析更新信息失败",
+	"error.locale_unsupported":               "不支持的语言: %s",
+	"error.operation_failed":                 "操作失败: %s",
+	"error.alert_list_failed":                "获取告警失败: %s",
+	"error.alert_id_invalid":                 "无效的告警ID",
+	"error.alert_closed":                     "告警不存在或已处理",
+	"error.alert_not_found":                  "告警不存在",
+	"error.alert_link_invalid":               "链接无效",
+	"error.alert_link_expired":               "链接已过期，请使用最新提醒中的链接",
+	"error.action_unknown":                   "未知的操作: %s",
+	"error.snooze_out_of_range":              "暂停时长必须在1-%d分钟之间",
+	"error.escalation_list_failed":           "获取提醒策略失败: %s",
+	"error.escalation_id_invalid":            "无效的策略ID",
+	"error.escalation_not_found":             "提醒策略不存在",
+	"error.status_invalid":                   "无效的状态: %s",
+	"error.escalation_interval_out_of_range": "重复间隔必须在1-1440分钟之间",
+	"error.escalation_repeats_negative":      "最大重复次数不能为负数",
+	"error.batch_count_failed":               "统计待汇总通知失败: %s",
+	"error.batch_window_out_of_range":        "合并窗口必须在1-3600秒之间",
+	"error.batch_lead_out_of_range":          "提前发送时间必须在0到合并窗口之间",
+	"error.batch_limit_out_of_range":         "批量上限必须在1-1000之间",
+	"error.calendar_token_failed":            "获取日历令牌失败: %s",
+	"error.success_param_invalid":            "success 参数必须为 true 或 false",
+	"error.since_invalid":                    "since 参数无效: %s",
+	"error.until_invalid":                    "until 参数无效: %s",
+	"error.delivery_list_failed":             "获取投递日志失败: %s",
+	"error.digest_frequency_invalid":         "发送频率必须为 daily 或 weekly",
+	"error.digest_hour_out_of_range":         "发送时刻必须在0-23之间",
+	"error.digest_weekday_out_of_range":      "星期必须在0-6之间（0为周日）",
+	"error.digest_expiry_out_of_range":       "过期天数必须在1-365之间",
+	"error.digest_build_failed":              "生成摘要失败: %s",
+	"error.digest_send_failed":               "发送摘要失败: %s",
+	"error.health_failures_out_of_range":     "连续失败次数必须在1-100之间",
+	"error.health_window_out_of_range":       "统计窗口必须在10分钟到7天之间",
+	"error.health_percent_out_of_range":      "失败占比必须在1-100之间",
+	"error.health_min_domains_invalid":       "最少域名数必须大于0",
+	"error.history_list_failed":              "获取状态历史失败: %s",
+	"error.snapshot_list_failed":             "获取快照列表失败: %s",
+	"error.snapshot_id_invalid":              "无效的快照ID",
+	"error.snapshot_load_failed":             "获取快照失败: %s",
+	"error.snapshot_not_found":               "快照不存在",
+	"error.snapshot_diff_failed":             "比较快照失败: %s",
+	"error.notifier_list_failed":             "获取通知渠道失败: %s",
+	"error.notifier_type_unsupported":        "不支持的通知渠道类型: %s",
+	"error.notifier_id_invalid":              "无效的通知渠道ID",
+	"error.notifier_not_found":               "通知渠道不存在",
+	"error.notifier_name_required":           "通知渠道名称不能为空",
+	"error.notifier_missing":                 "未找到通知渠道 %s",
+	"error.notifier_disabled":                "%s 未启用，请先启用并完善配置",
+	"error.push_disabled":                    "%s通知未启用，请先在设置中启用并配置密钥",
+	"error.robot_disabled":                   "%s通知未启用，请先在设置中启用并配置机器人地址",
+	"error.test_failed":                      "测试%s发送失败: %s",
+	"error.outbox_list_failed":               "获取发件箱失败: %s",
+	"error.record_id_invalid":                "无效的记录ID",
+	"error.owned_list_failed":                "获取自有域名失败: %s",
+	"error.reminder_days_out_of_range":       "提醒天数必须在 1-3650 之间",
+	"error.push_url_invalid":                 "%s服务地址格式无效",
+	"error.push_key_required":                "%s密钥不能为空",
+	"error.gotify_url_required":              "Gotify服务地址不能为空",
+	"error.robot_url_invalid":                "%s机器人Webhook地址格式无效",
+	"error.rule_list_failed":                 "获取路由规则失败: %s",
+	"error.rule_id_invalid":                  "无效的规则ID",
+	"error.rule_not_found":                   "路由规则不存在",
+	"error.rule_name_required":               "规则名称不能为空",
+	"error.event_type_invalid":               "无效的事件类型: %s",
+	"error.domain_pattern_invalid":           "域名匹配模式无效: %s",
+	"error.error_class_invalid":              "无效的错误分类: %s",
+	"error.error_class_event_type":           "错误分类条件仅适用于事件类型 %s",
+	"error.throttle_negative":                "节流间隔不能为负数",
+	"error.target_unknown":                   "未知的通知目标: %s",
+	"error.subscription_list_failed":         "获取订阅失败: %s",
+	"error.change_types_required":            "变化类型不能为空",
+	"error.change_type_unsupported":          "不支持的变化类型: %s",
+	"error.subscription_id_invalid":          "无效的订阅ID",
+	"error.template_list_failed":             "获取通知模板失败: %s",
+	"error.template_id_invalid":              "无效的模板ID",
+	"error.template_not_found":               "通知模板不存在",
+	"error.channel_unknown":                  "未知的通知渠道: %s",
+	"error.template_kind_invalid":            "无效的模板类型: %s",
+	"error.template_empty":                   "主题与正文模板不能同时为空",
+	"error.events_file_size_out_of_range":    "事件文件大小上限必须在1-10240MB之间",
+	"error.events_file_backups_out_of_range": "事件文件保留数量必须在1-100之间",
+}
+
+// en 英文消息目录
+var en = map[string]string{
+	"status.available":      "Available",
+	"status.registered":     "Registered",
+	"status.grace":          "Grace period",
+	"status.redemption":     "Redemption",
+	"status.pending_delete": "Pending delete",
+	"status.expired":        "Expired",
+	"status.error":          "Lookup failed",
+	"status.unknown":        "Unknown",
+
+	"status.available.desc":       "Domain is available",
+	"status.grace.desc":           "Grace period",
+	"status.redemption.desc":      "Domain is in redemption",
+	"status.pending_delete.desc":  "Domain is pending delete / drop",
+	"status.registered.desc":      "Domain is registered",
+	"status.transfer_locked.desc": "Domain is transfer locked",
+	"status.unknown.desc":         "Unknown status",
+	"status.error.desc":           "Lookup error",
+	"status.change_message":       "Domain %s status changed from [%s] to [%s]",
+
+	"field.registrar_change":  "Registrar",
+	"field.nameserver_change": "Name servers",
+	"field.expiry_change":     "Expiry date",
+	"field.epp_status_change": "EPP status codes",
+	"field.change_message":    "Domain %s: %s changed from [%s] to [%s]",
+
+	"reminder.expired": "Domain %s expired on %s, %d days ago; please renew it as soon as possible",
+	"reminder.today":   "Domain %s expires today (%s); please renew it as soon as possible",
+	"reminder.days":    "Domain %s expires on %s, %d days left",
+
+	"notify.subject.status_change":   "%s status changed",
+	"notify.subject.available":       "%s is available!",
+	"notify.subject.redemption":      "%s entered redemption",
+	"notify.subject.pending_delete":  "%s is pending delete",
+	"notify.subject.error":           "%s lookup failed",
+	"notify.subject.field_change":    "%s %s changed",
+	"notify.subject.expiry_reminder": "%s renewal reminder",
+	"notify.subject.default":         "%s notification",
+	"notify.subject.batch":           "Domain status changes (%d domains)",
+
+	"notify.label.domain":         "Domain",
+	"notify.label.time":           "Time",
+	"notify.label.status_change":  "Status change",
+	"notify.label.status":         "Status",
+	"notify.label.old_value":      "Old value",
+	"notify.label.new_value":      "New value",
+	"notify.label.detail":         "Details",
+	"notify.label.error":          "Error",
+	"notify.label.first_notified": "First notified",
+	"notify.label.old_status":     "Old status",
+	"notify.label.new_status":     "New status",
+	"notify.label.change":         "Change",
+
+	"notify.status.field_change":  "%s changed",
+	"notify.status.expiring":      "Expiring soon",
+	"notify.hint.available":       "This domain can be registered now!",
+	"notify.hint.redemption":      "This domain is in redemption and may be restored.",
+	"notify.hint.pending_delete":  "This domain is about to be deleted and enters the drop phase!",
+	"notify.hint.expiry_reminder": "This is one of your own domains, please renew it in time.",
+	"notify.whois_header":         "=== WHOIS/RDAP data ===",
+	"notify.truncated":            "...(truncated)",
+	"notify.footer":               "This message was sent automatically by Puff",
+	"notify.batch_header":         "Detected status changes for %d domains",
+	"notify.title":                "Domain status change",
+	"notify.batch_title":          "Domain status changes (%d)",
+	"notify.from":                 "From Puff",
+	"notify.alert_required":       "⚠️ %s needs acknowledgement (alert #%d)",
+	"notify.ack":                  "Acknowledge",
+	"notify.snooze":               "Snooze 1h",
+	"notify.reminder.subject":     "[Reminder #%d] %s %s",
+	"notify.reminder.message":     "Domain %s is still %s and has not been acknowledged",
+	"notify.reminder.count":       "Reminded: %d times",
+	"notify.reminder.max":         "Maximum number of reminders reached, no further repeats",
+	"notify.reminder.batch":       "[Reminder] %d alerts not acknowledged",
+	"notify.csv.header":           "Domain,Event,Old status,New status,Time,Details",
+	"notify.csv.caption":          "%s\n%d changes, see attachment\nTime: %s",
+
+	"notify.health.subject.health_lookup_failure":   "[Monitoring] Lookups for %s keep failing",
+	"notify.health.subject.health_lookup_recovered": "[Recovered] Lookups for %s recovered",
+	"notify.health.subject.health_server_down":      "[Monitoring] Lookup server %s is unavailable",
+	"notify.health.subject.health_server_recovered": "[Recovered] Lookup server %s recovered",
+	"notify.health.subject.default":                 "[Monitoring health] %s",
+	"notify.health.health_lookup_failure":           "Lookups for %s failed %d times in a row, the domain is not being monitored: %s",
+	"notify.health.health_lookup_recovered":         "Lookups for %s recovered (after %d consecutive failures)",
+	"notify.health.health_server_down":              "Lookup server %s: %[3]d/%[4]d domain lookups failed in the last %[2]d minutes",
+	"notify.health.health_server_recovered":         "Lookup server %s recovered: %[3]d/%[4]d domain lookups failed in the last %[2]d minutes",
+	"notify.health.server":                          "Lookup server",
+	"notify.test.subject":                           "Puff notification test",
+	"notify.test.exec":                              "This is a test notification to verify that the external command works",
+	"notify.test.mqtt":                              "This is a test notification to verify that MQTT publishing works",
+	"notify.test.mqtt_result":                       "Published a test event to %s and synced the state of %d domains",
+
+	"calendar.name":           "Puff domain calendar",
+	"calendar.expiry":         "%s expires",
+	"calendar.expiry_desc":    "Expiry date of %s\nCurrent status: %s",
+	"calendar.grace":          "grace period ends",
+	"calendar.redemption":     "redemption ends",
+	"calendar.pending_delete": "expected deletion",
+	"calendar.phase":          "%s %s (estimated)",
+	"calendar.phase_desc":     "%[1]s: %[3]s %[2]d days after expiry (estimated from the TLD's standard lifecycle)\nCurrent status: %[4]s",
+
+	"digest.daily":         "Daily digest",
+	"digest.weekly":        "Weekly digest",
+	"digest.period":        "Period",
+	"digest.total":         "Monitored domains",
+	"digest.expiring":      "Expiring within %d days",
+	"digest.changes":       "Status changes",
+	"digest.errors":        "Persistent lookup failures",
+	"digest.more":          "… and %d more",
+	"digest.none":          "None",
+	"digest.footer":        "This email was sent automatically by Puff",
+	"digest.days_left":     "%d days left",
+	"digest.days_expired":  "expired %d days ago",
+	"digest.expired_today": "expired today",
+
+	"channel.email":      "Email",
+	"channel.telegram":   "Telegram",
+	"channel.exec":       "External command",
+	"channel.mqtt":       "MQTT",
+	"channel.url":        "Notification URL",
+	"channel.webhook":    "Webhook",
+	"channel.dingtalk":   "DingTalk",
+	"channel.feishu":     "Feishu",
+	"channel.wecom":      "WeCom",
+	"channel.bark":       "Bark",
+	"channel.ntfy":       "ntfy",
+	"channel.gotify":     "Gotify",
+	"channel.serverchan": "ServerChan",
+
+	"alert.title":   "Puff alert",
+	"alert.heading": "Alert #%d",
+	"alert.domain":  "Domain",
+	"alert.status":  "Status",
+	"alert.snooze":  "Snooze for %d minutes",
+	"alert.ack":     "Acknowledge alert",
+
+	"login.title":         "Sign in",
+	"login.username":      "Username",
+	"login.password":      "Password",
+	"login.submit":        "Sign in",
+	"login.failed":        "Sign in failed",
+	"login.network_error": "Network error, please try again",
+
+	"msg.domain_added":         "Domain added successfully",
+	"msg.domain_removed":       "Domain removed successfully",
+	"msg.password_changed":     "Password changed",
+	"msg.username_updated":     "Username updated",
+	"msg.webhook_saved":        "Webhook settings saved",
+	"msg.webhook_test_ok":      "Test webhook request sent",
+	"msg.monitor_saved":        "Monitor settings saved and reloaded",
+	"msg.cleanup_done":         "Orphaned data cleaned up",
+	"msg.no_notifiers":         "No notification channel is enabled; add and enable one in settings first",
+	"msg.test_done":            "Test notifications sent",
+	"msg.test_all_failed":      "All notification channels failed",
+	"msg.test_partial_failed":  "Some notification channels failed",
+	"msg.send_ok":              "Sent",
+	"msg.send_failed":          "Failed: %s",
+	"msg.locales_saved":        "Notification language settings saved",
+	"msg.events_saved":         "Event stream export settings saved",
+	"msg.events_test_done":     "Test event written",
+	"msg.events_disabled":      "No event output is enabled",
+	"msg.alert_acked":          "Alert acknowledged, no more reminders",
+	"msg.alert_snoozed":        "Reminders snoozed until %s",
+	"msg.alert_handled":        "This alert has already been handled",
+	"msg.escalation_added":     "Reminder policy added",
+	"msg.escalation_updated":   "Reminder policy updated",
+	"msg.escalation_deleted":   "Reminder policy deleted",
+	"msg.batching_saved":       "Notification batching settings saved",
+	"msg.digest_saved":         "Digest settings saved",
+	"msg.digest_queued":        "Digest added to the outbox",
+	"msg.health_saved":         "Health alert settings saved",
+	"msg.notifier_added":       "Notification channel added",
+	"msg.notifier_updated":     "Notification channel updated",
+	"msg.notifier_deleted":     "Notification channel deleted",
+	"msg.test_sent":            "Test %s sent successfully",
+	"msg.push_test_sent":       "Test %s push sent, please check your phone",
+	"msg.robot_test_sent":      "Test %s message sent, please check the group chat",
+	"msg.settings_saved":       "%s settings saved",
+	"msg.outbox_replayed":      "Requeued %d notifications",
+	"msg.owned_set":            "Marked as owned domain",
+	"msg.owned_unset":          "Owned domain mark removed",
+	"msg.rule_added":           "Routing rule added",
+	"msg.rule_updated":         "Routing rule updated",
+	"msg.rule_deleted":         "Routing rule deleted",
+	"msg.subscription_saved":   "Subscription saved",
+	"msg.subscription_deleted": "Subscription deleted",
+	"msg.template_added":       "Notification template added",
+	"msg.template_updated":     "Notification template updated",
+	"msg.template_deleted":     "Notification template deleted, built-in layout restored",
+	"msg.all_targets":          "All notifiers",
+
+	"error.bad_request":                      "Bad request",
+	"error.unauthorized":                     "Not signed in or session expired",
+	"error.forbidden":                        "Forbidden",
+	"error.not_found":                        "Not found",
+	"error.method_not_allowed":               "Method not allowed",
+	"error.conflict":                         "Conflict",
+	"error.rate_limited":                     "Too many requests, please try again later",
+	"error.internal_error":                   "Internal server error",
+	"error.invalid_json":                     "Request body is not valid JSON",
+	"error.login_rate_limited":               "Too many sign-in attempts, please try again in 5 minutes",
+	"error.login_failed":                     "Incorrect username or password",
+	"error.domain_required":                  "Domain is required",
+	"error.domain_invalid":                   "Invalid domain: %s",
+	"error.tld_unsupported":                  "This TLD is not supported for monitoring yet",
+	"error.domain_exists":                    "Domain already exists",
+	"error.domain_check_failed":              "Failed to check domain: %s",
+	"error.domain_add_failed":                "Failed to add domain: %s",
+	"error.domain_reload_failed":             "Failed to reload domains: %s",
+	"error.domain_list_failed":               "Failed to list domains: %s",
+	"error.domain_remove_failed":             "Failed to remove domain: %s",
+	"error.domain_info_failed":               "Failed to load domain: %s",
+	"error.domain_query_failed":              "Failed to query domain: %s",
+	"error.monitor_start_failed":             "Failed to start monitoring: %s",
+	"error.monitor_reload_failed":            "Failed to reload domain list: %s",
+	"error.batch_rate_limited":               "Too many batch imports, please try again in 1 minute",
+	"error.batch_empty":                      "No domains provided",
+	"error.batch_too_many":                   "At most %d domains can be added at once",
+	"error.password_incorrect":               "Current password is incorrect",
+	"error.password_too_short":               "New password must be at least %d characters",
+	"error.password_update_failed":           "Failed to update password",
+	"error.username_too_short":               "Username must be at least %d characters",
+	"error.username_update_failed":           "Failed to update username",
+	"error.retries_out_of_range":             "Retries must be between 0 and 10",
+	"error.invalid_config":                   "%s",
+	"error.settings_save_failed":             "Failed to save settings: %s",
+	"error.interval_too_small":               "Check interval must be at least %d seconds",
+	"error.concurrency_out_of_range":         "Concurrency must be between 1 and 1000",
+	"error.timeout_out_of_range":             "Timeout must be between 1 and 120 seconds",
+	"error.max_attempts_out_of_range":        "Max delivery attempts must be between 1 and 50",
+	"error.public_url_invalid":               "Public URL must start with http:// or https://",
+	"error.whois_not_found":                  "WHOIS data not found, please wait for the query to complete",
+	"error.cleanup_failed":                   "Cleanup failed: %s",
+	"error.webhook_disabled":                 "Webhook notifications are disabled; enable them and set the webhook URL in settings first",
+	"error.webhook_not_found":                "Webhook notifier not found",
+	"error.webhook_test_failed":              "Test webhook failed: %s",
+	"error.update_check_failed":              "Unable to check for updates",
+	"error.update_parse_failed":              "Failed to parse update information",
+	"error.locale_unsupported":               "Unsupported language: %s",
+	"error.operation_failed":                 "Operation failed: %s",
+	"error.alert_list_failed":                "Failed to load alerts: %s",
+	"error.alert_id_invalid":                 "Invalid alert ID",
+	"error.alert_closed":                     "Alert does not exist or has already been handled",
+	"error.alert_not_found":                  "Alert not found",
+	"error.alert_link_invalid":               "The link is invalid",
+	"error.alert_link_expired":               "The link has expired, please use the link from the latest reminder",
+	"error.action_unknown":                   "Unknown action: %s",
+	"error.snooze_out_of_range":              "Snooze duration must be between 1 and %d minutes",
+	"error.escalation_list_failed":           "Failed to load reminder policies: %s",
+	"error.escalation_id_invalid":            "Invalid policy ID",
+	"error.escalation_not_found":             "Reminder policy not found",
+	"error.status_invalid":                   "Invalid status: %s",
+	"error.escalation_interval_out_of_range": "Repeat interval must be between 1 and 1440 minutes",
+	"error.escalation_repeats_negative":      "Maximum repeats cannot be negative",
+	"error.batch_count_failed":               "Failed to count pending batched notifications: %s",
+	"error.batch_window_out_of_range":        "Batch window must be between 1 and 3600 seconds",
+	"error.batch_lead_out_of_range":          "Early send time must be between 0 and the batch window",
+	"error.batch_limit_out_of_range":         "Batch limit must be between 1 and 1000",
+	"error.calendar_token_failed":            "Failed to get calendar token: %s",
+	"error.success_param_invalid":            "The success parameter must be true or false",
+	"error.since_invalid":                    "Invalid since parameter: %s",
+	"error.until_invalid":                    "Invalid until parameter: %s",
+	"error.delivery_list_failed":             "Failed to load delivery log: %s",
+	"error.digest_frequency_invalid":         "Frequency must be daily or weekly",
+	"error.digest_hour_out_of_range":         "Send hour must be between 0 and 23",
+	"error.digest_weekday_out_of_range":      "Weekday must be between 0 and 6 (0 is Sunday)",
+	"error.digest_expiry_out_of_range":       "Expiry days must be between 1 and 365",
+	"error.digest_build_failed":              "Failed to build digest: %s",
+	"error.digest_send_failed":               "Failed to send digest: %s",
+	"error.health_failures_out_of_range":     "Consecutive failures must be between 1 and 100",
+	"error.health_window_out_of_range":       "Window must be between 10 minutes and 7 days",
+	"error.health_percent_out_of_range":      "Failure percentage must be between 1 and 100",
+	"error.health_min_domains_invalid":       "Minimum domains must be greater than 0",
+	"error.history_list_failed":              "Failed to load status history: %s",
+	"error.snapshot_list_failed":             "Failed to load snapshots: %s",
+	"error.snapshot_id_invalid":              "Invalid snapshot ID",
+	"error.snapshot_load_failed":             "Failed to load snapshot: %s",
+	"error.snapshot_not_found":               "Snapshot not found",
+	"error.snapshot_diff_failed":             "Failed to compare snapshots: %s",
+	"error.notifier_list_failed":             "Failed to load notification channels: %s",
+	"error.notifier_type_unsupported":        "Unsupported notification channel type: %s",
+	"error.notifier_id_invalid":              "Invalid notification channel ID",
+	"error.notifier_not_found":               "Notification channel not found",
+	"error.notifier_name_required":           "Notification channel name is required",
+	"error.notifier_missing":                 "Notification channel %s not found",
+	"error.notifier_disabled":                "%s is disabled, please enable and configure it first",
+	"error.push_disabled":                    "%s notifications are disabled, please enable them and set the key in settings",
+	"error.robot_disabled":                   "%s notifications are disabled, please enable them and set the bot webhook in settings",
+	"error.test_failed":                      "Test %s failed: %s",
+	"error.outbox_list_failed":               "Failed to load outbox: %s",
+	"error.record_id_invalid":                "Invalid record ID",
+	"error.owned_list_failed":                "Failed to load owned domains: %s",
+	"error.reminder_days_out_of_range":       "Reminder days must be between 1 and 3650",
+	"error.push_url_invalid":                 "Invalid %s server URL",
+	"error.push_key_required":                "%s key is required",
+	"error.gotify_url_required":              "Gotify server URL is required",
+	"error.robot_url_invalid":                "Invalid %s bot webhook URL",
+	"error.rule_list_failed":                 "Failed to load routing rules: %s",
+	"error.rule_id_invalid":                  "Invalid rule ID",
+	"error.rule_not_found":                   "Routing rule not found",
+	"error.rule_name_required":               "Rule name is required",
+	"error.event_type_invalid":               "Invalid event type: %s",
+	"error.domain_pattern_invalid":           "Invalid domain pattern: %s",
+	"error.error_class_invalid":              "Invalid error class: %s",
+	"error.error_class_event_type":           "The error class condition only applies to event type %s",
+	"error.throttle_negative":                "Throttle interval cannot be negative",
+	"error.target_unknown":                   "Unknown notification target: %s",
+	"error.subscription_list_failed":         "Failed to load subscriptions: %s",
+	"error.change_types_required":            "Change types are required",
+	"error.change_type_unsupported":          "Unsupported change type: %s",
+	"error.subscription_id_invalid":          "Invalid subscription ID",
+	"error.template_list_failed":             "Failed to load notification templates: %s",
+	"error.template_id_invalid":              "Invalid template ID",
+	"error.template_not_found":               "Notification template not found",
+	"error.channel_unknown":                  "Unknown notification channel: %s",
+	"error.template_kind_invalid":            "Invalid template kind: %s",
+	"error.template_empty":                   "Subject and body templates cannot both be empty",
+	"error.events_file_size_out_of_range":    "Event file size limit must be between 1 and 10240 MB",
+	"error.events_file_backups_out_of_range": "Event file backups must be between 1 and 100",
+}
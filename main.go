@@ -11,6 +11,7 @@ import (
 	"Puff/auth"
 	"Puff/config"
 	"Puff/core"
+	"Puff/eventsink"
 	"Puff/logger"
 	"Puff/notification"
 	"Puff/storage"
@@ -65,35 +66,112 @@ func main() {
 	// 创建通知管理器
 	notificationMgr := notification.NewNotificationManager()
 
-	// 始终创建邮件通知器（启用状态由配置控制）
-	emailNotifier := notification.NewEmailNotifier(cfg.SMTP)
-	notificationMgr.AddNotifier(emailNotifier)
-	if cfg.SMTP.Enabled {
-		logger.Info("邮件通知器已启用")
-	} else {
-		logger.Info("邮件通知器已创建但未启用")
+	// 加载通知渠道实例（邮件、Telegram，可配置多个）
+	instances, err := storage.ListNotifierInstances("")
+	if err != nil {
+		logger.Error("加载通知渠道失败: %v", err)
+	}
+	for _, inst := range instances {
+		notifier, err := notification.NewInstanceNotifier(inst.ID, inst.Type, inst.Name, inst.Config, inst.Enabled)
+		if err != nil {
+			logger.Error("创建通知渠道 %s 失败: %v", inst.Name, err)
+			continue
+		}
+		notificationMgr.SetInstance(notifier)
+		if inst.Enabled {
+			logger.Info("%s通知渠道 %s 已启用", notification.InstanceTypes[inst.Type], inst.Name)
+		}
+	}
+
+	// 加载环境变量中的通知URL（不保存到数据库）
+	envInstances, envErrs := notification.EnvURLInstances(os.Getenv(notification.NotifyURLsEnv))
+	for _, err := range envErrs {
+		logger.Error("解析通知URL失败: %v", err)
+	}
+	for _, inst := range envInstances {
+		notificationMgr.SetInstance(inst)
+		logger.Info("已从 %s 加载 %s 通知渠道", inst.Name, inst.GetType())
 	}
 
-	// 始终创建Telegram通知器（启用状态由配置控制）
-	telegramNotifier := notification.NewTelegramNotifier(cfg.Telegram)
-	notificationMgr.AddNotifier(telegramNotifier)
-	if cfg.Telegram.Enabled {
-		logger.Info("Telegram通知器已启用")
+	// 始终创建Webhook通知器（启用状态由配置控制）
+	webhookNotifier := notification.NewWebhookNotifier(cfg.Webhook)
+	notificationMgr.AddNotifier(webhookNotifier)
+	if cfg.Webhook.Enabled {
+		logger.Info("Webhook通知器已启用")
 	} else {
-		logger.Info("Telegram通知器已创建但未启用")
+		logger.Info("Webhook通知器已创建但未启用")
+	}
+
+	// 始终创建群机器人通知器（钉钉、飞书、企业微信）
+	notificationMgr.AddNotifier(notification.NewDingTalkNotifier(cfg.DingTalk))
+	notificationMgr.AddNotifier(notification.NewFeishuNotifier(cfg.Feishu))
+	notificationMgr.AddNotifier(notification.NewWeComNotifier(cfg.WeCom))
+	for name, robot := range cfg.Robots() {
+		if robot.Enabled {
+			logger.Info("%s通知器已启用", name)
+		}
+	}
+
+	// 始终创建手机推送通知器（Bark、ntfy、Gotify、Server酱）
+	notificationMgr.AddNotifier(notification.NewBarkNotifier(cfg.Bark))
+	notificationMgr.AddNotifier(notification.NewNtfyNotifier(cfg.Ntfy))
+	notificationMgr.AddNotifier(notification.NewGotifyNotifier(cfg.Gotify))
+	notificationMgr.AddNotifier(notification.NewServerChanNotifier(cfg.ServerChan))
+	for name, push := range cfg.PushServices() {
+		if push.Enabled {
+			logger.Info("%s通知器已启用", name)
+		}
 	}
 
-	// 启动通知管理器
+	// 加载通知路由规则
+	if err := notificationMgr.LoadRoutingRules(); err != nil {
+		logger.Error("加载通知路由规则失败: %v", err)
+	}
+
+	// 加载自定义通知模板
+	if err := notificationMgr.LoadTemplates(); err != nil {
+		logger.Error("加载通知模板失败: %v", err)
+	}
+
+	// 加载重复提醒策略（未确认的告警按策略重复通知）
+	if err := notificationMgr.LoadEscalationPolicies(); err != nil {
+		logger.Error("加载重复提醒策略失败: %v", err)
+	}
+	notificationMgr.SetPublicURL(cfg.Notification.PublicURL)
+
+	// 启动通知管理器（持久化发件箱，失败自动重试；定期摘要）
+	notificationMgr.SetMaxAttempts(cfg.Notification.MaxAttempts)
+	notificationMgr.SetDigestConfig(cfg.Digest)
+	notificationMgr.SetHealthTargets(cfg.Health.Targets)
+	notificationMgr.SetAggregation(cfg.Notification)
+	notificationMgr.SetLocales(cfg.Notification)
 	notificationMgr.Start()
 
 	// 创建域名监控器（传入查询记录函数）
 	monitor := core.NewMonitor(cfg, notificationMgr.RecordDomainQuery)
 
+	// 事件流导出（syslog / JSONL），与通知渠道是否启用无关
+	eventSink := eventsink.New()
+	if err := eventSink.Configure(cfg.Events); err != nil {
+		logger.Error("配置事件流导出失败: %v", err)
+	}
+	notificationMgr.SetEventSink(eventSink)
+	monitor.SetEventSink(eventSink)
+
+	// 启动 Telegram 交互命令（仅对启用了交互命令的渠道）
+	notificationMgr.EnableTelegramBots(monitor)
+
 	// 启动通知处理协程
 	go handleNotifications(monitor, notificationMgr)
+	go handleFieldChanges(monitor, notificationMgr)
+	go handleCheckFailures(monitor, eventSink)
+	go handleCheckResults(monitor, notificationMgr)
+	go handleExpiryReminders(monitor, notificationMgr)
+	go handleHealthEvents(monitor, notificationMgr)
 
 	// 创建Web服务器
 	webServer := web.NewServer(cfg, monitor, authenticator, notificationMgr)
+	webServer.SetEventSink(eventSink)
 
 	// 启动监控器
 	if err := monitor.Start(); err != nil {
@@ -152,6 +230,10 @@ func main() {
 			logger.Info("Web服务器已停止")
 		}
 
+		// 写完剩余事件后关闭事件流导出
+		eventSink.Stop()
+		logger.Info("事件流导出已停止")
+
 		// 清理认证器
 		authenticator.CleanupExpiredSessions()
 		logger.Info("认证器已清理")
@@ -187,6 +269,8 @@ func handleNotifications(monitor *core.Monitor, notificationMgr *notification.No
 
 		if event.DomainInfo != nil {
 			notificationEvent.WhoisRaw = event.DomainInfo.WhoisRaw
+			notificationEvent.Info = event.DomainInfo
+			notificationEvent.ErrorClass = core.ClassifyError(event.DomainInfo.ErrorMessage)
 		}
 
 		// 发送通知
@@ -194,6 +278,85 @@ func handleNotifications(monitor *core.Monitor, notificationMgr *notification.No
 	}
 }
 
+// handleFieldChanges 处理字段变化通知事件
+func handleFieldChanges(monitor *core.Monitor, notificationMgr *notification.NotificationManager) {
+	for event := range monitor.GetFieldChanges() {
+		notificationEvent := notification.NotificationEvent{
+			Type:      event.Type,
+			Domain:    event.Domain,
+			Message:   event.Message,
+			Timestamp: event.Timestamp,
+			Field:     event.Field,
+			OldValue:  event.OldValue,
+			NewValue:  event.NewValue,
+		}
+
+		if event.DomainInfo != nil {
+			notificationEvent.Status = string(event.DomainInfo.Status)
+			notificationEvent.Info = event.DomainInfo
+		}
+
+		notificationMgr.SendNotification(notificationEvent)
+	}
+}
+
+// handleCheckFailures 将单次查询失败导出到事件流
+func handleCheckFailures(monitor *core.Monitor, sink *eventsink.Sink) {
+	for event := range monitor.GetCheckFailures() {
+		sink.Publish(eventsink.Event{
+			Time:       event.Timestamp,
+			Type:       eventsink.TypeCheckFailure,
+			Domain:     event.Domain,
+			Server:     event.Server,
+			Error:      event.Error,
+			ErrorClass: event.ErrorClass,
+			Failures:   event.Failures,
+		})
+	}
+}
+
+// handleCheckResults 将每次查询结果同步到 MQTT 保留状态；发布期间完成的查询合并到下一批
+func handleCheckResults(monitor *core.Monitor, notificationMgr *notification.NotificationManager) {
+	results := monitor.GetCheckResults()
+	for info := range results {
+		batch := []*core.DomainInfo{info}
+	drain:
+		for len(batch) < 100 {
+			select {
+			case more := <-results:
+				batch = append(batch, more)
+			default:
+				break drain
+			}
+		}
+		notificationMgr.SyncDomainStates(batch)
+	}
+}
+
+// handleExpiryReminders 处理自有域名续费提醒
+func handleExpiryReminders(monitor *core.Monitor, notificationMgr *notification.NotificationManager) {
+	for event := range monitor.GetReminders() {
+		expiry := event.ExpiryDate
+		notificationMgr.SendNotification(notification.NotificationEvent{
+			Type:      "expiry_reminder",
+			Domain:    event.Domain,
+			Message:   event.Message,
+			Timestamp: event.Timestamp,
+			// 附带到期时间，以便按各通知器的语言重新生成提醒
+			Info: &core.DomainInfo{Name: event.Domain, ExpiryDate: &expiry},
+		})
+	}
+}
+
+// handleHealthEvents 处理查询健康告警（发送到管理员通道）
+func handleHealthEvents(monitor *core.Monitor, notificationMgr *notification.NotificationManager) {
+	for event := range monitor.GetHealthEvents() {
+		if err := notificationMgr.SendHealthAlert(event); err != nil {
+			logger.Error("发送健康告警失败: %v", err)
+		}
+	}
+}
+
 // 显示帮助信息
 func showHelp() {
 	fmt.Printf(`%s v%s
@@ -1,38 +1,84 @@
 package notification
 
 import (
+	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 
+	"Puff/config"
 	"Puff/logger"
 	"Puff/storage"
 )
 
 // NotificationAggregator 通知聚合器
 // 实现智能合并逻辑：
-// 1. 10秒内的多个状态变化合并发送
-// 2. 如果8秒内没有新的域名进入查询，直接发送
+// 1. 合并窗口（默认10秒）内的多个状态变化合并发送
+// 2. 如果一段时间（默认8秒）内没有新的域名进入查询，直接发送
+// 3. 组内域名数达到上限时立即发送
+// 4. 关键状态（如变为可注册）跳过合并，立即发送给所有渠道
+// 合并只作用于发送方式为 batched 的通知器；immediate 立即发送，hourly 每小时汇总
+// 事件进入时先写入数据库，通知组发出后才删除，重启后继续处理
 type NotificationAggregator struct {
 	mgr                *NotificationManager
-	pendingEvents      []NotificationEvent
+	pendingEvents      []pendingEvent
 	lastQueryTime      time.Time
 	groupTimer         *time.Timer
 	mu                 sync.Mutex
-	eventCh            chan NotificationEvent
+	wakeCh             chan struct{} // 有新事件写入时唤醒主循环
 	stopCh             chan struct{}
 	isRunning          bool
 	lastDomainQueryMap map[string]time.Time // 记录每个域名最后的查询时间
+
+	window   time.Duration   // 合并窗口
+	quiet    time.Duration   // 无新查询的提前发送时间，0 表示不提前
+	maxBatch int             // 单组最多域名数
+	critical map[string]bool // 跳过合并的目标状态
+}
+
+// pendingEvent 等待合并发送的事件及其接收者（路由在事件进入时已确定）
+type pendingEvent struct {
+	id        int64 // 数据库记录 ID，0 表示未持久化
+	event     NotificationEvent
+	receivers []Notifier
+	alert     *storage.Alert
 }
 
 // NewNotificationAggregator 创建通知聚合器
 func NewNotificationAggregator(mgr *NotificationManager) *NotificationAggregator {
 	return &NotificationAggregator{
 		mgr:                mgr,
-		pendingEvents:      make([]NotificationEvent, 0),
-		eventCh:            make(chan NotificationEvent, 1000),
+		pendingEvents:      make([]pendingEvent, 0),
+		wakeCh:             make(chan struct{}, 1),
 		stopCh:             make(chan struct{}),
 		lastDomainQueryMap: make(map[string]time.Time),
+		window:             10 * time.Second,
+		quiet:              8 * time.Second,
+		maxBatch:           50,
+		critical:           map[string]bool{"available": true},
+	}
+}
+
+// configure 更新合并参数（对下一个通知组生效）
+func (a *NotificationAggregator) configure(cfg config.NotificationConfig) {
+	critical := make(map[string]bool)
+	for _, status := range strings.Split(cfg.CriticalStatuses, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			critical[status] = true
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cfg.AggregateWindow > 0 {
+		a.window = time.Duration(cfg.AggregateWindow) * time.Second
 	}
+	a.quiet = time.Duration(cfg.QuietTimeout) * time.Second
+	if cfg.MaxBatchSize > 0 {
+		a.maxBatch = cfg.MaxBatchSize
+	}
+	a.critical = critical
 }
 
 // Start 启动聚合器
@@ -63,18 +109,89 @@ func (a *NotificationAggregator) Stop() {
 	logger.Info("通知聚合器已停止")
 }
 
-// AddEvent 添加通知事件
+// AddEvent 添加通知事件：写入数据库后唤醒主循环，未运行时留待下次启动处理
 func (a *NotificationAggregator) AddEvent(event NotificationEvent) {
-	if !a.isRunning {
+	payload, err := json.Marshal(event)
+	if err == nil {
+		_, err = storage.AddPendingEvent(event.Domain, string(payload))
+	}
+	if err != nil {
+		logger.Error("保存状态变化通知失败，直接处理: %v", err)
+		a.handleEvent(0, event)
 		return
 	}
 
 	select {
-	case a.eventCh <- event:
-		// 成功加入队列
+	case a.wakeCh <- struct{}{}:
 	default:
-		logger.Warn("通知聚合器队列已满，丢弃通知: %s", event.Domain)
+		// 主循环已被唤醒，会一并处理
+	}
+}
+
+// restore 恢复重启前未发出的通知组
+func (a *NotificationAggregator) restore() {
+	grouped, err := storage.ListPendingEvents(true)
+	if err != nil {
+		logger.Error("读取未发出的通知组失败: %v", err)
+		return
+	}
+	if len(grouped) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, p := range grouped {
+		var event NotificationEvent
+		if err := json.Unmarshal([]byte(p.Event), &event); err != nil {
+			logger.Error("解析待处理通知 #%d 失败: %v", p.ID, err)
+			storage.DeletePendingEvents([]int64{p.ID})
+			continue
+		}
+		pending := pendingEvent{id: p.ID, event: event}
+		for _, key := range p.Receivers {
+			if n := a.mgr.notifierByKey(key); n != nil {
+				pending.receivers = append(pending.receivers, n)
+			}
+		}
+		if p.AlertID > 0 {
+			if alert, err := storage.GetAlert(p.AlertID); err == nil && alert != nil {
+				pending.alert = alert
+			}
+		}
+		a.pendingEvents = append(a.pendingEvents, pending)
+	}
+	logger.Info("恢复未发出的通知组，包含 %d 个域名状态变化", len(a.pendingEvents))
+	a.groupTimer = time.AfterFunc(a.window, func() {
+		a.sendPendingNotifications()
+	})
+}
+
+// processPending 按写入顺序处理尚未进入通知组的事件
+func (a *NotificationAggregator) processPending() {
+	events, err := storage.ListPendingEvents(false)
+	if err != nil {
+		logger.Error("读取待处理通知失败: %v", err)
+		return
 	}
+	for _, p := range events {
+		var event NotificationEvent
+		if err := json.Unmarshal([]byte(p.Event), &event); err != nil {
+			logger.Error("解析待处理通知 #%d 失败: %v", p.ID, err)
+			storage.DeletePendingEvents([]int64{p.ID})
+			continue
+		}
+		a.handleEvent(p.ID, event)
+	}
+}
+
+// isCritical 状态是否跳过合并立即发送
+func (a *NotificationAggregator) isCritical(status string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.critical[status]
 }
 
 // RecordDomainQuery 记录域名开始查询的时间
@@ -88,20 +205,41 @@ func (a *NotificationAggregator) RecordDomainQuery(domain string) {
 
 // run 主循环
 func (a *NotificationAggregator) run() {
+	// 按小时汇总的通知在整点发送
+	hourly := time.NewTimer(time.Until(nextHour(time.Now())))
+	defer hourly.Stop()
+
+	a.restore()
+	a.processPending()
 	for {
 		select {
-		case event := <-a.eventCh:
-			a.handleEvent(event)
+		case <-a.wakeCh:
+			a.processPending()
+		case now := <-hourly.C:
+			a.mu.Lock()
+			maxBatch := a.maxBatch
+			a.mu.Unlock()
+			a.mgr.flushDeferred(maxBatch)
+			hourly.Reset(time.Until(nextHour(now)))
 		case <-a.stopCh:
-			// 停止前发送所有待发送的通知
+			// 停止前发送所有待发送的通知（未处理的事件与按小时汇总的事件已持久化，重启后继续）
 			a.sendPendingNotifications()
 			return
 		}
 	}
 }
 
-// handleEvent 处理单个事件
-func (a *NotificationAggregator) handleEvent(event NotificationEvent) {
+// handleEvent 处理单个事件；id 为数据库记录 ID，事件未进入通知组时删除该记录
+func (a *NotificationAggregator) handleEvent(id int64, event NotificationEvent) {
+	grouped := false
+	defer func() {
+		if id > 0 && !grouped {
+			if err := storage.DeletePendingEvents([]int64{id}); err != nil {
+				logger.Error("删除待处理通知失败: %v", err)
+			}
+		}
+	}()
+
 	a.mu.Lock()
 
 	// 检查是否为首次查询（没有旧状态）
@@ -131,45 +269,88 @@ func (a *NotificationAggregator) handleEvent(event NotificationEvent) {
 		}
 	}
 
-	// 如果是第一个事件，创建通知组并启动10秒计时器
-	if len(a.pendingEvents) == 0 {
-		a.pendingEvents = append(a.pendingEvents, event)
+	// 检查该域名是否已在待发送列表中
+	for _, p := range a.pendingEvents {
+		if p.event.Domain == event.Domain {
+			a.mu.Unlock()
+			logger.Debug("域名 %s 已在通知组中，跳过", event.Domain)
+			return
+		}
+	}
+
+	// 路由只计算一次（规则节流按次计数），再按各通知器的发送方式拆分
+	receivers := a.mgr.RouteEvent(event)
+	alerts := a.mgr.trackAlerts([]NotificationEvent{event})
+
+	// 关键状态跳过合并，立即发送给所有接收者
+	if a.critical[event.Status] {
+		a.mu.Unlock()
+		logger.Info("域名 %s 变为关键状态 %s，跳过合并立即发送", event.Domain, event.Status)
+		if err := storage.SaveNotification(event.Domain, event.Status, event.OldStatus); err != nil {
+			logger.Error("保存通知历史失败: %v", err)
+		}
+		a.mgr.deliverRouted(receivers, routeAll(receivers, event), alerts)
+		a.mgr.recordNotification(event.Domain, event.Status)
+		return
+	}
+
+	split := a.mgr.splitByMode(receivers)
+	a.mgr.deliverRouted(split[ModeImmediate], routeAll(split[ModeImmediate], event), alerts)
+	a.mgr.deferEvent(event, split[ModeHourly])
+
+	pending := pendingEvent{
+		id:        id,
+		event:     event,
+		receivers: split[ModeBatched],
+		alert:     alerts[event.Domain],
+	}
+	a.pendingEvents = append(a.pendingEvents, pending)
+	grouped = true
+	if id > 0 {
+		keys := make([]string, 0, len(pending.receivers))
+		for _, n := range pending.receivers {
+			keys = append(keys, NotifierKey(n))
+		}
+		var alertID int64
+		if pending.alert != nil {
+			alertID = pending.alert.ID
+		}
+		if err := storage.MarkPendingGrouped(id, keys, alertID); err != nil {
+			logger.Error("保存通知组失败: %v", err)
+		}
+	}
+
+	// 如果是第一个事件，创建通知组并启动合并计时器
+	if len(a.pendingEvents) == 1 {
 		logger.Info("创建新的通知组，域名: %s, 状态变化: %s -> %s", event.Domain, event.OldStatus, event.Status)
 
-		// 启动10秒计时器
-		a.groupTimer = time.AfterFunc(10*time.Second, func() {
+		a.groupTimer = time.AfterFunc(a.window, func() {
 			a.sendPendingNotifications()
 		})
 
-		// 同时启动8秒无新查询检测
-		go a.checkNoNewQuery(event.Timestamp)
+		// 同时启动无新查询检测
+		if a.quiet > 0 {
+			go a.checkNoNewQuery(a.quiet)
+		}
 		a.mu.Unlock()
 		return
 	}
 
-	// 检查该域名是否已在待发送列表中
-	isDuplicate := false
-	for _, e := range a.pendingEvents {
-		if e.Domain == event.Domain {
-			isDuplicate = true
-			break
-		}
-	}
+	logger.Info("域名 %s 加入当前通知组，状态变化: %s -> %s，当前组内域名数: %d",
+		event.Domain, event.OldStatus, event.Status, len(a.pendingEvents))
 
-	if !isDuplicate {
-		a.pendingEvents = append(a.pendingEvents, event)
-		logger.Info("域名 %s 加入当前通知组，状态变化: %s -> %s，当前组内域名数: %d",
-			event.Domain, event.OldStatus, event.Status, len(a.pendingEvents))
-	} else {
-		logger.Debug("域名 %s 已在通知组中，跳过", event.Domain)
+	// 达到批量上限，立即发送
+	if len(a.pendingEvents) >= a.maxBatch {
+		logger.Info("通知组已达到 %d 个域名上限，立即发送", a.maxBatch)
+		a.sendPendingNotificationsLocked()
 	}
 
 	a.mu.Unlock()
 }
 
-// checkNoNewQuery 检查8秒内是否有新的查询
-func (a *NotificationAggregator) checkNoNewQuery(groupStartTime time.Time) {
-	time.Sleep(8 * time.Second)
+// checkNoNewQuery 检查 quiet 时间内是否有新的查询
+func (a *NotificationAggregator) checkNoNewQuery(quiet time.Duration) {
+	time.Sleep(quiet)
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -179,15 +360,10 @@ func (a *NotificationAggregator) checkNoNewQuery(groupStartTime time.Time) {
 		return
 	}
 
-	// 检查最后一次查询时间是否在8秒之前
+	// 检查最后一次查询时间是否在 quiet 之前
 	timeSinceLastQuery := time.Since(a.lastQueryTime)
-	if timeSinceLastQuery >= 8*time.Second {
-		logger.Info("8秒内无新域名查询，立即发送通知组（包含 %d 个域名）", len(a.pendingEvents))
-
-		// 取消10秒计时器
-		if a.groupTimer != nil {
-			a.groupTimer.Stop()
-		}
+	if timeSinceLastQuery >= quiet {
+		logger.Info("%v 内无新域名查询，立即发送通知组（包含 %d 个域名）", quiet, len(a.pendingEvents))
 
 		// 立即发送
 		a.sendPendingNotificationsLocked()
@@ -210,24 +386,40 @@ func (a *NotificationAggregator) sendPendingNotificationsLocked() {
 
 	logger.Info("发送通知组，包含 %d 个域名状态变化", len(a.pendingEvents))
 
-	// 如果只有一个事件，直接发送
-	if len(a.pendingEvents) == 1 {
-		event := a.pendingEvents[0]
-
+	// 按通知器汇总组内事件（各通知器只收到路由给它的事件）
+	var receivers []Notifier
+	routed := make(map[Notifier][]NotificationEvent)
+	alerts := make(map[string]*storage.Alert)
+	for _, p := range a.pendingEvents {
 		// 保存到通知历史
-		if err := storage.SaveNotification(event.Domain, event.Status, event.OldStatus); err != nil {
+		if err := storage.SaveNotification(p.event.Domain, p.event.Status, p.event.OldStatus); err != nil {
 			logger.Error("保存通知历史失败: %v", err)
 		}
+		for _, n := range p.receivers {
+			if _, ok := routed[n]; !ok {
+				receivers = append(receivers, n)
+			}
+			routed[n] = append(routed[n], p.event)
+		}
+		if p.alert != nil {
+			alerts[p.event.Domain] = p.alert
+		}
+	}
 
-		// 发送通知
-		a.mgr.SendNotificationDirect(event)
-	} else {
-		// 多个事件，合并发送
-		a.sendBatchNotification()
+	a.mgr.deliverRouted(receivers, routed, alerts)
+	var ids []int64
+	for _, p := range a.pendingEvents {
+		a.mgr.recordNotification(p.event.Domain, p.event.Status)
+		if p.id > 0 {
+			ids = append(ids, p.id)
+		}
+	}
+	if err := storage.DeletePendingEvents(ids); err != nil {
+		logger.Error("删除已发出的通知组失败: %v", err)
 	}
 
 	// 清空待发送列表
-	a.pendingEvents = make([]NotificationEvent, 0)
+	a.pendingEvents = make([]pendingEvent, 0)
 
 	// 停止计时器
 	if a.groupTimer != nil {
@@ -235,20 +427,3 @@ func (a *NotificationAggregator) sendPendingNotificationsLocked() {
 		a.groupTimer = nil
 	}
 }
-
-// sendBatchNotification 发送批量通知（合并多个域名状态变化）
-func (a *NotificationAggregator) sendBatchNotification() {
-	if len(a.pendingEvents) == 0 {
-		return
-	}
-
-	// 保存所有通知历史
-	for _, event := range a.pendingEvents {
-		if err := storage.SaveNotification(event.Domain, event.Status, event.OldStatus); err != nil {
-			logger.Error("保存通知历史失败: %v", err)
-		}
-	}
-
-	// 发送合并通知
-	a.mgr.SendNotificationDirectBatch(a.pendingEvents)
-}
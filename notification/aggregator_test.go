@@ -0,0 +1,238 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/storage"
+)
+
+func TestParseChannelModes(t *testing.T) {
+	modes, err := ParseChannelModes(" bark=immediate, email=hourly ,telegram:2=batched,")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(modes) != 3 || modes["bark"] != ModeImmediate || modes["email"] != ModeHourly || modes["telegram:2"] != ModeBatched {
+		t.Errorf("解析结果错误: %v", modes)
+	}
+
+	for _, value := range []string{"bark", "=immediate", "bark=daily"} {
+		if _, err := ParseChannelModes(value); err == nil {
+			t.Errorf("ParseChannelModes(%q) 应报错", value)
+		}
+	}
+}
+
+func TestModeOf(t *testing.T) {
+	nm := NewNotificationManager()
+	nm.SetAggregation(config.NotificationConfig{ChannelModes: "telegram=hourly,telegram:7=immediate"})
+
+	tests := []struct {
+		notifier Notifier
+		want     string
+	}{
+		{&InstanceNotifier{ID: 7, Notifier: &recordingNotifier{notifierType: "telegram"}}, ModeImmediate},
+		{&InstanceNotifier{ID: 8, Notifier: &recordingNotifier{notifierType: "telegram"}}, ModeHourly},
+		{&recordingNotifier{notifierType: "email"}, ModeBatched},
+	}
+	for _, tt := range tests {
+		if got := nm.modeOf(tt.notifier); got != tt.want {
+			t.Errorf("modeOf(%s) = %s, 期望 %s", NotifierKey(tt.notifier), got, tt.want)
+		}
+	}
+
+	// 配置无效时全部按合并发送
+	nm.SetAggregation(config.NotificationConfig{ChannelModes: "telegram=daily"})
+	if got := nm.modeOf(tests[0].notifier); got != ModeBatched {
+		t.Errorf("配置无效时应为合并发送，实际 %s", got)
+	}
+}
+
+// testAggregator 创建包含立即、合并、按小时三种发送方式通知器的聚合器（不启动主循环）
+func testAggregator(cfg config.NotificationConfig) (*NotificationAggregator, map[string]*recordingNotifier) {
+	receivers := map[string]*recordingNotifier{
+		ModeImmediate: {notifierType: "agg-push"},
+		ModeBatched:   {notifierType: "agg-chat"},
+		ModeHourly:    {notifierType: "agg-mail"},
+	}
+	nm := NewNotificationManager()
+	for _, mode := range []string{ModeImmediate, ModeBatched, ModeHourly} {
+		nm.AddNotifier(receivers[mode])
+	}
+	cfg.ChannelModes = "agg-push=immediate,agg-mail=hourly"
+	nm.SetAggregation(cfg)
+
+	a := NewNotificationAggregator(nm)
+	a.configure(cfg)
+	return a, receivers
+}
+
+// statusEvent 状态变化事件
+func statusEvent(domain, oldStatus, status string) NotificationEvent {
+	return NotificationEvent{Type: "status_change", Domain: domain, OldStatus: oldStatus, Status: status, Timestamp: time.Now()}
+}
+
+func TestAggregatorModes(t *testing.T) {
+	a, receivers := testAggregator(config.NotificationConfig{AggregateWindow: 60, MaxBatchSize: 2, CriticalStatuses: "available"})
+	push, chat, mail := receivers[ModeImmediate], receivers[ModeBatched], receivers[ModeHourly]
+
+	a.handleEvent(0, statusEvent("agg-1.com", "registered", "pending_delete"))
+	a.mgr.dispatchOutbox()
+	if subjects, _ := push.received(); len(subjects) != 1 {
+		t.Errorf("立即发送的通知器应收到 1 条，实际 %d", len(subjects))
+	}
+	if subjects, _ := chat.received(); len(subjects) != 0 {
+		t.Errorf("合并窗口未结束时不应发送: %v", subjects)
+	}
+
+	// 首次查询、状态未变化、已在组内的事件被忽略
+	a.handleEvent(0, statusEvent("agg-new.com", "", "registered"))
+	a.handleEvent(0, statusEvent("agg-same.com", "registered", "registered"))
+	a.handleEvent(0, statusEvent("agg-1.com", "registered", "pending_delete"))
+
+	// 达到批量上限立即合并发送
+	a.handleEvent(0, statusEvent("agg-2.com", "registered", "redemption"))
+	a.mgr.dispatchOutbox()
+	if subjects, _ := push.received(); len(subjects) != 2 {
+		t.Errorf("立即发送的通知器应逐条收到，实际 %d 条", len(subjects))
+	}
+	_, messages := chat.received()
+	if len(messages) != 1 || !strings.Contains(messages[0], "agg-1.com") || !strings.Contains(messages[0], "agg-2.com") {
+		t.Fatalf("合并发送的通知器应收到 1 条合并通知: %v", messages)
+	}
+	if len(a.pendingEvents) != 0 || a.groupTimer != nil {
+		t.Error("发送后应清空通知组")
+	}
+
+	// 关键状态跳过合并，按小时汇总的通知器也立即收到
+	a.handleEvent(0, statusEvent("agg-3.com", "pending_delete", "available"))
+	a.mgr.dispatchOutbox()
+	for mode, n := range receivers {
+		_, messages := n.received()
+		if len(messages) == 0 || !strings.Contains(messages[len(messages)-1], "agg-3.com") {
+			t.Errorf("%s 通知器应立即收到关键状态通知", mode)
+		}
+	}
+	// 已通知过的状态变化不再发送
+	a.handleEvent(0, statusEvent("agg-3.com", "pending_delete", "available"))
+	a.mgr.dispatchOutbox()
+	if subjects, _ := push.received(); len(subjects) != 3 {
+		t.Errorf("重复的状态变化不应再次发送，立即通知器共收到 %d 条", len(subjects))
+	}
+
+	// 按小时汇总的事件在整点合并发送
+	if _, messages := mail.received(); len(messages) != 1 {
+		t.Fatalf("按小时汇总的通知器只应收到关键状态通知，实际 %d 条", len(messages))
+	}
+	a.mgr.flushDeferred(0)
+	a.mgr.dispatchOutbox()
+	_, messages = mail.received()
+	if len(messages) != 2 || !strings.Contains(messages[1], "agg-1.com") || !strings.Contains(messages[1], "agg-2.com") {
+		t.Errorf("整点汇总应合并发送 2 个事件: %v", messages)
+	}
+}
+
+func TestAggregatorQuietTimeout(t *testing.T) {
+	a, receivers := testAggregator(config.NotificationConfig{AggregateWindow: 60, QuietTimeout: 1, MaxBatchSize: 50})
+	a.handleEvent(0, statusEvent("agg-quiet.com", "registered", "redemption"))
+
+	// 无新查询时提前发送，不等待合并窗口结束
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		a.mgr.dispatchOutbox()
+		if _, messages := receivers[ModeBatched].received(); len(messages) == 1 {
+			if !strings.Contains(messages[0], "agg-quiet.com") {
+				t.Errorf("通知内容错误: %s", messages[0])
+			}
+			a.mgr.flushDeferred(0)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("无新查询时应在 quiet 时间后发送通知组")
+}
+
+func TestAggregatorPersistsEvents(t *testing.T) {
+	cfg := config.NotificationConfig{AggregateWindow: 60, MaxBatchSize: 50}
+	a, receivers := testAggregator(cfg)
+	t.Cleanup(func() { a.mgr.flushDeferred(0) })
+
+	// 未运行时事件保存到数据库，不丢弃
+	a.AddEvent(statusEvent("agg-persist.com", "registered", "redemption"))
+	if events, _ := storage.ListPendingEvents(false); len(events) != 1 || events[0].Domain != "agg-persist.com" {
+		t.Fatalf("事件应写入数据库: %+v", events)
+	}
+
+	// 处理后加入通知组，记录合并发送的接收者
+	a.processPending()
+	a.mu.Lock()
+	if a.groupTimer != nil {
+		a.groupTimer.Stop()
+	}
+	a.mu.Unlock()
+	a.mgr.dispatchOutbox()
+	if _, messages := receivers[ModeImmediate].received(); len(messages) != 1 {
+		t.Errorf("立即发送的通知器应收到 1 条: %v", messages)
+	}
+	grouped, _ := storage.ListPendingEvents(true)
+	if len(grouped) != 1 || len(grouped[0].Receivers) != 1 || grouped[0].Receivers[0] != "agg-chat" {
+		t.Fatalf("通知组应持久化: %+v", grouped)
+	}
+	if events, _ := storage.ListPendingEvents(false); len(events) != 0 {
+		t.Errorf("已处理的事件不应重复处理: %+v", events)
+	}
+
+	// 重启后恢复通知组并按原接收者发送
+	restarted, restartedReceivers := testAggregator(cfg)
+	restarted.restore()
+	restarted.mu.Lock()
+	if restarted.groupTimer != nil {
+		restarted.groupTimer.Stop()
+	}
+	restarted.mu.Unlock()
+	restarted.sendPendingNotifications()
+	restarted.mgr.dispatchOutbox()
+	if _, messages := restartedReceivers[ModeBatched].received(); len(messages) != 1 || !strings.Contains(messages[0], "agg-persist.com") {
+		t.Errorf("恢复的通知组应发送给原接收者: %v", messages)
+	}
+	if _, messages := restartedReceivers[ModeImmediate].received(); len(messages) != 0 {
+		t.Errorf("立即发送的通知器不应重复收到: %v", messages)
+	}
+	if events, _ := storage.ListPendingEvents(true); len(events) != 0 {
+		t.Errorf("发送后应删除通知组记录: %+v", events)
+	}
+	if _, messages := receivers[ModeBatched].received(); len(messages) != 0 {
+		t.Errorf("重启前的通知组不应已发送: %v", messages)
+	}
+}
+
+func TestFlushDeferredMaxBatch(t *testing.T) {
+	mail := &recordingNotifier{notifierType: "agg-digest"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(mail)
+	for _, domain := range []string{"agg-h1.com", "agg-h2.com", "agg-h3.com"} {
+		nm.deferEvent(statusEvent(domain, "registered", "redemption"), []Notifier{mail})
+	}
+
+	nm.flushDeferred(2)
+	nm.dispatchOutbox()
+	if _, messages := mail.received(); len(messages) != 2 {
+		t.Errorf("3 个事件按每条最多 2 个应分 2 条发送，实际 %d 条", len(messages))
+	}
+
+	// 已取出的事件不再重复发送
+	nm.flushDeferred(2)
+	nm.dispatchOutbox()
+	if _, messages := mail.received(); len(messages) != 2 {
+		t.Errorf("延迟事件被重复发送: %d 条", len(messages))
+	}
+}
+
+func TestNextHour(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 59, 30, 0, time.UTC)
+	if got := nextHour(now); !got.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("nextHour(%s) = %s", now, got)
+	}
+}
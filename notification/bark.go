@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"fmt"
+
+	"Puff/config"
+)
+
+// barkDefaultServer Bark 官方服务地址
+const barkDefaultServer = "https://api.day.app"
+
+// barkLevels Bark 默认中断级别映射
+var barkLevels = map[Priority]string{
+	PriorityCritical: "critical",
+	PriorityHigh:     "timeSensitive",
+	PriorityNormal:   "active",
+	PriorityLow:      "passive",
+}
+
+// BarkNotifier Bark（iOS）推送通知器
+type BarkNotifier struct {
+	pushBase
+}
+
+// barkResponse Bark 响应
+type barkResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBarkNotifier 创建 Bark 通知器
+func NewBarkNotifier(cfg config.PushConfig) *BarkNotifier {
+	return &BarkNotifier{pushBase: newPushBase(cfg, barkLevels)}
+}
+
+// SendMessage 发送 Bark 推送
+func (b *BarkNotifier) SendMessage(subject, message string) error {
+	return b.SendEvents(subject, message, nil)
+}
+
+// SendEvents 按事件优先级发送 Bark 推送
+func (b *BarkNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if err := b.validate("Bark"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"device_key": b.config.Token,
+		"title":      subject,
+		"body":       formatPushBody(message),
+		"group":      "Puff",
+	}
+	if level := b.level(eventsPriority(events)); level != "" {
+		payload["level"] = level
+	}
+
+	var result barkResponse
+	if err := postJSON(b.httpClient, b.server(barkDefaultServer)+"/push", nil, payload, &result); err != nil {
+		return fmt.Errorf("发送Bark推送失败: %v", err)
+	}
+	if result.Code != 200 {
+		return fmt.Errorf("Bark API错误 (%d): %s", result.Code, result.Message)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (b *BarkNotifier) GetType() string {
+	return "bark"
+}
+
+// Test 发送测试消息
+func (b *BarkNotifier) Test() error {
+	return b.SendEvents("Bark推送测试", pushTestMessage("Bark"), []NotificationEvent{pushTestEvent()})
+}
@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// 通知器的发送方式（仅作用于状态变化通知）
+const (
+	ModeImmediate = "immediate" // 立即发送，不合并
+	ModeBatched   = "batched"   // 在合并窗口内合并发送（默认）
+	ModeHourly    = "hourly"    // 每小时汇总一次
+)
+
+// batching 各通知器的发送方式
+type batching struct {
+	mu    sync.RWMutex
+	modes map[string]string // 通知器标识或类型 -> 发送方式
+}
+
+// ParseChannelModes 解析发送方式配置，格式如 "bark=immediate,email=hourly"
+func ParseChannelModes(value string) (map[string]string, error) {
+	modes := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, mode, ok := strings.Cut(item, "=")
+		key, mode = strings.TrimSpace(key), strings.TrimSpace(mode)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("发送方式配置格式错误: %s", item)
+		}
+		switch mode {
+		case ModeImmediate, ModeBatched, ModeHourly:
+		default:
+			return nil, fmt.Errorf("未知的发送方式 %s（可选 immediate、batched、hourly）", mode)
+		}
+		modes[key] = mode
+	}
+	return modes, nil
+}
+
+// SetAggregation 更新合并窗口、批量上限、关键状态与各通知器的发送方式
+func (nm *NotificationManager) SetAggregation(cfg config.NotificationConfig) {
+	modes, err := ParseChannelModes(cfg.ChannelModes)
+	if err != nil {
+		logger.Error("解析通知发送方式失败，全部按合并发送: %v", err)
+		modes = map[string]string{}
+	}
+	nm.batching.mu.Lock()
+	nm.batching.modes = modes
+	nm.batching.mu.Unlock()
+
+	if nm.aggregator != nil {
+		nm.aggregator.configure(cfg)
+	}
+}
+
+// modeOf 返回通知器的发送方式：实例标识优先于类型，未配置时为合并发送
+func (nm *NotificationManager) modeOf(n Notifier) string {
+	nm.batching.mu.RLock()
+	defer nm.batching.mu.RUnlock()
+
+	if mode, ok := nm.batching.modes[NotifierKey(n)]; ok {
+		return mode
+	}
+	if mode, ok := nm.batching.modes[n.GetType()]; ok {
+		return mode
+	}
+	return ModeBatched
+}
+
+// splitByMode 按发送方式拆分接收通知器
+func (nm *NotificationManager) splitByMode(receivers []Notifier) map[string][]Notifier {
+	split := make(map[string][]Notifier)
+	for _, n := range receivers {
+		mode := nm.modeOf(n)
+		split[mode] = append(split[mode], n)
+	}
+	return split
+}
+
+// deferEvent 将事件保存到按小时汇总的队列
+func (nm *NotificationManager) deferEvent(event NotificationEvent, receivers []Notifier) {
+	if len(receivers) == 0 {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("序列化延迟通知失败: %v", err)
+		return
+	}
+
+	deferred := make([]storage.DeferredEvent, 0, len(receivers))
+	for _, n := range receivers {
+		deferred = append(deferred, storage.DeferredEvent{
+			NotifierKey: NotifierKey(n),
+			Domain:      event.Domain,
+			Event:       string(payload),
+		})
+	}
+	if err := storage.AddDeferredEvents(deferred); err != nil {
+		logger.Error("保存延迟通知失败，改为立即发送: %v", err)
+		nm.deliverRouted(receivers, routeAll(receivers, event), nil)
+	}
+}
+
+// routeAll 所有接收者都收到同一事件
+func routeAll(receivers []Notifier, event NotificationEvent) map[Notifier][]NotificationEvent {
+	routed := make(map[Notifier][]NotificationEvent, len(receivers))
+	for _, n := range receivers {
+		routed[n] = []NotificationEvent{event}
+	}
+	return routed
+}
+
+// flushDeferred 发送按小时汇总的通知，每条最多包含 maxBatch 个事件
+func (nm *NotificationManager) flushDeferred(maxBatch int) {
+	deferred, err := storage.TakeDeferredEvents()
+	if err != nil {
+		logger.Error("读取延迟通知失败: %v", err)
+		return
+	}
+	if len(deferred) == 0 {
+		return
+	}
+
+	var keys []string
+	grouped := make(map[string][]NotificationEvent)
+	for _, d := range deferred {
+		var event NotificationEvent
+		if err := json.Unmarshal([]byte(d.Event), &event); err != nil {
+			logger.Error("解析延迟通知 #%d 失败: %v", d.ID, err)
+			continue
+		}
+		if _, ok := grouped[d.NotifierKey]; !ok {
+			keys = append(keys, d.NotifierKey)
+		}
+		grouped[d.NotifierKey] = append(grouped[d.NotifierKey], event)
+	}
+
+	for _, key := range keys {
+		n := nm.notifierByKey(key)
+		if n == nil || !n.IsEnabled() {
+			logger.Warn("通知器 %s 不存在或已禁用，丢弃 %d 条汇总通知", key, len(grouped[key]))
+			continue
+		}
+		events := grouped[key]
+		size := maxBatch
+		if size <= 0 {
+			size = len(events)
+		}
+		for start := 0; start < len(events); start += size {
+			end := start + size
+			if end > len(events) {
+				end = len(events)
+			}
+			nm.deliverRouted([]Notifier{n}, map[Notifier][]NotificationEvent{n: events[start:end]}, nil)
+		}
+		logger.Info("按小时汇总的通知已加入发件箱: %s，共 %d 个事件", key, len(events))
+	}
+}
+
+// nextHour 下一个整点
+func nextHour(now time.Time) time.Time {
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
@@ -0,0 +1,442 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	digestLastSentKey   = "digest_last_sent" // 上次发送摘要的时间（RFC3339）
+	digestCheckInterval = time.Minute        // 检查是否到达发送时间的间隔
+	digestGracePeriod   = 6 * time.Hour      // 错过发送时刻超过该时长则跳过本期（如长时间停机）
+	digestMaxItems      = 50                 // 每个分组最多列出的域名数
+)
+
+// DigestCount 按状态统计的域名数
+type DigestCount struct {
+	Status string `json:"status"`
+	Label  string `json:"label"`
+	Count  int    `json:"count"`
+}
+
+// DigestExpiring 即将过期的域名
+type DigestExpiring struct {
+	Domain   string    `json:"domain"`
+	Status   string    `json:"status"`
+	ExpiryAt time.Time `json:"expiry_at"`
+	Days     int       `json:"days"` // 剩余天数（向上取整）；已过期时为负的已过天数，0 表示不足一天
+}
+
+// DigestChange 本期内的状态变化
+type DigestChange struct {
+	Domain    string    `json:"domain"`
+	OldStatus string    `json:"old_status"`
+	Status    string    `json:"status"`
+	At        time.Time `json:"at"`
+}
+
+// DigestError 持续查询失败的域名
+type DigestError struct {
+	Domain      string    `json:"domain"`
+	Error       string    `json:"error"`
+	LastChecked time.Time `json:"last_checked"`
+}
+
+// Digest 定期摘要
+type Digest struct {
+	Frequency  string           `json:"frequency"`
+	Since      time.Time        `json:"since"`
+	Until      time.Time        `json:"until"`
+	ExpiryDays int              `json:"expiry_days"`
+	Total      int              `json:"total"`
+	Counts     []DigestCount    `json:"counts"`
+	Expiring   []DigestExpiring `json:"expiring"`
+	Changes    []DigestChange   `json:"changes"`
+	Errors     []DigestError    `json:"errors"`
+}
+
+// DigestPeriod 摘要覆盖的时长
+func DigestPeriod(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// BuildDigest 根据查询结果与通知历史生成 [since, until) 区间的摘要
+func BuildDigest(frequency string, since, until time.Time, expiryDays int) (*Digest, error) {
+	domains, err := storage.ListDomains(true)
+	if err != nil {
+		return nil, err
+	}
+	results, err := storage.LoadDomainResults()
+	if err != nil {
+		return nil, err
+	}
+	records, err := storage.ListNotificationsSince(since)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{
+		Frequency:  frequency,
+		Since:      since,
+		Until:      until,
+		ExpiryDays: expiryDays,
+		Total:      len(domains),
+	}
+
+	// 本期内的状态变化（通知历史按时间倒序）
+	monitored := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		monitored[d.Name] = true
+	}
+	newErrors := make(map[string]bool)
+	for _, record := range records {
+		if !monitored[record.Domain] || !record.SentAt.Before(until) {
+			continue
+		}
+		if record.Status == "error" {
+			newErrors[record.Domain] = true
+		}
+		digest.Changes = append(digest.Changes, DigestChange{
+			Domain:    record.Domain,
+			OldStatus: record.OldStatus,
+			Status:    record.Status,
+			At:        record.SentAt,
+		})
+	}
+
+	counts := make(map[string]int)
+	deadline := until.Add(time.Duration(expiryDays) * 24 * time.Hour)
+	for _, d := range domains {
+		res, ok := results[d.Name]
+		status := res.Status
+		if !ok || status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+
+		if res.ExpiryAt != nil && res.ExpiryAt.Before(deadline) && status != "available" {
+			expiring := DigestExpiring{Domain: d.Name, Status: status, ExpiryAt: *res.ExpiryAt}
+			if res.ExpiryAt.After(until) {
+				// 与续费提醒一致，剩余天数向上取整
+				expiring.Days = core.DaysUntil(*res.ExpiryAt, until)
+			} else {
+				expiring.Days = -int(until.Sub(*res.ExpiryAt).Hours() / 24)
+			}
+			digest.Expiring = append(digest.Expiring, expiring)
+		}
+
+		// 本期新出现的失败已在状态变化中列出
+		if status == "error" && !newErrors[d.Name] {
+			digest.Errors = append(digest.Errors, DigestError{
+				Domain:      d.Name,
+				Error:       res.ErrorMessage,
+				LastChecked: res.LastChecked,
+			})
+		}
+	}
+
+	for status, count := range counts {
+		digest.Counts = append(digest.Counts, DigestCount{Status: status, Label: translateStatus(i18n.Default, status), Count: count})
+	}
+	sort.Slice(digest.Counts, func(i, j int) bool {
+		if digest.Counts[i].Count != digest.Counts[j].Count {
+			return digest.Counts[i].Count > digest.Counts[j].Count
+		}
+		return digest.Counts[i].Status < digest.Counts[j].Status
+	})
+	sort.Slice(digest.Expiring, func(i, j int) bool { return digest.Expiring[i].ExpiryAt.Before(digest.Expiring[j].ExpiryAt) })
+	sort.Slice(digest.Errors, func(i, j int) bool { return digest.Errors[i].Domain < digest.Errors[j].Domain })
+
+	return digest, nil
+}
+
+// Localize 按语言设置各状态统计的显示名称
+func (d *Digest) Localize(locale string) {
+	for i := range d.Counts {
+		d.Counts[i].Label = translateStatus(locale, d.Counts[i].Status)
+	}
+}
+
+// Subject 摘要标题
+func (d *Digest) Subject(locale string) string {
+	name := i18n.T(locale, "digest.daily")
+	if d.Frequency == "weekly" {
+		name = i18n.T(locale, "digest.weekly")
+	}
+	return fmt.Sprintf("Puff %s %s", name, d.Until.Format("2006-01-02"))
+}
+
+// Text 紧凑的纯文本摘要（聊天类渠道）
+func (d *Digest) Text(locale string) string {
+	var b strings.Builder
+	b.WriteString("📊 " + d.Subject(locale) + "\n")
+	b.WriteString(fmt.Sprintf("%s: %s ~ %s\n", i18n.T(locale, "digest.period"), d.Since.Format("01-02 15:04"), d.Until.Format("01-02 15:04")))
+
+	b.WriteString(fmt.Sprintf("\n%s: %d\n", i18n.T(locale, "digest.total"), d.Total))
+	for _, c := range d.Counts {
+		b.WriteString(fmt.Sprintf("• %s: %d\n", translateStatus(locale, c.Status), c.Count))
+	}
+
+	b.WriteString(fmt.Sprintf("\n⏰ %s (%d)\n", i18n.T(locale, "digest.expiring", d.ExpiryDays), len(d.Expiring)))
+	for i, e := range d.Expiring {
+		if i == digestMaxItems {
+			b.WriteString(i18n.T(locale, "digest.more", len(d.Expiring)-i) + "\n")
+			break
+		}
+		b.WriteString(fmt.Sprintf("• %s %s (%s)\n", e.Domain, e.ExpiryAt.Format("2006-01-02"), formatDays(locale, e.Days)))
+	}
+
+	b.WriteString(fmt.Sprintf("\n🔄 %s (%d)\n", i18n.T(locale, "digest.changes"), len(d.Changes)))
+	for i, c := range d.Changes {
+		if i == digestMaxItems {
+			b.WriteString(i18n.T(locale, "digest.more", len(d.Changes)-i) + "\n")
+			break
+		}
+		b.WriteString(fmt.Sprintf("• %s %s\n", c.Domain, translateStatusChange(locale, c.OldStatus, c.Status)))
+	}
+
+	b.WriteString(fmt.Sprintf("\n❌ %s (%d)\n", i18n.T(locale, "digest.errors"), len(d.Errors)))
+	for i, e := range d.Errors {
+		if i == digestMaxItems {
+			b.WriteString(i18n.T(locale, "digest.more", len(d.Errors)-i) + "\n")
+			break
+		}
+		b.WriteString("• " + e.Domain + "\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatDays 剩余天数描述（days 不大于 0 表示已过期）
+func formatDays(locale string, days int) string {
+	if days == 0 {
+		return i18n.T(locale, "digest.expired_today")
+	}
+	if days < 0 {
+		return i18n.T(locale, "digest.days_expired", -days)
+	}
+	return i18n.T(locale, "digest.days_left", days)
+}
+
+// digestHTML 邮件摘要模板（正文片段，由邮件通知器补充邮件头）；
+// t、status、days 依赖语言，渲染时按接收渠道的语言重新绑定
+var digestHTML = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"t":      func(key string, args ...interface{}) string { return key },
+	"status": func(status string) string { return status },
+	"days":   func(days int) string { return "" },
+	"limit": func(n int, v interface{}) interface{} {
+		switch items := v.(type) {
+		case []DigestExpiring:
+			if len(items) > n {
+				return items[:n]
+			}
+		case []DigestChange:
+			if len(items) > n {
+				return items[:n]
+			}
+		case []DigestError:
+			if len(items) > n {
+				return items[:n]
+			}
+		}
+		return v
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"></head>
+<body style="font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',sans-serif;color:#1f2937;background:#f9fafb;padding:24px;">
+<div style="max-width:640px;margin:0 auto;background:#fff;border:1px solid #e5e7eb;border-radius:8px;padding:24px;">
+<h2 style="margin:0 0 4px;">{{.Subject}}</h2>
+<p style="margin:0 0 16px;color:#6b7280;font-size:13px;">{{t "digest.period"}} {{.Since.Format "2006-01-02 15:04"}} ~ {{.Until.Format "2006-01-02 15:04"}}</p>
+
+<h3 style="margin:16px 0 8px;">{{t "digest.total"}} {{.Total}}</h3>
+<table style="border-collapse:collapse;width:100%;font-size:14px;">
+{{range .Counts}}<tr><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{status .Status}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;text-align:right;">{{.Count}}</td></tr>
+{{end}}</table>
+
+<h3 style="margin:16px 0 8px;">{{t "digest.expiring" .ExpiryDays}}（{{len .Expiring}}）</h3>
+{{if .Expiring}}<table style="border-collapse:collapse;width:100%;font-size:14px;">
+{{range limit 50 .Expiring}}<tr><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{.Domain}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{.ExpiryAt.Format "2006-01-02"}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;text-align:right;">{{days .Days}}</td></tr>
+{{end}}</table>{{else}}<p style="color:#6b7280;">{{t "digest.none"}}</p>{{end}}
+
+<h3 style="margin:16px 0 8px;">{{t "digest.changes"}}（{{len .Changes}}）</h3>
+{{if .Changes}}<table style="border-collapse:collapse;width:100%;font-size:14px;">
+{{range limit 50 .Changes}}<tr><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{.Domain}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{status .OldStatus}} → {{status .Status}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;text-align:right;">{{.At.Local.Format "01-02 15:04"}}</td></tr>
+{{end}}</table>{{else}}<p style="color:#6b7280;">{{t "digest.none"}}</p>{{end}}
+
+<h3 style="margin:16px 0 8px;">{{t "digest.errors"}}（{{len .Errors}}）</h3>
+{{if .Errors}}<table style="border-collapse:collapse;width:100%;font-size:14px;">
+{{range limit 50 .Errors}}<tr><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;">{{.Domain}}</td><td style="padding:4px 8px;border-bottom:1px solid #f3f4f6;color:#b91c1c;">{{.Error}}</td></tr>
+{{end}}</table>{{else}}<p style="color:#6b7280;">{{t "digest.none"}}</p>{{end}}
+
+<p style="margin-top:24px;color:#9ca3af;font-size:12px;">{{t "digest.footer"}}</p>
+</div></body></html>`))
+
+// HTML 邮件摘要正文
+func (d *Digest) HTML(locale string) (string, error) {
+	tmpl, err := digestHTML.Clone()
+	if err != nil {
+		return "", fmt.Errorf("渲染摘要失败: %w", err)
+	}
+	tmpl.Funcs(template.FuncMap{
+		"t":      func(key string, args ...interface{}) string { return i18n.T(locale, key, args...) },
+		"status": func(status string) string { return translateStatus(locale, status) },
+		"days":   func(days int) string { return formatDays(locale, days) },
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		*Digest
+		Subject string
+	}{d, d.Subject(locale)}); err != nil {
+		return "", fmt.Errorf("渲染摘要失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// digestScheduler 定期摘要的调度状态
+type digestScheduler struct {
+	mu     sync.RWMutex
+	config config.DigestConfig
+	stop   chan struct{}
+}
+
+// SetDigestConfig 更新定期摘要配置
+func (nm *NotificationManager) SetDigestConfig(cfg config.DigestConfig) {
+	nm.digest.mu.Lock()
+	nm.digest.config = cfg
+	nm.digest.mu.Unlock()
+}
+
+// DigestConfig 获取定期摘要配置
+func (nm *NotificationManager) DigestConfig() config.DigestConfig {
+	nm.digest.mu.RLock()
+	defer nm.digest.mu.RUnlock()
+	return nm.digest.config
+}
+
+// digestSlot 返回 now 之前最近一次计划发送时刻
+func digestSlot(cfg config.DigestConfig, now time.Time) time.Time {
+	slot := time.Date(now.Year(), now.Month(), now.Day(), cfg.Hour, 0, 0, 0, now.Location())
+	if cfg.Frequency == "weekly" {
+		slot = slot.AddDate(0, 0, -((int(now.Weekday()) - cfg.Weekday + 7) % 7))
+		if slot.After(now) {
+			slot = slot.AddDate(0, 0, -7)
+		}
+		return slot
+	}
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot
+}
+
+// runDigest 摘要调度循环：到达计划时刻且本期尚未发送时发送
+func (nm *NotificationManager) runDigest() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-nm.digest.stop:
+			return
+		case now := <-ticker.C:
+			cfg := nm.DigestConfig()
+			if !cfg.Enabled {
+				continue
+			}
+
+			slot := digestSlot(cfg, now)
+			if now.Sub(slot) > digestGracePeriod {
+				continue
+			}
+			if value, ok, err := storage.GetSetting(digestLastSentKey); err == nil && ok {
+				if last, err := time.Parse(time.RFC3339, value); err == nil && !last.Before(slot) {
+					continue
+				}
+			}
+
+			if err := nm.SendDigest(slot.Add(-DigestPeriod(cfg.Frequency)), now); err != nil {
+				logger.Error("发送定期摘要失败: %v", err)
+			}
+		}
+	}
+}
+
+// PreviewDigest 生成最近一个周期的摘要（不发送）
+func (nm *NotificationManager) PreviewDigest(now time.Time) (*Digest, error) {
+	cfg := nm.DigestConfig()
+	return BuildDigest(cfg.Frequency, now.Add(-DigestPeriod(cfg.Frequency)), now, cfg.ExpiryDays)
+}
+
+// SendDigest 生成 [since, now) 的摘要并写入发件箱：邮件发送 HTML，其他渠道发送纯文本
+func (nm *NotificationManager) SendDigest(since, now time.Time) error {
+	cfg := nm.DigestConfig()
+	digest, err := BuildDigest(cfg.Frequency, since, now, cfg.ExpiryDays)
+	if err != nil {
+		return err
+	}
+
+	receivers := nm.targetReceivers(cfg.Targets)
+	if len(receivers) == 0 {
+		return fmt.Errorf("没有可接收摘要的通知渠道")
+	}
+
+	// 按接收渠道的通知语言分别渲染
+	type rendered struct{ subject, text, html string }
+	byLocale := make(map[string]*rendered)
+	render := func(locale string) (*rendered, error) {
+		if r, ok := byLocale[locale]; ok {
+			return r, nil
+		}
+		html, err := digest.HTML(locale)
+		if err != nil {
+			return nil, err
+		}
+		r := &rendered{subject: digest.Subject(locale), text: digest.Text(locale), html: html}
+		byLocale[locale] = r
+		return r, nil
+	}
+
+	base, err := render(i18n.Default)
+	if err != nil {
+		return err
+	}
+	payload := eventsJSON([]NotificationEvent{{Type: "digest", Message: base.text, Timestamp: now}})
+
+	entries := make([]storage.OutboxEntry, 0, len(receivers))
+	for _, n := range receivers {
+		r, err := render(nm.localeOf(n))
+		if err != nil {
+			return err
+		}
+		body := r.text
+		if IsHTMLChannel(NotifierKey(n)) {
+			body = r.html
+		}
+		entries = append(entries, storage.OutboxEntry{
+			NotifierKey: NotifierKey(n),
+			Subject:     r.subject,
+			Message:     body,
+			Events:      payload,
+			Rendered:    true,
+		})
+	}
+	nm.enqueueEntries(receivers, entries)
+
+	logger.Info("定期摘要已加入发件箱，接收渠道 %d 个", len(receivers))
+	return storage.UpsertSettings(map[string]string{digestLastSentKey: now.Format(time.RFC3339)})
+}
@@ -0,0 +1,227 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+func testDigest() *Digest {
+	until := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	expiry := until.Add(10 * 24 * time.Hour)
+	return &Digest{
+		Frequency:  "daily",
+		Since:      until.Add(-24 * time.Hour),
+		Until:      until,
+		ExpiryDays: 30,
+		Total:      3,
+		Counts: []DigestCount{
+			{Status: "registered", Count: 2},
+			{Status: "available", Count: 1},
+		},
+		Expiring: []DigestExpiring{{Domain: "soon.com", Status: "registered", ExpiryAt: expiry, Days: 10}},
+		Changes:  []DigestChange{{Domain: "drop.com", OldStatus: "pending_delete", Status: "available", At: until.Add(-time.Hour)}},
+		Errors:   []DigestError{{Domain: "broken.com", Error: "timeout", LastChecked: until}},
+	}
+}
+
+func TestDigestHTMLWithStatusChange(t *testing.T) {
+	d := testDigest()
+	for _, tc := range []struct {
+		locale string
+		want   []string
+	}{
+		{i18n.ZhCN, []string{"Puff 每日摘要 2026-03-02", "drop.com", "待删除", "可注册", "剩余 10 天", "此邮件由 Puff 自动发送"}},
+		{i18n.En, []string{"Puff Daily digest 2026-03-02", "drop.com", "Pending delete", "Available", "10 days left", "sent automatically by Puff"}},
+	} {
+		html, err := d.HTML(tc.locale)
+		if err != nil {
+			t.Fatalf("HTML(%s) 失败: %v", tc.locale, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(html, want) {
+				t.Errorf("HTML(%s) 缺少 %q", tc.locale, want)
+			}
+		}
+	}
+}
+
+func TestDigestTextLocalized(t *testing.T) {
+	d := testDigest()
+	text := d.Text(i18n.En)
+	for _, want := range []string{"Daily digest", "Monitored domains: 3", "Expiring within 30 days (1)", "Status changes (1)", "drop.com", "broken.com"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text(en) 缺少 %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "监控域名") {
+		t.Errorf("Text(en) 含有中文文案:\n%s", text)
+	}
+
+	d.Frequency = "weekly"
+	if got := d.Subject(i18n.ZhCN); got != "Puff 每周摘要 2026-03-02" {
+		t.Errorf("Subject(zh-CN) = %q", got)
+	}
+}
+
+func TestFormatDays(t *testing.T) {
+	if got := formatDays(i18n.En, -3); got != "expired 3 days ago" {
+		t.Errorf("formatDays(en, -3) = %q", got)
+	}
+	if got := formatDays(i18n.ZhCN, 5); got != "剩余 5 天" {
+		t.Errorf("formatDays(zh-CN, 5) = %q", got)
+	}
+	if got := formatDays(i18n.ZhCN, 0); got != "今天已过期" {
+		t.Errorf("formatDays(zh-CN, 0) = %q", got)
+	}
+}
+
+func TestDigestSlot(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		cfg  config.DigestConfig
+		now  time.Time
+		want time.Time
+	}{
+		{config.DigestConfig{Frequency: "daily", Hour: 9}, at(4, 10), at(4, 9)},
+		{config.DigestConfig{Frequency: "daily", Hour: 9}, at(4, 9), at(4, 9)},
+		{config.DigestConfig{Frequency: "daily", Hour: 9}, at(4, 8), at(3, 9)},
+		// 2026-03-02 为周一
+		{config.DigestConfig{Frequency: "weekly", Weekday: 1, Hour: 9}, at(4, 10), at(2, 9)},
+		{config.DigestConfig{Frequency: "weekly", Weekday: 1, Hour: 9}, at(2, 9), at(2, 9)},
+		{config.DigestConfig{Frequency: "weekly", Weekday: 1, Hour: 9}, at(2, 8), time.Date(2026, 2, 23, 9, 0, 0, 0, time.UTC)},
+		{config.DigestConfig{Frequency: "weekly", Weekday: 0, Hour: 9}, at(7, 23), at(1, 9)},
+	}
+	for _, tt := range tests {
+		if got := digestSlot(tt.cfg, tt.now); !got.Equal(tt.want) {
+			t.Errorf("digestSlot(%s 周%d %d点, %s) = %s, 期望 %s", tt.cfg.Frequency, tt.cfg.Weekday, tt.cfg.Hour,
+				tt.now.Format("01-02 15:04"), got.Format("01-02 15:04"), tt.want.Format("01-02 15:04"))
+		}
+	}
+
+	if DigestPeriod("weekly") != 7*24*time.Hour || DigestPeriod("daily") != 24*time.Hour {
+		t.Error("摘要周期错误")
+	}
+}
+
+// seedDigestDomains 写入摘要测试用的域名、查询结果与通知历史，返回摘要区间
+func seedDigestDomains(t *testing.T) (since, until time.Time) {
+	t.Helper()
+	now := time.Now()
+	since, until = now.Add(-time.Hour), now.Add(time.Minute)
+	soon := until.Add(9*24*time.Hour + time.Hour)
+	lapsed := until.Add(-36 * time.Hour)
+	far := until.Add(100 * 24 * time.Hour)
+	past := until.Add(-24 * time.Hour)
+
+	results := []storage.DomainResult{
+		{Domain: "dg-soon.com", Status: "registered", ExpiryAt: &soon},
+		{Domain: "dg-lapsed.com", Status: "grace", ExpiryAt: &lapsed},
+		{Domain: "dg-far.com", Status: "registered", ExpiryAt: &far},
+		{Domain: "dg-drop.com", Status: "available", ExpiryAt: &past},
+		{Domain: "dg-broken.com", Status: "error", ErrorMessage: "timeout"},
+		{Domain: "dg-newerr.com", Status: "error", ErrorMessage: "connection refused"},
+		{Domain: "dg-off.com", Status: "error"},
+	}
+	for _, res := range results {
+		res.LastChecked = now
+		if err := storage.AddDomain(res.Domain, res.Domain != "dg-off.com", true); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SaveDomainResult(res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, res := range results {
+			storage.RemoveDomain(res.Domain)
+		}
+	})
+
+	for _, n := range [][3]string{
+		{"dg-drop.com", "available", "pending_delete"},
+		{"dg-newerr.com", "error", "registered"},
+		{"dg-off.com", "error", "registered"},
+		{"dg-gone.com", "available", "registered"},
+	} {
+		if err := storage.SaveNotification(n[0], n[1], n[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return since, until
+}
+
+func TestBuildDigest(t *testing.T) {
+	since, until := seedDigestDomains(t)
+	d, err := BuildDigest("daily", since, until, 30)
+	if err != nil {
+		t.Fatalf("生成摘要失败: %v", err)
+	}
+
+	// 只统计已启用的监控域名
+	counts := make(map[string]int)
+	for _, c := range d.Counts {
+		counts[c.Status] = c.Count
+	}
+	if d.Total != 6 || counts["registered"] != 2 || counts["grace"] != 1 || counts["error"] != 2 || counts["available"] != 1 {
+		t.Errorf("状态统计错误: total=%d %v", d.Total, d.Counts)
+	}
+	// 可注册的域名不列入即将过期
+	// 剩余天数与续费提醒一致向上取整（9 天 1 小时为 10 天），已过期的为负的已过天数
+	if len(d.Expiring) != 2 || d.Expiring[0].Domain != "dg-lapsed.com" || d.Expiring[0].Days != -1 ||
+		d.Expiring[1].Domain != "dg-soon.com" || d.Expiring[1].Days != 10 {
+		t.Errorf("即将过期错误: %+v", d.Expiring)
+	}
+	// 只列出监控中域名的状态变化
+	changed := make(map[string]string)
+	for _, c := range d.Changes {
+		changed[c.Domain] = c.OldStatus + "->" + c.Status
+	}
+	if len(changed) != 2 || changed["dg-drop.com"] != "pending_delete->available" || changed["dg-newerr.com"] != "registered->error" {
+		t.Errorf("状态变化错误: %+v", d.Changes)
+	}
+	// 本期新出现的失败已在状态变化中列出，不重复列为持续失败
+	if len(d.Errors) != 1 || d.Errors[0].Domain != "dg-broken.com" || d.Errors[0].Error != "timeout" {
+		t.Errorf("持续失败错误: %+v", d.Errors)
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	since, until := seedDigestDomains(t)
+	email := &recordingNotifier{notifierType: "email"}
+	chat := &recordingNotifier{notifierType: "telegram"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(email)
+	nm.AddNotifier(chat)
+	nm.SetLocales(config.NotificationConfig{Locale: i18n.ZhCN, Locales: "telegram=en"})
+	nm.SetDigestConfig(config.DigestConfig{Enabled: true, Frequency: "daily", ExpiryDays: 30})
+
+	if err := nm.SendDigest(since, until); err != nil {
+		t.Fatalf("发送摘要失败: %v", err)
+	}
+	nm.dispatchOutbox()
+
+	// 邮件发送 HTML，聊天渠道发送其语言的纯文本
+	subjects, messages := email.received()
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "<!DOCTYPE html>") || !strings.Contains(subjects[0], "每日摘要") {
+		t.Errorf("邮件摘要错误: %v", subjects)
+	}
+	subjects, messages = chat.received()
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "📊 Puff Daily digest") || !strings.Contains(messages[0], "dg-soon.com") {
+		t.Errorf("聊天摘要错误: %v %v", subjects, messages)
+	}
+
+	value, ok, err := storage.GetSetting(digestLastSentKey)
+	if err != nil || !ok || value != until.Format(time.RFC3339) {
+		t.Errorf("应记录本次发送时间: %q %v %v", value, ok, err)
+	}
+
+	nm.SetDigestConfig(config.DigestConfig{Enabled: true, Frequency: "daily", Targets: "slack"})
+	if err := nm.SendDigest(since, until); err == nil {
+		t.Error("没有接收渠道时应返回错误")
+	}
+}
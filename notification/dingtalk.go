@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"Puff/config"
+)
+
+// DingTalkNotifier 钉钉群机器人通知器
+type DingTalkNotifier struct {
+	robotBase
+}
+
+// dingTalkResponse 钉钉机器人响应
+type dingTalkResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// NewDingTalkNotifier 创建钉钉通知器
+func NewDingTalkNotifier(cfg config.RobotConfig) *DingTalkNotifier {
+	return &DingTalkNotifier{robotBase: newRobotBase(cfg)}
+}
+
+// SendMessage 发送钉钉 Markdown 消息
+func (d *DingTalkNotifier) SendMessage(subject, message string) error {
+	if err := d.validateWebhook("钉钉"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": subject,
+			"text":  formatRobotMarkdown(subject, message, true),
+		},
+	}
+
+	var result dingTalkResponse
+	if err := d.postJSON(d.signedURL(), payload, &result); err != nil {
+		return fmt.Errorf("发送钉钉消息失败: %v", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("钉钉API错误 (%d): %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (d *DingTalkNotifier) GetType() string {
+	return "dingtalk"
+}
+
+// Test 发送测试消息
+func (d *DingTalkNotifier) Test() error {
+	return d.SendMessage("钉钉通知测试", robotTestMessage("钉钉"))
+}
+
+// signedURL 加签：HmacSHA256(secret, timestamp+"\n"+secret) 的 Base64，附加到地址参数
+func (d *DingTalkNotifier) signedURL() string {
+	if d.config.Secret == "" {
+		return d.config.Webhook
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(d.config.Secret))
+	mac.Write([]byte(timestamp + "\n" + d.config.Secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	sep := "?"
+	if strings.Contains(d.config.Webhook, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%stimestamp=%s&sign=%s", d.config.Webhook, sep, timestamp, url.QueryEscape(sign))
+}
@@ -1,38 +1,14 @@
 package notification
 
 import (
-	"crypto/tls"
 	"fmt"
-	"net/smtp"
+	"html/template"
 	"strings"
 
 	"Puff/config"
+	"Puff/i18n"
 )
 
-// translateStatus 翻译状态为中文
-func translateStatus(status string) string {
-	statusMap := map[string]string{
-		"available":      "可注册",
-		"registered":     "已注册",
-		"grace":          "宽限期",
-		"redemption":     "赎回期",
-		"pending_delete": "待删除",
-		"expired":        "已过期",
-		"error":          "查询失败",
-		"unknown":        "未知",
-	}
-
-	if chinese, ok := statusMap[status]; ok {
-		return chinese
-	}
-	return status
-}
-
-// translateStatusChange 翻译状态变化为中文
-func translateStatusChange(oldStatus, newStatus string) string {
-	return translateStatus(oldStatus) + " → " + translateStatus(newStatus)
-}
-
 // EmailNotifier 邮件通知器
 type EmailNotifier struct {
 	config  config.SMTPConfig
@@ -59,12 +35,25 @@ func (e *EmailNotifier) SendMessage(subject, message string) error {
 	}
 
 	// 构建邮件内容
-	body := e.buildEmailBody(subject, message)
+	body := e.buildEmailBody(subject, e.buildHTMLContent(subject, message))
 
 	// 发送邮件
 	return e.sendEmail(subject, body)
 }
 
+// SendRendered 发送自定义模板渲染的HTML邮件（不使用内置排版）
+func (e *EmailNotifier) SendRendered(subject, body string, events []NotificationEvent) error {
+	if !e.enabled {
+		return fmt.Errorf("邮件通知未启用")
+	}
+
+	if err := e.validateConfig(); err != nil {
+		return fmt.Errorf("邮件配置无效: %v", err)
+	}
+
+	return e.sendEmail(subject, e.buildEmailBody(subject, body))
+}
+
 // IsEnabled 检查是否启用
 func (e *EmailNotifier) IsEnabled() bool {
 	return e.enabled && e.config.Enabled
@@ -117,12 +106,12 @@ func (e *EmailNotifier) validateConfig() error {
 		return fmt.Errorf("SMTP端口无效: %d", e.config.Port)
 	}
 
-	if e.config.User == "" {
-		return fmt.Errorf("SMTP用户名不能为空")
+	if smtpAuthMechanism(e.config) != SMTPAuthNone && e.config.Password == "" {
+		return fmt.Errorf("SMTP密码不能为空")
 	}
 
-	if e.config.Password == "" {
-		return fmt.Errorf("SMTP密码不能为空")
+	if err := ValidateSMTPConfig(e.config); err != nil {
+		return err
 	}
 
 	if e.config.From == "" {
@@ -136,8 +125,8 @@ func (e *EmailNotifier) validateConfig() error {
 	return nil
 }
 
-// buildEmailBody 构建邮件内容
-func (e *EmailNotifier) buildEmailBody(subject, message string) string {
+// buildEmailBody 构建邮件内容（htmlContent 为HTML正文）
+func (e *EmailNotifier) buildEmailBody(subject, htmlContent string) string {
 	var body strings.Builder
 
 	// 邮件头
@@ -150,7 +139,7 @@ func (e *EmailNotifier) buildEmailBody(subject, message string) string {
 	body.WriteString("\r\n")
 
 	// 邮件正文 - HTML格式
-	body.WriteString(e.buildHTMLContent(subject, message))
+	body.WriteString(htmlContent)
 
 	return body.String()
 }
@@ -158,36 +147,15 @@ func (e *EmailNotifier) buildEmailBody(subject, message string) string {
 // buildHTMLContent 构建HTML邮件内容（DaisyUI Lofi风格）
 func (e *EmailNotifier) buildHTMLContent(subject, message string) string {
 	// 检查是否为批量通知
-	if strings.Contains(message, "检测到") && strings.Contains(message, "个域名状态发生变化") {
+	if isBatchMessage(message) {
 		return e.buildBatchHTMLContent(subject, message)
 	}
 
 	// 解析消息内容
-	lines := strings.Split(message, "\n")
-	domain := ""
-	timestamp := ""
-	oldStatus := ""
-	newStatus := ""
-	statusInfo := ""
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "域名: ") {
-			domain = strings.TrimPrefix(line, "域名: ")
-		} else if strings.HasPrefix(line, "时间: ") {
-			timestamp = strings.TrimPrefix(line, "时间: ")
-		} else if strings.HasPrefix(line, "状态变化: ") {
-			statusChangeStr := strings.TrimPrefix(line, "状态变化: ")
-			// 解析 "old_status → new_status"
-			parts := strings.Split(statusChangeStr, "→")
-			if len(parts) == 2 {
-				oldStatus = strings.TrimSpace(parts[0])
-				newStatus = strings.TrimSpace(parts[1])
-			}
-		} else if strings.HasPrefix(line, "状态: ") {
-			statusInfo = strings.TrimPrefix(line, "状态: ")
-		}
-	}
+	p := parseMessage(message)
+	domain, timestamp := p.Domain, p.Timestamp
+	oldStatus, newStatus, statusInfo := p.OldStatus, p.NewStatus, p.StatusInfo
+	oldValue, newValue := p.OldValue, p.NewValue
 
 	// DaisyUI Lofi风格: 扁平、黑白灰、极简
 	html := `<!DOCTYPE html>
@@ -320,10 +288,10 @@ func (e *EmailNotifier) buildHTMLContent(subject, message string) string {
             </div>`
 	}
 
-	// 状态变化（翻译成中文）
+	// 状态变化（翻译为通知语言）
 	if oldStatus != "" && newStatus != "" {
-		translatedOld := translateStatus(oldStatus)
-		translatedNew := translateStatus(newStatus)
+		translatedOld := translateStatus(p.Locale, oldStatus)
+		translatedNew := translateStatus(p.Locale, newStatus)
 		html += `
             <div class="status-change-box">
                 <div class="status-arrow">
@@ -340,6 +308,20 @@ func (e *EmailNotifier) buildHTMLContent(subject, message string) string {
             </div>`
 	}
 
+	// 字段变化（原值 → 新值）
+	if oldValue != "" || newValue != "" {
+		html += `
+            <div class="status-change-box">
+                <div class="status-arrow">
+                    <div class="status-old">` + oldValue + `</div>
+                    <div class="arrow">→</div>
+                    <div class="status-new">` + newValue + `</div>
+                </div>
+            </div>`
+	}
+
+	html += alertActionsHTML(message)
+
 	html += `
         </div>
         <div class="footer">
@@ -353,49 +335,49 @@ func (e *EmailNotifier) buildHTMLContent(subject, message string) string {
 	return html
 }
 
-// buildBatchHTMLContent 构建批量通知的HTML内容
-func (e *EmailNotifier) buildBatchHTMLContent(subject, message string) string {
-	// 解析消息内容
-	lines := strings.Split(message, "\n")
-	timestamp := ""
-	domainChanges := []struct {
-		Domain    string
-		OldStatus string
-		NewStatus string
-	}{}
-
-	// 解析时间和域名变化
-	for i, line := range lines {
+// alertActionsHTML 将消息末尾的告警确认说明与链接（见 alertFooter）转为HTML
+func alertActionsHTML(message string) string {
+	var html string
+	open := false
+	for _, line := range strings.Split(message, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "时间: ") {
-			timestamp = strings.TrimPrefix(line, "时间: ")
-		} else if strings.Contains(line, ". ") && !strings.HasPrefix(line, "---") {
-			// 解析域名行 "1. test1.puff"
-			parts := strings.SplitN(line, ". ", 2)
-			if len(parts) == 2 {
-				domain := parts[1]
-				// 下一行应该是状态变化
-				if i+1 < len(lines) {
-					nextLine := strings.TrimSpace(lines[i+1])
-					if strings.HasPrefix(nextLine, "状态变化: ") {
-						statusChangeStr := strings.TrimPrefix(nextLine, "状态变化: ")
-						statusParts := strings.Split(statusChangeStr, "→")
-						if len(statusParts) == 2 {
-							domainChanges = append(domainChanges, struct {
-								Domain    string
-								OldStatus string
-								NewStatus string
-							}{
-								Domain:    domain,
-								OldStatus: strings.TrimSpace(statusParts[0]),
-								NewStatus: strings.TrimSpace(statusParts[1]),
-							})
-						}
-					}
-				}
+		if strings.HasPrefix(line, "⚠️ ") {
+			if open {
+				html += `
+            </div>`
 			}
+			html += `
+            <div class="info-box">
+                <div class="info-label">Action Required</div>
+                <div class="info-value">` + template.HTMLEscapeString(strings.TrimPrefix(line, "⚠️ ")) + `</div>`
+			open = true
+		} else if name, link, ok := strings.Cut(line, ": "); ok && open && isAlertAction(name) {
+			html += `
+                <a href="` + template.HTMLEscapeString(link) + `" style="display: inline-block; margin: 8px 8px 0 0; padding: 6px 12px; border: 2px solid #000000; color: #000000; text-decoration: none; font-weight: 700;">` + template.HTMLEscapeString(name) + `</a>`
 		}
 	}
+	if open {
+		html += `
+            </div>`
+	}
+	return html
+}
+
+// isAlertAction 判断是否为任一语言的告警操作链接标签（确认、暂停）
+func isAlertAction(name string) bool {
+	for _, locale := range i18n.Supported() {
+		if name == i18n.T(locale, "notify.ack") || name == i18n.T(locale, "notify.snooze") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildBatchHTMLContent 构建批量通知的HTML内容
+func (e *EmailNotifier) buildBatchHTMLContent(subject, message string) string {
+	// 解析消息内容
+	locale := messageLocale(message)
+	timestamp, domainChanges := parseBatchMessage(message)
 
 	// 构建HTML
 	html := `<!DOCTYPE html>
@@ -524,12 +506,12 @@ func (e *EmailNotifier) buildBatchHTMLContent(subject, message string) string {
 	}
 
 	html += `
-            <div class="summary">检测到 ` + fmt.Sprintf("%d", len(domainChanges)) + ` 个域名状态发生变化</div>`
+            <div class="summary">` + i18n.T(locale, "notify.batch_header", len(domainChanges)) + `</div>`
 
 	// 添加每个域名的变化
 	for _, change := range domainChanges {
-		translatedOld := translateStatus(change.OldStatus)
-		translatedNew := translateStatus(change.NewStatus)
+		translatedOld := translateStatus(locale, change.OldStatus)
+		translatedNew := translateStatus(locale, change.NewStatus)
 		html += `
             <div class="domain-item">
                 <div class="domain-name"><a style="color: #000000 !important;">` + change.Domain + `</a></div>
@@ -541,6 +523,8 @@ func (e *EmailNotifier) buildBatchHTMLContent(subject, message string) string {
             </div>`
 	}
 
+	html += alertActionsHTML(message)
+
 	html += `
         </div>
         <div class="footer">
@@ -556,48 +540,21 @@ func (e *EmailNotifier) buildBatchHTMLContent(subject, message string) string {
 
 // sendEmail 发送邮件
 func (e *EmailNotifier) sendEmail(subject, body string) error {
-	// 构建服务器地址
-	addr := fmt.Sprintf("%s:%d", e.config.Host, e.config.Port)
-
-	// 创建认证
-	auth := smtp.PlainAuth("", e.config.User, e.config.Password, e.config.Host)
-
-	// 解析收件人地址
-	recipients := e.parseRecipients(e.config.To)
-
-	// 判断是否使用TLS
-	if e.config.Port == 465 {
-		// 使用SSL/TLS连接
-		return e.sendWithTLS(addr, auth, recipients, body)
-	} else {
-		// 使用STARTTLS连接
-		return e.sendWithSTARTTLS(addr, auth, recipients, body)
-	}
-}
-
-// sendWithTLS 使用TLS发送邮件
-func (e *EmailNotifier) sendWithTLS(addr string, auth smtp.Auth, recipients []string, body string) error {
-	// 创建TLS连接
-	tlsConfig := &tls.Config{
-		ServerName: e.config.Host,
-	}
-
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+	// 按安全模式建立连接
+	client, err := dialSMTP(e.config)
 	if err != nil {
-		return fmt.Errorf("TLS连接失败: %v", err)
-	}
-	defer conn.Close()
-
-	// 创建SMTP客户端
-	client, err := smtp.NewClient(conn, e.config.Host)
-	if err != nil {
-		return fmt.Errorf("创建SMTP客户端失败: %v", err)
+		return err
 	}
 	defer client.Close()
 
 	// 认证
-	if err := client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP认证失败: %v", err)
+	if auth := smtpAuth(e.config); auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP服务器不支持认证，请将认证方式设为 none")
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP认证失败: %v", err)
+		}
 	}
 
 	// 设置发件人
@@ -606,7 +563,7 @@ func (e *EmailNotifier) sendWithTLS(addr string, auth smtp.Auth, recipients []st
 	}
 
 	// 设置收件人
-	for _, recipient := range recipients {
+	for _, recipient := range e.parseRecipients(e.config.To) {
 		if err := client.Rcpt(recipient); err != nil {
 			return fmt.Errorf("设置收件人失败: %v", err)
 		}
@@ -631,11 +588,6 @@ func (e *EmailNotifier) sendWithTLS(addr string, auth smtp.Auth, recipients []st
 	return client.Quit()
 }
 
-// sendWithSTARTTLS 使用STARTTLS发送邮件
-func (e *EmailNotifier) sendWithSTARTTLS(addr string, auth smtp.Auth, recipients []string, body string) error {
-	return smtp.SendMail(addr, auth, e.config.From, recipients, []byte(body))
-}
-
 // parseRecipients 解析收件人地址
 func (e *EmailNotifier) parseRecipients(to string) []string {
 	// 支持多个收件人，用逗号分隔
@@ -0,0 +1,372 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	escalationCheckInterval = 30 * time.Second    // 检查到期告警的间隔
+	alertSigningKeySetting  = "alert_signing_key" // 告警确认链接的签名密钥（设置键）
+	AlertLinkTTL            = 7 * 24 * time.Hour  // 告警确认链接的有效期（每次重复提醒都会生成新链接）
+	AlertLinkSnoozeMinutes  = 60                  // 暂停链接的暂停时长（固定，不接受链接中的参数）
+)
+
+// 告警确认链接校验错误
+var (
+	ErrAlertLinkInvalid = errors.New("告警链接签名无效")
+	ErrAlertLinkExpired = errors.New("告警链接已过期")
+)
+
+// escalation 重复提醒的运行状态
+type escalation struct {
+	mu        sync.RWMutex
+	policies  []storage.EscalationPolicy
+	publicURL string
+	key       []byte
+	stop      chan struct{}
+}
+
+// LoadEscalationPolicies 从数据库重新加载重复提醒策略
+func (nm *NotificationManager) LoadEscalationPolicies() error {
+	policies, err := storage.ListEscalationPolicies()
+	if err != nil {
+		return err
+	}
+	nm.escalation.mu.Lock()
+	nm.escalation.policies = policies
+	nm.escalation.mu.Unlock()
+	return nil
+}
+
+// SetPublicURL 设置对外访问地址，用于生成告警确认链接
+func (nm *NotificationManager) SetPublicURL(url string) {
+	nm.escalation.mu.Lock()
+	nm.escalation.publicURL = strings.TrimRight(strings.TrimSpace(url), "/")
+	nm.escalation.mu.Unlock()
+}
+
+// policyFor 查找域名在指定状态下生效的策略（域名策略优先于全局策略）
+func (nm *NotificationManager) policyFor(domain, status string) *storage.EscalationPolicy {
+	nm.escalation.mu.RLock()
+	defer nm.escalation.mu.RUnlock()
+
+	var global *storage.EscalationPolicy
+	for i := range nm.escalation.policies {
+		p := &nm.escalation.policies[i]
+		if !p.Enabled || p.Status != status {
+			continue
+		}
+		if p.Domain == domain {
+			copied := *p
+			return &copied
+		}
+		if p.Domain == storage.GlobalScope && global == nil {
+			copied := *p
+			global = &copied
+		}
+	}
+	return global
+}
+
+// policyByID 按 ID 查找策略，不存在时返回 nil
+func (nm *NotificationManager) policyByID(id int64) *storage.EscalationPolicy {
+	nm.escalation.mu.RLock()
+	defer nm.escalation.mu.RUnlock()
+
+	for _, p := range nm.escalation.policies {
+		if p.ID == id {
+			return &p
+		}
+	}
+	return nil
+}
+
+// trackAlerts 状态变化通知发出前：解除已失效的告警，并为匹配策略的新状态创建告警
+func (nm *NotificationManager) trackAlerts(events []NotificationEvent) map[string]*storage.Alert {
+	alerts := make(map[string]*storage.Alert)
+	now := time.Now()
+	for _, event := range events {
+		if event.Type != "status_change" || event.Domain == "" {
+			continue
+		}
+
+		if n, err := storage.ResolveAlerts(event.Domain, event.Status); err != nil {
+			logger.Error("解除域名 %s 的告警失败: %v", event.Domain, err)
+		} else if n > 0 {
+			logger.Info("域名 %s 状态已变为 %s，自动解除 %d 条告警", event.Domain, event.Status, n)
+		}
+
+		policy := nm.policyFor(event.Domain, event.Status)
+		if policy == nil {
+			continue
+		}
+		alert, err := storage.OpenAlert(event.Domain, event.Status, event.OldStatus, policy.ID, now.Add(time.Duration(policy.IntervalMinutes)*time.Minute))
+		if err != nil {
+			logger.Error("创建域名 %s 的告警失败: %v", event.Domain, err)
+			continue
+		}
+		alerts[event.Domain] = alert
+	}
+	return alerts
+}
+
+// alertFooter 生成通知末尾的确认说明（含签名链接）
+func (nm *NotificationManager) alertFooter(locale string, events []NotificationEvent, alerts map[string]*storage.Alert) string {
+	var b strings.Builder
+	for _, event := range events {
+		alert, ok := alerts[event.Domain]
+		if !ok {
+			continue
+		}
+		b.WriteString("\n\n" + i18n.T(locale, "notify.alert_required", alert.Domain, alert.ID))
+		if ack, snooze := nm.AlertLinks(alert.ID); ack != "" {
+			b.WriteString("\n" + i18n.T(locale, "notify.ack") + ": " + ack)
+			b.WriteString("\n" + i18n.T(locale, "notify.snooze") + ": " + snooze)
+		}
+	}
+	return b.String()
+}
+
+// signingKey 读取或生成告警链接签名密钥
+func (nm *NotificationManager) signingKey() ([]byte, error) {
+	nm.escalation.mu.Lock()
+	defer nm.escalation.mu.Unlock()
+
+	if nm.escalation.key != nil {
+		return nm.escalation.key, nil
+	}
+
+	value, ok, err := storage.GetSetting(alertSigningKeySetting)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || value == "" {
+		bytes := make([]byte, 32)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+		}
+		value = hex.EncodeToString(bytes)
+		if err := storage.UpsertSettings(map[string]string{alertSigningKeySetting: value}); err != nil {
+			return nil, err
+		}
+	}
+	nm.escalation.key = []byte(value)
+	return nm.escalation.key, nil
+}
+
+// AlertToken 告警确认链接的签名，格式为 "过期时间戳.签名"，过期时间参与签名
+func (nm *NotificationManager) AlertToken(id int64, expires time.Time) string {
+	key, err := nm.signingKey()
+	if err != nil {
+		logger.Error("读取告警签名密钥失败: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%d.%s", expires.Unix(), alertSignature(key, id, expires.Unix()))
+}
+
+// alertSignature 计算告警 ID 与过期时间的签名
+func alertSignature(key []byte, id, expires int64) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "alert:%d:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAlertToken 校验告警确认链接的签名与有效期
+func (nm *NotificationManager) VerifyAlertToken(id int64, token string, now time.Time) error {
+	key, err := nm.signingKey()
+	if err != nil {
+		return fmt.Errorf("读取告警签名密钥失败: %w", err)
+	}
+	expiresPart, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrAlertLinkInvalid
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil {
+		return ErrAlertLinkInvalid
+	}
+	if !hmac.Equal([]byte(alertSignature(key, id, expires)), []byte(signature)) {
+		return ErrAlertLinkInvalid
+	}
+	if now.Unix() > expires {
+		return ErrAlertLinkExpired
+	}
+	return nil
+}
+
+// AlertLinks 生成告警的确认与暂停链接；未配置对外访问地址时返回空
+func (nm *NotificationManager) AlertLinks(id int64) (ack, snooze string) {
+	nm.escalation.mu.RLock()
+	base := nm.escalation.publicURL
+	nm.escalation.mu.RUnlock()
+
+	if base == "" {
+		return "", ""
+	}
+	token := nm.AlertToken(id, time.Now().Add(AlertLinkTTL))
+	if token == "" {
+		return "", ""
+	}
+	query := fmt.Sprintf("?id=%d&token=%s", id, token)
+	return base + "/alerts/ack" + query, base + "/alerts/snooze" + query
+}
+
+// runEscalation 重复提醒循环：对到期且未确认的告警再次发送通知
+func (nm *NotificationManager) runEscalation() {
+	ticker := time.NewTicker(escalationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-nm.escalation.stop:
+			return
+		case now := <-ticker.C:
+			alerts, err := storage.DueAlerts(now)
+			if err != nil {
+				logger.Error("查询到期告警失败: %v", err)
+				continue
+			}
+			nm.repeatAlerts(alerts, now)
+		}
+	}
+}
+
+// reminder 一次到期的重复提醒
+type reminder struct {
+	alert storage.Alert
+	event NotificationEvent // 按原状态变化重建的事件，用于路由
+	count int               // 含首次通知在内的提醒次数
+	last  bool              // 已达到最大提醒次数
+}
+
+// repeatAlerts 重复发送到期告警：按原状态变化路由，再按各通知器的发送方式发送
+// immediate 逐条发送，batched 将本轮到期的提醒合并为一条，hourly 加入每小时汇总；关键状态与首次通知一样立即发送
+func (nm *NotificationManager) repeatAlerts(alerts []storage.Alert, now time.Time) {
+	var batched []Notifier
+	grouped := make(map[Notifier][]reminder)
+	for _, alert := range alerts {
+		r, ok := nm.prepareReminder(alert, now)
+		if !ok {
+			continue
+		}
+
+		receivers := nm.RouteEvent(r.event)
+		split := map[string][]Notifier{ModeImmediate: receivers}
+		if nm.aggregator == nil || !nm.aggregator.isCritical(r.event.Status) {
+			split = nm.splitByMode(receivers)
+		}
+		for _, n := range split[ModeImmediate] {
+			nm.sendReminders(n, []reminder{r})
+		}
+		nm.deferEvent(r.event, split[ModeHourly])
+		for _, n := range split[ModeBatched] {
+			if _, ok := grouped[n]; !ok {
+				batched = append(batched, n)
+			}
+			grouped[n] = append(grouped[n], r)
+		}
+		logger.Info("域名 %s 告警 #%d 未确认，第 %d 次提醒", alert.Domain, alert.ID, r.count)
+	}
+
+	for _, n := range batched {
+		nm.sendReminders(n, grouped[n])
+	}
+}
+
+// prepareReminder 检查告警是否仍需提醒并记录本次提醒，不需要时返回 false
+func (nm *NotificationManager) prepareReminder(alert storage.Alert, now time.Time) (reminder, bool) {
+	// 状态已变化但未收到变化通知（如重复变化被去重）时直接解除
+	result, err := storage.GetDomainResult(alert.Domain)
+	if err != nil {
+		logger.Error("读取域名 %s 状态失败: %v", alert.Domain, err)
+		return reminder{}, false
+	}
+	if result == nil || result.Status != alert.Status {
+		current := ""
+		if result != nil {
+			current = result.Status
+		}
+		if _, err := storage.ResolveAlerts(alert.Domain, current); err != nil {
+			logger.Error("解除域名 %s 的告警失败: %v", alert.Domain, err)
+		}
+		return reminder{}, false
+	}
+
+	policy := nm.policyByID(alert.PolicyID)
+	if policy == nil || !policy.Enabled {
+		if err := storage.StopAlert(alert.ID); err != nil {
+			logger.Error("停止告警 #%d 失败: %v", alert.ID, err)
+		}
+		return reminder{}, false
+	}
+
+	repeats := alert.Repeats + 1
+	var next time.Time
+	if policy.MaxRepeats == 0 || repeats < policy.MaxRepeats {
+		next = now.Add(time.Duration(policy.IntervalMinutes) * time.Minute)
+	}
+	if err := storage.MarkAlertRepeated(alert.ID, next); err != nil {
+		logger.Error("更新告警 #%d 失败: %v", alert.ID, err)
+		return reminder{}, false
+	}
+
+	return reminder{
+		alert: alert,
+		event: NotificationEvent{
+			Type:      "status_change",
+			Domain:    alert.Domain,
+			OldStatus: alert.OldStatus,
+			Status:    alert.Status,
+			Message:   i18n.T(i18n.Default, "notify.reminder.message", alert.Domain, translateStatus(i18n.Default, alert.Status)),
+			Timestamp: now,
+		},
+		count: repeats + 1,
+		last:  next.IsZero(),
+	}, true
+}
+
+// sendReminders 按通知器的通知语言生成提醒并加入发件箱，多条提醒合并为一条
+func (nm *NotificationManager) sendReminders(n Notifier, reminders []reminder) {
+	locale := nm.localeOf(n)
+	events := make([]NotificationEvent, 0, len(reminders))
+	alerts := make(map[string]*storage.Alert, len(reminders))
+	texts := make([]string, 0, len(reminders))
+	for i := range reminders {
+		r := &reminders[i]
+		events = append(events, r.event)
+		alerts[r.alert.Domain] = &r.alert
+
+		status := translateStatus(locale, r.alert.Status)
+		text := fmt.Sprintf("%s: %s\n%s: %s\n%s: %s\n%s",
+			label(locale, "domain"), r.alert.Domain,
+			label(locale, "status"), status,
+			label(locale, "first_notified"), r.alert.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			i18n.T(locale, "notify.reminder.count", r.count))
+		if r.last {
+			text += "\n" + i18n.T(locale, "notify.reminder.max")
+		}
+		texts = append(texts, text)
+	}
+
+	subject := i18n.T(locale, "notify.reminder.batch", len(reminders))
+	if len(reminders) == 1 {
+		r := reminders[0]
+		subject = i18n.T(locale, "notify.reminder.subject", r.count, r.alert.Domain, translateStatus(locale, r.alert.Status))
+	}
+	message := strings.Join(texts, "\n\n") + nm.alertFooter(locale, events, alerts)
+
+	nm.enqueue([]Notifier{n}, subject, message, events)
+}
@@ -0,0 +1,359 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/storage"
+)
+
+func TestVerifyAlertToken(t *testing.T) {
+	nm := NewNotificationManager()
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	token := nm.AlertToken(7, expires)
+	expiresPart, signature, _ := strings.Cut(token, ".")
+
+	// 篡改签名中的一个字符
+	flipped := []byte(signature)
+	if flipped[0] == 'a' {
+		flipped[0] = 'b'
+	} else {
+		flipped[0] = 'a'
+	}
+
+	tests := []struct {
+		name  string
+		id    int64
+		token string
+		now   time.Time
+		want  error
+	}{
+		{"有效链接", 7, token, now, nil},
+		{"到期前一刻仍有效", 7, token, expires, nil},
+		{"已过期", 7, token, expires.Add(time.Second), ErrAlertLinkExpired},
+		{"其他告警", 8, token, now, ErrAlertLinkInvalid},
+		{"篡改签名", 7, expiresPart + "." + string(flipped), now, ErrAlertLinkInvalid},
+		{"篡改过期时间", 7, fmt.Sprintf("%d.%s", expires.Add(24*time.Hour).Unix(), signature), now, ErrAlertLinkInvalid},
+		{"缺少过期时间", 7, signature, now, ErrAlertLinkInvalid},
+		{"过期时间不是数字", 7, "abc." + signature, now, ErrAlertLinkInvalid},
+		{"空链接", 7, "", now, ErrAlertLinkInvalid},
+	}
+	for _, tt := range tests {
+		err := nm.VerifyAlertToken(tt.id, tt.token, tt.now)
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: 校验结果 %v，期望 %v", tt.name, err, tt.want)
+		}
+	}
+
+	// 签名密钥持久化，重新创建的管理器仍可校验旧链接
+	if err := NewNotificationManager().VerifyAlertToken(7, token, now); err != nil {
+		t.Errorf("重新创建管理器后校验失败: %v", err)
+	}
+}
+
+func TestAlertLinks(t *testing.T) {
+	nm := NewNotificationManager()
+	if ack, snooze := nm.AlertLinks(3); ack != "" || snooze != "" {
+		t.Errorf("未配置对外地址时不应生成链接: %q %q", ack, snooze)
+	}
+
+	nm.SetPublicURL(" https://puff.example.com/ ")
+	ack, snooze := nm.AlertLinks(3)
+	if !strings.HasPrefix(ack, "https://puff.example.com/alerts/ack?id=3&token=") {
+		t.Fatalf("确认链接错误: %q", ack)
+	}
+	if !strings.HasPrefix(snooze, "https://puff.example.com/alerts/snooze?id=3&token=") || strings.Contains(snooze, "minutes=") {
+		t.Errorf("暂停链接错误: %q", snooze)
+	}
+
+	u, err := url.Parse(ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := u.Query().Get("token")
+	if err := nm.VerifyAlertToken(3, token, time.Now()); err != nil {
+		t.Errorf("生成的链接应可校验: %v", err)
+	}
+	if err := nm.VerifyAlertToken(3, token, time.Now().Add(AlertLinkTTL+time.Minute)); !errors.Is(err, ErrAlertLinkExpired) {
+		t.Errorf("超过有效期后应过期: %v", err)
+	}
+}
+
+// testPolicy 创建一条域名级提醒策略，测试结束后删除
+func testPolicy(t *testing.T, domain, status string, interval, maxRepeats int) storage.EscalationPolicy {
+	t.Helper()
+	p := storage.EscalationPolicy{Domain: domain, Status: status, IntervalMinutes: interval, MaxRepeats: maxRepeats, Enabled: true}
+	if err := storage.CreateEscalationPolicy(&p); err != nil {
+		t.Fatalf("创建提醒策略失败: %v", err)
+	}
+	t.Cleanup(func() { storage.DeleteEscalationPolicy(p.ID) })
+	return p
+}
+
+func TestPolicyFor(t *testing.T) {
+	nm := NewNotificationManager()
+	nm.escalation.policies = []storage.EscalationPolicy{
+		{ID: 1, Domain: storage.GlobalScope, Status: "available", Enabled: true},
+		{ID: 2, Domain: "vip.com", Status: "available", Enabled: true},
+		{ID: 3, Domain: "off.com", Status: "available", Enabled: false},
+		{ID: 4, Domain: storage.GlobalScope, Status: "available", Enabled: true},
+	}
+	tests := []struct {
+		domain, status string
+		want           int64 // 0 表示没有生效的策略
+	}{
+		{"vip.com", "available", 2},
+		{"other.com", "available", 1},
+		{"off.com", "available", 1},
+		{"vip.com", "registered", 0},
+	}
+	for _, tt := range tests {
+		var got int64
+		if p := nm.policyFor(tt.domain, tt.status); p != nil {
+			got = p.ID
+		}
+		if got != tt.want {
+			t.Errorf("policyFor(%s, %s) = %d, 期望 %d", tt.domain, tt.status, got, tt.want)
+		}
+	}
+	if p := nm.policyByID(3); p == nil || p.Domain != "off.com" {
+		t.Errorf("policyByID(3) = %+v", p)
+	}
+	if nm.policyByID(9) != nil {
+		t.Error("不存在的策略应返回 nil")
+	}
+}
+
+func TestTrackAlerts(t *testing.T) {
+	policy := testPolicy(t, "esc-track.com", "available", 30, 0)
+	nm := NewNotificationManager()
+	if err := nm.LoadEscalationPolicies(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 匹配策略的状态变化创建告警，下次提醒时间为一个间隔之后
+	start := time.Now()
+	alerts := nm.trackAlerts([]NotificationEvent{
+		statusEvent("esc-track.com", "pending_delete", "available"),
+		statusEvent("esc-none.com", "pending_delete", "available"),
+		{Type: "test", Domain: "esc-track.com", Status: "available"},
+	})
+	alert, ok := alerts["esc-track.com"]
+	if len(alerts) != 1 || !ok {
+		t.Fatalf("应只为匹配策略的域名创建告警: %v", alerts)
+	}
+	if alert.PolicyID != policy.ID || alert.State != storage.AlertActive || alert.NextAt == nil ||
+		alert.NextAt.Before(start.Add(29*time.Minute)) || alert.NextAt.After(start.Add(31*time.Minute)) {
+		t.Errorf("告警内容错误: %+v", alert)
+	}
+
+	// 相同状态再次变化时沿用未确认的告警
+	again := nm.trackAlerts([]NotificationEvent{statusEvent("esc-track.com", "registered", "available")})
+	if again["esc-track.com"] == nil || again["esc-track.com"].ID != alert.ID {
+		t.Errorf("应沿用未确认的告警: %+v", again["esc-track.com"])
+	}
+
+	// 状态变为其他值后自动解除
+	nm.trackAlerts([]NotificationEvent{statusEvent("esc-track.com", "available", "registered")})
+	if got, _ := storage.GetAlert(alert.ID); got == nil || got.State != storage.AlertResolved || got.NextAt != nil {
+		t.Errorf("状态变化后告警应解除: %+v", got)
+	}
+}
+
+func TestRepeatAlert(t *testing.T) {
+	policy := testPolicy(t, "esc-repeat.com", "available", 10, 2)
+	if err := storage.SaveDomainResult(storage.DomainResult{Domain: "esc-repeat.com", Status: "available", LastChecked: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.RemoveDomain("esc-repeat.com") })
+
+	recorder := &recordingNotifier{notifierType: "esc-repeat"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(recorder)
+	nm.SetPublicURL("https://puff.example.com")
+	if err := nm.LoadEscalationPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	alert, err := storage.OpenAlert("esc-repeat.com", "available", "registered", policy.ID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一次重复提醒：安排下一次
+	now := time.Now()
+	nm.repeatAlerts([]storage.Alert{*alert}, now)
+	nm.dispatchOutbox()
+	subjects, messages := recorder.received()
+	if len(subjects) != 1 || subjects[0] != "【第2次提醒】域名 esc-repeat.com 可注册" {
+		t.Fatalf("提醒主题错误: %q", subjects)
+	}
+	if !strings.Contains(messages[0], "已提醒: 2 次") || !strings.Contains(messages[0], "https://puff.example.com/alerts/ack?id=") || strings.Contains(messages[0], "不再重复") {
+		t.Errorf("提醒正文错误:\n%s", messages[0])
+	}
+	got, _ := storage.GetAlert(alert.ID)
+	if got.Repeats != 1 || got.NextAt == nil || got.NextAt.Unix() != now.Add(10*time.Minute).Unix() {
+		t.Errorf("第一次提醒后状态错误: %+v", got)
+	}
+
+	// 达到最大次数后不再安排
+	nm.repeatAlerts([]storage.Alert{*got}, now)
+	nm.dispatchOutbox()
+	if _, messages = recorder.received(); len(messages) != 2 || !strings.Contains(messages[1], "已达到最大提醒次数") {
+		t.Errorf("达到最大次数时应提示: %q", messages)
+	}
+	if got, _ = storage.GetAlert(alert.ID); got.Repeats != 2 || got.State != storage.AlertExhausted {
+		t.Errorf("达到最大次数后状态错误: %+v", got)
+	}
+}
+
+func TestRepeatAlertsRouting(t *testing.T) {
+	domains := []string{"esc-route-1.com", "esc-route-2.com"}
+	var alerts []storage.Alert
+	for _, domain := range domains {
+		policy := testPolicy(t, domain, "pending_delete", 10, 0)
+		if err := storage.SaveDomainResult(storage.DomainResult{Domain: domain, Status: "pending_delete", LastChecked: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		alert, err := storage.OpenAlert(domain, "pending_delete", "registered", policy.ID, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		alerts = append(alerts, *alert)
+	}
+	t.Cleanup(func() {
+		for _, domain := range domains {
+			storage.ResolveAlerts(domain, "")
+			storage.RemoveDomain(domain)
+		}
+		storage.TakeDeferredEvents()
+	})
+
+	push := &recordingNotifier{notifierType: "esc-push"}
+	chat := &recordingNotifier{notifierType: "esc-chat"}
+	mail := &recordingNotifier{notifierType: "esc-mail"}
+	other := &recordingNotifier{notifierType: "esc-other"}
+	nm := NewNotificationManager()
+	for _, n := range []Notifier{push, chat, mail, other} {
+		nm.AddNotifier(n)
+	}
+	nm.SetAggregation(config.NotificationConfig{ChannelModes: "esc-push=immediate,esc-mail=hourly"})
+	if err := nm.LoadEscalationPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	// 按原状态变化匹配的规则同样作用于重复提醒
+	nm.router.setRules([]storage.NotificationRule{{ID: 1, Enabled: true, FromStatus: "registered", ToStatus: "pending_delete",
+		Targets: []string{"esc-push", "esc-chat", "esc-mail"}}})
+
+	nm.repeatAlerts(alerts, time.Now())
+	nm.dispatchOutbox()
+
+	if subjects, _ := push.received(); len(subjects) != 2 || !strings.HasPrefix(subjects[0], "【第2次提醒】") {
+		t.Errorf("立即发送的通知器应逐条收到提醒: %q", subjects)
+	}
+	subjects, messages := chat.received()
+	if len(subjects) != 1 || subjects[0] != "【提醒】2 个告警尚未确认" ||
+		!strings.Contains(messages[0], "esc-route-1.com") || !strings.Contains(messages[0], "esc-route-2.com") {
+		t.Errorf("合并发送的通知器应收到 1 条合并提醒: %q %q", subjects, messages)
+	}
+	if subjects, _ := mail.received(); len(subjects) != 0 {
+		t.Errorf("按小时汇总的通知器不应立即收到: %q", subjects)
+	}
+	if counts, _ := storage.CountDeferredEvents(); counts["esc-mail"] != 2 {
+		t.Errorf("提醒应加入按小时汇总队列: %v", counts)
+	}
+	if subjects, _ := other.received(); len(subjects) != 0 {
+		t.Errorf("未被规则选中的通知器不应收到: %q", subjects)
+	}
+}
+
+func TestRepeatAlertStops(t *testing.T) {
+	policy := testPolicy(t, "esc-stop.com", "available", 10, 0)
+	if err := storage.SaveDomainResult(storage.DomainResult{Domain: "esc-stop.com", Status: "available", LastChecked: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.RemoveDomain("esc-stop.com") })
+
+	recorder := &recordingNotifier{notifierType: "esc-stop"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(recorder)
+
+	// 策略已删除：停止提醒
+	alert, _ := storage.OpenAlert("esc-stop.com", "available", "registered", policy.ID, time.Now())
+	nm.repeatAlerts([]storage.Alert{*alert}, time.Now())
+	if got, _ := storage.GetAlert(alert.ID); got.State != storage.AlertExhausted || got.Repeats != 0 {
+		t.Errorf("策略不存在时应停止提醒: %+v", got)
+	}
+
+	// 域名状态已变化：直接解除
+	if err := nm.LoadEscalationPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	storage.ResolveAlerts("esc-stop.com", "")
+	alert, _ = storage.OpenAlert("esc-stop.com", "available", "registered", policy.ID, time.Now())
+	storage.SaveDomainResult(storage.DomainResult{Domain: "esc-stop.com", Status: "registered", LastChecked: time.Now()})
+	nm.repeatAlerts([]storage.Alert{*alert}, time.Now())
+	if got, _ := storage.GetAlert(alert.ID); got.State != storage.AlertResolved {
+		t.Errorf("状态变化后应解除告警: %+v", got)
+	}
+
+	nm.dispatchOutbox()
+	if subjects, _ := recorder.received(); len(subjects) != 0 {
+		t.Errorf("不应发送提醒: %q", subjects)
+	}
+}
+
+func TestDueAlertsAckAndSnooze(t *testing.T) {
+	now := time.Now()
+	due, _ := storage.OpenAlert("esc-due.com", "available", "registered", 0, now.Add(-time.Minute))
+	later, _ := storage.OpenAlert("esc-later.com", "available", "registered", 0, now.Add(time.Hour))
+	snoozed, _ := storage.OpenAlert("esc-snoozed.com", "available", "registered", 0, now.Add(-time.Minute))
+	acked, _ := storage.OpenAlert("esc-acked.com", "available", "registered", 0, now.Add(-time.Minute))
+	t.Cleanup(func() {
+		for _, domain := range []string{"esc-due.com", "esc-later.com", "esc-snoozed.com", "esc-acked.com"} {
+			storage.ResolveAlerts(domain, "")
+		}
+	})
+
+	if err := storage.SnoozeAlert(snoozed.ID, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AckAlert(acked.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AckAlert(acked.ID, "admin"); !errors.Is(err, storage.ErrAlertClosed) {
+		t.Errorf("重复确认应返回 ErrAlertClosed: %v", err)
+	}
+	if got, _ := storage.GetAlert(snoozed.ID); got.State != storage.AlertSnoozed {
+		t.Errorf("暂停后状态错误: %+v", got)
+	}
+	if got, _ := storage.GetAlert(acked.ID); got.State != storage.AlertAcked || got.AckedBy != "admin" {
+		t.Errorf("确认后状态错误: %+v", got)
+	}
+
+	// 只有到期、未暂停且未确认的告警需要提醒
+	ids := func(at time.Time) map[int64]bool {
+		alerts, err := storage.DueAlerts(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make(map[int64]bool)
+		for _, a := range alerts {
+			found[a.ID] = true
+		}
+		return found
+	}
+	found := ids(now)
+	if !found[due.ID] || found[later.ID] || found[snoozed.ID] || found[acked.ID] {
+		t.Errorf("到期告警错误: %v", found)
+	}
+	// 暂停结束后恢复提醒
+	if found = ids(now.Add(2 * time.Hour)); !found[snoozed.ID] || !found[later.ID] || found[acked.ID] {
+		t.Errorf("暂停结束后应恢复提醒: %v", found)
+	}
+}
@@ -0,0 +1,354 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	execDefaultTimeout = 30 * time.Second
+	execMaxTimeout     = 600             // 超时时间上限（秒）
+	execOutputLimit    = 64 * 1024       // 每路输出最多保留的字节数
+	execLogLimit       = 2000            // 日志与错误信息中输出的最大长度
+	execWaitDelay      = 2 * time.Second // 超时终止后等待输出管道关闭的时间
+	execEnvJSONLimit   = 32 * 1024       // PUFF_EVENT_JSON 的最大长度，超出时只能从标准输入读取
+)
+
+// ExecNotifier 外部命令通知器：事件以 JSON 写入标准输入，并通过 PUFF_* 环境变量传递
+type ExecNotifier struct {
+	config  config.ExecConfig
+	enabled bool
+}
+
+// ExecPayload 写入命令标准输入的数据
+type ExecPayload struct {
+	Subject   string      `json:"subject"`
+	Message   string      `json:"message"`
+	Timestamp time.Time   `json:"timestamp"`
+	Event     *ExecEvent  `json:"event,omitempty"` // 第一个事件（单条通知时即为该事件）
+	Events    []ExecEvent `json:"events"`
+}
+
+// ExecEvent 通知事件及完整的域名详情
+type ExecEvent struct {
+	NotificationEvent
+	Info *core.DomainInfo `json:"info,omitempty"`
+}
+
+// ExecResult 命令执行结果
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+}
+
+// NewExecNotifier 创建外部命令通知器
+func NewExecNotifier(cfg config.ExecConfig) *ExecNotifier {
+	return &ExecNotifier{
+		config:  cfg,
+		enabled: cfg.Enabled,
+	}
+}
+
+// SendMessage 发送纯文本通知（不含结构化事件）
+func (e *ExecNotifier) SendMessage(subject, message string) error {
+	return e.SendEvents(subject, message, nil)
+}
+
+// SendEvents 执行命令；配置为逐个执行时每个事件单独运行一次
+func (e *ExecNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if !e.enabled {
+		return fmt.Errorf("外部命令通知未启用")
+	}
+	if err := e.validateConfig(); err != nil {
+		return err
+	}
+
+	if !e.config.PerEvent || len(events) <= 1 {
+		_, err := e.run(subject, message, events)
+		return err
+	}
+
+	var errs []error
+	for _, event := range events {
+		if _, err := e.run(subject, event.Message, []NotificationEvent{event}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", event.Domain, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PerEvent 是否逐事件执行（发件箱为每个事件写入单独的记录）
+func (e *ExecNotifier) PerEvent() bool {
+	return e.config.PerEvent
+}
+
+// IsEnabled 检查是否启用
+func (e *ExecNotifier) IsEnabled() bool {
+	return e.enabled
+}
+
+// GetType 获取通知器类型
+func (e *ExecNotifier) GetType() string {
+	return "exec"
+}
+
+// Test 以测试事件执行一次命令
+func (e *ExecNotifier) Test() error {
+	_, err := e.TestWithOutput(i18n.Default)
+	return err
+}
+
+// TestWithOutput 以测试事件执行一次命令，返回命令输出
+func (e *ExecNotifier) TestWithOutput(locale string) (string, error) {
+	if !e.enabled {
+		return "", fmt.Errorf("外部命令通知未启用")
+	}
+	if err := e.validateConfig(); err != nil {
+		return "", err
+	}
+
+	event := NotificationEvent{
+		Type:      "test",
+		Domain:    "example.com",
+		Status:    string(core.StatusRegistered),
+		Message:   i18n.T(locale, "notify.test.exec"),
+		Timestamp: time.Now(),
+	}
+	result, err := e.run(i18n.T(locale, "notify.test.subject"), event.Message, []NotificationEvent{event})
+	if result == nil {
+		return "", err
+	}
+	return result.output(), err
+}
+
+// validateConfig 验证配置
+func (e *ExecNotifier) validateConfig() error {
+	if strings.TrimSpace(e.config.Command) == "" {
+		return fmt.Errorf("未配置要执行的命令")
+	}
+	return nil
+}
+
+// timeout 命令超时时间
+func (e *ExecNotifier) timeout() time.Duration {
+	if e.config.Timeout > 0 {
+		return time.Duration(e.config.Timeout) * time.Second
+	}
+	return execDefaultTimeout
+}
+
+// run 执行一次命令，非零退出码或超时视为发送失败
+func (e *ExecNotifier) run(subject, message string, events []NotificationEvent) (*ExecResult, error) {
+	payload := buildExecPayload(subject, message, events)
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化事件失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.config.Command, e.config.Args...)
+	cmd.Dir = e.config.WorkDir
+	cmd.Env = append(os.Environ(), execEnv(payload, input)...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.WaitDelay = execWaitDelay
+	stdout := &limitedBuffer{limit: execOutputLimit}
+	stderr := &limitedBuffer{limit: execOutputLimit}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	result := &ExecResult{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+	e.logResult(result)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("命令执行超时（%v）%s", e.timeout(), result.errorDetail())
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return result, fmt.Errorf("命令退出码 %d%s", result.ExitCode, result.errorDetail())
+		}
+		return result, fmt.Errorf("执行命令失败: %w", err)
+	}
+	return result, nil
+}
+
+// logResult 将命令输出记录到日志
+func (e *ExecNotifier) logResult(result *ExecResult) {
+	logger.Info("外部命令 %s 执行完成，退出码 %d，耗时 %v", e.config.Command, result.ExitCode, result.Duration.Round(time.Millisecond))
+	if out := strings.TrimSpace(result.Stdout); out != "" {
+		logger.Info("外部命令输出: %s", truncateOutput(out))
+	}
+	if out := strings.TrimSpace(result.Stderr); out != "" {
+		logger.Warn("外部命令错误输出: %s", truncateOutput(out))
+	}
+}
+
+// output 合并标准输出与错误输出，供测试结果展示
+func (r *ExecResult) output() string {
+	var parts []string
+	if out := strings.TrimSpace(r.Stdout); out != "" {
+		parts = append(parts, truncateOutput(out))
+	}
+	if out := strings.TrimSpace(r.Stderr); out != "" {
+		parts = append(parts, "[stderr] "+truncateOutput(out))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// errorDetail 错误信息中附带的输出（优先错误输出）
+func (r *ExecResult) errorDetail() string {
+	out := strings.TrimSpace(r.Stderr)
+	if out == "" {
+		out = strings.TrimSpace(r.Stdout)
+	}
+	if out == "" {
+		return ""
+	}
+	return ": " + truncateOutput(out)
+}
+
+// buildExecPayload 组装标准输入数据，事件缺少域名详情时从数据库补全
+func buildExecPayload(subject, message string, events []NotificationEvent) ExecPayload {
+	payload := ExecPayload{
+		Subject:   subject,
+		Message:   message,
+		Timestamp: time.Now(),
+		Events:    make([]ExecEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		payload.Events = append(payload.Events, ExecEvent{NotificationEvent: event, Info: eventDomainInfo(event)})
+	}
+	if len(payload.Events) > 0 {
+		payload.Event = &payload.Events[0]
+	}
+	return payload
+}
+
+// eventDomainInfo 事件的域名详情，缺少时从数据库补全（经发件箱持久化的事件不含域名详情）
+func eventDomainInfo(event NotificationEvent) *core.DomainInfo {
+	if event.Info != nil || event.Domain == "" || event.Type == "test" {
+		return event.Info
+	}
+	res, err := storage.GetDomainResult(event.Domain)
+	if err != nil {
+		logger.Warn("读取域名 %s 详情失败: %v", event.Domain, err)
+		return nil
+	}
+	if res == nil {
+		return nil
+	}
+	return core.DomainInfoFromResult(res)
+}
+
+// execEnv 通过环境变量传递的事件信息；多个事件时单值变量取第一个事件
+func execEnv(payload ExecPayload, input []byte) []string {
+	domains := make([]string, 0, len(payload.Events))
+	for _, event := range payload.Events {
+		domains = append(domains, event.Domain)
+	}
+	env := []string{
+		"PUFF_SUBJECT=" + payload.Subject,
+		"PUFF_MESSAGE=" + payload.Message,
+		"PUFF_TIMESTAMP=" + payload.Timestamp.Format(time.RFC3339),
+		"PUFF_EVENT_COUNT=" + strconv.Itoa(len(payload.Events)),
+		"PUFF_DOMAINS=" + strings.Join(domains, ","),
+	}
+	if len(input) <= execEnvJSONLimit {
+		env = append(env, "PUFF_EVENT_JSON="+string(input))
+	}
+	if payload.Event == nil {
+		return env
+	}
+
+	event := payload.Event
+	env = append(env,
+		"PUFF_EVENT_TYPE="+event.Type,
+		"PUFF_DOMAIN="+event.Domain,
+		"PUFF_STATUS="+event.Status,
+		"PUFF_OLD_STATUS="+event.OldStatus,
+		"PUFF_FIELD="+event.Field,
+		"PUFF_OLD_VALUE="+event.OldValue,
+		"PUFF_NEW_VALUE="+event.NewValue,
+		"PUFF_ERROR_CLASS="+event.ErrorClass,
+	)
+	if info := event.Info; info != nil {
+		env = append(env,
+			"PUFF_REGISTRAR="+info.Registrar,
+			"PUFF_CREATED_DATE="+formatOptionalTime(info.CreatedDate),
+			"PUFF_EXPIRY_DATE="+formatOptionalTime(info.ExpiryDate),
+			"PUFF_UPDATED_DATE="+formatOptionalTime(info.UpdatedDate),
+			"PUFF_NAME_SERVERS="+strings.Join(info.NameServers, ","),
+			"PUFF_STATUS_CODES="+strings.Join(info.StatusCodes, ","),
+			"PUFF_QUERY_METHOD="+info.QueryMethod,
+			"PUFF_ERROR_MESSAGE="+info.ErrorMessage,
+		)
+	}
+	return env
+}
+
+// formatOptionalTime 格式化可能为空的时间
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// truncateOutput 截断过长的命令输出
+func truncateOutput(out string) string {
+	if len(out) <= execLogLimit {
+		return out
+	}
+	cut := execLogLimit
+	for cut > 0 && !utf8.RuneStart(out[cut]) {
+		cut--
+	}
+	return out[:cut] + "...（已截断）"
+}
+
+// limitedBuffer 只保留前 limit 字节的输出，超出部分丢弃
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
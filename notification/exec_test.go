@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+// shellNotifier 以 sh -c 执行脚本的外部命令通知器
+func shellNotifier(script string, cfg config.ExecConfig) *ExecNotifier {
+	cfg.Command = "/bin/sh"
+	cfg.Args = append([]string{"-c", script, "sh"}, cfg.Args...)
+	cfg.Enabled = true
+	return NewExecNotifier(cfg)
+}
+
+func TestExecPassesEventAsJSONAndEnv(t *testing.T) {
+	expiry := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	event := NotificationEvent{
+		Type:      "status_change",
+		Domain:    "exec.com",
+		OldStatus: "pending_delete",
+		Status:    "available",
+		Timestamp: time.Now(),
+		Info:      &core.DomainInfo{Name: "exec.com", Registrar: "Example Registrar", ExpiryDate: &expiry, NameServers: []string{"ns1.example.com", "ns2.example.com"}},
+	}
+
+	e := shellNotifier(`cat; echo; echo "$PUFF_DOMAIN|$PUFF_STATUS|$PUFF_OLD_STATUS|$PUFF_REGISTRAR|$PUFF_EXPIRY_DATE|$PUFF_NAME_SERVERS|$PUFF_EVENT_COUNT"; printf %s "$PUFF_EVENT_JSON" | head -c 1`, config.ExecConfig{})
+	result, err := e.run("主题", "正文", []NotificationEvent{event})
+	if err != nil {
+		t.Fatalf("执行失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("输出行数错误:\n%s", result.Stdout)
+	}
+
+	var payload ExecPayload
+	if err := json.Unmarshal([]byte(lines[0]), &payload); err != nil {
+		t.Fatalf("标准输入不是 JSON: %v", err)
+	}
+	if payload.Subject != "主题" || payload.Event == nil || payload.Event.Domain != "exec.com" || payload.Event.Info == nil || payload.Event.Info.Registrar != "Example Registrar" {
+		t.Errorf("标准输入内容错误: %+v", payload)
+	}
+	if want := "exec.com|available|pending_delete|Example Registrar|2026-05-01T00:00:00Z|ns1.example.com,ns2.example.com|1"; lines[1] != want {
+		t.Errorf("环境变量 = %q, 期望 %q", lines[1], want)
+	}
+	if lines[2] != "{" {
+		t.Errorf("PUFF_EVENT_JSON 应为 JSON: %q", lines[2])
+	}
+}
+
+func TestExecLoadsDomainInfo(t *testing.T) {
+	created := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := storage.SaveDomainResult(storage.DomainResult{Domain: "exec-db.com", Status: "registered", Registrar: "DB Registrar", CreatedAt: &created, LastChecked: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 经发件箱持久化的事件不含域名详情，从数据库补全
+	e := shellNotifier(`echo "$PUFF_REGISTRAR|$PUFF_CREATED_DATE"`, config.ExecConfig{})
+	result, err := e.run("主题", "正文", []NotificationEvent{{Type: "status_change", Domain: "exec-db.com", Status: "registered"}})
+	if err != nil {
+		t.Fatalf("执行失败: %v", err)
+	}
+	if got := strings.TrimSpace(result.Stdout); got != "DB Registrar|2020-01-02T00:00:00Z" {
+		t.Errorf("应从数据库补全域名详情，实际 %q", got)
+	}
+}
+
+func TestExecFailures(t *testing.T) {
+	e := shellNotifier(`echo out; echo "registrar rejected" >&2; exit 3`, config.ExecConfig{})
+	err := e.SendMessage("主题", "正文")
+	if err == nil || !strings.Contains(err.Error(), "命令退出码 3: registrar rejected") {
+		t.Errorf("非零退出码应视为失败并附带错误输出: %v", err)
+	}
+
+	e = shellNotifier(`sleep 10`, config.ExecConfig{Timeout: 1})
+	start := time.Now()
+	if err := e.SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), "命令执行超时") {
+		t.Errorf("超时应返回错误: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("超时后应终止命令，实际用时 %v", elapsed)
+	}
+
+	if err := NewExecNotifier(config.ExecConfig{Command: "/bin/true"}).SendMessage("主题", "正文"); err == nil {
+		t.Error("未启用时应报错")
+	}
+	if err := NewExecNotifier(config.ExecConfig{Enabled: true}).SendMessage("主题", "正文"); err == nil {
+		t.Error("未配置命令时应报错")
+	}
+	if err := NewExecNotifier(config.ExecConfig{Command: filepath.Join(t.TempDir(), "missing"), Enabled: true}).SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), "执行命令失败") {
+		t.Errorf("命令不存在时应报错: %v", err)
+	}
+}
+
+func TestExecPerEvent(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "events.log")
+	e := shellNotifier(`echo "$PUFF_DOMAIN $PUFF_EVENT_COUNT" >> "$1"; [ "$PUFF_DOMAIN" != "bad.com" ]`, config.ExecConfig{Args: []string{log}, PerEvent: true})
+	events := []NotificationEvent{
+		{Type: "test", Domain: "a.com"},
+		{Type: "test", Domain: "bad.com"},
+		{Type: "test", Domain: "c.com"},
+	}
+
+	// 每个事件单独执行，失败的事件不影响其他事件
+	err := e.SendEvents("主题", "正文", events)
+	if err == nil || !strings.Contains(err.Error(), "bad.com: 命令退出码 1") || strings.Contains(err.Error(), "a.com") {
+		t.Errorf("应只报告失败事件: %v", err)
+	}
+	data, _ := os.ReadFile(log)
+	if got := string(data); got != "a.com 1\nbad.com 1\nc.com 1\n" {
+		t.Errorf("执行记录错误:\n%s", got)
+	}
+}
+
+func TestExecPerEventOutboxEntries(t *testing.T) {
+	dir := t.TempDir()
+	log, ok := filepath.Join(dir, "events.log"), filepath.Join(dir, "ok")
+	e := shellNotifier(`echo "$PUFF_DOMAIN $PUFF_EVENT_COUNT" >> "$1"; [ "$PUFF_DOMAIN" != "pe-bad.com" ] || [ -f "$2" ]`,
+		config.ExecConfig{Args: []string{log, ok}, PerEvent: true})
+	nm := NewNotificationManager()
+	nm.AddNotifier(e)
+
+	// 每个事件单独写入一条发件箱记录
+	nm.enqueue([]Notifier{e}, "主题", "正文", []NotificationEvent{
+		{Type: "test", Domain: "pe-a.com", Message: "a"},
+		{Type: "test", Domain: "pe-bad.com", Message: "bad"},
+		{Type: "test", Domain: "pe-c.com", Message: "c"},
+	})
+	all, _, err := storage.ListOutbox("", 1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []storage.OutboxEntry
+	for _, entry := range all {
+		if strings.Contains(entry.Events, `"pe-`) {
+			entries = append([]storage.OutboxEntry{entry}, entries...)
+		}
+	}
+	if len(entries) != 3 || entries[1].Message != "bad" || !strings.Contains(entries[1].Events, "pe-bad.com") || strings.Contains(entries[1].Events, "pe-a.com") {
+		t.Fatalf("应为每个事件写入一条记录: %+v", entries)
+	}
+
+	// 只有失败的事件保留待重试，重试时不再执行已成功的事件
+	for _, entry := range entries {
+		nm.deliverEntry(entry)
+	}
+	if entry := outboxEntry(t, entries[1].ID); entry.Status != storage.OutboxPending || outboxEntry(t, entries[0].ID).Status != storage.OutboxSent {
+		t.Fatalf("失败的事件应等待重试: %+v", entry)
+	}
+	os.WriteFile(ok, nil, 0o644)
+	nm.deliverEntry(outboxEntry(t, entries[1].ID))
+	if entry := outboxEntry(t, entries[1].ID); entry.Status != storage.OutboxSent {
+		t.Errorf("重试后应投递成功: %+v", entry)
+	}
+	data, _ := os.ReadFile(log)
+	if got := string(data); got != "pe-a.com 1\npe-bad.com 1\npe-c.com 1\npe-bad.com 1\n" {
+		t.Errorf("执行记录错误:\n%s", got)
+	}
+}
+
+func TestExecTestWithOutput(t *testing.T) {
+	e := shellNotifier(`echo "$PUFF_EVENT_TYPE $PUFF_DOMAIN"; echo warn >&2`, config.ExecConfig{})
+	out, err := e.TestWithOutput(i18n.En)
+	if err != nil {
+		t.Fatalf("测试失败: %v", err)
+	}
+	if out != "test example.com\n[stderr] warn" {
+		t.Errorf("测试输出 = %q", out)
+	}
+}
+
+func TestExecOutputLimits(t *testing.T) {
+	b := &limitedBuffer{limit: 4}
+	if n, _ := b.Write([]byte("abcdef")); n != 6 || b.String() != "abcd" {
+		t.Errorf("limitedBuffer 应丢弃超出部分且不报错: %d %q", n, b.String())
+	}
+
+	long := strings.Repeat("域", execLogLimit)
+	got := truncateOutput(long)
+	if !strings.HasSuffix(got, "...（已截断）") || !utf8.ValidString(got) || len(got) > execLogLimit+len("...（已截断）") {
+		t.Errorf("截断后的输出错误（%d 字节）", len(got))
+	}
+	if truncateOutput("short") != "short" {
+		t.Error("短输出不应截断")
+	}
+}
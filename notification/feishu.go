@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"Puff/config"
+)
+
+// FeishuNotifier 飞书/Lark 群机器人通知器
+type FeishuNotifier struct {
+	robotBase
+}
+
+// feishuResponse 飞书机器人响应（旧版接口返回 StatusCode）
+type feishuResponse struct {
+	Code          int    `json:"code"`
+	Msg           string `json:"msg"`
+	StatusCode    int    `json:"StatusCode"`
+	StatusMessage string `json:"StatusMessage"`
+}
+
+// NewFeishuNotifier 创建飞书通知器
+func NewFeishuNotifier(cfg config.RobotConfig) *FeishuNotifier {
+	return &FeishuNotifier{robotBase: newRobotBase(cfg)}
+}
+
+// SendMessage 发送飞书消息卡片
+func (f *FeishuNotifier) SendMessage(subject, message string) error {
+	if err := f.validateWebhook("飞书"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]bool{"wide_screen_mode": true},
+			"header": map[string]interface{}{
+				"title":    map[string]string{"tag": "plain_text", "content": subject},
+				"template": "blue",
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag":  "div",
+					"text": map[string]string{"tag": "lark_md", "content": formatRobotMarkdown(subject, message, false)},
+				},
+			},
+		},
+	}
+
+	if f.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = f.sign(timestamp)
+	}
+
+	var result feishuResponse
+	if err := f.postJSON(f.config.Webhook, payload, &result); err != nil {
+		return fmt.Errorf("发送飞书消息失败: %v", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("飞书API错误 (%d): %s", result.Code, result.Msg)
+	}
+	if result.StatusCode != 0 {
+		return fmt.Errorf("飞书API错误 (%d): %s", result.StatusCode, result.StatusMessage)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (f *FeishuNotifier) GetType() string {
+	return "feishu"
+}
+
+// Test 发送测试消息
+func (f *FeishuNotifier) Test() error {
+	return f.SendMessage("飞书通知测试", robotTestMessage("飞书"))
+}
+
+// sign 签名校验：以 timestamp+"\n"+secret 为密钥对空串做 HmacSHA256，再 Base64
+func (f *FeishuNotifier) sign(timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+f.config.Secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
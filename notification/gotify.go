@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"Puff/config"
+)
+
+// gotifyPriorities Gotify 默认优先级映射（0-10）
+var gotifyPriorities = map[Priority]string{
+	PriorityCritical: "10",
+	PriorityHigh:     "8",
+	PriorityNormal:   "5",
+	PriorityLow:      "2",
+}
+
+// GotifyNotifier Gotify 自建推送通知器（Token 为应用Token）
+type GotifyNotifier struct {
+	pushBase
+}
+
+// NewGotifyNotifier 创建 Gotify 通知器
+func NewGotifyNotifier(cfg config.PushConfig) *GotifyNotifier {
+	return &GotifyNotifier{pushBase: newPushBase(cfg, gotifyPriorities)}
+}
+
+// SendMessage 发送 Gotify 推送
+func (g *GotifyNotifier) SendMessage(subject, message string) error {
+	return g.SendEvents(subject, message, nil)
+}
+
+// SendEvents 按事件优先级发送 Gotify 推送
+func (g *GotifyNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if err := g.validate("Gotify"); err != nil {
+		return err
+	}
+	server := g.server("")
+	if server == "" {
+		return fmt.Errorf("Gotify服务地址不能为空")
+	}
+
+	payload := map[string]interface{}{
+		"title":   subject,
+		"message": formatPushBody(message),
+	}
+	if level := g.level(eventsPriority(events)); level != "" {
+		priority, err := strconv.Atoi(level)
+		if err != nil || priority < 0 || priority > 10 {
+			return fmt.Errorf("Gotify优先级必须为0-10: %s", level)
+		}
+		payload["priority"] = priority
+	}
+
+	endpoint := server + "/message?token=" + url.QueryEscape(g.config.Token)
+	if err := postJSON(g.httpClient, endpoint, nil, payload, nil); err != nil {
+		return fmt.Errorf("发送Gotify推送失败: %v", err)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (g *GotifyNotifier) GetType() string {
+	return "gotify"
+}
+
+// Test 发送测试消息
+func (g *GotifyNotifier) Test() error {
+	return g.SendEvents("Gotify推送测试", pushTestMessage("Gotify"), []NotificationEvent{pushTestEvent()})
+}
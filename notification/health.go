@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+)
+
+// healthAlerts 查询健康告警的管理员通道
+type healthAlerts struct {
+	mu      sync.RWMutex
+	targets string
+}
+
+// SetHealthTargets 设置接收查询健康告警的通知器（逗号分隔，为空表示全部）
+func (nm *NotificationManager) SetHealthTargets(targets string) {
+	nm.health.mu.Lock()
+	nm.health.targets = targets
+	nm.health.mu.Unlock()
+}
+
+// healthText 按通知语言排版健康告警的主题与正文
+func healthText(locale string, event core.HealthEvent) (string, string) {
+	var subject, message string
+	switch event.Type {
+	case core.HealthLookupFailure:
+		subject = i18n.T(locale, "notify.health.subject."+event.Type, event.Domain)
+		message = i18n.T(locale, "notify.health."+event.Type, event.Domain, event.Failures, event.Error)
+	case core.HealthLookupRecovered:
+		subject = i18n.T(locale, "notify.health.subject."+event.Type, event.Domain)
+		message = i18n.T(locale, "notify.health."+event.Type, event.Domain, event.Failures)
+	case core.HealthServerDown, core.HealthServerRecovered:
+		subject = i18n.T(locale, "notify.health.subject."+event.Type, event.Server)
+		message = i18n.T(locale, "notify.health."+event.Type, event.Server, event.Window, event.Failed, event.Total)
+	default:
+		subject = i18n.T(locale, "notify.health.subject.default", event.Message)
+		message = event.Message
+	}
+
+	if event.Domain != "" {
+		message += "\n" + label(locale, "domain") + ": " + event.Domain
+	}
+	if event.Server != "" {
+		message += "\n" + i18n.T(locale, "notify.health.server") + ": " + event.Server
+	}
+	message += "\n" + label(locale, "time") + ": " + event.Timestamp.Local().Format("2006-01-02 15:04:05")
+	return subject, message
+}
+
+// SendHealthAlert 发送查询健康告警：与其他事件一样经过路由规则（未命中规则时发往管理员通道），
+// 并按通知器的发送方式立即发送或按小时汇总
+func (nm *NotificationManager) SendHealthAlert(event core.HealthEvent) error {
+	nm.health.mu.RLock()
+	targets := nm.health.targets
+	nm.health.mu.RUnlock()
+
+	health := event
+	notificationEvent := NotificationEvent{
+		Type:       event.Type,
+		Domain:     event.Domain,
+		Message:    event.Message,
+		Timestamp:  event.Timestamp,
+		ErrorClass: event.ErrorClass,
+		Health:     &health,
+	}
+	receivers, routed := nm.routeEvent(notificationEvent, targets)
+	if len(receivers) == 0 {
+		if routed {
+			return nil
+		}
+		return fmt.Errorf("没有可接收健康告警的通知渠道")
+	}
+
+	// 健康告警不参与合并窗口，仅按小时汇总的通知器延后发送
+	split := nm.splitByMode(receivers)
+	nm.deferEvent(notificationEvent, split[ModeHourly])
+	immediate := append(split[ModeImmediate], split[ModeBatched]...)
+	nm.deliverRouted(immediate, routeAll(immediate, notificationEvent), nil)
+
+	logger.Info("健康告警已加入发件箱: %s，接收渠道 %d 个", event.Type, len(receivers))
+	return nil
+}
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+func TestHealthTextLocalized(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale  string
+		event   core.HealthEvent
+		subject string
+		message []string
+	}{
+		{
+			i18n.ZhCN,
+			core.HealthEvent{Type: core.HealthLookupFailure, Domain: "a.com", Server: "whois.verisign-grs.com", Failures: 3, Error: "timeout", Timestamp: now},
+			"【监控异常】域名 a.com 持续查询失败",
+			[]string{"域名 a.com 已连续 3 次查询失败，当前未被有效监控: timeout", "查询服务器: whois.verisign-grs.com"},
+		},
+		{
+			i18n.En,
+			core.HealthEvent{Type: core.HealthLookupRecovered, Domain: "a.com", Failures: 4, Timestamp: now},
+			"[Recovered] Lookups for a.com recovered",
+			[]string{"after 4 consecutive failures", "Domain: a.com"},
+		},
+		{
+			i18n.En,
+			core.HealthEvent{Type: core.HealthServerDown, Server: "rdap.example", Window: 30, Failed: 8, Total: 10, Timestamp: now},
+			"[Monitoring] Lookup server rdap.example is unavailable",
+			[]string{"8/10 domain lookups failed in the last 30 minutes", "Lookup server: rdap.example"},
+		},
+	}
+	for _, tt := range tests {
+		subject, message := healthText(tt.locale, tt.event)
+		if subject != tt.subject {
+			t.Errorf("%s 主题 = %q，期望 %q", tt.event.Type, subject, tt.subject)
+		}
+		for _, want := range tt.message {
+			if !strings.Contains(message, want) {
+				t.Errorf("%s 正文缺少 %q:\n%s", tt.event.Type, want, message)
+			}
+		}
+		if tt.locale == i18n.En && strings.ContainsAny(message, "域名查询时间") {
+			t.Errorf("%s 英文正文含有中文:\n%s", tt.event.Type, message)
+		}
+	}
+}
+
+func TestSendHealthAlertRouting(t *testing.T) {
+	admin := &recordingNotifier{notifierType: "admin"}
+	ops := &recordingNotifier{notifierType: "ops"}
+	hourly := &recordingNotifier{notifierType: "hourly"}
+	nm := NewNotificationManager()
+	for _, n := range []Notifier{admin, ops, hourly} {
+		nm.AddNotifier(n)
+	}
+	nm.SetHealthTargets("admin,hourly")
+	nm.SetLocales(config.NotificationConfig{Locale: i18n.ZhCN, Locales: "ops=en"})
+	nm.SetAggregation(config.NotificationConfig{ChannelModes: "hourly=hourly"})
+	nm.router.setRules([]storage.NotificationRule{{
+		ID: 1, Name: "服务器故障", Enabled: true, EventType: core.HealthServerDown, Targets: []string{"ops"},
+	}})
+
+	now := time.Now()
+	lookup := core.HealthEvent{Type: core.HealthLookupFailure, Domain: "a.com", Failures: 5, Error: "timeout", Timestamp: now}
+	if err := nm.SendHealthAlert(lookup); err != nil {
+		t.Fatalf("发送健康告警失败: %v", err)
+	}
+	server := core.HealthEvent{Type: core.HealthServerDown, Server: "rdap.example", Window: 60, Failed: 3, Total: 3, Timestamp: now}
+	if err := nm.SendHealthAlert(server); err != nil {
+		t.Fatalf("发送健康告警失败: %v", err)
+	}
+	nm.dispatchOutbox()
+
+	// 未命中规则的告警发往管理员通道
+	subjects, _ := admin.received()
+	if len(subjects) != 1 || subjects[0] != "【监控异常】域名 a.com 持续查询失败" {
+		t.Errorf("管理员通道收到 %v", subjects)
+	}
+	// 命中规则的告警只发往规则目标，并使用其通知语言
+	subjects, messages := ops.received()
+	if len(subjects) != 1 || subjects[0] != "[Monitoring] Lookup server rdap.example is unavailable" {
+		t.Errorf("规则目标收到 %v", subjects)
+	} else if !strings.Contains(messages[0], "3/3 domain lookups failed in the last 60 minutes") {
+		t.Errorf("规则目标正文错误:\n%s", messages[0])
+	}
+	// 按小时汇总的通知器延后发送
+	if subjects, _ := hourly.received(); len(subjects) != 0 {
+		t.Errorf("按小时汇总的通知器不应立即收到告警: %v", subjects)
+	}
+
+	nm.flushDeferred(0)
+	nm.dispatchOutbox()
+	subjects, _ = hourly.received()
+	if len(subjects) != 1 || subjects[0] != "【监控异常】域名 a.com 持续查询失败" {
+		t.Errorf("汇总后收到 %v", subjects)
+	}
+}
+
+func TestSendHealthAlertBlockedByRule(t *testing.T) {
+	admin := &recordingNotifier{notifierType: "admin"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(admin)
+	nm.router.setRules([]storage.NotificationRule{{ID: 1, Name: "静默", Enabled: true, EventType: core.HealthLookupRecovered}})
+
+	event := core.HealthEvent{Type: core.HealthLookupRecovered, Domain: "a.com", Failures: 5, Timestamp: time.Now()}
+	if err := nm.SendHealthAlert(event); err != nil {
+		t.Fatalf("被规则拦截的告警不应返回错误: %v", err)
+	}
+	nm.dispatchOutbox()
+	if subjects, _ := admin.received(); len(subjects) != 0 {
+		t.Errorf("被规则拦截的告警不应发送: %v", subjects)
+	}
+
+	nm = NewNotificationManager()
+	if err := nm.SendHealthAlert(event); err == nil {
+		t.Error("没有接收渠道时应返回错误")
+	}
+}
@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"Puff/config"
+)
+
+// InstanceTypes 支持多实例的通知器类型及显示名称
+var InstanceTypes = map[string]string{
+	"email":    "邮件",
+	"telegram": "Telegram",
+	"exec":     "外部命令",
+	"mqtt":     "MQTT",
+	"url":      "通知URL",
+}
+
+// InstanceNotifier 通知渠道实例（同一类型可有多个，按 ID 区分）
+type InstanceNotifier struct {
+	Notifier
+	ID   int64
+	Name string
+}
+
+// SendEvents 转发给实际通知器，保留结构化事件
+func (i *InstanceNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	return deliver(i.Notifier, subject, message, events)
+}
+
+// SendRendered 转发给实际通知器，保留模板渲染结果
+func (i *InstanceNotifier) SendRendered(subject, body string, events []NotificationEvent) error {
+	return deliverRendered(i.Notifier, subject, body, events)
+}
+
+// PerEvent 转发给实际通知器
+func (i *InstanceNotifier) PerEvent() bool {
+	return perEvent(i.Notifier)
+}
+
+// SendEventsFrom 转发给实际通知器，保留分段发送进度
+func (i *InstanceNotifier) SendEventsFrom(subject, message string, events []NotificationEvent, resume Resume) error {
+	return deliverFrom(i.Notifier, subject, message, events, false, resume)
+}
+
+// SendRenderedFrom 转发给实际通知器，保留模板渲染结果与分段发送进度
+func (i *InstanceNotifier) SendRenderedFrom(subject, body string, events []NotificationEvent, resume Resume) error {
+	return deliverFrom(i.Notifier, subject, body, events, true, resume)
+}
+
+// NewInstanceNotifier 根据类型与 JSON 配置创建通知渠道实例
+func NewInstanceNotifier(id int64, notifierType, name, rawConfig string, enabled bool) (*InstanceNotifier, error) {
+	var notifier Notifier
+
+	switch notifierType {
+	case "email":
+		cfg, err := ParseEmailInstanceConfig(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Enabled = enabled
+		notifier = NewEmailNotifier(cfg)
+	case "telegram":
+		cfg, err := ParseTelegramInstanceConfig(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Enabled = enabled
+		notifier = NewTelegramNotifier(cfg)
+	case "exec":
+		cfg, err := ParseExecInstanceConfig(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Enabled = enabled
+		notifier = NewExecNotifier(cfg)
+	case "mqtt":
+		cfg, err := ParseMQTTInstanceConfig(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Enabled = enabled
+		notifier = NewMQTTNotifier(cfg)
+	case "url":
+		cfg, err := parseURLInstanceConfig(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		return NewURLInstanceNotifier(id, name, cfg.URL, enabled)
+	default:
+		return nil, fmt.Errorf("不支持的通知渠道类型: %s", notifierType)
+	}
+
+	return &InstanceNotifier{Notifier: notifier, ID: id, Name: name}, nil
+}
+
+// ParseEmailInstanceConfig 解析邮件渠道配置，端口缺省为 587
+func ParseEmailInstanceConfig(rawConfig string) (config.SMTPConfig, error) {
+	var cfg config.SMTPConfig
+	if err := json.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("解析邮件配置失败: %v", err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return cfg, nil
+}
+
+// ParseTelegramInstanceConfig 解析 Telegram 渠道配置
+func ParseTelegramInstanceConfig(rawConfig string) (config.TelegramConfig, error) {
+	var cfg config.TelegramConfig
+	if err := json.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("解析Telegram配置失败: %v", err)
+	}
+	return cfg, nil
+}
+
+// ParseExecInstanceConfig 解析外部命令渠道配置
+func ParseExecInstanceConfig(rawConfig string) (config.ExecConfig, error) {
+	var cfg config.ExecConfig
+	if err := json.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("解析外部命令配置失败: %v", err)
+	}
+	cfg.Command = strings.TrimSpace(cfg.Command)
+	return cfg, nil
+}
+
+// ParseMQTTInstanceConfig 解析 MQTT 渠道配置
+func ParseMQTTInstanceConfig(rawConfig string) (config.MQTTConfig, error) {
+	var cfg config.MQTTConfig
+	if err := json.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("解析MQTT配置失败: %v", err)
+	}
+	cfg.Broker = strings.TrimSpace(cfg.Broker)
+	return cfg, nil
+}
+
+// parseURLInstanceConfig 解析通知URL渠道配置
+func parseURLInstanceConfig(rawConfig string) (urlInstanceConfig, error) {
+	var cfg urlInstanceConfig
+	if err := json.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("解析通知URL配置失败: %v", err)
+	}
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	return cfg, nil
+}
+
+// ValidateInstanceConfig 校验通知渠道配置，启用时要求必填项完整
+func ValidateInstanceConfig(notifierType, rawConfig string, enabled bool) error {
+	switch notifierType {
+	case "email":
+		cfg, err := ParseEmailInstanceConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+		if cfg.Port < 1 || cfg.Port > 65535 {
+			return fmt.Errorf("SMTP端口必须在1-65535之间")
+		}
+		if enabled && (cfg.Host == "" || cfg.From == "" || cfg.To == "") {
+			return fmt.Errorf("请填写SMTP服务器、发件人和收件人")
+		}
+		if err := ValidateSMTPConfig(cfg); err != nil {
+			return err
+		}
+	case "telegram":
+		cfg, err := ParseTelegramInstanceConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+		if enabled && (cfg.BotToken == "" || cfg.ChatID == "") {
+			return fmt.Errorf("请填写Bot Token和Chat ID")
+		}
+		switch cfg.ParseMode {
+		case "", "MarkdownV2", "Markdown", "HTML":
+		default:
+			return fmt.Errorf("解析模式必须为 MarkdownV2、Markdown 或 HTML")
+		}
+		if cfg.CSVThreshold < 0 {
+			return fmt.Errorf("CSV附件阈值不能为负数")
+		}
+	case "exec":
+		cfg, err := ParseExecInstanceConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+		if enabled && cfg.Command == "" {
+			return fmt.Errorf("请填写要执行的命令")
+		}
+		if cfg.Timeout < 0 || cfg.Timeout > execMaxTimeout {
+			return fmt.Errorf("超时时间必须在0-%d秒之间", execMaxTimeout)
+		}
+		if cfg.WorkDir != "" {
+			if st, err := os.Stat(cfg.WorkDir); err != nil || !st.IsDir() {
+				return fmt.Errorf("工作目录不存在: %s", cfg.WorkDir)
+			}
+		}
+	case "mqtt":
+		cfg, err := ParseMQTTInstanceConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+		if enabled && cfg.Broker == "" {
+			return fmt.Errorf("请填写MQTT代理地址")
+		}
+		if cfg.Broker != "" {
+			if err := ValidateMQTTConfig(cfg); err != nil {
+				return err
+			}
+		}
+	case "url":
+		cfg, err := parseURLInstanceConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+		if cfg.URL == "" {
+			return fmt.Errorf("请填写通知URL")
+		}
+		if _, err := ParseNotifierURL(cfg.URL); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("不支持的通知渠道类型: %s", notifierType)
+	}
+	return nil
+}
+
+// notifierLabel 日志中显示的通知器名称（实例附带名称）
+func notifierLabel(n Notifier) string {
+	if inst, ok := n.(*InstanceNotifier); ok {
+		return fmt.Sprintf("%s(%s)", inst.GetType(), inst.Name)
+	}
+	return n.GetType()
+}
@@ -0,0 +1,115 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewInstanceNotifier(t *testing.T) {
+	tests := []struct {
+		notifierType, config, wantType string
+	}{
+		{"email", `{"host":"smtp.example.com","from":"a@example.com","to":"b@example.com"}`, "email"},
+		{"telegram", `{"bot_token":"1:a","chat_id":"2"}`, "telegram"},
+		{"exec", `{"command":" /bin/true "}`, "exec"},
+		{"mqtt", `{"broker":"mqtt://broker.local:1883"}`, "mqtt"},
+		{"url", `{"url":" ntfy://puff "}`, "ntfy"},
+	}
+	for _, tt := range tests {
+		inst, err := NewInstanceNotifier(3, tt.notifierType, "实例", tt.config, true)
+		if err != nil {
+			t.Errorf("创建 %s 实例失败: %v", tt.notifierType, err)
+			continue
+		}
+		if inst.GetType() != tt.wantType || inst.ID != 3 || inst.Name != "实例" || !inst.IsEnabled() {
+			t.Errorf("%s 实例错误: type=%s id=%d name=%s enabled=%v", tt.notifierType, inst.GetType(), inst.ID, inst.Name, inst.IsEnabled())
+		}
+		if key := NotifierKey(inst); key != tt.wantType+":3" {
+			t.Errorf("%s 实例标识 = %s", tt.notifierType, key)
+		}
+	}
+
+	// 邮件端口缺省为 587，命令去除首尾空白
+	inst, _ := NewInstanceNotifier(1, "email", "邮箱", `{"host":"smtp.example.com"}`, false)
+	if cfg := inst.Notifier.(*EmailNotifier).config; cfg.Port != 587 || inst.IsEnabled() {
+		t.Errorf("邮件实例配置错误: %+v", cfg)
+	}
+	inst, _ = NewInstanceNotifier(1, "exec", "脚本", `{"command":" /bin/true "}`, true)
+	if cmd := inst.Notifier.(*ExecNotifier).config.Command; cmd != "/bin/true" {
+		t.Errorf("命令应去除空白: %q", cmd)
+	}
+
+	if _, err := NewInstanceNotifier(1, "slack", "x", `{}`, true); err == nil {
+		t.Error("不支持的类型应报错")
+	}
+	if _, err := NewInstanceNotifier(1, "telegram", "x", `{"chat_id":`, true); err == nil || !strings.Contains(err.Error(), "解析Telegram配置失败") {
+		t.Errorf("配置无法解析时应报错: %v", err)
+	}
+}
+
+func TestValidateInstanceConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		notifierType, config string
+		enabled              bool
+		want                 string // 为空表示应通过
+	}{
+		{"email", `{}`, false, ""},
+		{"email", `{}`, true, "请填写SMTP服务器"},
+		{"email", `{"port":70000}`, false, "SMTP端口"},
+		{"telegram", `{}`, false, ""},
+		{"telegram", `{"bot_token":"1:a"}`, true, "请填写Bot Token和Chat ID"},
+		{"telegram", `{"parse_mode":"BBCode"}`, false, "解析模式"},
+		{"telegram", `{"csv_threshold":-1}`, false, "CSV附件阈值"},
+		{"exec", `{"command":"/bin/true","work_dir":"` + dir + `","timeout":60}`, true, ""},
+		{"exec", `{"command":"  "}`, true, "请填写要执行的命令"},
+		{"exec", `{"command":"/bin/true","timeout":601}`, true, "超时时间"},
+		{"exec", `{"command":"/bin/true","work_dir":"` + dir + `/missing"}`, true, "工作目录不存在"},
+		{"mqtt", `{}`, false, ""},
+		{"mqtt", `{}`, true, "请填写MQTT代理地址"},
+		{"url", `{"url":""}`, false, "请填写通知URL"},
+		{"url", `{"url":"slack://x"}`, false, "不支持的通知URL协议"},
+		{"url", `{"url":"bark://key"}`, true, ""},
+		{"pager", `{}`, true, "不支持的通知渠道类型"},
+	}
+	for _, tt := range tests {
+		err := ValidateInstanceConfig(tt.notifierType, tt.config, tt.enabled)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("ValidateInstanceConfig(%s, %s, %v) 应通过: %v", tt.notifierType, tt.config, tt.enabled, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("ValidateInstanceConfig(%s, %s, %v) = %v, 期望包含 %q", tt.notifierType, tt.config, tt.enabled, err, tt.want)
+		}
+	}
+}
+
+func TestManagerInstances(t *testing.T) {
+	nm := NewNotificationManager()
+	team, _ := NewInstanceNotifier(1, "exec", "团队", `{"command":"/bin/true"}`, true)
+	ops, _ := NewInstanceNotifier(2, "exec", "运维", `{"command":"/bin/true"}`, true)
+	nm.SetInstance(team)
+	nm.SetInstance(ops)
+
+	// 同类型的多个实例各自独立
+	if len(nm.GetNotifiers()) != 2 || nm.GetInstance(1) != team || nm.GetInstance(2) != ops {
+		t.Fatalf("应有两个独立实例: %v", nm.GetNotifiers())
+	}
+	if nm.notifierByKey("exec:2") != ops {
+		t.Error("应按实例标识查找通知器")
+	}
+
+	// 相同 ID 替换原实例
+	renamed, _ := NewInstanceNotifier(1, "exec", "团队群", `{"command":"/bin/true"}`, false)
+	nm.SetInstance(renamed)
+	if len(nm.GetNotifiers()) != 2 || nm.GetInstance(1).Name != "团队群" || nm.GetInstance(1).IsEnabled() {
+		t.Errorf("更新应替换原实例: %+v", nm.GetInstance(1))
+	}
+	if got := notifierLabel(renamed); got != "exec(团队群)" {
+		t.Errorf("notifierLabel = %q", got)
+	}
+
+	nm.RemoveInstance(1)
+	if nm.GetInstance(1) != nil || len(nm.GetNotifiers()) != 1 {
+		t.Error("删除后不应再包含该实例")
+	}
+}
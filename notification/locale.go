@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"Puff/config"
+	"Puff/i18n"
+	"Puff/logger"
+)
+
+// locales 通知语言设置
+type locales struct {
+	mu       sync.RWMutex
+	fallback string            // 默认通知语言
+	byKey    map[string]string // 通知器标识或类型 -> 语言
+}
+
+// ParseNotifierLocales 解析各通知器的通知语言，格式如 "telegram:3=en,email=zh-CN"
+func ParseNotifierLocales(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, tag, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("通知语言配置格式错误: %s", item)
+		}
+		locale := i18n.Normalize(tag)
+		if locale == "" {
+			return nil, fmt.Errorf("不支持的通知语言 %s（可选 %s）", strings.TrimSpace(tag), strings.Join(i18n.Supported(), "、"))
+		}
+		result[key] = locale
+	}
+	return result, nil
+}
+
+// SetLocales 更新默认通知语言与各通知器的通知语言
+func (nm *NotificationManager) SetLocales(cfg config.NotificationConfig) {
+	byKey, err := ParseNotifierLocales(cfg.Locales)
+	if err != nil {
+		logger.Error("解析通知语言配置失败，全部使用默认通知语言: %v", err)
+		byKey = map[string]string{}
+	}
+	nm.locales.mu.Lock()
+	nm.locales.fallback = i18n.Resolve(cfg.Locale)
+	nm.locales.byKey = byKey
+	nm.locales.mu.Unlock()
+}
+
+// localeOf 返回通知器的通知语言
+func (nm *NotificationManager) localeOf(n Notifier) string {
+	return nm.localeOfKey(NotifierKey(n))
+}
+
+// localeOfKey 按通知器标识返回通知语言：实例标识优先于类型，未配置时为默认通知语言
+func (nm *NotificationManager) localeOfKey(key string) string {
+	nm.locales.mu.RLock()
+	defer nm.locales.mu.RUnlock()
+
+	if locale, ok := nm.locales.byKey[key]; ok {
+		return locale
+	}
+	notifierType, _, _ := strings.Cut(key, ":")
+	if locale, ok := nm.locales.byKey[notifierType]; ok {
+		return locale
+	}
+	return i18n.Resolve(nm.locales.fallback)
+}
+
+// translateStatus 将状态翻译为指定语言，未知状态原样返回
+func translateStatus(locale, status string) string {
+	if key := "status." + status; i18n.Has(key) {
+		return i18n.T(locale, key)
+	}
+	return status
+}
+
+// translateStatusChange 将状态变化翻译为指定语言
+func translateStatusChange(locale, oldStatus, newStatus string) string {
+	return translateStatus(locale, oldStatus) + " → " + translateStatus(locale, newStatus)
+}
+
+// label 通知正文中的字段标签
+func label(locale, name string) string {
+	return i18n.T(locale, "notify.label."+name)
+}
+
+// cutLabel 去掉任一语言的字段标签前缀（"标签: "），返回标签后的内容与标签所属语言
+func cutLabel(line, name string) (value, locale string, ok bool) {
+	for _, loc := range i18n.Supported() {
+		if value, ok := strings.CutPrefix(line, label(loc, name)+": "); ok {
+			return value, loc, true
+		}
+	}
+	return "", "", false
+}
+
+// batchHeaderLocale 判断是否为批量通知（见 formatBatchMessage），返回其语言
+func batchHeaderLocale(message string) (string, bool) {
+	for _, loc := range i18n.Supported() {
+		prefix, suffix, _ := strings.Cut(i18n.T(loc, "notify.batch_header"), "%d")
+		if strings.Contains(message, prefix) && strings.Contains(message, suffix) {
+			return loc, true
+		}
+	}
+	return "", false
+}
+
+// messageLocale 根据通知文本的字段标签判断其语言，无法判断时为默认语言
+func messageLocale(message string) string {
+	if locale, ok := batchHeaderLocale(message); ok {
+		return locale
+	}
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		for _, name := range []string{"domain", "time"} {
+			if _, locale, ok := cutLabel(line, name); ok {
+				return locale
+			}
+		}
+	}
+	return i18n.Default
+}
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestMain 在临时目录中运行测试，数据库（data/puff.db）不写入源码目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "puff-notification-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// capturedRequest 模拟服务端收到的请求
+type capturedRequest struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// newCaptureServer 记录收到的请求的模拟服务：依次使用 statuses 作为响应码（用完后返回 200），响应体均为 body
+func newCaptureServer(t *testing.T, body string, statuses ...int) (*httptest.Server, func() []capturedRequest) {
+	var mu sync.Mutex
+	var requests []capturedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("读取请求失败: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, capturedRequest{path: r.URL.Path, query: r.URL.Query(), header: r.Header.Clone(), body: data})
+		n := len(requests)
+		mu.Unlock()
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
+}
+
+// onlyRequest 要求恰好收到一次请求
+func onlyRequest(t *testing.T, requests []capturedRequest) capturedRequest {
+	t.Helper()
+	if len(requests) != 1 {
+		t.Fatalf("应收到 1 次请求，实际 %d 次", len(requests))
+	}
+	return requests[0]
+}
+
+// jsonBody 解析 JSON 请求体
+func (r capturedRequest) jsonBody(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal(r.body, &payload); err != nil {
+		t.Fatalf("解析请求体失败: %v (%s)", err, r.body)
+	}
+	return payload
+}
+
+// recordingNotifier 记录收到消息的测试通知器
+type recordingNotifier struct {
+	notifierType string
+
+	mu       sync.Mutex
+	subjects []string
+	messages []string
+}
+
+func (r *recordingNotifier) SendMessage(subject, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subjects = append(r.subjects, subject)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *recordingNotifier) IsEnabled() bool { return true }
+func (r *recordingNotifier) GetType() string { return r.notifierType }
+func (r *recordingNotifier) Test() error     { return nil }
+
+// received 返回收到的主题与正文
+func (r *recordingNotifier) received() ([]string, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.subjects...), append([]string(nil), r.messages...)
+}
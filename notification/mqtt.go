@@ -0,0 +1,435 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	mqttDefaultPrefix          = "puff"
+	mqttDefaultDiscoveryPrefix = "homeassistant"
+	mqttDefaultTimeout         = 10 * time.Second
+	mqttMaxTimeout             = 120 // 超时时间上限（秒）
+)
+
+// mqttObjectIDPattern Home Assistant 对象ID中不允许的字符
+var mqttObjectIDPattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// MQTTNotifier MQTT 发布通知器
+//
+//	<前缀>/domain/<域名>/status  当前状态，如 registered（保留）
+//	<前缀>/domain/<域名>/state   域名详情 JSON（保留）
+//	<前缀>/events/<事件类型>     事件 JSON（不保留），如 puff/events/status_change
+//
+// 保留状态在每次查询后、渠道启用时与测试时同步
+type MQTTNotifier struct {
+	config  config.MQTTConfig
+	enabled bool
+}
+
+// mqttEvent 发布到事件主题的数据
+type mqttEvent struct {
+	NotificationEvent
+	Subject string           `json:"subject,omitempty"`
+	Info    *core.DomainInfo `json:"info,omitempty"`
+}
+
+// mqttDomainState 发布到 state 主题的域名详情
+type mqttDomainState struct {
+	Domain      string     `json:"domain"`
+	Status      string     `json:"status"`
+	Registrar   string     `json:"registrar,omitempty"`
+	CreatedDate *time.Time `json:"created_date,omitempty"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	DaysLeft    *int       `json:"days_until_expiry,omitempty"` // 按发布时刻计算，每次查询后随状态刷新
+
+	NameServers []string   `json:"name_servers,omitempty"`
+	StatusCodes []string   `json:"status_codes,omitempty"`
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewMQTTNotifier 创建 MQTT 通知器
+func NewMQTTNotifier(cfg config.MQTTConfig) *MQTTNotifier {
+	return &MQTTNotifier{
+		config:  cfg,
+		enabled: cfg.Enabled,
+	}
+}
+
+// SendMessage 发布纯文本通知到 <前缀>/events/message
+func (m *MQTTNotifier) SendMessage(subject, message string) error {
+	return m.SendEvents(subject, message, nil)
+}
+
+// SendEvents 发布事件，并更新事件中域名的保留状态
+func (m *MQTTNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if !m.enabled {
+		return fmt.Errorf("MQTT通知未启用")
+	}
+
+	var messages []mqttMessage
+	if len(events) == 0 {
+		payload, err := json.Marshal(map[string]interface{}{
+			"subject":   subject,
+			"message":   message,
+			"timestamp": time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("序列化消息失败: %w", err)
+		}
+		messages = append(messages, mqttMessage{Topic: m.topic("events", "message"), Payload: payload})
+	}
+	for _, event := range events {
+		info := eventDomainInfo(event)
+		// 原始 WHOIS 可能很大，不随事件发布
+		event.WhoisRaw = ""
+		published := info
+		if info != nil && info.WhoisRaw != "" {
+			copied := *info
+			copied.WhoisRaw = ""
+			published = &copied
+		}
+		payload, err := json.Marshal(mqttEvent{NotificationEvent: event, Subject: subject, Info: published})
+		if err != nil {
+			return fmt.Errorf("序列化事件失败: %w", err)
+		}
+		eventType := event.Type
+		if eventType == "" {
+			eventType = "message"
+		}
+		messages = append(messages, mqttMessage{Topic: m.topic("events", topicLevel(eventType)), Payload: payload})
+
+		// 续费提醒等不含状态的事件不更新保留状态
+		if event.Domain == "" || event.Status == "" || event.Type == "test" {
+			continue
+		}
+		state, err := m.stateMessages(event.Domain, event.Status, info)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, state...)
+	}
+	return m.publish(messages)
+}
+
+// IsEnabled 检查是否启用
+func (m *MQTTNotifier) IsEnabled() bool {
+	return m.enabled
+}
+
+// GetType 获取通知器类型
+func (m *MQTTNotifier) GetType() string {
+	return "mqtt"
+}
+
+// Test 发布测试事件，并同步全部域名的保留状态
+func (m *MQTTNotifier) Test() error {
+	_, err := m.TestWithOutput(i18n.Default)
+	return err
+}
+
+// TestWithOutput 发布测试事件并同步全部域名的保留状态，返回发布结果
+func (m *MQTTNotifier) TestWithOutput(locale string) (string, error) {
+	if !m.enabled {
+		return "", fmt.Errorf("MQTT通知未启用")
+	}
+
+	event := NotificationEvent{
+		Type:      "test",
+		Domain:    "example.com",
+		Status:    string(core.StatusRegistered),
+		Message:   i18n.T(locale, "notify.test.mqtt"),
+		Timestamp: time.Now(),
+	}
+	payload, err := json.Marshal(mqttEvent{NotificationEvent: event, Subject: i18n.T(locale, "notify.test.subject")})
+	if err != nil {
+		return "", fmt.Errorf("序列化事件失败: %w", err)
+	}
+	messages := []mqttMessage{{Topic: m.topic("events", "test"), Payload: payload}}
+
+	state, count, err := m.syncMessages()
+	if err != nil {
+		return "", err
+	}
+	messages = append(messages, state...)
+	if err := m.publish(messages); err != nil {
+		return "", err
+	}
+	return i18n.T(locale, "notify.test.mqtt_result", m.topic("events", "test"), count), nil
+}
+
+// PublishStates 发布查询结果的保留状态（每次查询后同步，不发布事件）
+func (m *MQTTNotifier) PublishStates(infos []*core.DomainInfo) error {
+	if !m.enabled {
+		return nil
+	}
+
+	var messages []mqttMessage
+	for _, info := range infos {
+		if info == nil || info.Status == "" {
+			continue
+		}
+		if info.Status == core.StatusError {
+			// 查询失败时没有注册信息，只更新状态，保留上次的详情
+			messages = append(messages, mqttMessage{
+				Topic:   m.topic("domain", topicLevel(info.Name), "status"),
+				Payload: []byte(info.Status),
+				Retain:  true,
+			})
+			continue
+		}
+		state, err := m.stateMessages(info.Name, string(info.Status), info)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, state...)
+	}
+	return m.publish(messages)
+}
+
+// syncAll 同步全部域名的保留状态（渠道启用或更新时）
+func (m *MQTTNotifier) syncAll() error {
+	messages, count, err := m.syncMessages()
+	if err != nil {
+		return err
+	}
+	if err := m.publish(messages); err != nil {
+		return err
+	}
+	logger.Info("MQTT已同步 %d 个域名的保留状态", count)
+	return nil
+}
+
+// syncMessages 全部监控域名的保留状态（测试时发布，便于订阅端立即获得完整状态）
+func (m *MQTTNotifier) syncMessages() ([]mqttMessage, int, error) {
+	domains, err := storage.ListDomains(false)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取域名列表失败: %w", err)
+	}
+	results, err := storage.LoadDomainResults()
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取域名查询结果失败: %w", err)
+	}
+
+	var messages []mqttMessage
+	count := 0
+	for _, domain := range domains {
+		res, ok := results[domain.Name]
+		if !ok || res.Status == "" {
+			continue
+		}
+		state, err := m.stateMessages(domain.Name, res.Status, core.DomainInfoFromResult(&res))
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, state...)
+		count++
+	}
+	return messages, count, nil
+}
+
+// stateMessages 域名的保留状态消息，启用自动发现时附带 Home Assistant 配置
+func (m *MQTTNotifier) stateMessages(domain, status string, info *core.DomainInfo) ([]mqttMessage, error) {
+	now := time.Now()
+	state := mqttDomainState{Domain: domain, Status: status, UpdatedAt: now}
+	if info != nil {
+		state.Registrar = info.Registrar
+		state.CreatedDate = info.CreatedDate
+		state.ExpiryDate = info.ExpiryDate
+		state.NameServers = info.NameServers
+		state.StatusCodes = info.StatusCodes
+		if !info.LastChecked.IsZero() {
+			lastChecked := info.LastChecked
+			state.LastChecked = &lastChecked
+		}
+		if info.ExpiryDate != nil {
+			days := int(info.ExpiryDate.Sub(now).Hours() / 24)
+			state.DaysLeft = &days
+		}
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("序列化域名状态失败: %w", err)
+	}
+
+	base := m.topic("domain", topicLevel(domain))
+	messages := []mqttMessage{
+		{Topic: base + "/status", Payload: []byte(status), Retain: true},
+		{Topic: base + "/state", Payload: payload, Retain: true},
+	}
+	if m.config.Discovery {
+		discovery, err := m.discoveryMessages(domain, base)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, discovery...)
+	}
+	return messages, nil
+}
+
+// discoveryMessages Home Assistant MQTT 自动发现配置：每个域名一个设备，包含状态与到期时间两个传感器
+func (m *MQTTNotifier) discoveryMessages(domain, base string) ([]mqttMessage, error) {
+	nodeID := mqttObjectIDPattern.ReplaceAllString(m.prefix(), "_")
+	objectID := mqttObjectIDPattern.ReplaceAllString(strings.ReplaceAll(domain, ".", "_"), "_")
+	device := map[string]interface{}{
+		"identifiers":  []string{nodeID + "_" + objectID},
+		"name":         domain,
+		"manufacturer": "Puff",
+		"model":        "Domain monitor",
+	}
+	sensors := []struct {
+		id     string
+		config map[string]interface{}
+	}{
+		{"status", map[string]interface{}{
+			"name":                  "Status",
+			"state_topic":           base + "/status",
+			"json_attributes_topic": base + "/state",
+			"icon":                  "mdi:web",
+		}},
+		{"expiry", map[string]interface{}{
+			"name":           "Expiry",
+			"state_topic":    base + "/state",
+			"value_template": "{{ value_json.expiry_date | default(None) }}",
+			"device_class":   "timestamp",
+		}},
+	}
+
+	discoveryPrefix := strings.Trim(m.config.DiscoveryPrefix, "/")
+	if discoveryPrefix == "" {
+		discoveryPrefix = mqttDefaultDiscoveryPrefix
+	}
+	messages := make([]mqttMessage, 0, len(sensors))
+	for _, sensor := range sensors {
+		sensor.config["unique_id"] = nodeID + "_" + objectID + "_" + sensor.id
+		sensor.config["device"] = device
+		payload, err := json.Marshal(sensor.config)
+		if err != nil {
+			return nil, fmt.Errorf("序列化自动发现配置失败: %w", err)
+		}
+		messages = append(messages, mqttMessage{
+			Topic:   fmt.Sprintf("%s/sensor/%s/%s_%s/config", discoveryPrefix, nodeID, objectID, sensor.id),
+			Payload: payload,
+			Retain:  true,
+		})
+	}
+	return messages, nil
+}
+
+// mqttNotifiers 已启用的 MQTT 渠道
+func (nm *NotificationManager) mqttNotifiers() []*MQTTNotifier {
+	var notifiers []*MQTTNotifier
+	for _, n := range nm.GetNotifiers() {
+		if inst, ok := n.(*InstanceNotifier); ok {
+			n = inst.Notifier
+		}
+		if m, ok := n.(*MQTTNotifier); ok && m.IsEnabled() {
+			notifiers = append(notifiers, m)
+		}
+	}
+	return notifiers
+}
+
+// SyncDomainStates 将查询结果同步到各 MQTT 渠道的保留状态
+func (nm *NotificationManager) SyncDomainStates(infos []*core.DomainInfo) {
+	for _, m := range nm.mqttNotifiers() {
+		if err := m.PublishStates(infos); err != nil {
+			logger.Warn("同步MQTT域名状态失败: %v", err)
+		}
+	}
+}
+
+// syncMQTTInstance MQTT 渠道启用或更新后，在后台同步全部域名的保留状态
+func (nm *NotificationManager) syncMQTTInstance(inst *InstanceNotifier) {
+	m, ok := inst.Notifier.(*MQTTNotifier)
+	if !ok || !m.IsEnabled() {
+		return
+	}
+	go func() {
+		if err := m.syncAll(); err != nil {
+			logger.Warn("同步MQTT渠道 %s 的域名状态失败: %v", inst.Name, err)
+		}
+	}()
+}
+
+// publish 连接代理、依次发布后断开
+func (m *MQTTNotifier) publish(messages []mqttMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	client, err := dialMQTT(m.config, m.timeout())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for _, msg := range messages {
+		if err := client.publish(msg, m.config.QoS); err != nil {
+			return fmt.Errorf("发布到 %s 失败: %w", msg.Topic, err)
+		}
+	}
+	logger.Info("MQTT已发布 %d 条消息到 %s", len(messages), m.config.Broker)
+	return nil
+}
+
+// prefix 主题前缀
+func (m *MQTTNotifier) prefix() string {
+	prefix := strings.Trim(m.config.TopicPrefix, "/")
+	if prefix == "" {
+		return mqttDefaultPrefix
+	}
+	return prefix
+}
+
+// topic 拼接主题
+func (m *MQTTNotifier) topic(levels ...string) string {
+	return m.prefix() + "/" + strings.Join(levels, "/")
+}
+
+// timeout 连接与发布超时时间
+func (m *MQTTNotifier) timeout() time.Duration {
+	if m.config.Timeout > 0 {
+		return time.Duration(m.config.Timeout) * time.Second
+	}
+	return mqttDefaultTimeout
+}
+
+// topicLevel 替换主题层级中的通配符与分隔符
+func topicLevel(value string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(value)
+}
+
+// ValidateMQTTConfig 校验 MQTT 配置
+func ValidateMQTTConfig(cfg config.MQTTConfig) error {
+	if _, _, err := parseMQTTBroker(cfg.Broker); err != nil {
+		return err
+	}
+	if cfg.QoS < 0 || cfg.QoS > 2 {
+		return fmt.Errorf("QoS必须为0、1或2")
+	}
+	if cfg.Timeout < 0 || cfg.Timeout > mqttMaxTimeout {
+		return fmt.Errorf("超时时间必须在0-%d秒之间", mqttMaxTimeout)
+	}
+	for _, prefix := range []string{cfg.TopicPrefix, cfg.DiscoveryPrefix} {
+		if strings.ContainsAny(prefix, "+#") {
+			return fmt.Errorf("主题前缀不能包含通配符 + 或 #: %s", prefix)
+		}
+	}
+	if cfg.Password != "" && cfg.Username == "" {
+		return fmt.Errorf("填写密码时必须同时填写用户名")
+	}
+	if _, err := mqttTLSConfig(cfg, ""); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package notification
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"Puff/config"
+)
+
+// MQTT 3.1.1 控制报文类型
+const (
+	mqttConnect    = 1
+	mqttConnack    = 2
+	mqttPublish    = 3
+	mqttPuback     = 4
+	mqttPubrec     = 5
+	mqttPubrel     = 6
+	mqttPubcomp    = 7
+	mqttDisconnect = 14
+
+	mqttKeepAlive    = 60        // 保活时间（秒），每次发送完即断开，仅用于满足协议
+	mqttMaxRemaining = 268435455 // 剩余长度上限
+	mqttMaxString    = 65535     // 字符串长度上限
+)
+
+// mqttConnackErrors CONNACK 返回码说明
+var mqttConnackErrors = map[byte]string{
+	1: "不支持的协议版本",
+	2: "客户端ID被拒绝",
+	3: "服务不可用",
+	4: "用户名或密码错误",
+	5: "未授权",
+}
+
+// mqttMessage 待发布的消息
+type mqttMessage struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// mqttClient 最小化的 MQTT 3.1.1 客户端，只负责发布（QoS 0、1、2）
+type mqttClient struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	nextID  uint16
+}
+
+// parseMQTTBroker 解析代理地址，返回 host:port 与是否使用 TLS；未写协议时按 mqtt:// 处理
+func parseMQTTBroker(broker string) (string, bool, error) {
+	broker = strings.TrimSpace(broker)
+	if broker == "" {
+		return "", false, fmt.Errorf("未配置MQTT代理地址")
+	}
+	if !strings.Contains(broker, "://") {
+		broker = "mqtt://" + broker
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, fmt.Errorf("解析MQTT代理地址失败: %v", err)
+	}
+
+	useTLS := false
+	port := "1883"
+	switch strings.ToLower(u.Scheme) {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		useTLS = true
+		port = "8883"
+	default:
+		return "", false, fmt.Errorf("不支持的MQTT代理协议: %s（可选 mqtt://、mqtts://）", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", false, fmt.Errorf("MQTT代理地址缺少主机")
+	}
+	if p := u.Port(); p != "" {
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return "", false, fmt.Errorf("MQTT代理端口必须在1-65535之间")
+		}
+		port = p
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// dialMQTT 连接代理并完成 CONNECT 握手
+func dialMQTT(cfg config.MQTTConfig, timeout time.Duration) (*mqttClient, error) {
+	address, useTLS, err := parseMQTTBroker(cfg.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if useTLS {
+		host, _, _ := net.SplitHostPort(address)
+		tlsConfig, tlsErr := mqttTLSConfig(cfg, host)
+		if tlsErr != nil {
+			return nil, tlsErr
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接MQTT代理 %s 失败: %w", address, err)
+	}
+
+	c := &mqttClient{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}
+	if err := c.connect(cfg); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// mqttTLSConfig TLS 连接配置
+func mqttTLSConfig(cfg config.MQTTConfig, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: cfg.SkipVerify}
+	if strings.TrimSpace(cfg.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, fmt.Errorf("解析MQTT CA证书失败")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// connect 发送 CONNECT（清除会话）并等待 CONNACK
+func (c *mqttClient) connect(cfg config.MQTTConfig) error {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = randomMQTTClientID()
+	}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4) // 协议级别 3.1.1
+	flags := byte(0x02)
+	if cfg.Username != "" {
+		flags |= 0x80
+		if cfg.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	body.Write([]byte{byte(mqttKeepAlive >> 8), byte(mqttKeepAlive & 0xff)})
+	writeMQTTString(&body, clientID)
+	if cfg.Username != "" {
+		writeMQTTString(&body, cfg.Username)
+		if cfg.Password != "" {
+			writeMQTTString(&body, cfg.Password)
+		}
+	}
+	if err := c.writePacket(mqttConnect<<4, body.Bytes()); err != nil {
+		return err
+	}
+
+	packetType, payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if packetType != mqttConnack || len(payload) != 2 {
+		return fmt.Errorf("MQTT代理返回了意外的响应（类型 %d）", packetType)
+	}
+	if code := payload[1]; code != 0 {
+		reason := mqttConnackErrors[code]
+		if reason == "" {
+			reason = fmt.Sprintf("返回码 %d", code)
+		}
+		return fmt.Errorf("MQTT代理拒绝连接: %s", reason)
+	}
+	return nil
+}
+
+// publish 发布一条消息，QoS 1、2 时等待代理确认
+func (c *mqttClient) publish(msg mqttMessage, qos int) error {
+	if msg.Topic == "" || len(msg.Topic) > mqttMaxString {
+		return fmt.Errorf("MQTT主题长度无效: %q", msg.Topic)
+	}
+	header := byte(mqttPublish<<4) | byte(qos<<1)
+	if msg.Retain {
+		header |= 0x01
+	}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, msg.Topic)
+	var id []byte
+	if qos > 0 {
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		id = []byte{byte(c.nextID >> 8), byte(c.nextID)}
+		body.Write(id)
+	}
+	body.Write(msg.Payload)
+	if err := c.writePacket(header, body.Bytes()); err != nil {
+		return err
+	}
+
+	switch qos {
+	case 1:
+		return c.await(mqttPuback, id)
+	case 2:
+		if err := c.await(mqttPubrec, id); err != nil {
+			return err
+		}
+		if err := c.writePacket(mqttPubrel<<4|0x02, id); err != nil {
+			return err
+		}
+		return c.await(mqttPubcomp, id)
+	}
+	return nil
+}
+
+// await 等待指定报文标识符的确认，忽略其他报文
+func (c *mqttClient) await(packetType byte, id []byte) error {
+	for {
+		t, payload, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		if t == packetType && len(payload) >= 2 && bytes.Equal(payload[:2], id) {
+			return nil
+		}
+	}
+}
+
+// Close 发送 DISCONNECT 并关闭连接
+func (c *mqttClient) Close() error {
+	c.writePacket(mqttDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+// writePacket 写入一个控制报文
+func (c *mqttClient) writePacket(header byte, body []byte) error {
+	if len(body) > mqttMaxRemaining {
+		return fmt.Errorf("MQTT消息过大（%d 字节）", len(body))
+	}
+	packet := make([]byte, 0, len(body)+5)
+	packet = append(packet, header)
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(packet); err != nil {
+		return fmt.Errorf("发送MQTT报文失败: %w", err)
+	}
+	return nil
+}
+
+// readPacket 读取一个控制报文，返回类型与剩余部分
+func (c *mqttClient) readPacket() (byte, []byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("读取MQTT响应失败: %w", err)
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("MQTT报文长度编码无效")
+		}
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, fmt.Errorf("读取MQTT响应失败: %w", err)
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, fmt.Errorf("读取MQTT响应失败: %w", err)
+	}
+	return header >> 4, payload, nil
+}
+
+// writeMQTTString 写入长度前缀的 UTF-8 字符串
+func writeMQTTString(buf *bytes.Buffer, s string) {
+	if len(s) > mqttMaxString {
+		s = s[:mqttMaxString]
+	}
+	buf.Write([]byte{byte(len(s) >> 8), byte(len(s))})
+	buf.WriteString(s)
+}
+
+// randomMQTTClientID 生成随机客户端ID（每次连接都清除会话，无需固定）
+func randomMQTTClientID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("puff-%d", time.Now().UnixNano())
+	}
+	return "puff-" + hex.EncodeToString(b)
+}
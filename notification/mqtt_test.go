@@ -0,0 +1,412 @@
+package notification
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/core"
+)
+
+// brokerPublish 代理收到的 PUBLISH
+type brokerPublish struct {
+	Topic   string
+	Payload string
+	QoS     int
+	Retain  bool
+}
+
+// fakeBroker 最小化的 MQTT 代理替身：记录 CONNECT 与 PUBLISH，按 QoS 回复确认
+type fakeBroker struct {
+	ln         net.Listener
+	returnCode byte // CONNACK 返回码
+	dropPubrec bool // 收到 QoS 2 消息后不回复 PUBREC
+
+	mu        sync.Mutex
+	connects  []map[string]string
+	published []brokerPublish
+	pubrels   int
+	done      chan struct{}
+}
+
+func startFakeBroker(t *testing.T, returnCode byte) *fakeBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{ln: ln, returnCode: returnCode, done: make(chan struct{})}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) config() config.MQTTConfig {
+	return config.MQTTConfig{Broker: "mqtt://" + b.ln.Addr().String(), Enabled: true, Timeout: 5}
+}
+
+// readBrokerPacket 独立实现的报文解析，用于校验客户端的剩余长度编码
+func readBrokerPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, shift := 0, 0
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+func readBrokerString(body []byte) (string, []byte) {
+	n := int(body[0])<<8 | int(body[1])
+	return string(body[2 : 2+n]), body[2+n:]
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readBrokerPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case mqttConnect:
+			protocol, rest := readBrokerString(body)
+			level, flags := rest[0], rest[1]
+			clientID, rest := readBrokerString(rest[4:])
+			info := map[string]string{"protocol": protocol, "level": fmt.Sprint(level), "client_id": clientID, "clean": fmt.Sprint(flags&0x02 != 0)}
+			if flags&0x80 != 0 {
+				info["username"], rest = readBrokerString(rest)
+			}
+			if flags&0x40 != 0 {
+				info["password"], _ = readBrokerString(rest)
+			}
+			b.mu.Lock()
+			b.connects = append(b.connects, info)
+			b.mu.Unlock()
+			conn.Write([]byte{mqttConnack << 4, 2, 0, b.returnCode})
+			if b.returnCode != 0 {
+				return
+			}
+		case mqttPublish:
+			qos := int(header>>1) & 0x03
+			topic, rest := readBrokerString(body)
+			var id []byte
+			if qos > 0 {
+				id, rest = rest[:2], rest[2:]
+			}
+			b.mu.Lock()
+			b.published = append(b.published, brokerPublish{Topic: topic, Payload: string(rest), QoS: qos, Retain: header&0x01 != 0})
+			b.mu.Unlock()
+			switch qos {
+			case 1:
+				// 先回复一个无关报文标识符的确认，客户端应继续等待
+				conn.Write([]byte{mqttPuback << 4, 2, 0xff, 0xff})
+				conn.Write(append([]byte{mqttPuback << 4, 2}, id...))
+			case 2:
+				if !b.dropPubrec {
+					conn.Write(append([]byte{mqttPubrec << 4, 2}, id...))
+				}
+			}
+		case mqttPubrel:
+			if header&0x0f != 0x02 {
+				return // PUBREL 固定头标志必须为 0010
+			}
+			b.mu.Lock()
+			b.pubrels++
+			b.mu.Unlock()
+			conn.Write(append([]byte{mqttPubcomp << 4, 2}, body[:2]...))
+		case mqttDisconnect:
+			close(b.done)
+			return
+		}
+	}
+}
+
+// waitDisconnect 等待客户端发送 DISCONNECT
+func (b *fakeBroker) waitDisconnect(t *testing.T) {
+	t.Helper()
+	select {
+	case <-b.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待客户端断开超时")
+	}
+}
+
+func TestMQTTClientConnectAndPublish(t *testing.T) {
+	for _, qos := range []int{0, 1, 2} {
+		t.Run(fmt.Sprintf("QoS%d", qos), func(t *testing.T) {
+			b := startFakeBroker(t, 0)
+			cfg := b.config()
+			cfg.Username, cfg.Password, cfg.ClientID = "puff", "secret", "puff-test"
+
+			client, err := dialMQTT(cfg, 5*time.Second)
+			if err != nil {
+				t.Fatalf("连接失败: %v", err)
+			}
+			messages := []mqttMessage{
+				{Topic: "puff/domain/example.com/status", Payload: []byte("registered"), Retain: true},
+				{Topic: "puff/events/status_change", Payload: []byte(`{"domain":"example.com"}`)},
+			}
+			for _, msg := range messages {
+				if err := client.publish(msg, qos); err != nil {
+					t.Fatalf("发布失败: %v", err)
+				}
+			}
+			client.Close()
+			b.waitDisconnect(t)
+
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			want := map[string]string{"protocol": "MQTT", "level": "4", "client_id": "puff-test", "clean": "true", "username": "puff", "password": "secret"}
+			if len(b.connects) != 1 || fmt.Sprint(b.connects[0]) != fmt.Sprint(want) {
+				t.Errorf("CONNECT = %v, 期望 %v", b.connects, want)
+			}
+			if len(b.published) != len(messages) {
+				t.Fatalf("代理收到 %d 条消息，期望 %d", len(b.published), len(messages))
+			}
+			for i, msg := range messages {
+				got := b.published[i]
+				if got.Topic != msg.Topic || got.Payload != string(msg.Payload) || got.QoS != qos || got.Retain != msg.Retain {
+					t.Errorf("第 %d 条消息 = %+v，期望 %s retain=%t qos=%d", i+1, got, msg.Topic, msg.Retain, qos)
+				}
+			}
+			if qos == 2 && b.pubrels != len(messages) {
+				t.Errorf("QoS 2 应发送 %d 个 PUBREL，实际 %d", len(messages), b.pubrels)
+			}
+		})
+	}
+}
+
+func TestMQTTClientConnackRejected(t *testing.T) {
+	b := startFakeBroker(t, 4)
+	_, err := dialMQTT(b.config(), 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "用户名或密码错误") {
+		t.Fatalf("期望用户名或密码错误，实际 %v", err)
+	}
+}
+
+func TestMQTTClientQoS2Timeout(t *testing.T) {
+	b := startFakeBroker(t, 0)
+	b.dropPubrec = true
+	client, err := dialMQTT(b.config(), 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.publish(mqttMessage{Topic: "puff/t", Payload: []byte("x")}, 2); err == nil {
+		t.Fatal("未收到 PUBREC 时应超时失败")
+	}
+}
+
+func TestMQTTRemainingLength(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2097152, []byte{0x80, 0x80, 0x80, 0x01}},
+	}
+	for _, tt := range tests {
+		server, conn := net.Pipe()
+		c := &mqttClient{conn: conn, reader: bufio.NewReader(conn), timeout: 5 * time.Second}
+		result := make(chan []byte, 1)
+		go func() {
+			packet, _ := io.ReadAll(server)
+			result <- packet
+		}()
+		if err := c.writePacket(mqttPublish<<4, make([]byte, tt.length)); err != nil {
+			t.Fatalf("写入 %d 字节失败: %v", tt.length, err)
+		}
+		conn.Close()
+		packet := <-result
+		server.Close()
+
+		if got := packet[1 : 1+len(tt.want)]; !bytes.Equal(got, tt.want) {
+			t.Errorf("长度 %d 编码为 % x，期望 % x", tt.length, got, tt.want)
+		}
+		if len(packet) != 1+len(tt.want)+tt.length {
+			t.Errorf("长度 %d 的报文总长 %d 不正确", tt.length, len(packet))
+		}
+
+		// 客户端解析同样的编码
+		reader, writer := net.Pipe()
+		rc := &mqttClient{conn: reader, reader: bufio.NewReader(reader), timeout: 5 * time.Second}
+		go func() {
+			writer.Write(packet)
+			writer.Close()
+		}()
+		packetType, body, err := rc.readPacket()
+		if err != nil || packetType != mqttPublish || len(body) != tt.length {
+			t.Errorf("readPacket(%d) = %d, %d 字节, %v", tt.length, packetType, len(body), err)
+		}
+		reader.Close()
+	}
+}
+
+func TestParseMQTTBroker(t *testing.T) {
+	tests := []struct {
+		broker  string
+		address string
+		tls     bool
+		wantErr bool
+	}{
+		{"broker.local", "broker.local:1883", false, false},
+		{"mqtt://broker.local:1884", "broker.local:1884", false, false},
+		{"mqtts://broker.local", "broker.local:8883", true, false},
+		{"ssl://[::1]:8884", "[::1]:8884", true, false},
+		{"", "", false, true},
+		{"ws://broker.local", "", false, true},
+		{"mqtt://broker.local:70000", "", false, true},
+		{"mqtt://:1883", "", false, true},
+	}
+	for _, tt := range tests {
+		address, useTLS, err := parseMQTTBroker(tt.broker)
+		if (err != nil) != tt.wantErr || address != tt.address || useTLS != tt.tls {
+			t.Errorf("parseMQTTBroker(%q) = %q, %t, %v", tt.broker, address, useTLS, err)
+		}
+	}
+}
+
+func TestMQTTSendEvents(t *testing.T) {
+	b := startFakeBroker(t, 0)
+	cfg := b.config()
+	cfg.TopicPrefix = "home/puff"
+	cfg.Discovery = true
+	m := NewMQTTNotifier(cfg)
+
+	expiry := time.Now().Add(10*24*time.Hour + time.Hour)
+	err := m.SendEvents("状态变化", "", []NotificationEvent{
+		{
+			Type: "status_change", Domain: "example.com", OldStatus: "available", Status: "registered",
+			Info: &core.DomainInfo{Name: "example.com", Status: core.StatusRegistered, Registrar: "Example Registrar", ExpiryDate: &expiry, WhoisRaw: "raw"},
+		},
+		{Type: "expiry_reminder", Domain: "owned.com", Info: &core.DomainInfo{Name: "owned.com"}},
+	})
+	if err != nil {
+		t.Fatalf("发布事件失败: %v", err)
+	}
+	b.waitDisconnect(t)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	topics := map[string]brokerPublish{}
+	for _, p := range b.published {
+		topics[p.Topic] = p
+	}
+
+	// 事件不保留，原始 WHOIS 不随事件发布
+	event, ok := topics["home/puff/events/status_change"]
+	if !ok || event.Retain || strings.Contains(event.Payload, `"whois_raw":"raw"`) {
+		t.Errorf("状态变化事件错误: %+v", event)
+	}
+	if p, ok := topics["home/puff/events/expiry_reminder"]; !ok || p.Retain {
+		t.Errorf("续费提醒事件错误: %+v", p)
+	}
+
+	// 状态变化更新保留状态并附带自动发现配置
+	if p := topics["home/puff/domain/example.com/status"]; p.Payload != "registered" || !p.Retain {
+		t.Errorf("status = %+v", p)
+	}
+	var state mqttDomainState
+	if err := json.Unmarshal([]byte(topics["home/puff/domain/example.com/state"].Payload), &state); err != nil {
+		t.Fatalf("解析 state 失败: %v", err)
+	}
+	if state.Registrar != "Example Registrar" || state.DaysLeft == nil || *state.DaysLeft != 10 {
+		t.Errorf("state = %+v", state)
+	}
+	if _, ok := topics["homeassistant/sensor/home_puff/example_com_status/config"]; !ok {
+		t.Error("缺少 Home Assistant 自动发现配置")
+	}
+
+	// 不含状态的事件不更新保留状态
+	if _, ok := topics["home/puff/domain/owned.com/status"]; ok {
+		t.Error("续费提醒不应更新保留状态")
+	}
+	if len(b.published) != 6 {
+		t.Errorf("期望 6 条消息，实际 %d 条", len(b.published))
+	}
+}
+
+func TestMQTTPublishStates(t *testing.T) {
+	b := startFakeBroker(t, 0)
+	cfg := b.config()
+	cfg.TopicPrefix = "home/puff"
+	cfg.Discovery = true
+	m := NewMQTTNotifier(cfg)
+
+	expiry := time.Now().Add(10*24*time.Hour + time.Hour)
+	err := m.PublishStates([]*core.DomainInfo{
+		{Name: "example.com", Status: core.StatusRegistered, Registrar: "Example Registrar", ExpiryDate: &expiry, WhoisRaw: "raw"},
+		{Name: "broken.com", Status: core.StatusError, ErrorMessage: "timeout"},
+	})
+	if err != nil {
+		t.Fatalf("发布状态失败: %v", err)
+	}
+	b.waitDisconnect(t)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	topics := map[string]brokerPublish{}
+	for _, p := range b.published {
+		if !p.Retain {
+			t.Errorf("状态消息应保留: %s", p.Topic)
+		}
+		topics[p.Topic] = p
+	}
+	if got := topics["home/puff/domain/example.com/status"].Payload; got != "registered" {
+		t.Errorf("status = %q", got)
+	}
+	var state mqttDomainState
+	if err := json.Unmarshal([]byte(topics["home/puff/domain/example.com/state"].Payload), &state); err != nil {
+		t.Fatalf("解析 state 失败: %v", err)
+	}
+	if state.Registrar != "Example Registrar" || state.DaysLeft == nil || *state.DaysLeft != 10 {
+		t.Errorf("state = %+v", state)
+	}
+	if _, ok := topics["homeassistant/sensor/home_puff/example_com_status/config"]; !ok {
+		t.Error("缺少 Home Assistant 自动发现配置")
+	}
+	if got := topics["home/puff/domain/broken.com/status"].Payload; got != "error" {
+		t.Errorf("查询失败时 status = %q", got)
+	}
+	if _, ok := topics["home/puff/domain/broken.com/state"]; ok {
+		t.Error("查询失败时不应覆盖 state 详情")
+	}
+	if len(b.published) != 5 {
+		t.Errorf("期望 5 条消息，实际 %d 条", len(b.published))
+	}
+}
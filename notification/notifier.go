@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/storage"
 )
 
 // Notifier 通知器接口
@@ -25,34 +28,138 @@ type Notifier interface {
 	Test() error
 }
 
+// EventNotifier 可接收结构化事件的通知器（如 Webhook），优先于 SendMessage 使用
+type EventNotifier interface {
+	SendEvents(subject, message string, events []NotificationEvent) error
+}
+
+// deliver 发送通知，支持结构化事件的通知器同时接收事件列表
+func deliver(n Notifier, subject, message string, events []NotificationEvent) error {
+	if en, ok := n.(EventNotifier); ok {
+		return en.SendEvents(subject, message, events)
+	}
+	return n.SendMessage(subject, message)
+}
+
+// RenderedNotifier 可直接发送自定义模板渲染结果的通知器（跳过内置排版，如邮件、Telegram）
+type RenderedNotifier interface {
+	SendRendered(subject, body string, events []NotificationEvent) error
+}
+
+// deliverRendered 发送模板渲染后的通知，不支持的通知器按普通消息发送
+func deliverRendered(n Notifier, subject, body string, events []NotificationEvent) error {
+	if rn, ok := n.(RenderedNotifier); ok {
+		return rn.SendRendered(subject, body, events)
+	}
+	return deliver(n, subject, body, events)
+}
+
+// Resume 分段发送的进度：从第 Done 段继续，每段发送成功后以累计段数调用 Sent
+type Resume struct {
+	Done int
+	Sent func(done int)
+}
+
+// ResumableNotifier 分段发送的通知器（如超长 Telegram 消息），重试时跳过已发出的分段
+type ResumableNotifier interface {
+	SendEventsFrom(subject, message string, events []NotificationEvent, resume Resume) error
+	SendRenderedFrom(subject, body string, events []NotificationEvent, resume Resume) error
+}
+
+// deliverFrom 按进度发送通知，不支持分段续发的通知器整条发送
+func deliverFrom(n Notifier, subject, message string, events []NotificationEvent, rendered bool, resume Resume) error {
+	if rn, ok := n.(ResumableNotifier); ok {
+		if rendered {
+			return rn.SendRenderedFrom(subject, message, events, resume)
+		}
+		return rn.SendEventsFrom(subject, message, events, resume)
+	}
+	if rendered {
+		return deliverRendered(n, subject, message, events)
+	}
+	return deliver(n, subject, message, events)
+}
+
+// PerEventNotifier 批量通知时每个事件单独投递的通知器（如逐事件执行的外部命令）
+type PerEventNotifier interface {
+	PerEvent() bool
+}
+
+// perEvent 通知器是否逐事件投递
+func perEvent(n Notifier) bool {
+	pn, ok := n.(PerEventNotifier)
+	return ok && pn.PerEvent()
+}
+
+// OutputTester 测试时可返回执行输出的通知器（如外部命令），测试内容使用 locale 语言
+type OutputTester interface {
+	TestWithOutput(locale string) (string, error)
+}
+
+// TestNotifier 按通知器的通知语言测试通知器，支持时一并返回输出
+func (nm *NotificationManager) TestNotifier(n Notifier) (string, error) {
+	locale := nm.localeOf(n)
+	if inst, ok := n.(*InstanceNotifier); ok {
+		n = inst.Notifier
+	}
+	if ot, ok := n.(OutputTester); ok {
+		return ot.TestWithOutput(locale)
+	}
+	return "", n.Test()
+}
+
 // NotificationEvent 通知事件
 type NotificationEvent struct {
-	Type      string    `json:"type"`       // 事件类型
-	Domain    string    `json:"domain"`     // 域名
-	Status    string    `json:"status"`     // 当前状态
-	OldStatus string    `json:"old_status"` // 之前状态
-	Message   string    `json:"message"`    // 消息内容
-	Timestamp time.Time `json:"timestamp"`  // 时间戳
-	WhoisRaw  string    `json:"whois_raw"`  // 原始Whois信息
+	Type       string    `json:"type"`                  // 事件类型
+	Domain     string    `json:"domain"`                // 域名
+	Status     string    `json:"status"`                // 当前状态
+	OldStatus  string    `json:"old_status"`            // 之前状态
+	Message    string    `json:"message"`               // 消息内容
+	Timestamp  time.Time `json:"timestamp"`             // 时间戳
+	WhoisRaw   string    `json:"whois_raw"`             // 原始Whois信息
+	Field      string    `json:"field,omitempty"`       // 变化字段（字段变化事件）
+	OldValue   string    `json:"old_value,omitempty"`   // 字段原值
+	NewValue   string    `json:"new_value,omitempty"`   // 字段新值
+	ErrorClass string    `json:"error_class,omitempty"` // 查询错误分类（连续查询失败的健康告警）
+
+	Info   *core.DomainInfo  `json:"-"`                // 域名详情，供通知模板使用
+	Health *core.HealthEvent `json:"health,omitempty"` // 查询健康告警详情，按通知语言重新排版
 }
 
 // NotificationManager 通知管理器
 type NotificationManager struct {
 	notifiers   []Notifier
-	queue       chan NotificationEvent
+	notifiersMu sync.RWMutex // 保护 notifiers（通知渠道实例可在运行时增删）
 	enabled     bool
 	sentHistory map[string]map[string]time.Time // domain -> status -> last_sent_time
 	mu          sync.RWMutex
 	aggregator  *NotificationAggregator // 通知聚合器
+	router      *router                 // 通知路由规则
+	outbox      *outbox                 // 持久化发件箱
+	templates   *templateStore          // 自定义通知模板
+	bots        *telegramBots           // Telegram 交互机器人
+	digest      *digestScheduler        // 定期摘要
+	escalation  *escalation             // 未确认告警的重复提醒
+	health      *healthAlerts           // 查询健康告警（管理员通道）
+	batching    *batching               // 各通知器的发送方式
+	locales     *locales                // 各通知器的通知语言
 }
 
 // NewNotificationManager 创建通知管理器
 func NewNotificationManager() *NotificationManager {
 	mgr := &NotificationManager{
 		notifiers:   make([]Notifier, 0),
-		queue:       make(chan NotificationEvent, 1000),
 		enabled:     true,
 		sentHistory: make(map[string]map[string]time.Time),
+		router:      newRouter(),
+		outbox:      newOutbox(),
+		templates:   newTemplateStore(),
+		bots:        &telegramBots{bots: make(map[int64]*TelegramBot)},
+		digest:      &digestScheduler{stop: make(chan struct{})},
+		escalation:  &escalation{stop: make(chan struct{})},
+		health:      &healthAlerts{},
+		batching:    &batching{modes: map[string]string{}},
+		locales:     &locales{fallback: i18n.Default, byKey: map[string]string{}},
 	}
 
 	// 创建聚合器
@@ -64,12 +171,60 @@ func NewNotificationManager() *NotificationManager {
 // AddNotifier 添加通知器
 func (nm *NotificationManager) AddNotifier(notifier Notifier) {
 	// 始终添加通知器，启用状态由配置控制
+	nm.notifiersMu.Lock()
+	defer nm.notifiersMu.Unlock()
 	nm.notifiers = append(nm.notifiers, notifier)
 }
 
+// SetInstance 添加或替换通知渠道实例（按 ID 匹配）
+func (nm *NotificationManager) SetInstance(inst *InstanceNotifier) {
+	nm.notifiersMu.Lock()
+	replaced := false
+	for i, notifier := range nm.notifiers {
+		if existing, ok := notifier.(*InstanceNotifier); ok && existing.ID == inst.ID {
+			nm.notifiers[i] = inst
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		nm.notifiers = append(nm.notifiers, inst)
+	}
+	nm.notifiersMu.Unlock()
+
+	nm.syncTelegramBot(inst.ID, inst)
+	nm.syncMQTTInstance(inst)
+}
+
+// RemoveInstance 移除通知渠道实例
+func (nm *NotificationManager) RemoveInstance(id int64) {
+	nm.notifiersMu.Lock()
+	for i, notifier := range nm.notifiers {
+		if existing, ok := notifier.(*InstanceNotifier); ok && existing.ID == id {
+			nm.notifiers = append(nm.notifiers[:i], nm.notifiers[i+1:]...)
+			break
+		}
+	}
+	nm.notifiersMu.Unlock()
+
+	nm.syncTelegramBot(id, nil)
+}
+
+// GetInstance 获取通知渠道实例，不存在时返回 nil
+func (nm *NotificationManager) GetInstance(id int64) *InstanceNotifier {
+	for _, notifier := range nm.GetNotifiers() {
+		if inst, ok := notifier.(*InstanceNotifier); ok && inst.ID == id {
+			return inst
+		}
+	}
+	return nil
+}
+
 // Start 启动通知管理器
 func (nm *NotificationManager) Start() {
-	go nm.processNotifications()
+	go nm.runOutbox()
+	go nm.runDigest()
+	go nm.runEscalation()
 	// 启动聚合器
 	if nm.aggregator != nil {
 		nm.aggregator.Start()
@@ -85,7 +240,10 @@ func (nm *NotificationManager) Stop() {
 		nm.aggregator.Stop()
 	}
 
-	close(nm.queue)
+	close(nm.outbox.stop)
+	close(nm.digest.stop)
+	close(nm.escalation.stop)
+	nm.stopTelegramBots()
 }
 
 // SendNotification 发送通知（通过聚合器）
@@ -110,15 +268,10 @@ func (nm *NotificationManager) SendNotificationDirect(event NotificationEvent) {
 		return
 	}
 
-	select {
-	case nm.queue <- event:
-		// 成功加入队列
-		if event.Type == "status_change" {
-			nm.recordNotification(event.Domain, event.Status)
-		}
-	default:
-		// 队列满了，丢弃消息
-		log.Printf("通知队列已满，丢弃通知: %s", event.Domain)
+	// 写入持久化发件箱，由投递协程异步发送
+	nm.sendToAllNotifiers(event)
+	if event.Type == "status_change" {
+		nm.recordNotification(event.Domain, event.Status)
 	}
 }
 
@@ -128,26 +281,9 @@ func (nm *NotificationManager) SendNotificationDirectBatch(events []Notification
 		return
 	}
 
-	// 构建批量通知的主题和消息
-	subject := nm.formatBatchSubject(events)
-	message := nm.formatBatchMessage(events)
-
-	// 发送给所有通知器
-	for _, notifier := range nm.notifiers {
-		if notifier.IsEnabled() {
-			go func(n Notifier) {
-				if err := n.SendMessage(subject, message); err != nil {
-					// 记录错误日志
-					if err != nil && strings.Contains(err.Error(), "short response") {
-					} else {
-						log.Printf("[错误] 发送 %s 批量通知失败: %v", n.GetType(), err)
-					}
-				} else {
-					log.Printf("[成功] %s 批量通知发送成功，包含 %d 个域名", n.GetType(), len(events))
-				}
-			}(notifier)
-		}
-	}
+	receivers, routed := nm.routeEvents(events)
+	// 匹配重复提醒策略的状态变化需要确认
+	nm.deliverRouted(receivers, routed, nm.trackAlerts(events))
 
 	// 记录所有域名的通知历史
 	for _, event := range events {
@@ -155,6 +291,40 @@ func (nm *NotificationManager) SendNotificationDirectBatch(events []Notification
 	}
 }
 
+// routeEvents 按路由规则计算每个通知器应接收的事件
+func (nm *NotificationManager) routeEvents(events []NotificationEvent) ([]Notifier, map[Notifier][]NotificationEvent) {
+	var receivers []Notifier
+	routed := make(map[Notifier][]NotificationEvent)
+	for _, event := range events {
+		for _, notifier := range nm.RouteEvent(event) {
+			if _, ok := routed[notifier]; !ok {
+				receivers = append(receivers, notifier)
+			}
+			routed[notifier] = append(routed[notifier], event)
+		}
+	}
+	return receivers, routed
+}
+
+// deliverRouted 为各通知器按其通知语言写入发件箱（只收到一个事件时按单条通知发送）
+func (nm *NotificationManager) deliverRouted(receivers []Notifier, routed map[Notifier][]NotificationEvent, alerts map[string]*storage.Alert) {
+	for _, notifier := range receivers {
+		subset := routed[notifier]
+		locale := nm.localeOf(notifier)
+		var subject, message string
+		if len(subset) == 1 {
+			subject = nm.formatSubject(locale, subset[0])
+			message = nm.formatMessage(locale, subset[0])
+		} else {
+			subject = nm.formatBatchSubject(locale, subset)
+			message = nm.formatBatchMessage(locale, subset)
+		}
+		message += nm.alertFooter(locale, subset, alerts)
+
+		nm.enqueue([]Notifier{notifier}, subject, message, subset)
+	}
+}
+
 // RecordDomainQuery 记录域名开始查询
 func (nm *NotificationManager) RecordDomainQuery(domain string) {
 	if nm.aggregator != nil {
@@ -214,87 +384,69 @@ func (nm *NotificationManager) ClearHistory() {
 	log.Printf("清理过期通知历史，当前跟踪 %d 个域名", len(nm.sentHistory))
 }
 
-// processNotifications 处理通知队列
-func (nm *NotificationManager) processNotifications() {
-	for event := range nm.queue {
-		nm.sendToAllNotifiers(event)
-	}
-}
-
 // sendToAllNotifiers 发送给所有通知器
 func (nm *NotificationManager) sendToAllNotifiers(event NotificationEvent) {
-	subject := nm.formatSubject(event)
-	message := nm.formatMessage(event)
-
-	for _, notifier := range nm.notifiers {
-		if notifier.IsEnabled() {
-			go func(n Notifier) {
-				if err := n.SendMessage(subject, message); err != nil {
-					// 记录错误日志
-					if err != nil && strings.Contains(err.Error(), "short response") {
-					} else {
-						log.Printf("[错误] 发送 %s 通知失败: %v", n.GetType(), err)
-					}
-					log.Printf("[错误] 通知内容: 域名=%s, 事件类型=%s", event.Domain, event.Type)
-				} else {
-					log.Printf("[成功] %s 通知发送成功: 域名=%s", n.GetType(), event.Domain)
-				}
-			}(notifier)
-		}
-	}
+	// 按路由规则写入发件箱（未命中规则时发送给全部已启用的通知器）
+	receivers := nm.RouteEvent(event)
+	nm.deliverRouted(receivers, routeAll(receivers, event), nm.trackAlerts([]NotificationEvent{event}))
 }
 
 // formatSubject 格式化主题
-func (nm *NotificationManager) formatSubject(event NotificationEvent) string {
+func (nm *NotificationManager) formatSubject(locale string, event NotificationEvent) string {
+	if event.Health != nil {
+		subject, _ := healthText(locale, *event.Health)
+		return subject
+	}
 	switch event.Type {
-	case "status_change":
-		return fmt.Sprintf("%s 状态变化", event.Domain)
-	case "available":
-		return fmt.Sprintf("%s 可注册！", event.Domain)
-	case "redemption":
-		return fmt.Sprintf("%s 进入赎回期", event.Domain)
-	case "pending_delete":
-		return fmt.Sprintf("%s 进入待删除期", event.Domain)
-	case "error":
-		return fmt.Sprintf("%s 查询失败", event.Domain)
+	case "status_change", "available", "redemption", "pending_delete", "error", "expiry_reminder":
+		return i18n.T(locale, "notify.subject."+event.Type, event.Domain)
+	case core.ChangeRegistrar, core.ChangeNameServers, core.ChangeExpiry, core.ChangeEPPStatus:
+		return i18n.T(locale, "notify.subject.field_change", event.Domain, core.GetFieldLabelFor(locale, event.Type))
 	default:
-		return fmt.Sprintf("%s 通知", event.Domain)
+		return i18n.T(locale, "notify.subject.default", event.Domain)
 	}
 }
 
-// formatMessage 格式化消息
-func (nm *NotificationManager) formatMessage(event NotificationEvent) string {
+// formatMessage 格式化消息（字段标签使用指定语言，各渠道据此解析）
+func (nm *NotificationManager) formatMessage(locale string, event NotificationEvent) string {
+	if event.Health != nil {
+		_, message := healthText(locale, *event.Health)
+		return message + "\n\n---\n" + i18n.T(locale, "notify.footer")
+	}
+
 	var message strings.Builder
 
-	message.WriteString(fmt.Sprintf("域名: %s\n", event.Domain))
-	message.WriteString(fmt.Sprintf("时间: %s\n", event.Timestamp.Format("2006-01-02 15:04:05")))
+	message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "domain"), event.Domain))
+	message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "time"), event.Timestamp.Format("2006-01-02 15:04:05")))
 
 	switch event.Type {
 	case "status_change":
-		message.WriteString(fmt.Sprintf("状态变化: %s → %s\n", event.OldStatus, event.Status))
-	case "available":
-		message.WriteString("状态: 可注册\n")
-		message.WriteString("此域名现在可以注册！\n")
-	case "redemption":
-		message.WriteString("状态: 赎回期\n")
-		message.WriteString("此域名现在处于赎回期，可以尝试赎回。\n")
-	case "pending_delete":
-		message.WriteString("状态: 待删除\n")
-		message.WriteString("此域名即将删除，进入抢注阶段！\n")
+		message.WriteString(fmt.Sprintf("%s: %s → %s\n", label(locale, "status_change"), event.OldStatus, event.Status))
+	case "available", "redemption", "pending_delete":
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "status"), translateStatus(locale, event.Type)))
+		message.WriteString(i18n.T(locale, "notify.hint."+event.Type) + "\n")
 	case "error":
-		message.WriteString("状态: 查询失败\n")
-		message.WriteString(fmt.Sprintf("错误信息: %s\n", event.Message))
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "status"), translateStatus(locale, "error")))
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "error"), event.Message))
+	case core.ChangeRegistrar, core.ChangeNameServers, core.ChangeExpiry, core.ChangeEPPStatus:
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "status"),
+			i18n.T(locale, "notify.status.field_change", core.GetFieldLabelFor(locale, event.Type))))
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "old_value"), event.OldValue))
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "new_value"), event.NewValue))
+	case "expiry_reminder":
+		message.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "status"), i18n.T(locale, "notify.status.expiring")))
+		message.WriteString(i18n.T(locale, "notify.hint.expiry_reminder") + "\n")
 	}
 
-	if event.Message != "" && event.Type != "error" {
-		message.WriteString(fmt.Sprintf("\n详细信息: %s\n", event.Message))
+	if detail := eventDetail(locale, event); detail != "" && event.Type != "error" {
+		message.WriteString(fmt.Sprintf("\n%s: %s\n", label(locale, "detail"), detail))
 	}
 
 	if event.WhoisRaw != "" {
-		message.WriteString("\n=== WHOIS/RDAP 信息 ===\n")
+		message.WriteString("\n" + i18n.T(locale, "notify.whois_header") + "\n")
 		// 限制长度以免消息过长
 		if len(event.WhoisRaw) > 2000 {
-			message.WriteString(event.WhoisRaw[:2000] + "\n...(已截断)")
+			message.WriteString(event.WhoisRaw[:2000] + "\n" + i18n.T(locale, "notify.truncated"))
 		} else {
 			message.WriteString(event.WhoisRaw)
 		}
@@ -302,34 +454,59 @@ func (nm *NotificationManager) formatMessage(event NotificationEvent) string {
 	}
 
 	message.WriteString("\n---\n")
-	message.WriteString("此消息由 Puff 自动发送")
+	message.WriteString(i18n.T(locale, "notify.footer"))
 
 	return message.String()
 }
 
+// eventDetail 事件的详细信息：可由事件字段重新生成的消息按指定语言生成，其余使用原消息
+func eventDetail(locale string, event NotificationEvent) string {
+	if event.Message == "" {
+		return ""
+	}
+	switch event.Type {
+	case "status_change":
+		if event.OldStatus != "" && event.Status != "" {
+			return core.GetStatusChangeMessageFor(locale, event.Domain, core.DomainStatus(event.OldStatus), core.DomainStatus(event.Status))
+		}
+	case core.ChangeRegistrar, core.ChangeNameServers, core.ChangeExpiry, core.ChangeEPPStatus:
+		return core.GetFieldChangeMessageFor(locale, event.Domain, event.Type, event.OldValue, event.NewValue)
+	case "expiry_reminder":
+		if event.Info != nil && event.Info.ExpiryDate != nil {
+			return core.GetExpiryReminderMessage(locale, event.Domain, *event.Info.ExpiryDate, event.Timestamp)
+		}
+	}
+	return event.Message
+}
+
 // formatBatchSubject 格式化批量通知主题
-func (nm *NotificationManager) formatBatchSubject(events []NotificationEvent) string {
-	return fmt.Sprintf("域名状态变化通知 (%d个域名)", len(events))
+func (nm *NotificationManager) formatBatchSubject(locale string, events []NotificationEvent) string {
+	return i18n.T(locale, "notify.subject.batch", len(events))
 }
 
 // formatBatchMessage 格式化批量通知消息
-func (nm *NotificationManager) formatBatchMessage(events []NotificationEvent) string {
+func (nm *NotificationManager) formatBatchMessage(locale string, events []NotificationEvent) string {
 	var message strings.Builder
 
-	message.WriteString(fmt.Sprintf("检测到 %d 个域名状态发生变化\n", len(events)))
-	message.WriteString(fmt.Sprintf("时间: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+	message.WriteString(i18n.T(locale, "notify.batch_header", len(events)) + "\n")
+	message.WriteString(fmt.Sprintf("%s: %s\n\n", label(locale, "time"), time.Now().Format("2006-01-02 15:04:05")))
 
 	// 列出所有域名的状态变化
 	for i, event := range events {
-		message.WriteString(fmt.Sprintf("%d. %s\n", i+1, event.Domain))
-		message.WriteString(fmt.Sprintf("   状态变化: %s → %s\n", event.OldStatus, event.Status))
+		if event.Health != nil {
+			subject, _ := healthText(locale, *event.Health)
+			message.WriteString(fmt.Sprintf("%d. %s\n", i+1, subject))
+		} else {
+			message.WriteString(fmt.Sprintf("%d. %s\n", i+1, event.Domain))
+			message.WriteString(fmt.Sprintf("   %s: %s → %s\n", label(locale, "status_change"), event.OldStatus, event.Status))
+		}
 		if i < len(events)-1 {
 			message.WriteString("\n")
 		}
 	}
 
 	message.WriteString("\n---\n")
-	message.WriteString("此消息由 Puff 自动发送")
+	message.WriteString(i18n.T(locale, "notify.footer"))
 
 	return message.String()
 }
@@ -338,8 +515,8 @@ func (nm *NotificationManager) formatBatchMessage(events []NotificationEvent) st
 func (nm *NotificationManager) TestAllNotifiers() map[string]error {
 	results := make(map[string]error)
 
-	for _, notifier := range nm.notifiers {
-		results[notifier.GetType()] = notifier.Test()
+	for _, notifier := range nm.GetNotifiers() {
+		results[notifierLabel(notifier)] = notifier.Test()
 	}
 
 	return results
@@ -349,9 +526,9 @@ func (nm *NotificationManager) TestAllNotifiers() map[string]error {
 func (nm *NotificationManager) GetEnabledNotifiers() []string {
 	var enabled []string
 
-	for _, notifier := range nm.notifiers {
+	for _, notifier := range nm.GetNotifiers() {
 		if notifier.IsEnabled() {
-			enabled = append(enabled, notifier.GetType())
+			enabled = append(enabled, notifierLabel(notifier))
 		}
 	}
 
@@ -360,42 +537,61 @@ func (nm *NotificationManager) GetEnabledNotifiers() []string {
 
 // GetNotifiers 获取所有通知器
 func (nm *NotificationManager) GetNotifiers() []Notifier {
-	return nm.notifiers
+	nm.notifiersMu.RLock()
+	defer nm.notifiersMu.RUnlock()
+
+	notifiers := make([]Notifier, len(nm.notifiers))
+	copy(notifiers, nm.notifiers)
+	return notifiers
 }
 
-// UpdateEmailConfig 更新邮件通知器配置
-func (nm *NotificationManager) UpdateEmailConfig(cfg config.SMTPConfig) error {
-	for _, notifier := range nm.notifiers {
-		if notifier.GetType() == "email" {
-			if emailNotifier, ok := notifier.(*EmailNotifier); ok {
-				emailNotifier.UpdateConfig(cfg)
+// UpdateWebhookConfig 更新Webhook通知器配置
+func (nm *NotificationManager) UpdateWebhookConfig(cfg config.WebhookConfig) error {
+	for _, notifier := range nm.GetNotifiers() {
+		if notifier.GetType() == "webhook" {
+			if webhookNotifier, ok := notifier.(*WebhookNotifier); ok {
+				webhookNotifier.UpdateConfig(cfg)
 				return nil
 			}
 		}
 	}
-	return fmt.Errorf("未找到邮件通知器")
+	return fmt.Errorf("未找到Webhook通知器")
 }
 
-// UpdateTelegramConfig 更新Telegram通知器配置
-func (nm *NotificationManager) UpdateTelegramConfig(cfg config.TelegramConfig) error {
-	for _, notifier := range nm.notifiers {
-		if notifier.GetType() == "telegram" {
-			if telegramNotifier, ok := notifier.(*TelegramNotifier); ok {
-				telegramNotifier.UpdateConfig(cfg)
-				return nil
-			}
+// UpdateRobotConfig 更新群机器人通知器配置（dingtalk、feishu、wecom）
+func (nm *NotificationManager) UpdateRobotConfig(notifierType string, cfg config.RobotConfig) error {
+	for _, notifier := range nm.GetNotifiers() {
+		if notifier.GetType() != notifierType {
+			continue
+		}
+		if robot, ok := notifier.(interface{ UpdateConfig(config.RobotConfig) }); ok {
+			robot.UpdateConfig(cfg)
+			return nil
+		}
+	}
+	return fmt.Errorf("未找到%s通知器", notifierType)
+}
+
+// UpdatePushConfig 更新手机推送通知器配置（bark、ntfy、gotify、serverchan）
+func (nm *NotificationManager) UpdatePushConfig(notifierType string, cfg config.PushConfig) error {
+	for _, notifier := range nm.GetNotifiers() {
+		if notifier.GetType() != notifierType {
+			continue
+		}
+		if push, ok := notifier.(interface{ UpdateConfig(config.PushConfig) }); ok {
+			push.UpdateConfig(cfg)
+			return nil
 		}
 	}
-	return fmt.Errorf("未找到Telegram通知器")
+	return fmt.Errorf("未找到%s通知器", notifierType)
 }
 
 // GetStats 获取统计信息
 func (nm *NotificationManager) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"enabled":           nm.enabled,
-		"notifier_count":    len(nm.notifiers),
-		"queue_length":      len(nm.queue),
-		"queue_capacity":    cap(nm.queue),
+		"notifier_count":    len(nm.GetNotifiers()),
+		"outbox":            nm.OutboxStats(),
 		"enabled_notifiers": nm.GetEnabledNotifiers(),
 	}
 }
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"fmt"
+	"strconv"
+
+	"Puff/config"
+)
+
+// ntfyDefaultServer ntfy 官方服务地址
+const ntfyDefaultServer = "https://ntfy.sh"
+
+// ntfyPriorities ntfy 默认优先级映射（1-5）
+var ntfyPriorities = map[Priority]string{
+	PriorityCritical: "5",
+	PriorityHigh:     "4",
+	PriorityNormal:   "3",
+	PriorityLow:      "2",
+}
+
+// NtfyNotifier ntfy 推送通知器（Token 为主题名）
+type NtfyNotifier struct {
+	pushBase
+}
+
+// NewNtfyNotifier 创建 ntfy 通知器
+func NewNtfyNotifier(cfg config.PushConfig) *NtfyNotifier {
+	return &NtfyNotifier{pushBase: newPushBase(cfg, ntfyPriorities)}
+}
+
+// SendMessage 发送 ntfy 推送
+func (n *NtfyNotifier) SendMessage(subject, message string) error {
+	return n.SendEvents(subject, message, nil)
+}
+
+// SendEvents 按事件优先级发送 ntfy 推送（JSON 发布接口）
+func (n *NtfyNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if err := n.validate("ntfy"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"topic":   n.config.Token,
+		"title":   subject,
+		"message": formatPushBody(message),
+		"tags":    []string{"globe_with_meridians"},
+	}
+	if level := n.level(eventsPriority(events)); level != "" {
+		priority, err := strconv.Atoi(level)
+		if err != nil || priority < 1 || priority > 5 {
+			return fmt.Errorf("ntfy优先级必须为1-5: %s", level)
+		}
+		payload["priority"] = priority
+	}
+
+	var headers map[string]string
+	if n.config.AccessToken != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.config.AccessToken}
+	}
+
+	if err := postJSON(n.httpClient, n.server(ntfyDefaultServer), headers, payload, nil); err != nil {
+		return fmt.Errorf("发送ntfy推送失败: %v", err)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (n *NtfyNotifier) GetType() string {
+	return "ntfy"
+}
+
+// Test 发送测试消息
+func (n *NtfyNotifier) Test() error {
+	return n.SendEvents("ntfy推送测试", pushTestMessage("ntfy"), []NotificationEvent{pushTestEvent()})
+}
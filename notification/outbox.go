@@ -0,0 +1,292 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	outboxPollInterval  = 5 * time.Second    // 轮询到期记录的间隔
+	outboxLease         = 2 * time.Minute    // 领取后的租约，进程崩溃时到期重新投递
+	outboxBatchSize     = 50                 // 每轮最多领取的记录数
+	outboxRetention     = 7 * 24 * time.Hour // 已投递记录保留时间
+	outboxBaseBackoff   = 30 * time.Second   // 首次重试间隔
+	outboxMaxBackoff    = time.Hour          // 最大重试间隔
+	defaultMaxAttempts  = 5                  // 默认最大投递次数
+	outboxShortResponse = "short response"   // 部分SMTP服务器发送成功后返回的不完整响应
+)
+
+// outbox 持久化发件箱的运行状态
+type outbox struct {
+	wake        chan struct{}
+	stop        chan struct{}
+	mu          sync.RWMutex
+	maxAttempts int
+}
+
+// newOutbox 创建发件箱
+func newOutbox() *outbox {
+	return &outbox{
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		maxAttempts: defaultMaxAttempts,
+	}
+}
+
+// SetMaxAttempts 设置单个通知器的最大投递次数
+func (nm *NotificationManager) SetMaxAttempts(n int) {
+	if n <= 0 {
+		n = defaultMaxAttempts
+	}
+	nm.outbox.mu.Lock()
+	nm.outbox.maxAttempts = n
+	nm.outbox.mu.Unlock()
+}
+
+// maxAttempts 获取最大投递次数
+func (nm *NotificationManager) maxAttempts() int {
+	nm.outbox.mu.RLock()
+	defer nm.outbox.mu.RUnlock()
+	return nm.outbox.maxAttempts
+}
+
+// WakeOutbox 立即触发一轮投递
+func (nm *NotificationManager) WakeOutbox() {
+	select {
+	case nm.outbox.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue 为每个接收通知器写入一条发件箱记录；逐事件投递的通知器每个事件一条，失败时只重试该事件；写入失败时退回直接发送
+func (nm *NotificationManager) enqueue(receivers []Notifier, subject, message string, events []NotificationEvent) {
+	if len(receivers) == 0 {
+		return
+	}
+
+	// 按通知器渲染自定义模板
+	var targets []Notifier
+	var entries []storage.OutboxEntry
+	add := func(n Notifier, subject, message string, events []NotificationEvent) {
+		s, m, rendered := nm.renderFor(n, subject, message, events)
+		targets = append(targets, n)
+		entries = append(entries, storage.OutboxEntry{
+			NotifierKey: NotifierKey(n),
+			Subject:     s,
+			Message:     m,
+			Events:      eventsJSON(events),
+			Rendered:    rendered,
+		})
+	}
+	for _, n := range receivers {
+		if len(events) > 1 && perEvent(n) {
+			for _, event := range events {
+				add(n, subject, event.Message, []NotificationEvent{event})
+			}
+			continue
+		}
+		add(n, subject, message, events)
+	}
+
+	nm.enqueueEntries(targets, entries)
+}
+
+// eventsJSON 序列化发件箱记录中的结构化事件
+func eventsJSON(events []NotificationEvent) string {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return "[]"
+	}
+	return string(payload)
+}
+
+// enqueueEntries 写入已排版的发件箱记录（与 receivers 一一对应）；写入失败时退回直接发送
+func (nm *NotificationManager) enqueueEntries(receivers []Notifier, entries []storage.OutboxEntry) {
+	if err := storage.EnqueueOutbox(entries); err != nil {
+		logger.Error("写入通知发件箱失败，改为直接发送: %v", err)
+		for i, n := range receivers {
+			go func(n Notifier, entry storage.OutboxEntry) {
+				var events []NotificationEvent
+				json.Unmarshal([]byte(entry.Events), &events)
+				if err := sendEntry(n, entry, events); err != nil && !strings.Contains(err.Error(), outboxShortResponse) {
+					logger.Error("发送 %s 通知失败: %v", notifierLabel(n), err)
+				}
+			}(n, entries[i])
+		}
+		return
+	}
+
+	nm.WakeOutbox()
+}
+
+// runOutbox 投递循环：定时或被唤醒时投递到期记录，并定期清理已投递记录
+func (nm *NotificationManager) runOutbox() {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	nm.dispatchOutbox()
+	for {
+		select {
+		case <-nm.outbox.stop:
+			return
+		case <-purge.C:
+			if n, err := storage.PurgeSentOutbox(time.Now().Add(-outboxRetention)); err != nil {
+				logger.Error("清理通知发件箱失败: %v", err)
+			} else if n > 0 {
+				logger.Info("已清理 %d 条已投递通知", n)
+			}
+			continue
+		case <-nm.outbox.wake:
+		case <-ticker.C:
+		}
+		nm.dispatchOutbox()
+	}
+}
+
+// dispatchOutbox 领取到期记录并并发投递
+func (nm *NotificationManager) dispatchOutbox() {
+	entries, err := storage.ClaimDueOutbox(time.Now(), outboxLease, outboxBatchSize)
+	if err != nil {
+		logger.Error("读取通知发件箱失败: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry storage.OutboxEntry) {
+			defer wg.Done()
+			nm.deliverEntry(entry)
+		}(entry)
+	}
+	wg.Wait()
+
+	// 本轮已满，可能还有积压
+	if len(entries) == outboxBatchSize {
+		nm.WakeOutbox()
+	}
+}
+
+// deliverEntry 投递单条记录并更新状态
+func (nm *NotificationManager) deliverEntry(entry storage.OutboxEntry) {
+	attempts := entry.Attempts + 1
+
+	var events []NotificationEvent
+	if err := json.Unmarshal([]byte(entry.Events), &events); err != nil {
+		events = nil
+	}
+
+	var err error
+	start := time.Now()
+	notifier := nm.notifierByKey(entry.NotifierKey)
+	switch {
+	case notifier == nil:
+		err = fmt.Errorf("通知器 %s 不存在", entry.NotifierKey)
+	case !notifier.IsEnabled():
+		err = fmt.Errorf("通知器 %s 未启用", notifierLabel(notifier))
+	default:
+		err = sendEntry(notifier, entry, events)
+		if err != nil && strings.Contains(err.Error(), outboxShortResponse) {
+			err = nil
+		}
+	}
+	recordDelivery(entry, events, attempts, time.Since(start), err)
+
+	if err == nil {
+		if err := storage.MarkOutboxSent(entry.ID, attempts); err != nil {
+			logger.Error("%v", err)
+		}
+		logger.Info("[成功] %s 通知发送成功: %s", notifierLabel(notifier), entry.Subject)
+		return
+	}
+
+	var next time.Time
+	if attempts < nm.maxAttempts() {
+		next = time.Now().Add(outboxBackoff(attempts))
+		logger.Warn("[重试] %s 第 %d 次发送失败，%s 后重试: %v", entry.NotifierKey, attempts, outboxBackoff(attempts), err)
+	} else {
+		logger.Error("[死信] %s 发送失败 %d 次，已放弃: %v", entry.NotifierKey, attempts, err)
+	}
+	if err := storage.MarkOutboxFailed(entry.ID, attempts, err.Error(), next); err != nil {
+		logger.Error("%v", err)
+	}
+}
+
+// recordDelivery 追加投递日志
+func recordDelivery(entry storage.OutboxEntry, events []NotificationEvent, attempt int, latency time.Duration, sendErr error) {
+	delivery := storage.NotificationDelivery{
+		OutboxID:  entry.ID,
+		Channel:   entry.NotifierKey,
+		Subject:   entry.Subject,
+		Message:   entry.Message,
+		Success:   sendErr == nil,
+		Attempt:   attempt,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+	for _, event := range events {
+		if delivery.EventType == "" {
+			delivery.EventType = event.Type
+		}
+		delivery.Domains = append(delivery.Domains, event.Domain)
+	}
+
+	if err := storage.RecordDelivery(delivery); err != nil {
+		logger.Error("%v", err)
+	}
+}
+
+// sendEntry 按记录发送：模板渲染的正文跳过内置排版，分段发送的通知器从已记录的进度继续
+func sendEntry(n Notifier, entry storage.OutboxEntry, events []NotificationEvent) error {
+	resume := Resume{Done: entry.Progress}
+	if entry.ID > 0 {
+		resume.Sent = func(done int) {
+			if err := storage.SetOutboxProgress(entry.ID, done); err != nil {
+				logger.Error("%v", err)
+			}
+		}
+	}
+	return deliverFrom(n, entry.Subject, entry.Message, events, entry.Rendered, resume)
+}
+
+// outboxBackoff 第 n 次失败后的重试间隔（指数退避）
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay
+}
+
+// notifierByKey 按路由标识查找通知器
+func (nm *NotificationManager) notifierByKey(key string) Notifier {
+	for _, n := range nm.GetNotifiers() {
+		if NotifierKey(n) == key {
+			return n
+		}
+	}
+	return nil
+}
+
+// OutboxStats 发件箱各状态的记录数
+func (nm *NotificationManager) OutboxStats() map[string]int {
+	counts, err := storage.CountOutboxByStatus()
+	if err != nil {
+		logger.Error("统计通知发件箱失败: %v", err)
+		return map[string]int{}
+	}
+	return counts
+}
@@ -0,0 +1,182 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"Puff/storage"
+)
+
+// flakyNotifier 前 failures 次发送失败的测试通知器
+type flakyNotifier struct {
+	notifierType string
+	err          error
+
+	mu       sync.Mutex
+	failures int
+	sent     int
+}
+
+func (f *flakyNotifier) SendMessage(subject, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return f.err
+	}
+	f.sent++
+	return nil
+}
+
+func (f *flakyNotifier) IsEnabled() bool { return true }
+func (f *flakyNotifier) GetType() string { return f.notifierType }
+func (f *flakyNotifier) Test() error     { return nil }
+
+// outboxEntry 按 ID 读取发件箱记录
+func outboxEntry(t *testing.T, id int64) storage.OutboxEntry {
+	t.Helper()
+	entries, _, err := storage.ListOutbox("", 1000, 0)
+	if err != nil {
+		t.Fatalf("读取发件箱失败: %v", err)
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	t.Fatalf("发件箱记录 %d 不存在", id)
+	return storage.OutboxEntry{}
+}
+
+// enqueueTestEntry 写入一条指向 key 的待投递记录
+func enqueueTestEntry(t *testing.T, key, domain string) storage.OutboxEntry {
+	t.Helper()
+	entries := []storage.OutboxEntry{{
+		NotifierKey: key,
+		Subject:     "主题",
+		Message:     "域名: " + domain,
+		Events:      fmt.Sprintf(`[{"type":"status_change","domain":%q,"status":"available"}]`, domain),
+	}}
+	if err := storage.EnqueueOutbox(entries); err != nil {
+		t.Fatalf("写入发件箱失败: %v", err)
+	}
+	return outboxEntry(t, entries[0].ID)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{30, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, 期望 %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxRetryDeadLetterReplay(t *testing.T) {
+	flaky := &flakyNotifier{notifierType: "outbox-flaky", err: errors.New("connection refused"), failures: 2}
+	nm := NewNotificationManager()
+	nm.AddNotifier(flaky)
+	nm.SetMaxAttempts(2)
+
+	entry := enqueueTestEntry(t, "outbox-flaky", "retry.com")
+
+	// 第一次失败：保持待投递并按退避推迟
+	start := time.Now()
+	nm.deliverEntry(entry)
+	entry = outboxEntry(t, entry.ID)
+	if entry.Status != storage.OutboxPending || entry.Attempts != 1 || entry.LastError != "connection refused" {
+		t.Fatalf("首次失败后状态错误: %+v", entry)
+	}
+	if entry.NextAttemptAt.Before(start.Add(outboxBaseBackoff - time.Second)) {
+		t.Errorf("下次投递时间应推迟约 %v，实际 %v", outboxBaseBackoff, entry.NextAttemptAt.Sub(start))
+	}
+
+	// 达到最大次数：进入死信
+	nm.deliverEntry(entry)
+	entry = outboxEntry(t, entry.ID)
+	if entry.Status != storage.OutboxDead || entry.Attempts != 2 {
+		t.Fatalf("达到最大次数后应进入死信: %+v", entry)
+	}
+
+	// 重放后清零次数并重新投递
+	if n, err := storage.ReplayOutbox([]int64{entry.ID}); err != nil || n != 1 {
+		t.Fatalf("重放死信失败: %d %v", n, err)
+	}
+	entry = outboxEntry(t, entry.ID)
+	if entry.Status != storage.OutboxPending || entry.Attempts != 0 {
+		t.Fatalf("重放后状态错误: %+v", entry)
+	}
+	nm.deliverEntry(entry)
+	entry = outboxEntry(t, entry.ID)
+	if entry.Status != storage.OutboxSent || entry.Attempts != 1 || entry.SentAt == nil || flaky.sent != 1 {
+		t.Fatalf("重放后应投递成功: %+v (已发送 %d 次)", entry, flaky.sent)
+	}
+
+	// 每次尝试都记录投递日志
+	deliveries, total, err := storage.ListDeliveries(storage.DeliveryFilter{Channel: "outbox-flaky"}, 10, 0)
+	if err != nil {
+		t.Fatalf("读取投递日志失败: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("应记录 3 次投递，实际 %d", total)
+	}
+	for _, d := range deliveries {
+		if d.OutboxID != entry.ID || d.EventType != "status_change" || len(d.Domains) != 1 || d.Domains[0] != "retry.com" {
+			t.Errorf("投递日志错误: %+v", d)
+		}
+	}
+}
+
+func TestOutboxDeliveryFailures(t *testing.T) {
+	nm := NewNotificationManager()
+	nm.AddNotifier(&flakyNotifier{notifierType: "outbox-short", err: errors.New("short response: 250"), failures: 1})
+	nm.SetMaxAttempts(1)
+
+	// 发送成功后返回不完整响应的 SMTP 服务器视为成功
+	entry := enqueueTestEntry(t, "outbox-short", "short.com")
+	nm.deliverEntry(entry)
+	if entry = outboxEntry(t, entry.ID); entry.Status != storage.OutboxSent {
+		t.Errorf("不完整响应应视为投递成功: %+v", entry)
+	}
+
+	// 通知器已删除：达到最大次数后直接进入死信
+	entry = enqueueTestEntry(t, "outbox-missing", "missing.com")
+	nm.deliverEntry(entry)
+	if entry = outboxEntry(t, entry.ID); entry.Status != storage.OutboxDead || entry.LastError == "" {
+		t.Errorf("通知器不存在时应进入死信: %+v", entry)
+	}
+}
+
+func TestDispatchOutboxClaimsDueEntries(t *testing.T) {
+	recorder := &recordingNotifier{notifierType: "outbox-dispatch"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(recorder)
+
+	entry := enqueueTestEntry(t, "outbox-dispatch", "due.com")
+	nm.dispatchOutbox()
+	if subjects, _ := recorder.received(); len(subjects) != 1 {
+		t.Fatalf("到期记录应被投递一次，实际 %d 次", len(subjects))
+	}
+	if entry = outboxEntry(t, entry.ID); entry.Status != storage.OutboxSent {
+		t.Errorf("投递后状态错误: %+v", entry)
+	}
+
+	// 已投递的记录不再被领取
+	nm.dispatchOutbox()
+	if subjects, _ := recorder.received(); len(subjects) != 1 {
+		t.Errorf("已投递记录被重复发送: %d 次", len(subjects))
+	}
+}
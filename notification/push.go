@@ -0,0 +1,199 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Puff/config"
+)
+
+// Priority 通知优先级（与推送平台无关）
+type Priority string
+
+const (
+	PriorityLow      Priority = "low"
+	PriorityNormal   Priority = "normal"
+	PriorityHigh     Priority = "high"
+	PriorityCritical Priority = "critical"
+)
+
+// priorityRank 优先级排序
+var priorityRank = map[Priority]int{
+	PriorityLow:      0,
+	PriorityNormal:   1,
+	PriorityHigh:     2,
+	PriorityCritical: 3,
+}
+
+// statusPriority 域名状态对应的优先级
+func statusPriority(status string) Priority {
+	switch status {
+	case "available":
+		return PriorityCritical
+	case "pending_delete", "redemption":
+		return PriorityHigh
+	case "error", "unknown":
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// EventPriority 获取事件优先级：可注册为紧急，待删除/赎回期与续费提醒为高，字段变化为普通，查询失败为低
+func EventPriority(event NotificationEvent) Priority {
+	switch event.Type {
+	case "status_change":
+		return statusPriority(event.Status)
+	case "available", "pending_delete", "redemption", "error":
+		return statusPriority(event.Type)
+	case "expiry_reminder":
+		return PriorityHigh
+	default:
+		return PriorityNormal
+	}
+}
+
+// eventsPriority 多个事件取最高优先级
+func eventsPriority(events []NotificationEvent) Priority {
+	if len(events) == 0 {
+		return PriorityNormal
+	}
+	highest := PriorityLow
+	for _, e := range events {
+		if p := EventPriority(e); priorityRank[p] > priorityRank[highest] {
+			highest = p
+		}
+	}
+	return highest
+}
+
+// ParsePriorityMap 解析优先级映射，格式为 "critical=5,high=4,normal=3,low=2"
+func ParsePriorityMap(text string) (map[Priority]string, error) {
+	mapping := make(map[Priority]string)
+	for _, item := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' }) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		p := Priority(strings.ToLower(strings.TrimSpace(key)))
+		if _, valid := priorityRank[p]; !ok || !valid {
+			return nil, fmt.Errorf("优先级映射格式无效: %s", item)
+		}
+		mapping[p] = strings.TrimSpace(value)
+	}
+	return mapping, nil
+}
+
+// pushBase 手机推送通知器的公共部分（Bark、ntfy、Gotify、Server酱）
+type pushBase struct {
+	config     config.PushConfig
+	httpClient *http.Client
+	enabled    bool
+	defaults   map[Priority]string // 平台默认的优先级映射
+}
+
+// newPushBase 创建手机推送公共部分
+func newPushBase(cfg config.PushConfig, defaults map[Priority]string) pushBase {
+	return pushBase{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		enabled:  cfg.Enabled,
+		defaults: defaults,
+	}
+}
+
+// IsEnabled 检查是否启用
+func (p *pushBase) IsEnabled() bool {
+	return p.enabled && p.config.Enabled
+}
+
+// UpdateConfig 更新配置
+func (p *pushBase) UpdateConfig(cfg config.PushConfig) {
+	p.config = cfg
+	p.enabled = cfg.Enabled
+}
+
+// validate 验证公共配置
+func (p *pushBase) validate(name string) error {
+	if !p.enabled {
+		return fmt.Errorf("%s通知未启用", name)
+	}
+	if p.config.Token == "" {
+		return fmt.Errorf("%s密钥不能为空", name)
+	}
+	if _, err := ParsePriorityMap(p.config.Priorities); err != nil {
+		return err
+	}
+	return nil
+}
+
+// server 返回服务地址（去除末尾斜杠），未配置时使用默认地址
+func (p *pushBase) server(defaultServer string) string {
+	server := strings.TrimSpace(p.config.Server)
+	if server == "" {
+		server = defaultServer
+	}
+	return strings.TrimRight(server, "/")
+}
+
+// level 将优先级映射为平台取值（自定义映射优先）
+func (p *pushBase) level(priority Priority) string {
+	if mapping, err := ParsePriorityMap(p.config.Priorities); err == nil {
+		if v, ok := mapping[priority]; ok {
+			return v
+		}
+	}
+	return p.defaults[priority]
+}
+
+// formatPushBody 将通知文本压缩为适合手机推送的简短正文
+func formatPushBody(message string) string {
+	var body strings.Builder
+	locale := messageLocale(message)
+
+	if isBatchMessage(message) {
+		_, changes := parseBatchMessage(message)
+		for _, change := range changes {
+			body.WriteString(fmt.Sprintf("%s: %s\n", change.Domain,
+				translateStatusChange(locale, change.OldStatus, change.NewStatus)))
+		}
+		return strings.TrimSpace(body.String())
+	}
+
+	p := parseMessage(message)
+	if p.OldStatus != "" && p.NewStatus != "" {
+		body.WriteString(translateStatusChange(locale, p.OldStatus, p.NewStatus) + "\n")
+	} else if p.StatusInfo != "" {
+		body.WriteString(p.StatusInfo + "\n")
+	}
+	if p.OldValue != "" || p.NewValue != "" {
+		body.WriteString(fmt.Sprintf("%s → %s\n", p.OldValue, p.NewValue))
+	}
+	if p.Detail != "" {
+		body.WriteString(p.Detail + "\n")
+	}
+	if p.Timestamp != "" {
+		body.WriteString(p.Timestamp)
+	}
+	return strings.TrimSpace(body.String())
+}
+
+// pushTestEvent 手机推送测试事件
+func pushTestEvent() NotificationEvent {
+	return NotificationEvent{
+		Type:      "test",
+		Domain:    "example.com",
+		Timestamp: time.Now(),
+	}
+}
+
+// pushTestMessage 手机推送测试消息
+func pushTestMessage(name string) string {
+	return fmt.Sprintf("域名: example.com\n时间: %s\n状态: %s推送测试",
+		time.Now().Format("2006-01-02 15:04:05"), name)
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"Puff/config"
+)
+
+func TestEventPriority(t *testing.T) {
+	tests := []struct {
+		event NotificationEvent
+		want  Priority
+	}{
+		{NotificationEvent{Type: "status_change", Status: "available"}, PriorityCritical},
+		{NotificationEvent{Type: "status_change", Status: "redemption"}, PriorityHigh},
+		{NotificationEvent{Type: "status_change", Status: "registered"}, PriorityNormal},
+		{NotificationEvent{Type: "status_change", Status: "error"}, PriorityLow},
+		{NotificationEvent{Type: "pending_delete"}, PriorityHigh},
+		{NotificationEvent{Type: "expiry_reminder"}, PriorityHigh},
+		{NotificationEvent{Type: "field_change"}, PriorityNormal},
+	}
+	for _, tt := range tests {
+		if got := EventPriority(tt.event); got != tt.want {
+			t.Errorf("EventPriority(%s/%s) = %s, 期望 %s", tt.event.Type, tt.event.Status, got, tt.want)
+		}
+	}
+
+	mixed := []NotificationEvent{{Type: "field_change"}, {Type: "status_change", Status: "available"}, {Type: "error"}}
+	if got := eventsPriority(mixed); got != PriorityCritical {
+		t.Errorf("多个事件应取最高优先级，实际 %s", got)
+	}
+	if got := eventsPriority(nil); got != PriorityNormal {
+		t.Errorf("无事件时应为普通优先级，实际 %s", got)
+	}
+}
+
+func TestParsePriorityMap(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    map[Priority]string
+		wantErr bool
+	}{
+		{"", map[Priority]string{}, false},
+		{"critical=5,high=4", map[Priority]string{PriorityCritical: "5", PriorityHigh: "4"}, false},
+		{" Critical = 9 \n low=1,", map[Priority]string{PriorityCritical: "9", PriorityLow: "1"}, false},
+		{"urgent=5", nil, true},
+		{"critical", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePriorityMap(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePriorityMap(%q) 错误 = %v, 期望出错 %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParsePriorityMap(%q) = %v, 期望 %v", tt.text, got, tt.want)
+			continue
+		}
+		for p, v := range tt.want {
+			if got[p] != v {
+				t.Errorf("ParsePriorityMap(%q)[%s] = %q, 期望 %q", tt.text, p, got[p], v)
+			}
+		}
+	}
+}
+
+func TestBarkPush(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"code":200,"message":"success"}`)
+	available := []NotificationEvent{{Type: "status_change", Domain: "a.com", Status: "available"}}
+
+	b := NewBarkNotifier(config.PushConfig{Server: srv.URL + "/", Token: "device", Enabled: true})
+	if err := b.SendEvents("主题", "域名: a.com", available); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	req := onlyRequest(t, requests())
+	payload := req.jsonBody(t)
+	if req.path != "/push" || payload["device_key"] != "device" || payload["title"] != "主题" {
+		t.Errorf("请求错误: %s %v", req.path, payload)
+	}
+	if payload["level"] != "critical" {
+		t.Errorf("可注册事件应使用默认级别 critical，实际 %v", payload["level"])
+	}
+
+	// 自定义映射优先于平台默认
+	b.UpdateConfig(config.PushConfig{Server: srv.URL, Token: "device", Priorities: "critical=timeSensitive", Enabled: true})
+	if err := b.SendEvents("主题", "域名: a.com", available); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if level := requests()[1].jsonBody(t)["level"]; level != "timeSensitive" {
+		t.Errorf("应使用自定义级别，实际 %v", level)
+	}
+
+	failing, _ := newCaptureServer(t, `{"code":400,"message":"device not found"}`)
+	b = NewBarkNotifier(config.PushConfig{Server: failing.URL, Token: "device", Enabled: true})
+	if err := b.SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), "device not found") {
+		t.Errorf("Bark 返回错误码时应报错: %v", err)
+	}
+}
+
+func TestNtfyPush(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{}`)
+	n := NewNtfyNotifier(config.PushConfig{Server: srv.URL, Token: "puff-alerts", AccessToken: "tk_secret", Enabled: true})
+	if err := n.SendEvents("主题", "域名: a.com", []NotificationEvent{{Type: "expiry_reminder"}}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	req := onlyRequest(t, requests())
+	payload := req.jsonBody(t)
+	if payload["topic"] != "puff-alerts" || payload["priority"] != float64(4) {
+		t.Errorf("主题或优先级错误: %v", payload)
+	}
+	if auth := req.header.Get("Authorization"); auth != "Bearer tk_secret" {
+		t.Errorf("访问令牌错误: %q", auth)
+	}
+
+	n.UpdateConfig(config.PushConfig{Server: srv.URL, Token: "puff-alerts", Priorities: "high=9", Enabled: true})
+	if err := n.SendEvents("主题", "正文", []NotificationEvent{{Type: "expiry_reminder"}}); err == nil {
+		t.Error("超出 1-5 的优先级应报错")
+	}
+	if len(requests()) != 1 {
+		t.Error("优先级无效时不应发送请求")
+	}
+}
+
+func TestGotifyPush(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"id":1}`)
+	g := NewGotifyNotifier(config.PushConfig{Server: srv.URL, Token: "app token", Enabled: true})
+	if err := g.SendEvents("主题", "域名: a.com", []NotificationEvent{{Type: "error"}}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	req := onlyRequest(t, requests())
+	if req.path != "/message" || req.query.Get("token") != "app token" {
+		t.Errorf("请求地址错误: %s?%s", req.path, req.query.Encode())
+	}
+	if priority := req.jsonBody(t)["priority"]; priority != float64(2) {
+		t.Errorf("查询失败事件应为低优先级 2，实际 %v", priority)
+	}
+
+	g = NewGotifyNotifier(config.PushConfig{Token: "app", Enabled: true})
+	if err := g.SendMessage("主题", "正文"); err == nil {
+		t.Error("Gotify 未配置服务地址时应报错")
+	}
+}
+
+func TestServerChanPush(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"code":0,"message":""}`)
+	s := NewServerChanNotifier(config.PushConfig{Server: srv.URL, Token: "SCT123", Priorities: "critical=9|98", Enabled: true})
+	if err := s.SendEvents("主题", "域名: a.com", []NotificationEvent{{Type: "available"}}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	req := onlyRequest(t, requests())
+	form, err := url.ParseQuery(string(req.body))
+	if err != nil {
+		t.Fatalf("解析表单失败: %v", err)
+	}
+	if req.path != "/SCT123.send" || form.Get("title") != "主题" || form.Get("channel") != "9|98" {
+		t.Errorf("请求错误: %s %v", req.path, form)
+	}
+
+	// 未配置映射时不指定消息通道
+	s.UpdateConfig(config.PushConfig{Server: srv.URL, Token: "SCT123", Enabled: true})
+	if err := s.SendEvents("主题", "域名: a.com", []NotificationEvent{{Type: "available"}}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if form, _ := url.ParseQuery(string(requests()[1].body)); form.Has("channel") {
+		t.Errorf("默认不应指定 channel: %v", form)
+	}
+}
+
+func TestServerChanEndpoint(t *testing.T) {
+	tests := []struct {
+		server, token, want string
+	}{
+		{"", "SCT123", "https://sctapi.ftqq.com/SCT123.send"},
+		{"", "sctp42tabc", "https://42.push.ft07.com/send/sctp42tabc.send"},
+		{"https://push.example.com/", "sctp42tabc", "https://push.example.com/sctp42tabc.send"},
+	}
+	for _, tt := range tests {
+		s := NewServerChanNotifier(config.PushConfig{Server: tt.server, Token: tt.token, Enabled: true})
+		if got := s.endpoint(); got != tt.want {
+			t.Errorf("endpoint(%q, %q) = %q, 期望 %q", tt.server, tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestPushValidate(t *testing.T) {
+	if err := NewBarkNotifier(config.PushConfig{Token: "k"}).SendMessage("主题", "正文"); err == nil {
+		t.Error("未启用时应报错")
+	}
+	if err := NewNtfyNotifier(config.PushConfig{Enabled: true}).SendMessage("主题", "正文"); err == nil {
+		t.Error("密钥为空时应报错")
+	}
+	if err := NewBarkNotifier(config.PushConfig{Token: "k", Priorities: "urgent=1", Enabled: true}).SendMessage("主题", "正文"); err == nil {
+		t.Error("优先级映射无效时应报错")
+	}
+}
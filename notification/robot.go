@@ -0,0 +1,262 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"Puff/config"
+	"Puff/i18n"
+)
+
+// robotBase 群机器人通知器的公共部分（钉钉、飞书、企业微信）
+type robotBase struct {
+	config     config.RobotConfig
+	httpClient *http.Client
+	enabled    bool
+}
+
+// newRobotBase 创建群机器人公共部分
+func newRobotBase(cfg config.RobotConfig) robotBase {
+	return robotBase{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+// IsEnabled 检查是否启用
+func (r *robotBase) IsEnabled() bool {
+	return r.enabled && r.config.Enabled
+}
+
+// UpdateConfig 更新配置
+func (r *robotBase) UpdateConfig(cfg config.RobotConfig) {
+	r.config = cfg
+	r.enabled = cfg.Enabled
+}
+
+// validateWebhook 验证机器人地址
+func (r *robotBase) validateWebhook(name string) error {
+	if !r.enabled {
+		return fmt.Errorf("%s通知未启用", name)
+	}
+	if r.config.Webhook == "" {
+		return fmt.Errorf("%s机器人Webhook地址不能为空", name)
+	}
+	if !strings.HasPrefix(r.config.Webhook, "https://") && !strings.HasPrefix(r.config.Webhook, "http://") {
+		return fmt.Errorf("%s机器人Webhook地址格式无效", name)
+	}
+	return nil
+}
+
+// postJSON 发送JSON请求并解析响应
+func (r *robotBase) postJSON(url string, payload interface{}, result interface{}) error {
+	return postJSON(r.httpClient, url, nil, payload, result)
+}
+
+// postJSON 发送JSON请求，响应为 2xx 时解析到 result（result 为 nil 时忽略响应体）
+func postJSON(client *http.Client, url string, headers map[string]string, payload interface{}, result interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// robotTestMessage 群机器人测试消息
+func robotTestMessage(name string) string {
+	return fmt.Sprintf("域名: example.com\n时间: %s\n状态: %s通知测试\n\n如果您收到这条消息，说明%s机器人配置正确。",
+		time.Now().Format("2006-01-02 15:04:05"), name, name)
+}
+
+// batchChange 批量通知中的单个域名变化
+type batchChange struct {
+	Domain    string
+	OldStatus string
+	NewStatus string
+}
+
+// parsedMessage 从通知文本中解析出的字段
+type parsedMessage struct {
+	Locale     string // 通知文本的语言（由字段标签判断）
+	Domain     string
+	Timestamp  string
+	OldStatus  string
+	NewStatus  string
+	StatusInfo string
+	OldValue   string
+	NewValue   string
+	Detail     string
+}
+
+// isBatchMessage 判断是否为 formatBatchMessage 生成的批量通知
+func isBatchMessage(message string) bool {
+	_, ok := batchHeaderLocale(message)
+	return ok
+}
+
+// splitStatusChange 解析 "old → new"
+func splitStatusChange(value string) (oldStatus, newStatus string, ok bool) {
+	parts := strings.Split(value, "→")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// parseMessage 解析单条通知文本（字段标签可为任一支持的语言）
+func parseMessage(message string) parsedMessage {
+	p := parsedMessage{Locale: messageLocale(message)}
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if v, _, ok := cutLabel(line, "domain"); ok {
+			p.Domain = v
+		} else if v, _, ok := cutLabel(line, "time"); ok {
+			p.Timestamp = v
+		} else if v, _, ok := cutLabel(line, "status_change"); ok {
+			if oldStatus, newStatus, ok := splitStatusChange(v); ok {
+				p.OldStatus, p.NewStatus = oldStatus, newStatus
+			}
+		} else if v, _, ok := cutLabel(line, "status"); ok {
+			p.StatusInfo = v
+		} else if v, _, ok := cutLabel(line, "old_value"); ok {
+			p.OldValue = v
+		} else if v, _, ok := cutLabel(line, "new_value"); ok {
+			p.NewValue = v
+		} else if v, _, ok := cutLabel(line, "detail"); ok {
+			p.Detail = v
+		} else if v, _, ok := cutLabel(line, "error"); ok {
+			p.Detail = v
+		}
+	}
+	return p
+}
+
+// parseBatchMessage 解析批量通知文本
+func parseBatchMessage(message string) (string, []batchChange) {
+	lines := strings.Split(message, "\n")
+	timestamp := ""
+	var changes []batchChange
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if v, _, ok := cutLabel(line, "time"); ok {
+			timestamp = v
+			continue
+		}
+		// 域名行 "1. example.com"，下一行为状态变化
+		num, domain, ok := strings.Cut(line, ". ")
+		if !ok || num == "" || strings.Trim(num, "0123456789") != "" || i+1 >= len(lines) {
+			continue
+		}
+		v, _, ok := cutLabel(strings.TrimSpace(lines[i+1]), "status_change")
+		if !ok {
+			continue
+		}
+		if oldStatus, newStatus, ok := splitStatusChange(v); ok {
+			changes = append(changes, batchChange{
+				Domain:    domain,
+				OldStatus: oldStatus,
+				NewStatus: newStatus,
+			})
+		}
+	}
+
+	return timestamp, changes
+}
+
+// formatRobotMarkdown 将通知文本渲染为群机器人通用的 Markdown，withTitle 为 false 时不输出标题
+func formatRobotMarkdown(subject, message string, withTitle bool) string {
+	var md strings.Builder
+	locale := messageLocale(message)
+
+	if isBatchMessage(message) {
+		timestamp, changes := parseBatchMessage(message)
+		if withTitle {
+			md.WriteString(fmt.Sprintf("### %s\n\n", i18n.T(locale, "notify.batch_title", len(changes))))
+		}
+		if timestamp != "" {
+			md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "time"), timestamp))
+		}
+		for i, change := range changes {
+			md.WriteString(fmt.Sprintf("%d. **%s**  %s\n", i+1, change.Domain,
+				translateStatusChange(locale, change.OldStatus, change.NewStatus)))
+		}
+		md.WriteString("\n> " + i18n.T(locale, "notify.from"))
+		return md.String()
+	}
+
+	p := parseMessage(message)
+	if withTitle {
+		md.WriteString(fmt.Sprintf("### %s\n\n", subject))
+	}
+	if p.Domain != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "domain"), p.Domain))
+	}
+	if p.OldStatus != "" && p.NewStatus != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "status_change"), translateStatusChange(locale, p.OldStatus, p.NewStatus)))
+	} else if p.StatusInfo != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "status"), p.StatusInfo))
+	}
+	if p.OldValue != "" || p.NewValue != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "old_value"), p.OldValue))
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "new_value"), p.NewValue))
+	}
+	if p.Detail != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "detail"), p.Detail))
+	}
+	if p.Timestamp != "" {
+		md.WriteString(fmt.Sprintf("**%s**: %s\n\n", label(locale, "time"), p.Timestamp))
+	}
+	md.WriteString("> " + i18n.T(locale, "notify.from"))
+
+	return md.String()
+}
+
+// truncateUTF8 按字节截断字符串，不拆分多字节字符
+func truncateUTF8(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	cut := maxBytes
+	for cut > 0 && (text[cut]&0xC0) == 0x80 {
+		cut--
+	}
+	return text[:cut]
+}
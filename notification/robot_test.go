@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Puff/config"
+	"Puff/i18n"
+)
+
+// hmacBase64 计算 HmacSHA256 的 Base64
+func hmacBase64(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestDingTalkSignedMarkdown(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"errcode":0,"errmsg":"ok"}`)
+	d := NewDingTalkNotifier(config.RobotConfig{Webhook: srv.URL + "/robot/send?access_token=abc", Secret: "SECabc", Enabled: true})
+	if err := d.SendMessage("主题", "域名: a.com"); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	reqs := requests()
+	if len(reqs) != 1 {
+		t.Fatalf("应发送 1 个请求，实际 %d 个", len(reqs))
+	}
+	query := reqs[0].query
+	if query.Get("access_token") != "abc" {
+		t.Errorf("应保留原有参数: %v", query)
+	}
+	if want := hmacBase64("SECabc", query.Get("timestamp")+"\nSECabc"); query.Get("timestamp") == "" || query.Get("sign") != want {
+		t.Errorf("签名错误: %q，期望 %q", query.Get("sign"), want)
+	}
+	payload := reqs[0].jsonBody(t)
+	markdown, _ := payload["markdown"].(map[string]interface{})
+	if payload["msgtype"] != "markdown" || markdown["title"] != "主题" || !strings.Contains(markdown["text"].(string), "a.com") {
+		t.Errorf("请求体错误: %v", payload)
+	}
+
+	srv, _ = newCaptureServer(t, `{"errcode":310000,"errmsg":"sign not match"}`)
+	d = NewDingTalkNotifier(config.RobotConfig{Webhook: srv.URL, Enabled: true})
+	if err := d.SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Errorf("应返回钉钉错误码: %v", err)
+	}
+}
+
+func TestFeishuSignedCard(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"code":0,"msg":"success"}`)
+	f := NewFeishuNotifier(config.RobotConfig{Webhook: srv.URL, Secret: "feishu-secret", Enabled: true})
+	if err := f.SendMessage("主题", "域名: a.com"); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	payload := requests()[0].jsonBody(t)
+	timestamp, _ := payload["timestamp"].(string)
+	if want := hmacBase64(timestamp+"\nfeishu-secret", ""); timestamp == "" || payload["sign"] != want {
+		t.Errorf("签名错误: %v，期望 %q", payload["sign"], want)
+	}
+	if payload["msg_type"] != "interactive" {
+		t.Errorf("应发送消息卡片: %v", payload)
+	}
+
+	tests := []struct {
+		response string
+		code     string
+	}{
+		{`{"code":19021,"msg":"sign match fail"}`, "19021"},
+		{`{"StatusCode":9499,"StatusMessage":"Bad Request"}`, "9499"},
+	}
+	for _, tt := range tests {
+		srv, _ := newCaptureServer(t, tt.response)
+		f := NewFeishuNotifier(config.RobotConfig{Webhook: srv.URL, Enabled: true})
+		if err := f.SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), tt.code) {
+			t.Errorf("应返回飞书错误码 %s: %v", tt.code, err)
+		}
+	}
+}
+
+func TestWeComTruncatesMarkdown(t *testing.T) {
+	srv, requests := newCaptureServer(t, `{"errcode":0,"errmsg":"ok"}`)
+	w := NewWeComNotifier(config.RobotConfig{Webhook: srv.URL, Enabled: true})
+	if err := w.SendMessage("主题", "详情: "+strings.Repeat("域名", 2000)); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	markdown, _ := requests()[0].jsonBody(t)["markdown"].(map[string]interface{})
+	content, _ := markdown["content"].(string)
+	if len(content) > wecomMarkdownLimit || !utf8.ValidString(content) || !strings.HasPrefix(content, "### 主题") {
+		t.Errorf("内容应按字节截断且不拆分字符: %d 字节，有效 UTF-8 = %v", len(content), utf8.ValidString(content))
+	}
+}
+
+func TestRobotValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.RobotConfig
+		want string
+	}{
+		{"未启用", config.RobotConfig{Webhook: "https://oapi.dingtalk.com/robot/send"}, "未启用"},
+		{"缺少地址", config.RobotConfig{Enabled: true}, "不能为空"},
+		{"地址无效", config.RobotConfig{Webhook: "oapi.dingtalk.com", Enabled: true}, "格式无效"},
+	}
+	for _, tt := range tests {
+		if err := NewDingTalkNotifier(tt.cfg).SendMessage("主题", "正文"); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
+
+func TestFormatRobotMarkdown(t *testing.T) {
+	nm := NewNotificationManager()
+	event := NotificationEvent{Type: "status_change", Domain: "a.com", Status: "available", OldStatus: "registered", Timestamp: time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)}
+
+	single := formatRobotMarkdown("Subject", nm.formatMessage(i18n.En, event), true)
+	for _, want := range []string{"### Subject", "**Domain**: a.com", "**Status change**: Registered → Available", "**Time**: 2026-03-01 08:00:00", "> From Puff"} {
+		if !strings.Contains(single, want) {
+			t.Errorf("单条通知缺少 %q:\n%s", want, single)
+		}
+	}
+
+	batch := formatRobotMarkdown("主题", nm.formatBatchMessage(i18n.ZhCN, []NotificationEvent{event, event}), false)
+	if strings.Contains(batch, "###") || !strings.Contains(batch, "2. **a.com**  已注册 → 可注册") {
+		t.Errorf("批量通知排版错误:\n%s", batch)
+	}
+}
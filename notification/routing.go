@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// AllTargets 路由目标：全部通知器
+const AllTargets = "*"
+
+// NotifierKey 路由规则中引用通知器的标识：通知渠道实例为 "类型:ID"，其余为类型
+func NotifierKey(n Notifier) string {
+	if inst, ok := n.(*InstanceNotifier); ok {
+		return fmt.Sprintf("%s:%d", inst.GetType(), inst.ID)
+	}
+	return n.GetType()
+}
+
+// router 通知路由：按规则决定事件由哪些通知器接收
+type router struct {
+	mu       sync.Mutex
+	rules    []storage.NotificationRule
+	lastSent map[string]time.Time // "规则ID|域名" -> 上次放行时间（节流）
+}
+
+// newRouter 创建路由器
+func newRouter() *router {
+	return &router{lastSent: make(map[string]time.Time)}
+}
+
+// setRules 替换规则并清理已删除规则的节流记录
+func (r *router) setRules(rules []storage.NotificationRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = rules
+	active := make(map[string]bool)
+	for _, rule := range rules {
+		active[fmt.Sprintf("%d|", rule.ID)] = true
+	}
+	for key := range r.lastSent {
+		id, _, _ := strings.Cut(key, "|")
+		if !active[id+"|"] {
+			delete(r.lastSent, key)
+		}
+	}
+}
+
+// resolve 计算事件的接收目标；未命中任何规则时 routed 为 false，表示发送给全部通知器
+func (r *router) resolve(event NotificationEvent, now time.Time) (targets map[string]bool, routed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets = make(map[string]bool)
+	for _, rule := range r.rules {
+		if !rule.Enabled || !MatchRule(rule, event) {
+			continue
+		}
+		routed = true
+
+		// 节流：同一规则对同一域名在间隔内只放行一次
+		if rule.ThrottleSeconds > 0 {
+			key := fmt.Sprintf("%d|%s", rule.ID, event.Domain)
+			if last, ok := r.lastSent[key]; ok && now.Sub(last) < time.Duration(rule.ThrottleSeconds)*time.Second {
+				logger.Debug("路由规则 %s 节流中，跳过 %s", rule.Name, event.Domain)
+				continue
+			}
+			r.lastSent[key] = now
+		}
+
+		for _, target := range rule.Targets {
+			targets[target] = true
+		}
+	}
+	return targets, routed
+}
+
+// MatchRule 判断事件是否满足规则的全部条件（空条件不限制）
+func MatchRule(rule storage.NotificationRule, event NotificationEvent) bool {
+	domain := strings.ToLower(event.Domain)
+
+	if rule.EventType != "" && rule.EventType != event.Type {
+		return false
+	}
+	if rule.DomainPattern != "" {
+		if ok, err := path.Match(strings.ToLower(rule.DomainPattern), domain); err != nil || !ok {
+			return false
+		}
+	}
+	if rule.TLD != "" {
+		tld := strings.ToLower(strings.TrimPrefix(rule.TLD, "."))
+		if !strings.HasSuffix(domain, "."+tld) {
+			return false
+		}
+	}
+	// 状态转换条件仅适用于状态变化事件
+	if rule.FromStatus != "" && (event.Type != "status_change" || rule.FromStatus != event.OldStatus) {
+		return false
+	}
+	if rule.ToStatus != "" && (event.Type != "status_change" || rule.ToStatus != event.Status) {
+		return false
+	}
+	if rule.ErrorClass != "" && rule.ErrorClass != event.ErrorClass {
+		return false
+	}
+	return true
+}
+
+// accepts 判断通知器是否在目标集合中
+func accepts(targets map[string]bool, n Notifier) bool {
+	return targets[AllTargets] || targets[NotifierKey(n)] || targets[n.GetType()]
+}
+
+// targetReceivers 返回目标列表（逗号分隔的通知器标识，为空表示全部）中已启用的通知器
+func (nm *NotificationManager) targetReceivers(list string) []Notifier {
+	targets := make(map[string]bool)
+	for _, target := range strings.Split(list, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets[target] = true
+		}
+	}
+	if len(targets) == 0 {
+		targets[AllTargets] = true
+	}
+
+	var receivers []Notifier
+	for _, n := range nm.GetNotifiers() {
+		if n.IsEnabled() && accepts(targets, n) {
+			receivers = append(receivers, n)
+		}
+	}
+	return receivers
+}
+
+// LoadRoutingRules 从数据库重新加载路由规则
+func (nm *NotificationManager) LoadRoutingRules() error {
+	rules, err := storage.ListNotificationRules()
+	if err != nil {
+		return err
+	}
+	nm.router.setRules(rules)
+	return nil
+}
+
+// RouteEvent 返回事件将发送到的通知器（仅包含已启用的通知器）
+func (nm *NotificationManager) RouteEvent(event NotificationEvent) []Notifier {
+	receivers, _ := nm.routeEvent(event, AllTargets)
+	return receivers
+}
+
+// routeEvent 按路由规则计算接收者；未命中任何规则时发往 fallback（逗号分隔的通知器标识，为空表示全部）
+func (nm *NotificationManager) routeEvent(event NotificationEvent, fallback string) ([]Notifier, bool) {
+	targets, routed := nm.router.resolve(event, time.Now())
+	if !routed {
+		return nm.targetReceivers(fallback), false
+	}
+
+	var receivers []Notifier
+	for _, notifier := range nm.GetNotifiers() {
+		if notifier.IsEnabled() && accepts(targets, notifier) {
+			receivers = append(receivers, notifier)
+		}
+	}
+	if len(receivers) == 0 {
+		logger.Info("通知被路由规则拦截: 域名=%s, 事件类型=%s", event.Domain, event.Type)
+	}
+	return receivers, true
+}
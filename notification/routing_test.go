@@ -0,0 +1,161 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"Puff/core"
+	"Puff/storage"
+)
+
+func TestMatchRule(t *testing.T) {
+	statusChange := NotificationEvent{Type: "status_change", Domain: "Shop.Example.COM", OldStatus: "registered", Status: "available"}
+	lookupFailure := NotificationEvent{Type: core.HealthLookupFailure, Domain: "a.de", ErrorClass: core.ErrorClassTimeout}
+	fieldChange := NotificationEvent{Type: core.FieldChangeTypes[0], Domain: "a.com", Status: "registered"}
+
+	tests := []struct {
+		name  string
+		rule  storage.NotificationRule
+		event NotificationEvent
+		want  bool
+	}{
+		{"空条件匹配全部", storage.NotificationRule{}, statusChange, true},
+		{"事件类型匹配", storage.NotificationRule{EventType: "status_change"}, statusChange, true},
+		{"事件类型不匹配", storage.NotificationRule{EventType: "expiry_reminder"}, statusChange, false},
+		{"通配符忽略大小写", storage.NotificationRule{DomainPattern: "*.example.com"}, statusChange, true},
+		{"通配符不匹配", storage.NotificationRule{DomainPattern: "*.example.net"}, statusChange, false},
+		{"无效通配符不匹配", storage.NotificationRule{DomainPattern: "[a"}, statusChange, false},
+		{"后缀匹配", storage.NotificationRule{TLD: ".COM"}, statusChange, true},
+		{"后缀需完整匹配标签", storage.NotificationRule{TLD: "om"}, statusChange, false},
+		{"状态转换匹配", storage.NotificationRule{FromStatus: "registered", ToStatus: "available"}, statusChange, true},
+		{"原状态不匹配", storage.NotificationRule{FromStatus: "grace"}, statusChange, false},
+		{"新状态不匹配", storage.NotificationRule{ToStatus: "redemption"}, statusChange, false},
+		{"状态条件不适用于字段变化", storage.NotificationRule{ToStatus: "registered"}, fieldChange, false},
+		{"错误分类匹配", storage.NotificationRule{EventType: core.HealthLookupFailure, ErrorClass: core.ErrorClassTimeout}, lookupFailure, true},
+		{"错误分类不匹配", storage.NotificationRule{EventType: core.HealthLookupFailure, ErrorClass: core.ErrorClassDNS}, lookupFailure, false},
+		{"错误分类不匹配无错误的事件", storage.NotificationRule{ErrorClass: core.ErrorClassTimeout}, statusChange, false},
+		{"多个条件同时满足", storage.NotificationRule{EventType: core.HealthLookupFailure, TLD: "de", ErrorClass: core.ErrorClassTimeout}, lookupFailure, true},
+		{"多个条件之一不满足", storage.NotificationRule{EventType: core.HealthLookupFailure, TLD: "com", ErrorClass: core.ErrorClassTimeout}, lookupFailure, false},
+	}
+	for _, tt := range tests {
+		if got := MatchRule(tt.rule, tt.event); got != tt.want {
+			t.Errorf("%s: MatchRule = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRouterResolve(t *testing.T) {
+	r := newRouter()
+	r.setRules([]storage.NotificationRule{
+		{ID: 1, Name: "停用", Enabled: false, Targets: []string{"email"}},
+		{ID: 2, Name: "超时", Enabled: true, EventType: core.HealthLookupFailure, ErrorClass: core.ErrorClassTimeout, Targets: []string{"telegram:1"}, ThrottleSeconds: 60},
+		{ID: 3, Name: "德国域名", Enabled: true, TLD: "de", Targets: []string{"webhook"}},
+	})
+	now := time.Now()
+	event := NotificationEvent{Type: core.HealthLookupFailure, Domain: "a.de", ErrorClass: core.ErrorClassTimeout}
+
+	targets, routed := r.resolve(event, now)
+	if !routed || len(targets) != 2 || !targets["telegram:1"] || !targets["webhook"] {
+		t.Errorf("命中多条规则时应合并目标: %v %v", targets, routed)
+	}
+
+	// 节流间隔内只保留未节流规则的目标
+	targets, routed = r.resolve(event, now.Add(30*time.Second))
+	if !routed || len(targets) != 1 || !targets["webhook"] {
+		t.Errorf("节流中的规则不应放行: %v %v", targets, routed)
+	}
+	targets, _ = r.resolve(event, now.Add(61*time.Second))
+	if !targets["telegram:1"] {
+		t.Errorf("节流间隔后应再次放行: %v", targets)
+	}
+
+	event.ErrorClass = core.ErrorClassDNS
+	event.Domain = "a.com"
+	if targets, routed := r.resolve(event, now); routed || len(targets) != 0 {
+		t.Errorf("未命中规则时 routed 应为 false: %v %v", targets, routed)
+	}
+}
+
+func TestRouteEventFallback(t *testing.T) {
+	admin := &recordingNotifier{notifierType: "admin"}
+	ops := &recordingNotifier{notifierType: "ops"}
+	nm := NewNotificationManager()
+	nm.AddNotifier(admin)
+	nm.AddNotifier(ops)
+
+	event := NotificationEvent{Type: "status_change", Domain: "a.com"}
+	if receivers := nm.RouteEvent(event); len(receivers) != 2 {
+		t.Errorf("未配置规则时应发送给全部通知器，实际 %d 个", len(receivers))
+	}
+	if receivers, routed := nm.routeEvent(event, "admin"); routed || len(receivers) != 1 || receivers[0] != admin {
+		t.Errorf("未命中规则时应发往备用目标: %v %v", receivers, routed)
+	}
+
+	nm.router.setRules([]storage.NotificationRule{{ID: 1, Name: "运维", Enabled: true, Targets: []string{"ops"}}})
+	if receivers, routed := nm.routeEvent(event, "admin"); !routed || len(receivers) != 1 || receivers[0] != ops {
+		t.Errorf("命中规则时应发往规则目标: %v %v", receivers, routed)
+	}
+}
+
+func TestRouteEventTargets(t *testing.T) {
+	team, _ := NewInstanceNotifier(1, "exec", "团队", `{"command":"/bin/true"}`, true)
+	oncall, _ := NewInstanceNotifier(2, "exec", "值班", `{"command":"/bin/true"}`, true)
+	off, _ := NewInstanceNotifier(3, "exec", "停用", `{"command":"/bin/true"}`, false)
+	admin := &recordingNotifier{notifierType: "admin"}
+	nm := NewNotificationManager()
+	for _, n := range []Notifier{team, oncall, off, admin} {
+		nm.AddNotifier(n)
+	}
+
+	tests := []struct {
+		name    string
+		targets []string
+		want    []Notifier
+	}{
+		{"按实例标识", []string{"exec:2"}, []Notifier{oncall}},
+		{"按类型包含该类型的全部已启用实例", []string{"exec"}, []Notifier{team, oncall}},
+		{"全部", []string{AllTargets}, []Notifier{team, oncall, admin}},
+		{"停用的实例不接收", []string{"exec:3"}, nil},
+		{"空目标表示丢弃", nil, nil},
+	}
+	event := NotificationEvent{Type: "status_change", Domain: "route.com"}
+	for _, tt := range tests {
+		nm.router.setRules([]storage.NotificationRule{{ID: 1, Enabled: true, Targets: tt.targets}})
+		receivers, routed := nm.routeEvent(event, AllTargets)
+		if !routed || len(receivers) != len(tt.want) {
+			t.Errorf("%s: 接收者 %v，期望 %v", tt.name, receivers, tt.want)
+			continue
+		}
+		for i := range receivers {
+			if receivers[i] != tt.want[i] {
+				t.Errorf("%s: 第 %d 个接收者错误", tt.name, i+1)
+			}
+		}
+	}
+
+	// 备用目标支持逗号分隔的多个标识
+	nm.router.setRules(nil)
+	if receivers := nm.targetReceivers(" exec:1 , admin "); len(receivers) != 2 || receivers[0] != team || receivers[1] != admin {
+		t.Errorf("targetReceivers 结果错误: %v", receivers)
+	}
+}
+
+func TestSetRulesClearsThrottle(t *testing.T) {
+	r := newRouter()
+	rules := []storage.NotificationRule{
+		{ID: 1, Enabled: true, Targets: []string{"a"}, ThrottleSeconds: 3600},
+		{ID: 2, Enabled: true, Targets: []string{"b"}, ThrottleSeconds: 3600},
+	}
+	r.setRules(rules)
+	now := time.Now()
+	event := NotificationEvent{Type: "status_change", Domain: "throttle.com"}
+	r.resolve(event, now)
+
+	// 保留的规则继续节流，删除后重新添加的规则不再受旧记录影响
+	r.setRules(rules[:1])
+	r.setRules(rules)
+	targets, _ := r.resolve(event, now.Add(time.Minute))
+	if targets["a"] || !targets["b"] {
+		t.Errorf("节流记录应只在规则删除时清理: %v", targets)
+	}
+}
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"Puff/config"
+)
+
+// serverChanDefaultServer Server酱 Turbo 服务地址
+const serverChanDefaultServer = "https://sctapi.ftqq.com"
+
+// serverChan3Key Server酱³ SendKey 格式（sctp{uid}t...）
+var serverChan3Key = regexp.MustCompile(`^sctp(\d+)t`)
+
+// ServerChanNotifier Server酱推送通知器（优先级映射为消息通道 channel，默认不指定）
+type ServerChanNotifier struct {
+	pushBase
+}
+
+// serverChanResponse Server酱响应
+type serverChanResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewServerChanNotifier 创建 Server酱通知器
+func NewServerChanNotifier(cfg config.PushConfig) *ServerChanNotifier {
+	return &ServerChanNotifier{pushBase: newPushBase(cfg, map[Priority]string{})}
+}
+
+// SendMessage 发送 Server酱推送
+func (s *ServerChanNotifier) SendMessage(subject, message string) error {
+	return s.SendEvents(subject, message, nil)
+}
+
+// SendEvents 按事件优先级发送 Server酱推送
+func (s *ServerChanNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if err := s.validate("Server酱"); err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("title", subject)
+	form.Set("desp", formatRobotMarkdown(subject, message, false))
+	if channel := s.level(eventsPriority(events)); channel != "" {
+		form.Set("channel", channel)
+	}
+
+	resp, err := s.httpClient.PostForm(s.endpoint(), form)
+	if err != nil {
+		return fmt.Errorf("发送Server酱推送失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return fmt.Errorf("读取Server酱响应失败: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("发送Server酱推送失败: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result serverChanResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("解析Server酱响应失败: %v", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("Server酱API错误 (%d): %s", result.Code, result.Message)
+	}
+	return nil
+}
+
+// endpoint 返回推送地址：自定义地址 > Server酱³ > Turbo
+func (s *ServerChanNotifier) endpoint() string {
+	key := url.PathEscape(s.config.Token)
+	if strings.TrimSpace(s.config.Server) == "" {
+		if m := serverChan3Key.FindStringSubmatch(s.config.Token); m != nil {
+			return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", m[1], key)
+		}
+	}
+	return fmt.Sprintf("%s/%s.send", s.server(serverChanDefaultServer), key)
+}
+
+// GetType 获取通知器类型
+func (s *ServerChanNotifier) GetType() string {
+	return "serverchan"
+}
+
+// Test 发送测试消息
+func (s *ServerChanNotifier) Test() error {
+	return s.SendEvents("Server酱推送测试", pushTestMessage("Server酱"), []NotificationEvent{pushTestEvent()})
+}
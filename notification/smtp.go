@@ -0,0 +1,219 @@
+package notification
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"Puff/config"
+)
+
+// SMTP 连接安全模式
+const (
+	SMTPSecurityAuto     = ""         // 465 端口使用 TLS，其余端口在服务器支持时 STARTTLS
+	SMTPSecurityNone     = "none"     // 明文连接
+	SMTPSecuritySTARTTLS = "starttls" // 明文连接后升级，服务器不支持时报错
+	SMTPSecurityTLS      = "tls"      // 隐式TLS（SMTPS）
+)
+
+// SMTP 认证方式
+const (
+	SMTPAuthAuto    = ""         // 填写了用户名时使用 PLAIN，否则不认证
+	SMTPAuthNone    = "none"     // 不认证（内网中继）
+	SMTPAuthPlain   = "plain"    // AUTH PLAIN
+	SMTPAuthLogin   = "login"    // AUTH LOGIN
+	SMTPAuthCRAMMD5 = "cram-md5" // AUTH CRAM-MD5
+)
+
+// defaultSMTPTimeout 默认连接与发送超时
+const defaultSMTPTimeout = 30 * time.Second
+
+// ValidateSMTPConfig 校验安全模式、认证方式与CA证书
+func ValidateSMTPConfig(cfg config.SMTPConfig) error {
+	switch cfg.Security {
+	case SMTPSecurityAuto, SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecurityTLS:
+	default:
+		return fmt.Errorf("不支持的连接安全模式: %s", cfg.Security)
+	}
+	switch cfg.Auth {
+	case SMTPAuthAuto, SMTPAuthNone, SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5:
+	default:
+		return fmt.Errorf("不支持的认证方式: %s", cfg.Auth)
+	}
+	if cfg.Auth != SMTPAuthAuto && cfg.Auth != SMTPAuthNone && cfg.User == "" {
+		return fmt.Errorf("认证方式 %s 需要填写用户名", cfg.Auth)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("超时时间不能为负数")
+	}
+	if _, err := smtpTLSConfig(cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// smtpAuthMechanism 实际使用的认证方式
+func smtpAuthMechanism(cfg config.SMTPConfig) string {
+	if cfg.Auth == SMTPAuthAuto {
+		if cfg.User == "" {
+			return SMTPAuthNone
+		}
+		return SMTPAuthPlain
+	}
+	return cfg.Auth
+}
+
+// smtpTimeout 实际使用的超时时间
+func smtpTimeout(cfg config.SMTPConfig) time.Duration {
+	if cfg.Timeout > 0 {
+		return time.Duration(cfg.Timeout) * time.Second
+	}
+	return defaultSMTPTimeout
+}
+
+// smtpTLSConfig 构建TLS配置（证书校验、自定义CA）
+func smtpTLSConfig(cfg config.SMTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.SkipVerify,
+	}
+	if strings.TrimSpace(cfg.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, fmt.Errorf("CA证书无效，请填写PEM格式证书")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// dialSMTP 按安全模式建立连接，返回已完成 EHLO 与（需要时）STARTTLS 的客户端
+func dialSMTP(cfg config.SMTPConfig) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, fmt.Sprintf("%d", cfg.Port))
+	timeout := smtpTimeout(cfg)
+	tlsConfig, err := smtpTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	security := cfg.Security
+	if security == SMTPSecurityAuto && cfg.Port == 465 {
+		security = SMTPSecurityTLS
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("TLS连接失败: %v", err)
+		}
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("连接SMTP服务器失败: %v", err)
+		}
+	}
+	// 整个会话共用一个截止时间，避免服务器无响应时长时间阻塞
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("创建SMTP客户端失败: %v", err)
+	}
+
+	helo := cfg.HeloName
+	if helo == "" {
+		helo = "localhost"
+	}
+	if err := client.Hello(helo); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("EHLO失败: %v", err)
+	}
+
+	if security == SMTPSecuritySTARTTLS || security == SMTPSecurityAuto {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("STARTTLS失败: %v", err)
+			}
+		} else if security == SMTPSecuritySTARTTLS {
+			client.Close()
+			return nil, fmt.Errorf("服务器不支持STARTTLS")
+		}
+	}
+
+	return client, nil
+}
+
+// smtpAuth 按认证方式创建认证器；明文连接下仅在安全模式为 none 时允许发送密码
+func smtpAuth(cfg config.SMTPConfig) smtp.Auth {
+	allowInsecure := cfg.Security == SMTPSecurityNone
+	switch smtpAuthMechanism(cfg) {
+	case SMTPAuthPlain:
+		return &plainAuth{username: cfg.User, password: cfg.Password, allowInsecure: allowInsecure}
+	case SMTPAuthLogin:
+		return &loginAuth{username: cfg.User, password: cfg.Password, allowInsecure: allowInsecure}
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(cfg.User, cfg.Password)
+	default:
+		return nil
+	}
+}
+
+// errInsecureAuth 明文连接拒绝发送密码
+var errInsecureAuth = errors.New("连接未加密，拒绝发送密码（如确需明文认证，请将连接安全设为 none）")
+
+// plainAuth AUTH PLAIN（与 smtp.PlainAuth 相同，但允许显式配置的明文连接）
+type plainAuth struct {
+	username, password string
+	allowInsecure      bool
+}
+
+func (a *plainAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !a.allowInsecure {
+		return "", nil, errInsecureAuth
+	}
+	return "PLAIN", []byte("\x00" + a.username + "\x00" + a.password), nil
+}
+
+func (a *plainAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return nil, errors.New("服务器返回了意外的认证质询")
+	}
+	return nil, nil
+}
+
+// loginAuth AUTH LOGIN（按服务器提示依次发送用户名与密码）
+type loginAuth struct {
+	username, password string
+	allowInsecure      bool
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !a.allowInsecure {
+		return "", nil, errInsecureAuth
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("无法识别的LOGIN认证质询: %s", fromServer)
+	}
+}
@@ -0,0 +1,351 @@
+package notification
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Puff/config"
+)
+
+// testCertificate 生成 127.0.0.1 的自签名证书，返回服务端证书与 PEM 格式的 CA
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "puff-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// fakeSMTP 记录会话内容的测试用 SMTP 服务器
+type fakeSMTP struct {
+	tlsConfig   *tls.Config
+	implicitTLS bool // 连接即 TLS（SMTPS）
+	startTLS    bool // 通告 STARTTLS
+	auth        bool // 通告 AUTH PLAIN LOGIN
+
+	mu        sync.Mutex
+	mechanism string // 收到的认证方式
+	username  string
+	password  string
+	authTLS   bool // 认证时连接是否已加密
+	from      string
+	rcpts     []string
+	data      string
+	done      chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, s *fakeSMTP) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s.done = make(chan struct{})
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer close(s.done)
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		if s.implicitTLS {
+			conn = tls.Server(conn, s.tlsConfig)
+		}
+		s.serve(conn)
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	encrypted := s.implicitTLS
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+
+	reply("220 fake ESMTP")
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			if s.startTLS && !encrypted {
+				reply("250-STARTTLS")
+			}
+			if s.auth {
+				reply("250-AUTH PLAIN LOGIN")
+			}
+			reply("250 OK")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, encrypted = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			s.mu.Lock()
+			s.mechanism, s.authTLS = strings.ToUpper(mechanism), encrypted
+			s.mu.Unlock()
+			switch s.mechanism {
+			case "PLAIN":
+				if initial == "" {
+					reply("334 ")
+					initial, _ = readLine()
+				}
+				decoded, _ := base64.StdEncoding.DecodeString(initial)
+				parts := strings.Split(string(decoded), "\x00")
+				if len(parts) == 3 {
+					s.mu.Lock()
+					s.username, s.password = parts[1], parts[2]
+					s.mu.Unlock()
+				}
+			case "LOGIN":
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				user, _ := readLine()
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				pass, _ := readLine()
+				u, _ := base64.StdEncoding.DecodeString(user)
+				p, _ := base64.StdEncoding.DecodeString(pass)
+				s.mu.Lock()
+				s.username, s.password = string(u), string(p)
+				s.mu.Unlock()
+			}
+			reply("235 authenticated")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = arg
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, arg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				line, ok := readLine()
+				if !ok || line == "." {
+					break
+				}
+				b.WriteString(line + "\n")
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// wait 等待会话结束
+func (s *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待 SMTP 会话结束超时")
+	}
+}
+
+func testSMTPConfig(port int, caCert string) config.SMTPConfig {
+	return config.SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    port,
+		From:    "puff@example.com",
+		To:      "a@example.com, b@example.com",
+		CACert:  caCert,
+		Timeout: 5,
+		Enabled: true,
+	}
+}
+
+func TestSMTPSecurityAndAuth(t *testing.T) {
+	cert, caCert := testCertificate(t)
+	serverTLS := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	tests := []struct {
+		name      string
+		server    *fakeSMTP
+		security  string
+		auth      string
+		user      string
+		mechanism string
+		encrypted bool
+	}{
+		{name: "明文不认证", server: &fakeSMTP{}, security: SMTPSecurityNone, auth: SMTPAuthNone},
+		{name: "显式明文 PLAIN", server: &fakeSMTP{auth: true}, security: SMTPSecurityNone, auth: SMTPAuthPlain, user: "user", mechanism: "PLAIN"},
+		{name: "STARTTLS PLAIN", server: &fakeSMTP{startTLS: true, auth: true}, security: SMTPSecuritySTARTTLS, auth: SMTPAuthPlain, user: "user", mechanism: "PLAIN", encrypted: true},
+		{name: "STARTTLS LOGIN", server: &fakeSMTP{startTLS: true, auth: true}, security: SMTPSecuritySTARTTLS, auth: SMTPAuthLogin, user: "user", mechanism: "LOGIN", encrypted: true},
+		{name: "隐式TLS LOGIN", server: &fakeSMTP{implicitTLS: true, auth: true}, security: SMTPSecurityTLS, auth: SMTPAuthLogin, user: "user", mechanism: "LOGIN", encrypted: true},
+		{name: "隐式TLS 不认证", server: &fakeSMTP{implicitTLS: true}, security: SMTPSecurityTLS, auth: SMTPAuthNone, encrypted: true},
+		{name: "自动模式升级 STARTTLS", server: &fakeSMTP{startTLS: true, auth: true}, security: SMTPSecurityAuto, auth: SMTPAuthAuto, user: "user", mechanism: "PLAIN", encrypted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server
+			server.tlsConfig = serverTLS
+			cfg := testSMTPConfig(startFakeSMTP(t, server), caCert)
+			cfg.Security, cfg.Auth, cfg.User, cfg.Password = tt.security, tt.auth, tt.user, "p@ss word"
+
+			if err := NewEmailNotifier(cfg).SendMessage("测试主题", "测试正文"); err != nil {
+				t.Fatalf("发送失败: %v", err)
+			}
+			server.wait(t)
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.mechanism != tt.mechanism {
+				t.Errorf("认证方式 = %q，期望 %q", server.mechanism, tt.mechanism)
+			}
+			if tt.mechanism != "" {
+				if server.username != "user" || server.password != "p@ss word" {
+					t.Errorf("认证凭据错误: %q / %q", server.username, server.password)
+				}
+				if server.authTLS != tt.encrypted {
+					t.Errorf("认证时加密状态 = %v，期望 %v", server.authTLS, tt.encrypted)
+				}
+			}
+			if server.from != "FROM:<puff@example.com>" || len(server.rcpts) != 2 {
+				t.Errorf("信封错误: %q %v", server.from, server.rcpts)
+			}
+			if !strings.Contains(server.data, "Content-Type: text/html") {
+				t.Errorf("邮件内容不完整:\n%s", server.data)
+			}
+		})
+	}
+}
+
+func TestSMTPRefusesPlainAuthWithoutTLS(t *testing.T) {
+	for _, auth := range []string{SMTPAuthAuto, SMTPAuthPlain, SMTPAuthLogin} {
+		t.Run("auth="+auth, func(t *testing.T) {
+			server := fakeSMTP{auth: true}
+			cfg := testSMTPConfig(startFakeSMTP(t, &server), "")
+			cfg.Auth, cfg.User, cfg.Password = auth, "user", "secret"
+
+			err := NewEmailNotifier(cfg).SendMessage("测试主题", "测试正文")
+			if err == nil || !strings.Contains(err.Error(), errInsecureAuth.Error()) {
+				t.Fatalf("明文连接应拒绝发送密码，实际: %v", err)
+			}
+			server.wait(t)
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.password != "" || server.from != "" {
+				t.Errorf("拒绝认证后不应发送密码或邮件: %q %q", server.password, server.from)
+			}
+		})
+	}
+}
+
+func TestSMTPStartTLSRequired(t *testing.T) {
+	server := fakeSMTP{}
+	cfg := testSMTPConfig(startFakeSMTP(t, &server), "")
+	cfg.Security = SMTPSecuritySTARTTLS
+
+	err := NewEmailNotifier(cfg).SendMessage("测试主题", "测试正文")
+	if err == nil || !strings.Contains(err.Error(), "不支持STARTTLS") {
+		t.Fatalf("服务器不支持 STARTTLS 时应报错，实际: %v", err)
+	}
+}
+
+func TestSMTPUntrustedCertificate(t *testing.T) {
+	cert, _ := testCertificate(t)
+	server := fakeSMTP{implicitTLS: true, tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}}}
+	cfg := testSMTPConfig(startFakeSMTP(t, &server), "")
+	cfg.Security = SMTPSecurityTLS
+
+	if err := NewEmailNotifier(cfg).SendMessage("测试主题", "测试正文"); err == nil || !strings.Contains(err.Error(), "TLS连接失败") {
+		t.Fatalf("未信任的证书应导致 TLS 连接失败，实际: %v", err)
+	}
+}
+
+func TestValidateSMTPConfig(t *testing.T) {
+	valid := config.SMTPConfig{Host: "smtp.example.com", Port: 587, Security: SMTPSecuritySTARTTLS, Auth: SMTPAuthLogin, User: "u"}
+	if err := ValidateSMTPConfig(valid); err != nil {
+		t.Errorf("有效配置校验失败: %v", err)
+	}
+
+	invalid := map[string]func(*config.SMTPConfig){
+		"安全模式":   func(c *config.SMTPConfig) { c.Security = "ssl" },
+		"认证方式":   func(c *config.SMTPConfig) { c.Auth = "xoauth2" },
+		"缺少用户名":  func(c *config.SMTPConfig) { c.User = "" },
+		"负数超时":   func(c *config.SMTPConfig) { c.Timeout = -1 },
+		"无效CA证书": func(c *config.SMTPConfig) { c.CACert = "not a pem" },
+	}
+	for name, mutate := range invalid {
+		cfg := valid
+		mutate(&cfg)
+		if err := ValidateSMTPConfig(cfg); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestSMTPAuthMechanism(t *testing.T) {
+	tests := []struct {
+		auth, user, want string
+	}{
+		{SMTPAuthAuto, "", SMTPAuthNone},
+		{SMTPAuthAuto, "u", SMTPAuthPlain},
+		{SMTPAuthLogin, "u", SMTPAuthLogin},
+		{SMTPAuthNone, "u", SMTPAuthNone},
+	}
+	for _, tt := range tests {
+		if got := smtpAuthMechanism(config.SMTPConfig{Auth: tt.auth, User: tt.user}); got != tt.want {
+			t.Errorf("smtpAuthMechanism(%q, %q) = %q，期望 %q", tt.auth, tt.user, got, tt.want)
+		}
+	}
+	if smtpTimeout(config.SMTPConfig{}) != defaultSMTPTimeout || smtpTimeout(config.SMTPConfig{Timeout: 3}) != 3*time.Second {
+		t.Error("smtpTimeout 结果错误")
+	}
+}
@@ -2,14 +2,18 @@ package notification
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"Puff/config"
+	"Puff/i18n"
 )
 
 // TelegramNotifier Telegram通知器
@@ -21,16 +25,34 @@ type TelegramNotifier struct {
 
 // TelegramMessage Telegram消息结构
 type TelegramMessage struct {
-	ChatID    string `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	ChatID      string                `json:"chat_id"`
+	Text        string                `json:"text"`
+	ParseMode   string                `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// InlineKeyboardMarkup 消息内联按钮
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton 内联按钮（点击后以 callback_data 回调）
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
 }
 
 // TelegramResponse Telegram API响应
 type TelegramResponse struct {
-	OK          bool   `json:"ok"`
-	Description string `json:"description,omitempty"`
-	ErrorCode   int    `json:"error_code,omitempty"`
+	OK          bool                `json:"ok"`
+	Description string              `json:"description,omitempty"`
+	ErrorCode   int                 `json:"error_code,omitempty"`
+	Parameters  *TelegramParameters `json:"parameters,omitempty"`
+}
+
+// TelegramParameters 错误响应附带的参数（如限流时的等待秒数）
+type TelegramParameters struct {
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
 // NewTelegramNotifier 创建Telegram通知器
@@ -62,6 +84,59 @@ func (t *TelegramNotifier) SendMessage(subject, message string) error {
 	return t.sendToTelegram(formattedMessage)
 }
 
+// SendEvents 发送通知，启用交互命令时为单个域名的告警附加操作按钮
+func (t *TelegramNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	return t.SendEventsFrom(subject, message, events, Resume{})
+}
+
+// SendEventsFrom 同 SendEvents，超长消息从第 resume.Done 段继续发送
+func (t *TelegramNotifier) SendEventsFrom(subject, message string, events []NotificationEvent, resume Resume) error {
+	if !t.enabled {
+		return fmt.Errorf("Telegram通知未启用")
+	}
+
+	if err := t.validateConfig(); err != nil {
+		return fmt.Errorf("Telegram配置无效: %v", err)
+	}
+
+	if t.useCSV(events) {
+		return t.sendCSV(messageLocale(message), subject, events)
+	}
+
+	return t.send(TelegramMessage{
+		ChatID:      t.config.ChatID,
+		Text:        t.formatMessage(subject, message),
+		ReplyMarkup: t.alertKeyboard(events),
+	}, resume)
+}
+
+// SendRendered 发送自定义模板渲染的消息（不使用内置排版）
+func (t *TelegramNotifier) SendRendered(subject, body string, events []NotificationEvent) error {
+	return t.SendRenderedFrom(subject, body, events, Resume{})
+}
+
+// SendRenderedFrom 同 SendRendered，超长消息从第 resume.Done 段继续发送
+func (t *TelegramNotifier) SendRenderedFrom(subject, body string, events []NotificationEvent, resume Resume) error {
+	if !t.enabled {
+		return fmt.Errorf("Telegram通知未启用")
+	}
+
+	if err := t.validateConfig(); err != nil {
+		return fmt.Errorf("Telegram配置无效: %v", err)
+	}
+
+	if t.useCSV(events) {
+		return t.sendCSV(messageLocale(body), subject, events)
+	}
+
+	return t.send(TelegramMessage{
+		ChatID:      t.config.ChatID,
+		Text:        body,
+		ParseMode:   t.config.ParseMode,
+		ReplyMarkup: t.alertKeyboard(events),
+	}, resume)
+}
+
 // IsEnabled 检查是否启用
 func (t *TelegramNotifier) IsEnabled() bool {
 	return t.enabled && t.config.Enabled
@@ -128,69 +203,51 @@ func (t *TelegramNotifier) validateConfig() error {
 // formatMessage 格式化Telegram消息（简洁文本模板）
 func (t *TelegramNotifier) formatMessage(subject, message string) string {
 	// 检查是否为批量通知
-	if strings.Contains(message, "检测到") && strings.Contains(message, "个域名状态发生变化") {
+	if isBatchMessage(message) {
 		return t.formatBatchMessage(subject, message)
 	}
 
 	// 解析消息内容
-	lines := strings.Split(message, "\n")
-	domain := ""
-	timestamp := ""
-	statusChange := ""
-	statusInfo := ""
-	oldStatus := ""
-	newStatus := ""
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "域名: ") {
-			domain = strings.TrimPrefix(line, "域名: ")
-		} else if strings.HasPrefix(line, "时间: ") {
-			timestamp = strings.TrimPrefix(line, "时间: ")
-		} else if strings.HasPrefix(line, "状态变化: ") {
-			statusChange = strings.TrimPrefix(line, "状态变化: ")
-			// 解析 oldStatus -> newStatus
-			parts := strings.Split(statusChange, "→")
-			if len(parts) == 2 {
-				oldStatus = strings.TrimSpace(parts[0])
-				newStatus = strings.TrimSpace(parts[1])
-			}
-		} else if strings.HasPrefix(line, "状态: ") {
-			statusInfo = strings.TrimPrefix(line, "状态: ")
-		}
-	}
+	p := parseMessage(message)
+	locale := p.Locale
 
 	var formatted strings.Builder
 
 	// 标题
 	formatted.WriteString("\n")
-	formatted.WriteString("       域名状态变化通知\n")
+	formatted.WriteString("       " + i18n.T(locale, "notify.title") + "\n")
 	formatted.WriteString("\n\n")
 
 	// 域名信息
-	if domain != "" {
-		formatted.WriteString(fmt.Sprintf("域名: %s\n", domain))
+	if p.Domain != "" {
+		formatted.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "domain"), p.Domain))
+	}
+
+	// 状态变化（翻译为通知语言）
+	if p.OldStatus != "" && p.NewStatus != "" {
+		translatedOld := translateStatus(locale, p.OldStatus)
+		translatedNew := translateStatus(locale, p.NewStatus)
+		formatted.WriteString(fmt.Sprintf("\n%s: %s\n", label(locale, "old_status"), translatedOld))
+		formatted.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "new_status"), translatedNew))
+		formatted.WriteString(fmt.Sprintf("%s: %s → %s\n", label(locale, "change"), translatedOld, translatedNew))
+	} else if p.StatusInfo != "" {
+		formatted.WriteString(fmt.Sprintf("\n%s: %s\n", label(locale, "status"), p.StatusInfo))
 	}
 
-	// 状态变化（翻译成中文）
-	if oldStatus != "" && newStatus != "" {
-		translatedOld := translateStatus(oldStatus)
-		translatedNew := translateStatus(newStatus)
-		formatted.WriteString(fmt.Sprintf("\n旧状态: %s\n", translatedOld))
-		formatted.WriteString(fmt.Sprintf("新状态: %s\n", translatedNew))
-		formatted.WriteString(fmt.Sprintf("变化: %s → %s\n", translatedOld, translatedNew))
-	} else if statusInfo != "" {
-		formatted.WriteString(fmt.Sprintf("\n状态: %s\n", statusInfo))
+	// 字段变化
+	if p.OldValue != "" || p.NewValue != "" {
+		formatted.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "old_value"), p.OldValue))
+		formatted.WriteString(fmt.Sprintf("%s: %s\n", label(locale, "new_value"), p.NewValue))
 	}
 
 	// 时间
-	if timestamp != "" {
-		formatted.WriteString(fmt.Sprintf("\n时间: %s\n", timestamp))
+	if p.Timestamp != "" {
+		formatted.WriteString(fmt.Sprintf("\n%s: %s\n", label(locale, "time"), p.Timestamp))
 	}
 
 	// 底部
 	formatted.WriteString("\n\n")
-	formatted.WriteString("来自 Puff\n")
+	formatted.WriteString(i18n.T(locale, "notify.from") + "\n")
 
 	return formatted.String()
 }
@@ -198,66 +255,25 @@ func (t *TelegramNotifier) formatMessage(subject, message string) string {
 // formatBatchMessage 格式化批量通知消息
 func (t *TelegramNotifier) formatBatchMessage(subject, message string) string {
 	// 解析消息内容
-	lines := strings.Split(message, "\n")
-	timestamp := ""
-	domainChanges := []struct {
-		Domain    string
-		OldStatus string
-		NewStatus string
-	}{}
-
-	// 解析时间和域名变化
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "时间: ") {
-			timestamp = strings.TrimPrefix(line, "时间: ")
-		} else if strings.Contains(line, ". ") && !strings.HasPrefix(line, "---") {
-			// 解析域名行 "1. test1.puff"
-			parts := strings.SplitN(line, ". ", 2)
-			if len(parts) == 2 {
-				domain := parts[1]
-				// 下一行应该是状态变化
-				if i+1 < len(lines) {
-					nextLine := strings.TrimSpace(lines[i+1])
-					if strings.HasPrefix(nextLine, "状态变化: ") {
-						statusChangeStr := strings.TrimPrefix(nextLine, "状态变化: ")
-						statusParts := strings.Split(statusChangeStr, "→")
-						if len(statusParts) == 2 {
-							domainChanges = append(domainChanges, struct {
-								Domain    string
-								OldStatus string
-								NewStatus string
-							}{
-								Domain:    domain,
-								OldStatus: strings.TrimSpace(statusParts[0]),
-								NewStatus: strings.TrimSpace(statusParts[1]),
-							})
-						}
-					}
-				}
-			}
-		}
-	}
+	locale := messageLocale(message)
+	timestamp, domainChanges := parseBatchMessage(message)
 
 	var formatted strings.Builder
 
 	// 标题
 	formatted.WriteString("\n")
-	formatted.WriteString(fmt.Sprintf("  域名状态变化通知 (%d个)\n", len(domainChanges)))
+	formatted.WriteString("  " + i18n.T(locale, "notify.batch_title", len(domainChanges)) + "\n")
 	formatted.WriteString("\n\n")
 
 	// 时间
 	if timestamp != "" {
-		formatted.WriteString(fmt.Sprintf("时间: %s\n\n", timestamp))
+		formatted.WriteString(fmt.Sprintf("%s: %s\n\n", label(locale, "time"), timestamp))
 	}
 
 	// 列出所有域名变化
 	for i, change := range domainChanges {
-		translatedOld := translateStatus(change.OldStatus)
-		translatedNew := translateStatus(change.NewStatus)
-
 		formatted.WriteString(fmt.Sprintf("%d. %s\n", i+1, change.Domain))
-		formatted.WriteString(fmt.Sprintf("   %s → %s\n", translatedOld, translatedNew))
+		formatted.WriteString(fmt.Sprintf("   %s\n", translateStatusChange(locale, change.OldStatus, change.NewStatus)))
 
 		if i < len(domainChanges)-1 {
 			formatted.WriteString("\n")
@@ -266,7 +282,7 @@ func (t *TelegramNotifier) formatBatchMessage(subject, message string) string {
 
 	// 底部
 	formatted.WriteString("\n\n")
-	formatted.WriteString("来自 Puff\n")
+	formatted.WriteString(i18n.T(locale, "notify.from") + "\n")
 
 	return formatted.String()
 }
@@ -283,19 +299,58 @@ func (t *TelegramNotifier) escapeMarkdown(text string) string {
 	return text
 }
 
+// apiURL 构建 Bot API 方法地址
+func (t *TelegramNotifier) apiURL(method string) string {
+	base := strings.TrimRight(t.config.APIBase, "/")
+	if base == "" {
+		base = "https://api.telegram.org"
+	}
+	return fmt.Sprintf("%s/bot%s/%s", base, t.config.BotToken, method)
+}
+
 // sendToTelegram 发送消息到Telegram
 func (t *TelegramNotifier) sendToTelegram(message string) error {
-	// 构建API URL
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.config.BotToken)
-
-	// 构建消息（不使用Markdown格式，使用纯文本）
-	telegramMsg := TelegramMessage{
+	// 不使用Markdown格式，使用纯文本
+	return t.send(TelegramMessage{
 		ChatID: t.config.ChatID,
 		Text:   message,
+	}, Resume{})
+}
+
+// send 通过聊天队列调用 sendMessage 发送消息，超长时拆分为多条（按钮附在最后一条）
+// 拆分结果只取决于正文，重试时从 resume.Done 段继续，不重复发送已成功的分段
+func (t *TelegramNotifier) send(telegramMsg TelegramMessage, resume Resume) error {
+	chunks := splitTelegramMessage(telegramMsg.Text, telegramMsg.ParseMode, telegramMaxMessageLen)
+	queue := telegramQueue(t.config.BotToken, telegramMsg.ChatID)
+	interval := telegramInterval(telegramMsg.ChatID)
+
+	queue.message.Lock()
+	defer queue.message.Unlock()
+	for i := resume.Done; i < len(chunks); i++ {
+		part := telegramMsg
+		part.Text = chunks[i]
+		if i < len(chunks)-1 {
+			part.ReplyMarkup = nil
+		}
+		if err := queue.do(interval, func() error { return t.call("sendMessage", part) }); err != nil {
+			if len(chunks) > 1 {
+				return fmt.Errorf("发送第%d/%d段消息失败: %w", i+1, len(chunks), err)
+			}
+			return err
+		}
+		if resume.Sent != nil {
+			resume.Sent(i + 1)
+		}
 	}
+	return nil
+}
+
+// call 以 JSON 请求体调用 Bot API 方法
+func (t *TelegramNotifier) call(method string, payload interface{}) error {
+	apiURL := t.apiURL(method)
 
 	// 编码为JSON
-	jsonData, err := json.Marshal(telegramMsg)
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("编码JSON失败: %v", err)
 	}
@@ -321,11 +376,21 @@ func (t *TelegramNotifier) sendToTelegram(message string) error {
 		return fmt.Errorf("解析响应失败: %v", err)
 	}
 
-	if !telegramResp.OK {
-		return fmt.Errorf("Telegram API错误 [%d]: %s", telegramResp.ErrorCode, telegramResp.Description)
-	}
+	return telegramError(telegramResp)
+}
 
-	return nil
+// telegramError 将失败响应转换为错误，限流时返回 telegramRetryError
+func telegramError(resp TelegramResponse) error {
+	if resp.OK {
+		return nil
+	}
+	if resp.ErrorCode == http.StatusTooManyRequests && resp.Parameters != nil && resp.Parameters.RetryAfter > 0 {
+		return &telegramRetryError{
+			RetryAfter:  time.Duration(resp.Parameters.RetryAfter) * time.Second,
+			Description: resp.Description,
+		}
+	}
+	return fmt.Errorf("Telegram API错误 [%d]: %s", resp.ErrorCode, resp.Description)
 }
 
 // GetBotInfo 获取Bot信息
@@ -334,7 +399,7 @@ func (t *TelegramNotifier) GetBotInfo() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("Telegram通知未启用")
 	}
 
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/getMe", t.config.BotToken)
+	apiURL := t.apiURL("getMe")
 
 	resp, err := t.httpClient.Get(apiURL)
 	if err != nil {
@@ -356,7 +421,7 @@ func (t *TelegramNotifier) GetChatInfo() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("Telegram通知未启用")
 	}
 
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/getChat?chat_id=%s", t.config.BotToken, t.config.ChatID)
+	apiURL := t.apiURL("getChat") + "?chat_id=" + url.QueryEscape(t.config.ChatID)
 
 	resp, err := t.httpClient.Get(apiURL)
 	if err != nil {
@@ -376,3 +441,78 @@ func (t *TelegramNotifier) GetChatInfo() (map[string]interface{}, error) {
 func (t *TelegramNotifier) SetEnabled(enabled bool) {
 	t.enabled = enabled
 }
+
+// useCSV 批量事件数达到阈值时改为发送 CSV 附件
+func (t *TelegramNotifier) useCSV(events []NotificationEvent) bool {
+	return t.config.CSVThreshold > 0 && len(events) >= t.config.CSVThreshold
+}
+
+// buildEventsCSV 生成事件列表的 CSV（带 BOM，便于表格软件识别中文）
+func buildEventsCSV(locale string, events []NotificationEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	w.Write(strings.Split(i18n.T(locale, "notify.csv.header"), ","))
+	for _, e := range events {
+		w.Write([]string{
+			e.Domain,
+			e.Type,
+			translateStatus(locale, e.OldStatus),
+			translateStatus(locale, e.Status),
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			e.Message,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// sendCSV 以 CSV 文件发送批量通知，正文只保留概要
+func (t *TelegramNotifier) sendCSV(locale, subject string, events []NotificationEvent) error {
+	data, err := buildEventsCSV(locale, events)
+	if err != nil {
+		return fmt.Errorf("生成CSV失败: %v", err)
+	}
+	now := time.Now()
+	caption := i18n.T(locale, "notify.csv.caption", subject, len(events), now.Format("2006-01-02 15:04:05"))
+	filename := fmt.Sprintf("puff-%s.csv", now.Format("20060102-150405"))
+
+	queue := telegramQueue(t.config.BotToken, t.config.ChatID)
+	return queue.do(telegramInterval(t.config.ChatID), func() error {
+		return t.sendDocument(caption, filename, data)
+	})
+}
+
+// sendDocument 调用 sendDocument 上传文件
+func (t *TelegramNotifier) sendDocument(caption, filename string, data []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("chat_id", t.config.ChatID)
+	form.WriteField("caption", caption)
+	part, err := form.CreateFormFile("document", filename)
+	if err != nil {
+		return fmt.Errorf("创建上传表单失败: %v", err)
+	}
+	part.Write(data)
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("创建上传表单失败: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", t.apiURL("sendDocument"), &body)
+	if err != nil {
+		return fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var telegramResp TelegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&telegramResp); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return telegramError(telegramResp)
+}
@@ -0,0 +1,542 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/core"
+	"Puff/eventsink"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+const (
+	botPollTimeout    = 25              // getUpdates 长轮询时间（秒）
+	botRetryInterval  = 5 * time.Second // 轮询出错后的等待时间
+	botMaxMessageLen  = 3800            // 单条回复的最大长度（Telegram 上限 4096）
+	botMaxCallbackLen = 64              // callback_data 的最大字节数
+	botCommandHelp    = `可用命令：
+/add 域名 [域名...] - 添加监控
+/remove 域名 - 移除监控
+/list - 查看监控列表
+/check 域名 - 立即检查
+/status - 查看监控状态`
+)
+
+// telegramChat 聊天信息
+type telegramChat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// telegramIncoming 收到的消息
+type telegramIncoming struct {
+	MessageID int64        `json:"message_id"`
+	Chat      telegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+// telegramUpdate getUpdates 返回的更新
+type telegramUpdate struct {
+	UpdateID      int64             `json:"update_id"`
+	Message       *telegramIncoming `json:"message"`
+	CallbackQuery *struct {
+		ID      string            `json:"id"`
+		Data    string            `json:"data"`
+		Message *telegramIncoming `json:"message"`
+	} `json:"callback_query"`
+}
+
+// alertKeyboard 单个域名的告警附加“重新检查 / 静音 / WHOIS”按钮
+func (t *TelegramNotifier) alertKeyboard(events []NotificationEvent) *InlineKeyboardMarkup {
+	if !t.config.Commands || len(events) != 1 || events[0].Domain == "" {
+		return nil
+	}
+	domain := events[0].Domain
+	if len("whois:"+domain) > botMaxCallbackLen {
+		return nil
+	}
+	return &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "🔄 重新检查", CallbackData: "check:" + domain},
+		{Text: "🔕 静音", CallbackData: "mute:" + domain},
+		{Text: "📄 WHOIS", CallbackData: "whois:" + domain},
+	}}}
+}
+
+// TelegramBot 通过长轮询接收 Telegram 命令与按钮回调（仅响应配置的聊天）
+type TelegramBot struct {
+	notifier *TelegramNotifier
+	monitor  *core.Monitor
+	events   *eventsink.Sink // 记录会修改监控的命令，可为空
+	client   *http.Client
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewTelegramBot 创建 Telegram 交互机器人
+func NewTelegramBot(notifier *TelegramNotifier, monitor *core.Monitor) *TelegramBot {
+	return &TelegramBot{
+		notifier: notifier,
+		monitor:  monitor,
+		client:   &http.Client{Timeout: (botPollTimeout + 10) * time.Second},
+	}
+}
+
+// Start 启动长轮询
+func (b *TelegramBot) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	go b.run(ctx)
+}
+
+// Stop 停止长轮询并等待退出
+func (b *TelegramBot) Stop() {
+	if b.cancel == nil {
+		return
+	}
+	b.cancel()
+	<-b.done
+}
+
+// run 轮询循环
+func (b *TelegramBot) run(ctx context.Context) {
+	defer close(b.done)
+	logger.Info("Telegram 交互命令已启动，聊天: %s", b.notifier.config.ChatID)
+
+	var offset int64
+	for {
+		updates, err := b.getUpdates(ctx, offset)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warn("获取Telegram更新失败: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(botRetryInterval):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			b.handleUpdate(update)
+		}
+	}
+}
+
+// getUpdates 长轮询获取更新
+func (b *TelegramBot) getUpdates(ctx context.Context, offset int64) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("timeout", strconv.Itoa(botPollTimeout))
+	params.Set("allowed_updates", `["message","callback_query"]`)
+	if offset > 0 {
+		params.Set("offset", strconv.FormatInt(offset, 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.notifier.apiURL("getUpdates")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		TelegramResponse
+		Result []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("Telegram API错误 [%d]: %s", result.ErrorCode, result.Description)
+	}
+	return result.Result, nil
+}
+
+// authorized 是否来自配置的聊天
+func (b *TelegramBot) authorized(chat telegramChat) bool {
+	chatID := strings.TrimSpace(b.notifier.config.ChatID)
+	return chatID == strconv.FormatInt(chat.ID, 10) ||
+		(chat.Username != "" && strings.EqualFold(chatID, "@"+chat.Username))
+}
+
+// handleUpdate 处理一条更新
+func (b *TelegramBot) handleUpdate(update telegramUpdate) {
+	switch {
+	case update.Message != nil:
+		if !b.authorized(update.Message.Chat) {
+			logger.Warn("忽略来自未授权聊天 %d 的Telegram消息", update.Message.Chat.ID)
+			return
+		}
+		if reply := b.handleCommand(update.Message.Text); reply != "" {
+			if isMutatingCommand(update.Message.Text) {
+				b.audit(update.Message.Chat, strings.TrimSpace(update.Message.Text), "success", reply)
+			}
+			b.reply(reply)
+		}
+	case update.CallbackQuery != nil:
+		query := update.CallbackQuery
+		if query.Message == nil || !b.authorized(query.Message.Chat) {
+			b.answerCallback(query.ID, "无权操作")
+			return
+		}
+		b.handleCallback(query.Message.Chat, query.ID, query.Data)
+	}
+}
+
+// isMutatingCommand 是否为会修改监控列表的命令
+func isMutatingCommand(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return false
+	}
+	command := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+	return command == "/add" || command == "/remove"
+}
+
+// audit 将命令记录到事件流
+func (b *TelegramBot) audit(chat telegramChat, action, result, message string) {
+	actor := strconv.FormatInt(chat.ID, 10)
+	if chat.Username != "" {
+		actor = "@" + chat.Username
+	}
+	b.events.Publish(eventsink.Event{
+		Type:    eventsink.TypeAdminAction,
+		Source:  "telegram",
+		Actor:   actor,
+		Action:  action,
+		Result:  result,
+		Message: message,
+	})
+}
+
+// handleCommand 执行命令，返回回复内容（非命令消息返回空）
+func (b *TelegramBot) handleCommand(text string) string {
+	fields := strings.Fields(strings.ReplaceAll(text, ",", " "))
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// 群聊中命令形如 /add@PuffBot
+	command := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+	args := fields[1:]
+
+	switch command {
+	case "/start", "/help":
+		return botCommandHelp
+	case "/add":
+		if len(args) == 0 {
+			return "用法: /add 域名 [域名...]"
+		}
+		return b.addDomains(args)
+	case "/remove":
+		if len(args) == 0 {
+			return "用法: /remove 域名"
+		}
+		return b.removeDomains(args)
+	case "/list":
+		return b.listDomains()
+	case "/check":
+		if len(args) == 0 {
+			return "用法: /check 域名"
+		}
+		return b.checkDomain(args[0])
+	case "/status":
+		return b.status()
+	default:
+		return "未知命令\n\n" + botCommandHelp
+	}
+}
+
+// handleCallback 处理告警按钮：先应答回调，再执行操作并回复结果
+func (b *TelegramBot) handleCallback(chat telegramChat, id, data string) {
+	action, domain, ok := strings.Cut(data, ":")
+	if !ok || domain == "" {
+		b.answerCallback(id, "无效的操作")
+		return
+	}
+
+	switch action {
+	case "check":
+		b.answerCallback(id, "正在检查 "+domain)
+		b.reply(b.checkDomain(domain))
+	case "mute":
+		if err := b.monitor.SetDomainNotify(domain, false); err != nil {
+			b.audit(chat, "mute "+domain, "failure", err.Error())
+			b.answerCallback(id, "静音失败: "+err.Error())
+			return
+		}
+		b.audit(chat, "mute "+domain, "success", "")
+		b.answerCallback(id, "已静音 "+domain)
+		b.reply(fmt.Sprintf("🔕 %s 已静音，将不再发送通知", domain))
+	case "whois":
+		result, err := storage.GetDomainResult(domain)
+		if err != nil || result == nil || result.WhoisRaw == "" {
+			b.answerCallback(id, "暂无 "+domain+" 的WHOIS信息")
+			return
+		}
+		b.answerCallback(id, "")
+		b.reply(fmt.Sprintf("%s WHOIS/RDAP 信息:\n\n%s", domain, result.WhoisRaw))
+	default:
+		b.answerCallback(id, "未知操作")
+	}
+}
+
+// addDomains 添加域名到监控
+func (b *TelegramBot) addDomains(domains []string) string {
+	existing := make(map[string]bool)
+	entries, err := storage.ListDomains(false)
+	if err != nil {
+		return "读取域名列表失败: " + err.Error()
+	}
+	for _, entry := range entries {
+		existing[entry.Name] = true
+	}
+
+	var added, skipped, failed []string
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if existing[domain] {
+			skipped = append(skipped, domain)
+			continue
+		}
+		if err := b.monitor.GetChecker().ValidateDomain(domain); err != nil {
+			failed = append(failed, domain)
+			continue
+		}
+		if err := storage.AddDomain(domain, true, true); err != nil {
+			failed = append(failed, domain)
+			continue
+		}
+		if err := b.monitor.AddDomain(domain, true); err != nil {
+			failed = append(failed, domain)
+			continue
+		}
+		existing[domain] = true
+		added = append(added, domain)
+	}
+
+	var reply strings.Builder
+	if len(added) > 0 {
+		reply.WriteString("✅ 已添加: " + strings.Join(added, ", ") + "\n")
+	}
+	if len(skipped) > 0 {
+		reply.WriteString("已存在: " + strings.Join(skipped, ", ") + "\n")
+	}
+	if len(failed) > 0 {
+		reply.WriteString("❌ 无效或添加失败: " + strings.Join(failed, ", ") + "\n")
+	}
+	return strings.TrimSpace(reply.String())
+}
+
+// removeDomains 从监控中移除域名
+func (b *TelegramBot) removeDomains(domains []string) string {
+	var removed, failed []string
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if err := storage.RemoveDomain(domain); err != nil {
+			failed = append(failed, domain)
+			continue
+		}
+		b.monitor.RemoveDomain(domain)
+		removed = append(removed, domain)
+	}
+
+	var reply strings.Builder
+	if len(removed) > 0 {
+		reply.WriteString("🗑 已移除: " + strings.Join(removed, ", ") + "\n")
+	}
+	if len(failed) > 0 {
+		reply.WriteString("❌ 不存在或移除失败: " + strings.Join(failed, ", ") + "\n")
+	}
+	return strings.TrimSpace(reply.String())
+}
+
+// listDomains 列出监控中的域名及状态
+func (b *TelegramBot) listDomains() string {
+	entries, err := storage.ListDomains(false)
+	if err != nil {
+		return "读取域名列表失败: " + err.Error()
+	}
+	if len(entries) == 0 {
+		return "监控列表为空，使用 /add 添加域名"
+	}
+
+	statuses := make(map[string]core.DomainStatus)
+	for _, info := range b.monitor.GetAllDomainInfo() {
+		statuses[info.Name] = info.Status
+	}
+
+	var list strings.Builder
+	list.WriteString(fmt.Sprintf("监控中的域名（%d个）:\n", len(entries)))
+	for i, entry := range entries {
+		status := translateStatus(i18n.Default, string(statuses[entry.Name]))
+		if status == "" {
+			status = "待检查"
+		}
+		line := fmt.Sprintf("%d. %s - %s", i+1, entry.Name, status)
+		if !entry.Notify {
+			line += " 🔕"
+		}
+		if !entry.Enabled {
+			line += "（已停用）"
+		}
+		if list.Len()+len(line) > botMaxMessageLen {
+			list.WriteString(fmt.Sprintf("... 其余 %d 个未显示", len(entries)-i))
+			break
+		}
+		list.WriteString(line + "\n")
+	}
+	return strings.TrimSpace(list.String())
+}
+
+// checkDomain 立即检查域名并返回结果
+func (b *TelegramBot) checkDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	info, err := b.monitor.ForceCheck(domain)
+	if err != nil {
+		return fmt.Sprintf("❌ 检查 %s 失败: %v", domain, err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("域名: %s\n", info.Name))
+	result.WriteString(fmt.Sprintf("状态: %s\n", translateStatus(i18n.Default, string(info.Status))))
+	if info.Registrar != "" {
+		result.WriteString(fmt.Sprintf("注册商: %s\n", info.Registrar))
+	}
+	if info.ExpiryDate != nil {
+		result.WriteString(fmt.Sprintf("过期时间: %s\n", info.ExpiryDate.Format("2006-01-02")))
+	}
+	if info.ErrorMessage != "" {
+		result.WriteString(fmt.Sprintf("错误信息: %s\n", info.ErrorMessage))
+	}
+	result.WriteString(fmt.Sprintf("查询方式: %s", info.QueryMethod))
+	return result.String()
+}
+
+// status 监控运行状态概览
+func (b *TelegramBot) status() string {
+	stats := b.monitor.GetStats()
+
+	var result strings.Builder
+	if running, _ := stats["is_running"].(bool); running {
+		result.WriteString("监控状态: 运行中\n")
+	} else {
+		result.WriteString("监控状态: 已停止\n")
+	}
+	result.WriteString(fmt.Sprintf("域名数量: %v\n", stats["domain_count"]))
+	result.WriteString(fmt.Sprintf("运行时间: %v\n", stats["uptime"]))
+
+	if counts, ok := stats["status_counts"].(map[core.DomainStatus]int); ok && len(counts) > 0 {
+		statuses := make([]string, 0, len(counts))
+		for status := range counts {
+			statuses = append(statuses, string(status))
+		}
+		sort.Strings(statuses)
+		result.WriteString("\n")
+		for _, status := range statuses {
+			result.WriteString(fmt.Sprintf("%s: %d\n", translateStatus(i18n.Default, status), counts[core.DomainStatus(status)]))
+		}
+	}
+	return strings.TrimSpace(result.String())
+}
+
+// reply 向配置的聊天发送回复（超长时截断）
+func (b *TelegramBot) reply(text string) {
+	if runes := []rune(text); len(runes) > botMaxMessageLen {
+		text = string(runes[:botMaxMessageLen]) + "\n...(已截断)"
+	}
+	if err := b.notifier.sendToTelegram(text); err != nil {
+		logger.Error("回复Telegram命令失败: %v", err)
+	}
+}
+
+// answerCallback 应答按钮回调（消除按钮上的加载状态）
+func (b *TelegramBot) answerCallback(id, text string) {
+	payload := map[string]string{"callback_query_id": id}
+	if text != "" {
+		payload["text"] = text
+	}
+	if err := b.notifier.call("answerCallbackQuery", payload); err != nil {
+		logger.Warn("应答Telegram回调失败: %v", err)
+	}
+}
+
+// telegramBots 按通知渠道实例管理交互机器人
+type telegramBots struct {
+	mu      sync.Mutex
+	monitor *core.Monitor // 为空时不启动机器人
+	events  *eventsink.Sink
+	bots    map[int64]*TelegramBot
+}
+
+// SetEventSink 设置事件流导出，机器人执行的修改操作会记录为管理操作事件（需在 EnableTelegramBots 之前调用）
+func (nm *NotificationManager) SetEventSink(sink *eventsink.Sink) {
+	nm.bots.mu.Lock()
+	defer nm.bots.mu.Unlock()
+	nm.bots.events = sink
+}
+
+// EnableTelegramBots 设置监控器，并为已启用交互命令的 Telegram 渠道启动机器人
+func (nm *NotificationManager) EnableTelegramBots(monitor *core.Monitor) {
+	nm.bots.mu.Lock()
+	nm.bots.monitor = monitor
+	nm.bots.mu.Unlock()
+
+	for _, notifier := range nm.GetNotifiers() {
+		if inst, ok := notifier.(*InstanceNotifier); ok {
+			nm.syncTelegramBot(inst.ID, inst)
+		}
+	}
+}
+
+// syncTelegramBot 渠道变更后重启（或停止）对应的机器人；inst 为空表示渠道已删除
+func (nm *NotificationManager) syncTelegramBot(id int64, inst *InstanceNotifier) {
+	nm.bots.mu.Lock()
+	defer nm.bots.mu.Unlock()
+
+	if bot, ok := nm.bots.bots[id]; ok {
+		bot.Stop()
+		delete(nm.bots.bots, id)
+	}
+	if inst == nil || nm.bots.monitor == nil || !inst.IsEnabled() {
+		return
+	}
+	tg, ok := inst.Notifier.(*TelegramNotifier)
+	if !ok || !tg.config.Commands || tg.config.BotToken == "" {
+		return
+	}
+
+	bot := NewTelegramBot(tg, nm.bots.monitor)
+	bot.events = nm.bots.events
+	bot.Start()
+	nm.bots.bots[id] = bot
+}
+
+// stopTelegramBots 停止全部机器人
+func (nm *NotificationManager) stopTelegramBots() {
+	nm.bots.mu.Lock()
+	defer nm.bots.mu.Unlock()
+
+	for id, bot := range nm.bots.bots {
+		bot.Stop()
+		delete(nm.bots.bots, id)
+	}
+	nm.bots.monitor = nil
+}
@@ -0,0 +1,241 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/storage"
+)
+
+// botCall 机器人发出的一次 Bot API 调用
+type botCall struct {
+	method  string
+	payload map[string]interface{}
+}
+
+// testBot 创建指向模拟 API 的交互机器人，返回读取已发出调用的函数
+func testBot(t *testing.T) (*TelegramBot, func() []botCall) {
+	var mu sync.Mutex
+	var calls []botCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		calls = append(calls, botCall{r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], payload})
+		mu.Unlock()
+		json.NewEncoder(w).Encode(TelegramResponse{OK: true})
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier := NewTelegramNotifier(config.TelegramConfig{
+		BotToken: testBotToken(t),
+		ChatID:   "201",
+		APIBase:  srv.URL,
+		Commands: true,
+		Enabled:  true,
+	})
+	bot := NewTelegramBot(notifier, core.NewMonitor(&config.Config{}, nil))
+	return bot, func() []botCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]botCall(nil), calls...)
+	}
+}
+
+func TestBotAuthorized(t *testing.T) {
+	bot, _ := testBot(t)
+	tests := []struct {
+		chatID  string
+		chat    telegramChat
+		allowed bool
+	}{
+		{"201", telegramChat{ID: 201}, true},
+		{" 201 ", telegramChat{ID: 201}, true},
+		{"201", telegramChat{ID: 202}, false},
+		{"@PuffOps", telegramChat{ID: 9, Username: "puffops"}, true},
+		{"@PuffOps", telegramChat{ID: 9}, false},
+		{"@", telegramChat{ID: 9}, false},
+	}
+	for _, tt := range tests {
+		bot.notifier.config.ChatID = tt.chatID
+		if got := bot.authorized(tt.chat); got != tt.allowed {
+			t.Errorf("authorized(%q, %+v) = %v, 期望 %v", tt.chatID, tt.chat, got, tt.allowed)
+		}
+	}
+}
+
+func TestBotCommands(t *testing.T) {
+	bot, _ := testBot(t)
+	t.Cleanup(func() {
+		for _, domain := range []string{"bot-a.com", "bot-b.com"} {
+			storage.RemoveDomain(domain)
+		}
+	})
+
+	tests := []struct {
+		text string
+		want []string // 回复应包含的内容，为空表示不回复
+	}{
+		{"你好", nil},
+		{"", nil},
+		{"/help", []string{"/add 域名"}},
+		{"/add", []string{"用法: /add"}},
+		{"/add@PuffBot BOT-A.com,bot-b.com bad_domain", []string{"✅ 已添加: bot-a.com, bot-b.com", "❌ 无效或添加失败: bad_domain"}},
+		{"/add bot-a.com", []string{"已存在: bot-a.com"}},
+		{"/list", []string{"监控中的域名", "bot-a.com - 待检查", "bot-b.com - 待检查"}},
+		{"/remove bot-b.com", []string{"🗑 已移除: bot-b.com"}},
+		{"/remove", []string{"用法: /remove"}},
+		{"/check", []string{"用法: /check"}},
+		{"/status", []string{"监控状态: 已停止", "域名数量:"}},
+		{"/unknown", []string{"未知命令", "/add 域名"}},
+	}
+	for _, tt := range tests {
+		got := bot.handleCommand(tt.text)
+		if tt.want == nil {
+			if got != "" {
+				t.Errorf("%q 不应回复: %q", tt.text, got)
+			}
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%q 的回复缺少 %q:\n%s", tt.text, want, got)
+			}
+		}
+	}
+
+	if list := bot.handleCommand("/list"); strings.Contains(list, "bot-b.com") {
+		t.Errorf("移除后不应再列出: %s", list)
+	}
+}
+
+func TestIsMutatingCommand(t *testing.T) {
+	for text, want := range map[string]bool{
+		"/add a.com":            true,
+		"/REMOVE@PuffBot a.com": true,
+		"/add":                  false,
+		"/list":                 false,
+		"/check a.com":          false,
+		"add a.com":             false,
+	} {
+		if got := isMutatingCommand(text); got != want {
+			t.Errorf("isMutatingCommand(%q) = %v, 期望 %v", text, got, want)
+		}
+	}
+}
+
+func TestBotCallbacks(t *testing.T) {
+	bot, calls := testBot(t)
+	chat := telegramChat{ID: 201}
+	if err := storage.AddDomain("bot-cb.com", true, true); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.RemoveDomain("bot-cb.com") })
+	if err := storage.SaveDomainResult(storage.DomainResult{Domain: "bot-cb.com", Status: "registered", WhoisRaw: "Domain Name: BOT-CB.COM"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.handleCallback(chat, "q1", "mute:bot-cb.com")
+	bot.handleCallback(chat, "q2", "whois:bot-cb.com")
+	bot.handleCallback(chat, "q3", "whois:bot-none.com")
+	bot.handleCallback(chat, "q4", "check")
+	bot.handleCallback(chat, "q5", "reboot:bot-cb.com")
+	bot.handleCallback(chat, "q6", "mute:bot-none.com")
+
+	answers := make(map[string]string)
+	var replies []string
+	for _, call := range calls() {
+		switch call.method {
+		case "answerCallbackQuery":
+			text, _ := call.payload["text"].(string)
+			answers[call.payload["callback_query_id"].(string)] = text
+		case "sendMessage":
+			replies = append(replies, call.payload["text"].(string))
+		}
+	}
+
+	want := map[string]string{
+		"q1": "已静音 bot-cb.com",
+		"q2": "",
+		"q3": "暂无 bot-none.com 的WHOIS信息",
+		"q4": "无效的操作",
+		"q5": "未知操作",
+		"q6": "静音失败: 域名 bot-none.com 不在监控列表中",
+	}
+	for id, text := range want {
+		if got, ok := answers[id]; !ok || got != text {
+			t.Errorf("回调 %s 的应答 = %q, 期望 %q", id, got, text)
+		}
+	}
+	if len(replies) != 2 || !strings.Contains(replies[0], "bot-cb.com 已静音") || !strings.Contains(replies[1], "Domain Name: BOT-CB.COM") {
+		t.Errorf("回复内容错误: %q", replies)
+	}
+
+	entries, _ := storage.ListDomains(false)
+	for _, entry := range entries {
+		if entry.Name == "bot-cb.com" && entry.Notify {
+			t.Error("静音后应关闭域名通知")
+		}
+	}
+}
+
+func TestBotHandleUpdateUnauthorized(t *testing.T) {
+	bot, calls := testBot(t)
+	other := telegramChat{ID: 999}
+
+	// 未授权聊天的消息直接忽略，按钮回调应答“无权操作”
+	bot.handleUpdate(telegramUpdate{Message: &telegramIncoming{Chat: other, Text: "/help"}})
+	update := telegramUpdate{}
+	update.CallbackQuery = &struct {
+		ID      string            `json:"id"`
+		Data    string            `json:"data"`
+		Message *telegramIncoming `json:"message"`
+	}{ID: "q1", Data: "mute:a.com", Message: &telegramIncoming{Chat: other}}
+	bot.handleUpdate(update)
+
+	got := calls()
+	if len(got) != 1 || got[0].method != "answerCallbackQuery" || got[0].payload["text"] != "无权操作" {
+		t.Errorf("未授权聊天的调用错误: %+v", got)
+	}
+
+	// 授权聊天的命令会得到回复
+	bot.handleUpdate(telegramUpdate{Message: &telegramIncoming{Chat: telegramChat{ID: 201}, Text: "/help"}})
+	if got = calls(); len(got) != 2 || got[1].method != "sendMessage" || got[1].payload["chat_id"] != "201" {
+		t.Errorf("授权聊天应收到回复: %+v", got)
+	}
+}
+
+func TestAlertKeyboard(t *testing.T) {
+	notifier := NewTelegramNotifier(config.TelegramConfig{Commands: true})
+	keyboard := notifier.alertKeyboard([]NotificationEvent{{Domain: "kb.com"}})
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 1 || len(keyboard.InlineKeyboard[0]) != 3 {
+		t.Fatalf("单个域名应附加三个按钮: %+v", keyboard)
+	}
+	for i, prefix := range []string{"check:", "mute:", "whois:"} {
+		if data := keyboard.InlineKeyboard[0][i].CallbackData; data != prefix+"kb.com" {
+			t.Errorf("第 %d 个按钮数据 = %q", i+1, data)
+		}
+	}
+
+	long := strings.Repeat("a", botMaxCallbackLen) + ".com"
+	for name, events := range map[string][]NotificationEvent{
+		"多个事件": {{Domain: "a.com"}, {Domain: "b.com"}},
+		"无域名":  {{Type: "test"}},
+		"域名过长": {{Domain: long}},
+		"无事件":  nil,
+	} {
+		if notifier.alertKeyboard(events) != nil {
+			t.Errorf("%s时不应附加按钮", name)
+		}
+	}
+	notifier.config.Commands = false
+	if notifier.alertKeyboard([]NotificationEvent{{Domain: "kb.com"}}) != nil {
+		t.Error("未开启交互命令时不应附加按钮")
+	}
+}
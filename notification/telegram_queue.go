@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"Puff/logger"
+)
+
+const (
+	telegramChatInterval  = 1 * time.Second  // 私聊相邻消息的最小间隔
+	telegramGroupInterval = 3 * time.Second  // 群组/频道相邻消息的最小间隔（每分钟最多 20 条）
+	telegramMaxRetryWait  = 60 * time.Second // 429 要求等待超过该时间时交给发件箱稍后重试
+	telegramMaxRetries    = 3                // 429 时的最大重试次数
+)
+
+// telegramRetryError Telegram 返回 429 时的错误，附带建议等待时间
+type telegramRetryError struct {
+	RetryAfter  time.Duration
+	Description string
+}
+
+func (e *telegramRetryError) Error() string {
+	return fmt.Sprintf("Telegram API错误 [429]: %s（需等待 %v）", e.Description, e.RetryAfter)
+}
+
+// telegramChatQueue 单个聊天的发送队列：串行发送并遵守发送间隔
+type telegramChatQueue struct {
+	mu      sync.Mutex
+	next    time.Time  // 下一条消息最早的发送时间
+	message sync.Mutex // 拆分后的消息整体持有，保证各段连续发送、不与其他消息交错
+}
+
+var telegramQueues = struct {
+	mu    sync.Mutex
+	chats map[string]*telegramChatQueue
+}{chats: make(map[string]*telegramChatQueue)}
+
+// telegramQueue 获取 Bot 与聊天对应的发送队列
+func telegramQueue(botToken, chatID string) *telegramChatQueue {
+	key := botToken + "|" + chatID
+	telegramQueues.mu.Lock()
+	defer telegramQueues.mu.Unlock()
+
+	q, ok := telegramQueues.chats[key]
+	if !ok {
+		q = &telegramChatQueue{}
+		telegramQueues.chats[key] = q
+	}
+	return q
+}
+
+// telegramInterval 群组（负数 ID）与频道（@username）的发送限制比私聊更严格
+func telegramInterval(chatID string) time.Duration {
+	if strings.HasPrefix(chatID, "-") || strings.HasPrefix(chatID, "@") {
+		return telegramGroupInterval
+	}
+	return telegramChatInterval
+}
+
+// do 按队列顺序发送；遇到 429 时等待 retry_after 后重试，等待过长则返回错误由发件箱重试
+func (q *telegramChatQueue) do(interval time.Duration, send func() error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		if wait := time.Until(q.next); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		err := send()
+		q.next = time.Now().Add(interval)
+
+		var retry *telegramRetryError
+		if !errors.As(err, &retry) {
+			return err
+		}
+		q.next = time.Now().Add(retry.RetryAfter)
+		if retry.RetryAfter > telegramMaxRetryWait || attempt >= telegramMaxRetries {
+			return err
+		}
+		logger.Warn("Telegram 发送过于频繁，%v 后重试（第%d次）", retry.RetryAfter, attempt)
+	}
+}
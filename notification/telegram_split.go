@@ -0,0 +1,290 @@
+package notification
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	telegramMaxMessageLen = 4096 // Telegram 单条消息上限（UTF-16 码元）
+	telegramSplitReserve  = 96   // 为补全/重开格式标记和分页序号预留的长度
+)
+
+// telegramLen Telegram 按 UTF-16 码元计算消息长度
+func telegramLen(text string) int {
+	n := 0
+	for _, r := range text {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// splitTelegramMessage 将超长消息拆分为多条，优先在换行处拆分；
+// parseMode 为 MarkdownV2/Markdown/HTML 时，在拆分处补全未闭合的格式并在下一条开头重新打开
+func splitTelegramMessage(text, parseMode string, limit int) []string {
+	if telegramLen(text) <= limit {
+		return []string{text}
+	}
+	budget := limit - telegramSplitReserve
+	if budget < 1 {
+		budget = limit
+	}
+
+	var tracker entityTracker
+	switch parseMode {
+	case "MarkdownV2":
+		tracker = &markdownTracker{v2: true}
+	case "Markdown":
+		tracker = &markdownTracker{}
+	case "HTML":
+		tracker = &htmlTracker{}
+	default:
+		tracker = plainTracker{}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	prefix := ""
+	flush := func() {
+		body := current.String()
+		if strings.TrimSpace(body) == "" {
+			current.Reset()
+			return
+		}
+		closing, reopening := tracker.feed(strings.TrimPrefix(body, prefix))
+		chunks = append(chunks, strings.TrimRight(body, "\n")+closing)
+		current.Reset()
+		current.WriteString(reopening)
+		prefix = reopening
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if telegramLen(current.String())+telegramLen(line) <= budget {
+			current.WriteString(line)
+			continue
+		}
+		if current.Len() > len(prefix) {
+			flush()
+		}
+		// 单行超长时按长度切分，尽量在空白处且不切断链接或标签
+		for telegramLen(current.String())+telegramLen(line) > budget {
+			cut := tracker.cutPoint(line, budget-telegramLen(current.String()))
+			current.WriteString(line[:cut])
+			line = line[cut:]
+			flush()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > len(prefix) {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// entityTracker 跟踪格式标记的开闭状态
+type entityTracker interface {
+	// feed 扫描一段文本，返回该段末尾需要补全的闭合标记与下一段开头需要重开的标记
+	feed(text string) (closing, reopening string)
+	// cutPoint 返回在 max 长度内可安全切分的字节位置（至少一个字符）
+	cutPoint(line string, max int) int
+}
+
+// plainTracker 纯文本无需处理格式
+type plainTracker struct{}
+
+func (plainTracker) feed(string) (string, string) { return "", "" }
+
+func (plainTracker) cutPoint(line string, max int) int {
+	return cutAtSpace(line, max, nil)
+}
+
+// cutAtSpace 在 max 长度内的最后一个空白处切分；avoid 返回 true 的位置不可切分
+func cutAtSpace(line string, max int, avoid func(pos int) bool) int {
+	if max < 1 {
+		max = 1
+	}
+	end, n := 0, 0
+	for i, r := range line {
+		w := 1
+		if r >= 0x10000 {
+			w = 2
+		}
+		if n+w > max {
+			break
+		}
+		n += w
+		end = i + utf8.RuneLen(r)
+	}
+	if end == 0 {
+		_, size := utf8.DecodeRuneInString(line)
+		return size
+	}
+	if end >= len(line) {
+		return len(line)
+	}
+	for i := end; i > end/2; i-- {
+		if (line[i-1] == ' ' || line[i-1] == '\t') && (avoid == nil || !avoid(i)) {
+			return i
+		}
+	}
+	for i := end; i > 0; i-- {
+		if utf8.RuneStart(line[i]) && (avoid == nil || !avoid(i)) {
+			return i
+		}
+	}
+	return end
+}
+
+// markdownTracker Markdown/MarkdownV2 格式标记
+type markdownTracker struct {
+	v2    bool
+	stack []string // 未闭合的标记（```lang 以完整开头保存）
+}
+
+func (m *markdownTracker) markers() []string {
+	if m.v2 {
+		return []string{"```", "||", "__", "`", "*", "_", "~"}
+	}
+	return []string{"```", "`", "*", "_"}
+}
+
+func (m *markdownTracker) inCode() bool {
+	if len(m.stack) == 0 {
+		return false
+	}
+	top := m.stack[len(m.stack)-1]
+	return top == "`" || strings.HasPrefix(top, "```")
+}
+
+func (m *markdownTracker) toggle(marker, open string) {
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if m.stack[i] == marker || (marker == "```" && strings.HasPrefix(m.stack[i], "```")) {
+			m.stack = append(m.stack[:i], m.stack[i+1:]...)
+			return
+		}
+	}
+	m.stack = append(m.stack, open)
+}
+
+func (m *markdownTracker) feed(text string) (string, string) {
+	for i := 0; i < len(text); {
+		if text[i] == '\\' {
+			i += 2
+			continue
+		}
+		matched := false
+		for _, marker := range m.markers() {
+			if !strings.HasPrefix(text[i:], marker) {
+				continue
+			}
+			// 代码内只识别对应的结束标记
+			if m.inCode() {
+				top := m.stack[len(m.stack)-1]
+				if !(marker == "`" && top == "`") && !(marker == "```" && strings.HasPrefix(top, "```")) {
+					continue
+				}
+			}
+			open := marker
+			if marker == "```" && !m.inCode() {
+				// 保留代码块语言，重开时一并带上
+				rest := text[i+3:]
+				if nl := strings.IndexByte(rest, '\n'); nl >= 0 && !strings.ContainsAny(rest[:nl], " `") {
+					open = "```" + rest[:nl+1]
+				}
+			}
+			m.toggle(marker, open)
+			i += len(marker)
+			matched = true
+			break
+		}
+		if !matched {
+			i++
+		}
+	}
+
+	var closing, reopening strings.Builder
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		marker := m.stack[i]
+		if strings.HasPrefix(marker, "```") {
+			closing.WriteString("\n```")
+		} else {
+			closing.WriteString(marker)
+		}
+	}
+	for _, marker := range m.stack {
+		reopening.WriteString(marker)
+	}
+	return closing.String(), reopening.String()
+}
+
+// markdownLink 匹配 [文本](地址)
+var markdownLink = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
+
+func (m *markdownTracker) cutPoint(line string, max int) int {
+	links := markdownLink.FindAllStringIndex(line, -1)
+	return cutAtSpace(line, max, func(pos int) bool {
+		for _, l := range links {
+			if pos > l[0] && pos < l[1] {
+				return true
+			}
+		}
+		// 不切在转义符与被转义字符之间
+		return pos > 0 && line[pos-1] == '\\'
+	})
+}
+
+// htmlTracker HTML 格式标签
+type htmlTracker struct {
+	stack []htmlTag
+}
+
+type htmlTag struct {
+	name string
+	open string // 原始开始标签（含属性）
+}
+
+var htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+
+func (h *htmlTracker) feed(text string) (string, string) {
+	for _, m := range htmlTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		name := strings.ToLower(text[m[4]:m[5]])
+		if m[3] > m[2] {
+			for i := len(h.stack) - 1; i >= 0; i-- {
+				if h.stack[i].name == name {
+					h.stack = append(h.stack[:i], h.stack[i+1:]...)
+					break
+				}
+			}
+			continue
+		}
+		h.stack = append(h.stack, htmlTag{name: name, open: text[m[0]:m[1]]})
+	}
+
+	var closing, reopening strings.Builder
+	for i := len(h.stack) - 1; i >= 0; i-- {
+		closing.WriteString("</" + h.stack[i].name + ">")
+	}
+	for _, tag := range h.stack {
+		reopening.WriteString(tag.open)
+	}
+	return closing.String(), reopening.String()
+}
+
+func (h *htmlTracker) cutPoint(line string, max int) int {
+	return cutAtSpace(line, max, func(pos int) bool {
+		// 不切在标签或字符实体内部
+		before := line[:pos]
+		if lt := strings.LastIndexByte(before, '<'); lt > strings.LastIndexByte(before, '>') {
+			return true
+		}
+		if amp := strings.LastIndexByte(before, '&'); amp >= 0 && !strings.ContainsAny(before[amp:], "; ") {
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTelegramLen(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"域名", 2},
+		{"🚀a", 3}, // 辅助平面字符占两个 UTF-16 码元
+	}
+	for _, tt := range tests {
+		if got := telegramLen(tt.text); got != tt.want {
+			t.Errorf("telegramLen(%q) = %d, 期望 %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// checkChunks 检查每段不超过上限
+func checkChunks(t *testing.T, chunks []string, limit int) {
+	t.Helper()
+	if len(chunks) < 2 {
+		t.Fatalf("应拆分为多段，实际 %d 段", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := telegramLen(chunk); n > limit {
+			t.Errorf("第 %d 段长度 %d 超过上限 %d", i+1, n, limit)
+		}
+	}
+}
+
+func TestSplitTelegramShortMessage(t *testing.T) {
+	text := "*标题*\n正文"
+	if chunks := splitTelegramMessage(text, "MarkdownV2", telegramMaxMessageLen); len(chunks) != 1 || chunks[0] != text {
+		t.Errorf("未超长的消息不应拆分: %q", chunks)
+	}
+}
+
+func TestSplitTelegramPlain(t *testing.T) {
+	// 单行超长时在空白处切分，内容不丢失
+	words := make([]string, 300)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%03d", i)
+	}
+	text := strings.Join(words, " ")
+	chunks := splitTelegramMessage(text, "", 400)
+	checkChunks(t, chunks, 400)
+	for i, chunk := range chunks[:len(chunks)-1] {
+		if !strings.HasSuffix(chunk, " ") {
+			t.Errorf("第 %d 段应在空白处切分: ...%q", i+1, chunk[len(chunk)-10:])
+		}
+	}
+	if joined := strings.Join(chunks, ""); joined != text {
+		t.Error("拆分后内容与原文不一致")
+	}
+}
+
+func TestSplitTelegramHTML(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`<b>域名列表</b>` + "\n" + `<blockquote expandable>`)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "<a href=\"https://example.com/%03d\">d%03d.com</a> A &amp; B\n", i, i)
+	}
+	b.WriteString("</blockquote>")
+
+	chunks := splitTelegramMessage(b.String(), "HTML", 1000)
+	checkChunks(t, chunks, 1000)
+	for i, chunk := range chunks {
+		if strings.Count(chunk, "<blockquote") != strings.Count(chunk, "</blockquote>") {
+			t.Errorf("第 %d 段标签未闭合:\n%s", i+1, chunk)
+		}
+		if i > 0 && !strings.HasPrefix(chunk, "<blockquote expandable>") {
+			t.Errorf("第 %d 段应以原始开始标签（含属性）重新打开: %q", i+1, chunk[:30])
+		}
+		if strings.Count(chunk, "<a ") != strings.Count(chunk, "</a>") {
+			t.Errorf("第 %d 段切断了链接", i+1)
+		}
+	}
+}
+
+func TestSplitTelegramHTMLCutPoint(t *testing.T) {
+	h := &htmlTracker{}
+	line := `aaaa <a href="https://example.com/x">link</a> &amp;bbb`
+	for max := 1; max < len(line); max++ {
+		cut := h.cutPoint(line, max)
+		before := line[:cut]
+		if lt := strings.LastIndexByte(before, '<'); lt > strings.LastIndexByte(before, '>') && cut > 1 {
+			t.Errorf("max=%d 切在标签内部: %q", max, before)
+		}
+		if amp := strings.LastIndexByte(before, '&'); amp >= 0 && !strings.ContainsAny(before[amp:], "; ") && cut > 1 {
+			t.Errorf("max=%d 切在字符实体内部: %q", max, before)
+		}
+	}
+}
+
+func TestSplitTelegramMarkdown(t *testing.T) {
+	tests := []struct {
+		name, parseMode, text string
+		check                 func(t *testing.T, chunks []string)
+	}{
+		{
+			name:      "MarkdownV2 粗体跨段",
+			parseMode: "MarkdownV2",
+			text:      "*" + strings.Repeat("粗体内容 \\- 第一行\n", 60) + "*",
+			check: func(t *testing.T, chunks []string) {
+				for i, chunk := range chunks {
+					unescaped := strings.ReplaceAll(chunk, "\\-", "")
+					if strings.Count(unescaped, "*")%2 != 0 {
+						t.Errorf("第 %d 段粗体未闭合", i+1)
+					}
+					if i > 0 && !strings.HasPrefix(chunk, "*") {
+						t.Errorf("第 %d 段应重新打开粗体", i+1)
+					}
+				}
+			},
+		},
+		{
+			name:      "链接不被切断",
+			parseMode: "Markdown",
+			text:      strings.Repeat("[example domain link](https://example.com/some/long/path) ", 40),
+			check: func(t *testing.T, chunks []string) {
+				for i, chunk := range chunks {
+					if strings.Count(chunk, "[") != strings.Count(chunk, ")") {
+						t.Errorf("第 %d 段切断了链接: %q", i+1, chunk)
+					}
+				}
+			},
+		},
+		{
+			name:      "代码块内的标记不计入",
+			parseMode: "MarkdownV2",
+			text:      "```go\n" + strings.Repeat("a_b*c := x_y\n", 60) + "```",
+			check: func(t *testing.T, chunks []string) {
+				for i, chunk := range chunks {
+					if i > 0 && !strings.HasPrefix(chunk, "```go\n") {
+						t.Errorf("第 %d 段应带语言重新打开代码块", i+1)
+					}
+					if strings.Count(chunk, "```")%2 != 0 {
+						t.Errorf("第 %d 段代码块未闭合", i+1)
+					}
+					if strings.HasSuffix(chunk, "_```") || strings.HasSuffix(chunk, "*\n```") {
+						t.Errorf("第 %d 段补全了代码块内的标记", i+1)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitTelegramMessage(tt.text, tt.parseMode, 500)
+			checkChunks(t, chunks, 500)
+			tt.check(t, chunks)
+		})
+	}
+}
+
+func TestMarkdownCutPointKeepsEscape(t *testing.T) {
+	m := &markdownTracker{v2: true}
+	line := `abc\.def\.ghi`
+	for max := 2; max < len(line); max++ {
+		if cut := m.cutPoint(line, max); line[cut-1] == '\\' {
+			t.Errorf("max=%d 切在转义符之后: %q", max, line[:cut])
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/storage"
+)
+
+// fakeBotAPI 模拟 Telegram Bot API，记录收到的 sendMessage 请求
+type fakeBotAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []TelegramMessage
+	times    []time.Time
+	// respond 返回第 n 次（从 1 开始）请求的响应，为空时返回成功
+	respond func(n int) TelegramResponse
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	api := &fakeBotAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			http.NotFound(w, r)
+			return
+		}
+		var msg TelegramMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+
+		api.mu.Lock()
+		api.times = append(api.times, time.Now())
+		n := len(api.times)
+		resp := TelegramResponse{OK: true}
+		if api.respond != nil {
+			resp = api.respond(n)
+		}
+		if resp.OK {
+			api.messages = append(api.messages, msg)
+		}
+		api.mu.Unlock()
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// received 返回成功送达的消息与全部请求时间
+func (api *fakeBotAPI) received() ([]TelegramMessage, []time.Time) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]TelegramMessage(nil), api.messages...), append([]time.Time(nil), api.times...)
+}
+
+// testBotToken 每次运行使用不同的 Bot Token，避免共享（进程级的）发送队列与限流状态
+func testBotToken(t *testing.T) string {
+	return fmt.Sprintf("1:%s-%d", t.Name(), time.Now().UnixNano())
+}
+
+// testTelegramNotifier 创建指向模拟 API 的通知器
+func testTelegramNotifier(api *fakeBotAPI, token, chatID, parseMode string) *TelegramNotifier {
+	return NewTelegramNotifier(config.TelegramConfig{
+		BotToken:  token,
+		ChatID:    chatID,
+		APIBase:   api.URL,
+		ParseMode: parseMode,
+		Enabled:   true,
+	})
+}
+
+func TestTelegramSplitsLongMarkdown(t *testing.T) {
+	api := newFakeBotAPI(t)
+	notifier := testTelegramNotifier(api, testBotToken(t), "101", "MarkdownV2")
+
+	// 代码块跨越拆分点，并含有占两个 UTF-16 码元的字符
+	var body strings.Builder
+	body.WriteString("*域名列表*\n```text\n")
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&body, "line-%03d example-%03d.com 🚀\n", i, i)
+	}
+	body.WriteString("```\n_结束_")
+	if err := notifier.SendRendered("主题", body.String(), nil); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	messages, _ := api.received()
+	if len(messages) < 2 {
+		t.Fatalf("超长消息应拆分为多条，实际 %d 条", len(messages))
+	}
+	var joined strings.Builder
+	for i, msg := range messages {
+		if msg.ChatID != "101" || msg.ParseMode != "MarkdownV2" {
+			t.Errorf("第 %d 条聊天或解析模式错误: %q %q", i+1, msg.ChatID, msg.ParseMode)
+		}
+		if n := telegramLen(msg.Text); n > telegramMaxMessageLen {
+			t.Errorf("第 %d 条长度 %d 超过上限", i+1, n)
+		}
+		if strings.Count(msg.Text, "```")%2 != 0 {
+			t.Errorf("第 %d 条代码块未闭合:\n%s", i+1, msg.Text)
+		}
+		if i > 0 && !strings.HasPrefix(msg.Text, "```text\n") {
+			t.Errorf("第 %d 条应重新打开代码块: %q", i+1, msg.Text[:20])
+		}
+		joined.WriteString(msg.Text)
+	}
+
+	// 每行恰好出现一次且顺序不变
+	all := joined.String()
+	last := -1
+	for i := 0; i < 300; i++ {
+		line := fmt.Sprintf("line-%03d example-%03d.com 🚀", i, i)
+		if strings.Count(all, line) != 1 {
+			t.Fatalf("%q 出现 %d 次", line, strings.Count(all, line))
+		}
+		pos := strings.Index(all, line)
+		if pos < last {
+			t.Fatalf("%q 顺序错误", line)
+		}
+		last = pos
+	}
+}
+
+func TestTelegramResumesChunksFromOutbox(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.respond = func(n int) TelegramResponse {
+		if n == 2 {
+			return TelegramResponse{ErrorCode: http.StatusBadRequest, Description: "Bad Request"}
+		}
+		return TelegramResponse{OK: true}
+	}
+	nm := NewNotificationManager()
+	nm.AddNotifier(testTelegramNotifier(api, testBotToken(t), "107", ""))
+
+	var body strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&body, "line-%03d resume-%03d.com\n", i, i)
+	}
+	entries := []storage.OutboxEntry{{NotifierKey: "telegram", Subject: "主题", Message: body.String(), Events: "[]", Rendered: true}}
+	if err := storage.EnqueueOutbox(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第 2 段失败：记录已发出 1 段
+	nm.deliverEntry(outboxEntry(t, entries[0].ID))
+	entry := outboxEntry(t, entries[0].ID)
+	if entry.Status != storage.OutboxPending || entry.Progress != 1 {
+		t.Fatalf("第 2 段失败后应保留进度: %+v", entry)
+	}
+
+	// 重试从第 2 段继续，每行恰好送达一次
+	nm.deliverEntry(entry)
+	if entry = outboxEntry(t, entry.ID); entry.Status != storage.OutboxSent {
+		t.Fatalf("重试后应投递成功: %+v", entry)
+	}
+	messages, _ := api.received()
+	if len(messages) < 3 {
+		t.Fatalf("超长消息应拆分为至少 3 段，实际送达 %d 段", len(messages))
+	}
+	var joined strings.Builder
+	for _, msg := range messages {
+		joined.WriteString(msg.Text)
+	}
+	for i := 0; i < 500; i++ {
+		line := fmt.Sprintf("line-%03d resume-%03d.com", i, i)
+		if n := strings.Count(joined.String(), line); n != 1 {
+			t.Fatalf("%q 送达 %d 次", line, n)
+		}
+	}
+}
+
+func TestTelegramRetryAfter(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.respond = func(n int) TelegramResponse {
+		if n == 1 {
+			return TelegramResponse{ErrorCode: http.StatusTooManyRequests, Description: "Too Many Requests: retry after 1", Parameters: &TelegramParameters{RetryAfter: 1}}
+		}
+		return TelegramResponse{OK: true}
+	}
+	notifier := testTelegramNotifier(api, testBotToken(t), "102", "")
+
+	if err := notifier.SendMessage("主题", "正文"); err != nil {
+		t.Fatalf("429 后应等待并重试成功: %v", err)
+	}
+	messages, times := api.received()
+	if len(times) != 2 || len(messages) != 1 {
+		t.Fatalf("应请求 2 次并送达 1 条，实际 %d 次 %d 条", len(times), len(messages))
+	}
+	if wait := times[1].Sub(times[0]); wait < time.Second {
+		t.Errorf("重试前应等待 retry_after，实际 %v", wait)
+	}
+}
+
+func TestTelegramRetryAfterTooLong(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.respond = func(int) TelegramResponse {
+		return TelegramResponse{ErrorCode: http.StatusTooManyRequests, Description: "Too Many Requests", Parameters: &TelegramParameters{RetryAfter: 120}}
+	}
+	notifier := testTelegramNotifier(api, testBotToken(t), "103", "")
+
+	start := time.Now()
+	err := notifier.SendMessage("主题", "正文")
+	var retry *telegramRetryError
+	if !errors.As(err, &retry) || retry.RetryAfter != 120*time.Second {
+		t.Fatalf("等待过长时应返回限流错误交给发件箱重试: %v", err)
+	}
+	if _, times := api.received(); len(times) != 1 || time.Since(start) > 10*time.Second {
+		t.Errorf("等待过长时不应在进程内重试: %d 次，用时 %v", len(times), time.Since(start))
+	}
+}
+
+func TestTelegramPerChatOrdering(t *testing.T) {
+	api := newFakeBotAPI(t)
+
+	// 两个通知器共用同一 Bot 与聊天，并发发送会拆分的消息
+	long := func(tag string) string {
+		var b strings.Builder
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&b, "%s-%03d %s\n", tag, i, strings.Repeat("x", 20))
+		}
+		return b.String()
+	}
+	token := testBotToken(t)
+	var wg sync.WaitGroup
+	for _, tag := range []string{"A", "B"} {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+			notifier := testTelegramNotifier(api, token, "104", "")
+			if err := notifier.SendRendered("主题", long(tag), nil); err != nil {
+				t.Errorf("%s 发送失败: %v", tag, err)
+			}
+		}(tag)
+	}
+	wg.Wait()
+
+	messages, times := api.received()
+	if len(messages) < 4 {
+		t.Fatalf("两条消息都应被拆分，实际共 %d 条", len(messages))
+	}
+	// 同一聊天的一条消息的各段连续发送，不与另一条交错
+	switches := 0
+	for i := 1; i < len(messages); i++ {
+		if messages[i].Text[0] != messages[i-1].Text[0] {
+			switches++
+		}
+	}
+	if switches != 1 {
+		t.Errorf("同一聊天的消息段交错发送: %d 次切换", switches)
+	}
+	// 相邻请求遵守私聊发送间隔
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < telegramChatInterval-50*time.Millisecond {
+			t.Errorf("第 %d 条与上一条间隔 %v，少于 %v", i+1, gap, telegramChatInterval)
+		}
+	}
+}
+
+func TestTelegramCSVAttachment(t *testing.T) {
+	type upload struct {
+		chatID, caption, filename string
+		data                      []byte
+	}
+	uploads := make(chan upload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendDocument") {
+			t.Errorf("达到阈值时应发送附件，实际请求 %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			t.Errorf("读取附件失败: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		uploads <- upload{r.FormValue("chat_id"), r.FormValue("caption"), header.Filename, data}
+		json.NewEncoder(w).Encode(TelegramResponse{OK: true})
+	}))
+	defer srv.Close()
+
+	notifier := NewTelegramNotifier(config.TelegramConfig{
+		BotToken:     testBotToken(t),
+		ChatID:       "105",
+		APIBase:      srv.URL,
+		CSVThreshold: 2,
+		Enabled:      true,
+	})
+	now := time.Now()
+	events := []NotificationEvent{
+		{Type: "status_change", Domain: "a.com", OldStatus: "pending_delete", Status: "available", Timestamp: now},
+		{Type: "status_change", Domain: "b.com", OldStatus: "registered", Status: "redemption", Timestamp: now, Message: "含,逗号"},
+	}
+	if err := notifier.SendEvents("批量通知", "Domain: a.com", events); err != nil {
+		t.Fatalf("发送附件失败: %v", err)
+	}
+
+	got := <-uploads
+	if got.chatID != "105" || !strings.HasPrefix(got.filename, "puff-") || !strings.HasSuffix(got.filename, ".csv") {
+		t.Errorf("附件信息错误: %q %q", got.chatID, got.filename)
+	}
+	// 正文为英文时附件说明与表头也使用英文
+	if !strings.HasPrefix(got.caption, "批量通知\n2 changes, see attachment") {
+		t.Errorf("附件说明错误: %q", got.caption)
+	}
+	csv := string(got.data)
+	for _, want := range []string{"\ufeffDomain,Event,Old status,New status,Time,Details\n", "a.com,status_change,Pending delete,Available,", `"含,逗号"`} {
+		if !strings.Contains(csv, want) {
+			t.Errorf("CSV 缺少 %q:\n%s", want, csv)
+		}
+	}
+
+	// 未达到阈值时按普通消息发送
+	if notifier.useCSV(events[:1]) {
+		t.Error("未达到阈值时不应发送附件")
+	}
+}
@@ -0,0 +1,268 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// TemplateData 通知模板可用的数据
+type TemplateData struct {
+	Channel string              // 通知器标识，如 telegram:2
+	Locale  string              // 通知语言，如 zh-CN、en
+	Subject string              // 内置主题
+	Message string              // 内置正文
+	Event   NotificationEvent   // 当前事件（聚合通知时为第一条）
+	Events  []NotificationEvent // 全部事件
+	Info    *core.DomainInfo    // 域名详情（单条通知，可能为空）
+	Count   int                 // 事件数量
+	Now     time.Time           // 渲染时间
+}
+
+// executor text/template 与 html/template 的共同接口
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// compiledTemplate 已编译的模板，主题或正文为空时对应部分使用内置排版
+type compiledTemplate struct {
+	subject executor
+	body    executor
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = map[string]interface{}{
+	"status": func(status string) string { return translateStatus(i18n.Default, status) },
+	// statusIn 按指定语言翻译状态，如 {{statusIn .Locale .Event.Status}}
+	"statusIn": translateStatus,
+	// t 按指定语言取消息目录中的文本，如 {{t .Locale "notify.label.domain"}}
+	"t":     i18n.T,
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"date": func(layout string, t interface{}) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format(layout)
+		case *time.Time:
+			if v != nil {
+				return v.Format(layout)
+			}
+		}
+		return ""
+	},
+	"truncate": func(n int, s string) string {
+		if r := []rune(s); len(r) > n {
+			return string(r[:n]) + "..."
+		}
+		return s
+	},
+}
+
+// IsHTMLChannel 邮件正文使用 html/template（自动转义），其余渠道使用 text/template
+func IsHTMLChannel(channel string) bool {
+	return channel == "email" || strings.HasPrefix(channel, "email:")
+}
+
+// ValidateTemplate 检查模板语法
+func ValidateTemplate(tpl storage.NotificationTemplate) error {
+	_, err := compileTemplate(tpl)
+	return err
+}
+
+// compileTemplate 编译模板，语法错误时返回错误
+func compileTemplate(tpl storage.NotificationTemplate) (*compiledTemplate, error) {
+	compiled := &compiledTemplate{}
+	if strings.TrimSpace(tpl.Subject) != "" {
+		t, err := template.New("subject").Funcs(templateFuncs).Option("missingkey=zero").Parse(tpl.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("主题模板解析失败: %v", err)
+		}
+		compiled.subject = t
+	}
+	if strings.TrimSpace(tpl.Body) != "" {
+		var err error
+		if IsHTMLChannel(tpl.Channel) {
+			compiled.body, err = htmltemplate.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(tpl.Body)
+		} else {
+			compiled.body, err = template.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(tpl.Body)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("正文模板解析失败: %v", err)
+		}
+	}
+	return compiled, nil
+}
+
+// render 渲染模板；rendered 表示正文来自自定义模板
+func (c *compiledTemplate) render(data TemplateData) (subject, body string, rendered bool, err error) {
+	subject, body = data.Subject, data.Message
+	if c.subject != nil {
+		var buf bytes.Buffer
+		if err := c.subject.Execute(&buf, data); err != nil {
+			return "", "", false, fmt.Errorf("渲染主题模板失败: %v", err)
+		}
+		subject = strings.TrimSpace(buf.String())
+	}
+	if c.body != nil {
+		var buf bytes.Buffer
+		if err := c.body.Execute(&buf, data); err != nil {
+			return "", "", false, fmt.Errorf("渲染正文模板失败: %v", err)
+		}
+		body = buf.String()
+		rendered = true
+	}
+	return subject, body, rendered, nil
+}
+
+// templateStore 按 "渠道/类型" 缓存已编译的模板
+type templateStore struct {
+	mu        sync.RWMutex
+	templates map[string]*compiledTemplate
+}
+
+// newTemplateStore 创建模板缓存
+func newTemplateStore() *templateStore {
+	return &templateStore{templates: make(map[string]*compiledTemplate)}
+}
+
+// lookup 查找通知器的模板：先按通知器标识，再按类型
+func (s *templateStore) lookup(n Notifier, kind string) *compiledTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if tpl, ok := s.templates[NotifierKey(n)+"/"+kind]; ok {
+		return tpl
+	}
+	return s.templates[n.GetType()+"/"+kind]
+}
+
+// LoadTemplates 从数据库重新加载通知模板（无法编译的模板会被跳过）
+func (nm *NotificationManager) LoadTemplates() error {
+	templates, err := storage.ListNotificationTemplates()
+	if err != nil {
+		return err
+	}
+
+	compiled := make(map[string]*compiledTemplate)
+	for _, tpl := range templates {
+		if !tpl.Enabled {
+			continue
+		}
+		c, err := compileTemplate(tpl)
+		if err != nil {
+			logger.Error("通知模板 %s/%s 无效，使用内置排版: %v", tpl.Channel, tpl.Kind, err)
+			continue
+		}
+		compiled[tpl.Channel+"/"+tpl.Kind] = c
+	}
+
+	nm.templates.mu.Lock()
+	nm.templates.templates = compiled
+	nm.templates.mu.Unlock()
+	return nil
+}
+
+// renderFor 为通知器渲染主题与正文，无模板或渲染失败时使用内置排版
+func (nm *NotificationManager) renderFor(n Notifier, subject, message string, events []NotificationEvent) (string, string, bool) {
+	kind := storage.TemplateSingle
+	if len(events) > 1 {
+		kind = storage.TemplateBatch
+	}
+	tpl := nm.templates.lookup(n, kind)
+	if tpl == nil {
+		return subject, message, false
+	}
+
+	s, body, rendered, err := tpl.render(newTemplateData(NotifierKey(n), nm.localeOf(n), subject, message, events))
+	if err != nil {
+		logger.Error("%s 通知模板渲染失败，使用内置排版: %v", notifierLabel(n), err)
+		return subject, message, false
+	}
+	return s, body, rendered
+}
+
+// newTemplateData 构建模板数据
+func newTemplateData(channel, locale, subject, message string, events []NotificationEvent) TemplateData {
+	data := TemplateData{
+		Channel: channel,
+		Locale:  locale,
+		Subject: subject,
+		Message: message,
+		Events:  events,
+		Count:   len(events),
+		Now:     time.Now(),
+	}
+	if len(events) > 0 {
+		data.Event = events[0]
+		if len(events) == 1 {
+			data.Info = events[0].Info
+		}
+	}
+	return data
+}
+
+// PreviewTemplate 使用示例数据渲染模板
+func (nm *NotificationManager) PreviewTemplate(tpl storage.NotificationTemplate) (subject, body string, err error) {
+	compiled, err := compileTemplate(tpl)
+	if err != nil {
+		return "", "", err
+	}
+
+	events := sampleEvents(tpl.Kind)
+	locale := nm.localeOfKey(tpl.Channel)
+	if tpl.Kind == storage.TemplateBatch {
+		subject, body = nm.formatBatchSubject(locale, events), nm.formatBatchMessage(locale, events)
+	} else {
+		subject, body = nm.formatSubject(locale, events[0]), nm.formatMessage(locale, events[0])
+	}
+
+	subject, body, _, err = compiled.render(newTemplateData(tpl.Channel, locale, subject, body, events))
+	return subject, body, err
+}
+
+// sampleEvents 预览用的示例事件
+func sampleEvents(kind string) []NotificationEvent {
+	now := time.Now()
+	created := now.AddDate(-3, 0, 0)
+	expiry := now.AddDate(0, 0, -35)
+	info := &core.DomainInfo{
+		Name:        "example.com",
+		Status:      core.StatusAvailable,
+		Registrar:   "Example Registrar, Inc.",
+		CreatedDate: &created,
+		ExpiryDate:  &expiry,
+		NameServers: []string{"ns1.example.com", "ns2.example.com"},
+		StatusCodes: []string{"pendingDelete"},
+		LastChecked: now,
+		QueryMethod: "rdap",
+	}
+
+	events := []NotificationEvent{{
+		Type:      "status_change",
+		Domain:    "example.com",
+		Status:    string(core.StatusAvailable),
+		OldStatus: string(core.StatusPendingDelete),
+		Message:   "域名状态从 pending_delete 变为 available",
+		Timestamp: now,
+		Info:      info,
+	}}
+	if kind == storage.TemplateBatch {
+		events = append(events, NotificationEvent{
+			Type:      "status_change",
+			Domain:    "example.net",
+			Status:    string(core.StatusRedemption),
+			OldStatus: string(core.StatusRegistered),
+			Timestamp: now,
+		})
+	}
+	return events
+}
@@ -0,0 +1,158 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+// templateEvent 模板测试用的单条事件
+func templateEvent() NotificationEvent {
+	expiry := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	return NotificationEvent{
+		Type:      "status_change",
+		Domain:    "a.com",
+		Status:    "available",
+		OldStatus: "pending_delete",
+		Timestamp: time.Now(),
+		Info:      &core.DomainInfo{Name: "a.com", Registrar: "Example <Registrar>", ExpiryDate: &expiry},
+	}
+}
+
+func TestCompileTemplateErrors(t *testing.T) {
+	tests := []struct {
+		tpl  storage.NotificationTemplate
+		want string
+	}{
+		{storage.NotificationTemplate{Channel: "telegram", Subject: "{{.Event.Domain"}, "主题模板解析失败"},
+		{storage.NotificationTemplate{Channel: "telegram", Body: "{{if .Info}}"}, "正文模板解析失败"},
+		{storage.NotificationTemplate{Channel: "email", Body: "{{unknown .Event}}"}, "正文模板解析失败"},
+	}
+	for _, tt := range tests {
+		if err := ValidateTemplate(tt.tpl); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateTemplate(%+v) = %v, 期望包含 %q", tt.tpl, err, tt.want)
+		}
+	}
+	if err := ValidateTemplate(storage.NotificationTemplate{Channel: "telegram"}); err != nil {
+		t.Errorf("空模板应合法: %v", err)
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	events := []NotificationEvent{templateEvent()}
+	data := newTemplateData("telegram:2", i18n.En, "内置主题", "内置正文", events)
+
+	// 只有正文模板时主题使用内置排版
+	compiled, err := compileTemplate(storage.NotificationTemplate{
+		Channel: "telegram:2",
+		Body:    `{{.Event.Domain}} {{statusIn .Locale .Event.Status}} {{date "2006-01-02" .Info.ExpiryDate}} {{.Info.Registrar}} {{truncate 3 "abcdef"}} {{.Count}}`,
+	})
+	if err != nil {
+		t.Fatalf("编译失败: %v", err)
+	}
+	subject, body, rendered, err := compiled.render(data)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	if subject != "内置主题" || !rendered {
+		t.Errorf("主题应为内置主题且正文来自模板: %q %v", subject, rendered)
+	}
+	if want := "a.com Available 2026-05-01 Example <Registrar> abc... 1"; body != want {
+		t.Errorf("正文 = %q, 期望 %q", body, want)
+	}
+
+	// 只有主题模板时正文使用内置排版
+	compiled, _ = compileTemplate(storage.NotificationTemplate{Channel: "telegram", Subject: "  [{{upper .Event.Domain}}]\n"})
+	subject, body, rendered, _ = compiled.render(data)
+	if subject != "[A.COM]" || body != "内置正文" || rendered {
+		t.Errorf("主题模板渲染错误: %q %q %v", subject, body, rendered)
+	}
+
+	// 邮件正文使用 html/template 自动转义
+	compiled, _ = compileTemplate(storage.NotificationTemplate{Channel: "email:1", Subject: "{{.Info.Registrar}}", Body: "<p>{{.Info.Registrar}}</p>"})
+	subject, body, _, _ = compiled.render(data)
+	if body != "<p>Example &lt;Registrar&gt;</p>" {
+		t.Errorf("邮件正文应转义: %q", body)
+	}
+	if subject != "Example <Registrar>" {
+		t.Errorf("邮件主题不应转义: %q", subject)
+	}
+
+	// 执行出错时返回错误
+	compiled, _ = compileTemplate(storage.NotificationTemplate{Channel: "telegram", Body: "{{index .Events 5}}"})
+	if _, _, _, err := compiled.render(data); err == nil {
+		t.Error("越界访问应返回渲染错误")
+	}
+}
+
+func TestRenderForTemplates(t *testing.T) {
+	templates := []storage.NotificationTemplate{
+		{Channel: "tpl-chan", Kind: storage.TemplateSingle, Body: "类型模板 {{.Event.Domain}}", Enabled: true},
+		{Channel: "tpl-chan", Kind: storage.TemplateBatch, Body: "共 {{.Count}} 条", Enabled: true},
+		{Channel: "tpl-off", Kind: storage.TemplateSingle, Body: "已停用", Enabled: false},
+		{Channel: "tpl-bad", Kind: storage.TemplateSingle, Body: "{{index .Events 9}}", Enabled: true},
+	}
+	for i := range templates {
+		if err := storage.CreateNotificationTemplate(&templates[i]); err != nil {
+			t.Fatalf("保存模板失败: %v", err)
+		}
+		id := templates[i].ID
+		t.Cleanup(func() { storage.DeleteNotificationTemplate(id) })
+	}
+
+	nm := NewNotificationManager()
+	nm.SetLocales(config.NotificationConfig{Locale: i18n.ZhCN})
+	if err := nm.LoadTemplates(); err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+
+	single := []NotificationEvent{templateEvent()}
+	batch := append(single, NotificationEvent{Type: "status_change", Domain: "b.com", Status: "redemption"})
+	tests := []struct {
+		notifier     string
+		events       []NotificationEvent
+		wantBody     string
+		wantRendered bool
+	}{
+		{"tpl-chan", single, "类型模板 a.com", true},
+		{"tpl-chan", batch, "共 2 条", true},
+		{"tpl-off", single, "内置正文", false},
+		{"tpl-bad", single, "内置正文", false},
+		{"tpl-none", single, "内置正文", false},
+	}
+	for _, tt := range tests {
+		subject, body, rendered := nm.renderFor(&recordingNotifier{notifierType: tt.notifier}, "内置主题", "内置正文", tt.events)
+		if subject != "内置主题" || body != tt.wantBody || rendered != tt.wantRendered {
+			t.Errorf("%s (%d 条事件) = %q %q %v, 期望正文 %q %v", tt.notifier, len(tt.events), subject, body, rendered, tt.wantBody, tt.wantRendered)
+		}
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	nm := NewNotificationManager()
+	nm.SetLocales(config.NotificationConfig{Locale: i18n.ZhCN, Locales: "webhook=en"})
+
+	subject, body, err := nm.PreviewTemplate(storage.NotificationTemplate{
+		Channel: "webhook",
+		Kind:    storage.TemplateBatch,
+		Body:    `{{range .Events}}{{.Domain}}={{statusIn $.Locale .Status}};{{end}}`,
+	})
+	if err != nil {
+		t.Fatalf("预览失败: %v", err)
+	}
+	if body != "example.com=Available;example.net=Redemption;" {
+		t.Errorf("预览正文错误: %q", body)
+	}
+	if subject == "" || strings.ContainsAny(subject, "域名状态") {
+		t.Errorf("预览主题应为通知器语言的内置主题: %q", subject)
+	}
+
+	if _, _, err := nm.PreviewTemplate(storage.NotificationTemplate{Channel: "webhook", Body: "{{"}); err == nil {
+		t.Error("语法错误的模板预览应返回错误")
+	}
+}
@@ -0,0 +1,287 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"Puff/config"
+)
+
+// Webhook 重试退避参数
+const (
+	webhookBaseBackoff = 1 * time.Second
+	webhookMaxBackoff  = 30 * time.Second
+)
+
+// WebhookNotifier Webhook通知器
+type WebhookNotifier struct {
+	config     config.WebhookConfig
+	httpClient *http.Client
+	enabled    bool
+}
+
+// WebhookPayload 请求体模板数据，未配置模板时直接序列化为请求体
+type WebhookPayload struct {
+	Subject   string              `json:"subject"`
+	Message   string              `json:"message"`
+	Timestamp time.Time           `json:"timestamp"`
+	Event     *NotificationEvent  `json:"event,omitempty"` // 第一个事件（单条通知时即为该事件）
+	Events    []NotificationEvent `json:"events"`
+}
+
+// NewWebhookNotifier 创建Webhook通知器
+func NewWebhookNotifier(cfg config.WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+// SendMessage 发送纯文本通知（不含结构化事件）
+func (w *WebhookNotifier) SendMessage(subject, message string) error {
+	return w.SendEvents(subject, message, nil)
+}
+
+// SendEvents 发送包含结构化事件的通知
+func (w *WebhookNotifier) SendEvents(subject, message string, events []NotificationEvent) error {
+	if !w.enabled {
+		return fmt.Errorf("Webhook通知未启用")
+	}
+
+	if err := w.validateConfig(); err != nil {
+		return fmt.Errorf("Webhook配置无效: %v", err)
+	}
+
+	payload := WebhookPayload{
+		Subject:   subject,
+		Message:   message,
+		Timestamp: time.Now(),
+		Events:    events,
+	}
+	if payload.Events == nil {
+		payload.Events = []NotificationEvent{}
+	}
+	if len(events) > 0 {
+		payload.Event = &events[0]
+	}
+
+	body, err := w.renderBody(payload)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, url := range w.urls() {
+		if err := w.post(url, body); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Webhook发送失败: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// IsEnabled 检查是否启用
+func (w *WebhookNotifier) IsEnabled() bool {
+	return w.enabled && w.config.Enabled
+}
+
+// UpdateConfig 更新配置
+func (w *WebhookNotifier) UpdateConfig(cfg config.WebhookConfig) {
+	w.config = cfg
+	w.enabled = cfg.Enabled
+}
+
+// GetType 获取通知器类型
+func (w *WebhookNotifier) GetType() string {
+	return "webhook"
+}
+
+// Test 发送测试请求
+func (w *WebhookNotifier) Test() error {
+	event := NotificationEvent{
+		Type:      "test",
+		Domain:    "example.com",
+		Status:    "registered",
+		Message:   "这是一条测试消息，用于验证Webhook通知功能是否正常工作。",
+		Timestamp: time.Now(),
+	}
+	return w.SendEvents("Puff Webhook 通知测试", event.Message, []NotificationEvent{event})
+}
+
+// ValidateWebhookConfig 验证Webhook配置，并使用示例事件试渲染模板
+func ValidateWebhookConfig(cfg config.WebhookConfig) error {
+	w := &WebhookNotifier{config: cfg}
+	if err := w.validateConfig(); err != nil {
+		return err
+	}
+
+	event := NotificationEvent{Type: "test", Domain: "example.com", Status: "registered", Timestamp: time.Now()}
+	_, err := w.renderBody(WebhookPayload{
+		Subject:   "example.com 通知",
+		Timestamp: event.Timestamp,
+		Event:     &event,
+		Events:    []NotificationEvent{event},
+	})
+	return err
+}
+
+// validateConfig 验证配置
+func (w *WebhookNotifier) validateConfig() error {
+	urls := w.urls()
+	if len(urls) == 0 {
+		return fmt.Errorf("Webhook地址不能为空")
+	}
+	for _, url := range urls {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return fmt.Errorf("Webhook地址格式无效: %s", url)
+		}
+	}
+	if _, err := ParseHeaders(w.config.Headers); err != nil {
+		return err
+	}
+	return nil
+}
+
+// urls 解析目标地址列表
+func (w *WebhookNotifier) urls() []string {
+	var urls []string
+	for _, url := range strings.FieldsFunc(w.config.URL, func(r rune) bool {
+		return r == '\n' || r == ',' || r == '\r'
+	}) {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// renderBody 渲染请求体，未配置模板时使用默认JSON
+func (w *WebhookNotifier) renderBody(payload WebhookPayload) ([]byte, error) {
+	if strings.TrimSpace(w.config.Template) == "" {
+		return json.Marshal(payload)
+	}
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		// json 将任意值编码为JSON（字符串会带引号并转义）
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(w.config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("解析Webhook模板失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("渲染Webhook模板失败: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("Webhook模板渲染结果不是有效的JSON")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// post 发送请求，网络错误、429 和 5xx 时按指数退避重试
+func (w *WebhookNotifier) post(url string, body []byte) error {
+	headers, _ := ParseHeaders(w.config.Headers)
+
+	var lastErr error
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := webhookBaseBackoff << (attempt - 1)
+			if backoff > webhookMaxBackoff {
+				backoff = webhookMaxBackoff
+			}
+			time.Sleep(backoff)
+		}
+
+		retry, err := w.postOnce(url, body, headers)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// postOnce 发送一次请求，返回是否值得重试
+func (w *WebhookNotifier) postOnce(url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Puff-Webhook")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if w.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Puff-Timestamp", timestamp)
+		req.Header.Set("X-Puff-Signature", "sha256="+SignWebhook(w.config.Secret, timestamp, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// SignWebhook 计算签名：HMAC-SHA256(secret, timestamp + "." + body) 的十六进制
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseHeaders 解析自定义请求头（每行一个 "Key: Value"）
+func ParseHeaders(text string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("请求头格式无效: %s", line)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
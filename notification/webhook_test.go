@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/config"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// 与 Python hmac.new(b"secret", b'1700000000.{"a":1}', hashlib.sha256).hexdigest() 一致
+	if got := SignWebhook("secret", "1700000000", []byte(`{"a":1}`)); got != "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686" {
+		t.Errorf("签名 = %s", got)
+	}
+}
+
+func TestWebhookSignedDefaultPayload(t *testing.T) {
+	srv, requests := newCaptureServer(t, "")
+	w := NewWebhookNotifier(config.WebhookConfig{
+		URL:     srv.URL,
+		Secret:  "s3cret",
+		Headers: "Authorization: Bearer abc\nX-Env: test",
+		Enabled: true,
+	})
+
+	event := NotificationEvent{Type: "status_change", Domain: "a.com", Status: "available", OldStatus: "registered", Timestamp: time.Now()}
+	if err := w.SendEvents("主题", "正文", []NotificationEvent{event}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	reqs := requests()
+	if len(reqs) != 1 {
+		t.Fatalf("应发送 1 个请求，实际 %d 个", len(reqs))
+	}
+	req := reqs[0]
+	if req.header.Get("Authorization") != "Bearer abc" || req.header.Get("X-Env") != "test" || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("请求头错误: %v", req.header)
+	}
+	timestamp := req.header.Get("X-Puff-Timestamp")
+	if want := "sha256=" + SignWebhook("s3cret", timestamp, req.body); timestamp == "" || req.header.Get("X-Puff-Signature") != want {
+		t.Errorf("签名错误: %q，期望 %q", req.header.Get("X-Puff-Signature"), want)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("默认请求体不是 JSON: %v", err)
+	}
+	if payload.Subject != "主题" || payload.Event == nil || payload.Event.Domain != "a.com" || len(payload.Events) != 1 {
+		t.Errorf("默认请求体错误: %s", req.body)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	srv, requests := newCaptureServer(t, "")
+	w := NewWebhookNotifier(config.WebhookConfig{
+		URL:      srv.URL,
+		Template: `{"text": {{json .Subject}}, "domain": {{json .Event.Domain}}, "count": {{len .Events}}}`,
+		Enabled:  true,
+	})
+	event := NotificationEvent{Domain: `a"b.com`}
+	if err := w.SendEvents(`主题 "引号"`, "", []NotificationEvent{event}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	reqs := requests()
+	if len(reqs) != 1 || string(reqs[0].body) != `{"text": "主题 \"引号\"", "domain": "a\"b.com", "count": 1}` {
+		t.Errorf("模板请求体错误: %q", reqs)
+	}
+	if reqs[0].header.Get("X-Puff-Signature") != "" {
+		t.Error("未配置密钥时不应签名")
+	}
+}
+
+func TestValidateWebhookConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.WebhookConfig
+		ok   bool
+	}{
+		{"多个地址", config.WebhookConfig{URL: "https://a.example/hook,\nhttp://b.example/hook"}, true},
+		{"缺少地址", config.WebhookConfig{}, false},
+		{"地址协议无效", config.WebhookConfig{URL: "ftp://a.example"}, false},
+		{"请求头格式无效", config.WebhookConfig{URL: "https://a.example", Headers: "NoColon"}, false},
+		{"模板语法错误", config.WebhookConfig{URL: "https://a.example", Template: `{"a": {{.Subject}`}, false},
+		{"模板结果不是 JSON", config.WebhookConfig{URL: "https://a.example", Template: `text {{.Subject}}`}, false},
+		{"模板引用不存在的字段", config.WebhookConfig{URL: "https://a.example", Template: `{"a": {{json .Missing}}}`}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateWebhookConfig(tt.cfg); (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	// 5xx 重试后成功
+	srv, requests := newCaptureServer(t, "", http.StatusBadGateway)
+	w := NewWebhookNotifier(config.WebhookConfig{URL: srv.URL, MaxRetries: 2, Enabled: true})
+	if err := w.SendMessage("主题", "正文"); err != nil {
+		t.Fatalf("重试后应成功: %v", err)
+	}
+	if n := len(requests()); n != 2 {
+		t.Errorf("应请求 2 次，实际 %d 次", n)
+	}
+
+	// 4xx 不重试，多个地址中一个失败时返回该地址的错误
+	bad, badRequests := newCaptureServer(t, "", http.StatusBadRequest)
+	good, goodRequests := newCaptureServer(t, "")
+	w = NewWebhookNotifier(config.WebhookConfig{URL: bad.URL + "\n" + good.URL, MaxRetries: 2, Enabled: true})
+	err := w.SendMessage("主题", "正文")
+	if err == nil || !strings.Contains(err.Error(), bad.URL) || !strings.Contains(err.Error(), "HTTP 400") {
+		t.Errorf("应返回失败地址的错误: %v", err)
+	}
+	if len(badRequests()) != 1 || len(goodRequests()) != 1 {
+		t.Errorf("4xx 不应重试且其他地址仍应发送: %d %d", len(badRequests()), len(goodRequests()))
+	}
+}
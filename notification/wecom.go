@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"fmt"
+
+	"Puff/config"
+)
+
+// wecomMarkdownLimit 企业微信 Markdown 消息内容上限（字节）
+const wecomMarkdownLimit = 4096
+
+// WeComNotifier 企业微信群机器人通知器（地址中的 key 即为凭证，无需签名）
+type WeComNotifier struct {
+	robotBase
+}
+
+// wecomResponse 企业微信机器人响应
+type wecomResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// NewWeComNotifier 创建企业微信通知器
+func NewWeComNotifier(cfg config.RobotConfig) *WeComNotifier {
+	return &WeComNotifier{robotBase: newRobotBase(cfg)}
+}
+
+// SendMessage 发送企业微信 Markdown 消息
+func (w *WeComNotifier) SendMessage(subject, message string) error {
+	if err := w.validateWebhook("企业微信"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": truncateUTF8(formatRobotMarkdown(subject, message, true), wecomMarkdownLimit),
+		},
+	}
+
+	var result wecomResponse
+	if err := w.postJSON(w.config.Webhook, payload, &result); err != nil {
+		return fmt.Errorf("发送企业微信消息失败: %v", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("企业微信API错误 (%d): %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// GetType 获取通知器类型
+func (w *WeComNotifier) GetType() string {
+	return "wecom"
+}
+
+// Test 发送测试消息
+func (w *WeComNotifier) Test() error {
+	return w.SendMessage("企业微信通知测试", robotTestMessage("企业微信"))
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeferredEvent 延迟到下一次按小时汇总时发送的通知事件
+type DeferredEvent struct {
+	ID          int64     `json:"id"`
+	NotifierKey string    `json:"notifier_key"`
+	Domain      string    `json:"domain"`
+	Event       string    `json:"event"` // 结构化事件（JSON）
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AddDeferredEvents 批量写入延迟发送的事件
+func AddDeferredEvents(events []DeferredEvent) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range events {
+		if _, err := tx.Exec(`INSERT INTO notification_deferred(notifier_key, domain, event) VALUES(?, ?, ?)`,
+			e.NotifierKey, e.Domain, e.Event); err != nil {
+			return fmt.Errorf("写入延迟通知失败: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// TakeDeferredEvents 取出并删除全部延迟事件（按写入顺序）
+func TakeDeferredEvents() ([]DeferredEvent, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, notifier_key, domain, event, created_at FROM notification_deferred ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询延迟通知失败: %w", err)
+	}
+	var events []DeferredEvent
+	for rows.Next() {
+		var e DeferredEvent
+		if err := rows.Scan(&e.ID, &e.NotifierKey, &e.Domain, &e.Event, &e.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取延迟通知失败: %w", err)
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+	// 只删除已读取的记录，避免误删读取后新写入的事件
+	if _, err := tx.Exec(`DELETE FROM notification_deferred WHERE id <= ?`, events[len(events)-1].ID); err != nil {
+		return nil, fmt.Errorf("删除延迟通知失败: %w", err)
+	}
+	return events, tx.Commit()
+}
+
+// CountDeferredEvents 按通知器统计待汇总的事件数
+func CountDeferredEvents() (map[string]int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT notifier_key, COUNT(*) FROM notification_deferred GROUP BY notifier_key`)
+	if err != nil {
+		return nil, fmt.Errorf("统计延迟通知失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAlertClosed 告警不存在或已被确认、解除
+var ErrAlertClosed = errors.New("告警不存在或已处理")
+
+// 告警状态（由确认、暂停、解除时间推算）
+const (
+	AlertActive    = "active"    // 未确认，按策略重复提醒
+	AlertSnoozed   = "snoozed"   // 暂停提醒中
+	AlertExhausted = "exhausted" // 已达到最大提醒次数，仍未确认
+	AlertAcked     = "acked"     // 已确认
+	AlertResolved  = "resolved"  // 域名状态已变化，自动解除
+)
+
+// EscalationPolicy 重复提醒策略：域名进入指定状态后按间隔重复提醒，直到确认
+type EscalationPolicy struct {
+	ID              int64     `json:"id"`
+	Domain          string    `json:"domain"`           // 域名，GlobalScope 表示全部域名
+	Status          string    `json:"status"`           // 触发状态，如 available
+	IntervalMinutes int       `json:"interval_minutes"` // 重复间隔（分钟）
+	MaxRepeats      int       `json:"max_repeats"`      // 最多重复次数，0 表示直到确认
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Alert 需要确认的告警
+type Alert struct {
+	ID           int64      `json:"id"`
+	Domain       string     `json:"domain"`
+	Status       string     `json:"status"`
+	OldStatus    string     `json:"old_status"` // 触发告警的状态变化前的状态，重复提醒按原状态变化路由
+	PolicyID     int64      `json:"policy_id"`
+	Repeats      int        `json:"repeats"`                 // 已重复提醒次数（不含首次通知）
+	NextAt       *time.Time `json:"next_at,omitempty"`       // 下次提醒时间，为空表示不再提醒
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"` // 暂停提醒截止时间
+	AckedAt      *time.Time `json:"acked_at,omitempty"`
+	AckedBy      string     `json:"acked_by,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	State        string     `json:"state"`
+}
+
+// escalationPolicyColumns 查询列
+const escalationPolicyColumns = `id, domain, status, interval_minutes, max_repeats, enabled, created_at`
+
+// alertColumns 查询列
+const alertColumns = `id, domain, status, old_status, policy_id, repeats, next_at, snoozed_until, acked_at, acked_by, resolved_at, created_at`
+
+// alertOpenClause 未确认且未解除的告警
+const alertOpenClause = `acked_at IS NULL AND resolved_at IS NULL`
+
+// scanEscalationPolicy 读取一行策略
+func scanEscalationPolicy(scanner interface{ Scan(...interface{}) error }) (EscalationPolicy, error) {
+	var p EscalationPolicy
+	err := scanner.Scan(&p.ID, &p.Domain, &p.Status, &p.IntervalMinutes, &p.MaxRepeats, &p.Enabled, &p.CreatedAt)
+	return p, err
+}
+
+// scanAlert 读取一行告警
+func scanAlert(scanner interface{ Scan(...interface{}) error }) (Alert, error) {
+	var a Alert
+	var nextAt, snoozedUntil int64
+	var ackedAt, resolvedAt sql.NullTime
+	if err := scanner.Scan(&a.ID, &a.Domain, &a.Status, &a.OldStatus, &a.PolicyID, &a.Repeats, &nextAt, &snoozedUntil,
+		&ackedAt, &a.AckedBy, &resolvedAt, &a.CreatedAt); err != nil {
+		return a, err
+	}
+	if nextAt > 0 {
+		t := time.Unix(nextAt, 0)
+		a.NextAt = &t
+	}
+	if snoozedUntil > 0 {
+		t := time.Unix(snoozedUntil, 0)
+		a.SnoozedUntil = &t
+	}
+	if ackedAt.Valid {
+		a.AckedAt = &ackedAt.Time
+	}
+	if resolvedAt.Valid {
+		a.ResolvedAt = &resolvedAt.Time
+	}
+
+	switch {
+	case a.ResolvedAt != nil:
+		a.State = AlertResolved
+	case a.AckedAt != nil:
+		a.State = AlertAcked
+	case a.SnoozedUntil != nil && a.SnoozedUntil.After(time.Now()):
+		a.State = AlertSnoozed
+	case a.NextAt == nil:
+		a.State = AlertExhausted
+	default:
+		a.State = AlertActive
+	}
+	return a, nil
+}
+
+// ListEscalationPolicies 列出全部重复提醒策略
+func ListEscalationPolicies() ([]EscalationPolicy, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT ` + escalationPolicyColumns + ` FROM escalation_policies ORDER BY domain ASC, status ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询提醒策略失败: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []EscalationPolicy
+	for rows.Next() {
+		p, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取提醒策略失败: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// GetEscalationPolicy 获取单条策略，不存在时返回 nil
+func GetEscalationPolicy(id int64) (*EscalationPolicy, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := scanEscalationPolicy(db.QueryRow(`SELECT `+escalationPolicyColumns+` FROM escalation_policies WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询提醒策略失败: %w", err)
+	}
+	return &p, nil
+}
+
+// CreateEscalationPolicy 新增策略，成功后回填 ID
+func CreateEscalationPolicy(p *EscalationPolicy) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`INSERT INTO escalation_policies(domain, status, interval_minutes, max_repeats, enabled) VALUES(?, ?, ?, ?, ?)`,
+		p.Domain, p.Status, p.IntervalMinutes, p.MaxRepeats, p.Enabled)
+	if err != nil {
+		return fmt.Errorf("保存提醒策略失败: %w", err)
+	}
+	p.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// UpdateEscalationPolicy 更新策略
+func UpdateEscalationPolicy(p *EscalationPolicy) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE escalation_policies SET domain = ?, status = ?, interval_minutes = ?, max_repeats = ?, enabled = ? WHERE id = ?`,
+		p.Domain, p.Status, p.IntervalMinutes, p.MaxRepeats, p.Enabled, p.ID)
+	if err != nil {
+		return fmt.Errorf("更新提醒策略失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("提醒策略不存在: %d", p.ID)
+	}
+	return nil
+}
+
+// DeleteEscalationPolicy 删除策略
+func DeleteEscalationPolicy(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM escalation_policies WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除提醒策略失败: %w", err)
+	}
+	return nil
+}
+
+// OpenAlert 为域名当前状态创建告警；已有未确认的同状态告警时直接返回该告警
+func OpenAlert(domain, status, oldStatus string, policyID int64, nextAt time.Time) (*Alert, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := scanAlert(db.QueryRow(`SELECT `+alertColumns+` FROM alerts WHERE domain = ? AND status = ? AND `+alertOpenClause+` ORDER BY id DESC LIMIT 1`,
+		domain, status))
+	if err == nil {
+		return &existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("查询告警失败: %w", err)
+	}
+
+	res, err := db.Exec(`INSERT INTO alerts(domain, status, old_status, policy_id, next_at) VALUES(?, ?, ?, ?, ?)`, domain, status, oldStatus, policyID, nextAt.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("保存告警失败: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return GetAlert(id)
+}
+
+// GetAlert 获取单条告警，不存在时返回 nil
+func GetAlert(id int64) (*Alert, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := scanAlert(db.QueryRow(`SELECT `+alertColumns+` FROM alerts WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询告警失败: %w", err)
+	}
+	return &alert, nil
+}
+
+// ListAlerts 分页列出告警（按时间倒序），openOnly 为 true 时仅返回未确认且未解除的告警
+func ListAlerts(openOnly bool, limit, offset int) ([]Alert, int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := ""
+	if openOnly {
+		where = ` WHERE ` + alertOpenClause
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM alerts` + where).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计告警失败: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT `+alertColumns+` FROM alerts`+where+` ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询告警失败: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("读取告警失败: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, total, rows.Err()
+}
+
+// DueAlerts 列出到达提醒时间且未暂停的告警
+func DueAlerts(now time.Time) ([]Alert, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT `+alertColumns+` FROM alerts WHERE `+alertOpenClause+`
+AND next_at > 0 AND next_at <= ? AND snoozed_until <= ? ORDER BY next_at ASC`, now.Unix(), now.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("查询告警失败: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取告警失败: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// MarkAlertRepeated 记录一次重复提醒；nextAt 为零值时不再提醒
+func MarkAlertRepeated(id int64, nextAt time.Time) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	var next int64
+	if !nextAt.IsZero() {
+		next = nextAt.Unix()
+	}
+	if _, err := db.Exec(`UPDATE alerts SET repeats = repeats + 1, next_at = ? WHERE id = ?`, next, id); err != nil {
+		return fmt.Errorf("更新告警失败: %w", err)
+	}
+	return nil
+}
+
+// StopAlert 停止告警的重复提醒（策略被删除或停用）
+func StopAlert(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`UPDATE alerts SET next_at = 0 WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("更新告警失败: %w", err)
+	}
+	return nil
+}
+
+// AckAlert 确认告警，停止重复提醒
+func AckAlert(id int64, by string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE alerts SET acked_at = CURRENT_TIMESTAMP, acked_by = ?, next_at = 0 WHERE id = ? AND `+alertOpenClause, by, id)
+	if err != nil {
+		return fmt.Errorf("确认告警失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %d", ErrAlertClosed, id)
+	}
+	return nil
+}
+
+// SnoozeAlert 暂停告警提醒至 until
+func SnoozeAlert(id int64, until time.Time) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE alerts SET snoozed_until = ? WHERE id = ? AND `+alertOpenClause, until.Unix(), id)
+	if err != nil {
+		return fmt.Errorf("暂停告警失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %d", ErrAlertClosed, id)
+	}
+	return nil
+}
+
+// ResolveAlerts 域名状态变化后解除不再匹配的告警；status 为空时解除该域名的全部告警
+func ResolveAlerts(domain, status string) (int64, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.Exec(`UPDATE alerts SET resolved_at = CURRENT_TIMESTAMP, next_at = 0 WHERE domain = ? AND status != ? AND `+alertOpenClause,
+		domain, status)
+	if err != nil {
+		return 0, fmt.Errorf("解除告警失败: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotificationDelivery 通知投递日志（每次投递尝试一条，只追加）
+type NotificationDelivery struct {
+	ID        int64     `json:"id"`
+	OutboxID  int64     `json:"outbox_id"`  // 对应的发件箱记录
+	EventType string    `json:"event_type"` // 事件类型（聚合通知为第一条事件的类型）
+	Domains   []string  `json:"domains"`    // 涉及的域名
+	Channel   string    `json:"channel"`    // 通知器标识
+	Subject   string    `json:"subject"`    // 实际发送的主题
+	Message   string    `json:"message"`    // 实际发送的正文
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	Attempt   int       `json:"attempt"`    // 第几次尝试
+	LatencyMs int64     `json:"latency_ms"` // 发送耗时（毫秒）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeliveryFilter 投递日志查询条件（零值表示不限制）
+type DeliveryFilter struct {
+	Domain    string
+	Channel   string // 通知器标识（如 telegram:2）或类型（如 telegram）
+	EventType string
+	Success   *bool
+	Since     time.Time
+	Until     time.Time
+}
+
+// RecordDelivery 追加一条投递日志
+func RecordDelivery(d NotificationDelivery) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO notification_deliveries(outbox_id, event_type, domains, channel, subject, message, success, error, attempt, latency_ms)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.OutboxID, d.EventType, strings.Join(d.Domains, ","), d.Channel, d.Subject, d.Message, d.Success, d.Error, d.Attempt, d.LatencyMs)
+	if err != nil {
+		return fmt.Errorf("记录投递日志失败: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries 按时间倒序分页查询投递日志，返回记录与总数
+func ListDeliveries(filter DeliveryFilter, limit, offset int) ([]NotificationDelivery, int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var conds []string
+	var args []interface{}
+	if filter.Domain != "" {
+		conds = append(conds, `(',' || domains || ',') LIKE ?`)
+		args = append(args, "%,"+filter.Domain+",%")
+	}
+	if filter.Channel != "" {
+		conds = append(conds, `(channel = ? OR channel LIKE ?)`)
+		args = append(args, filter.Channel, filter.Channel+":%")
+	}
+	if filter.EventType != "" {
+		conds = append(conds, `event_type = ?`)
+		args = append(args, filter.EventType)
+	}
+	if filter.Success != nil {
+		conds = append(conds, `success = ?`)
+		args = append(args, *filter.Success)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, `created_at >= ?`)
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, `created_at < ?`)
+		args = append(args, filter.Until.UTC().Format("2006-01-02 15:04:05"))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM notification_deliveries`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计投递日志失败: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT id, outbox_id, event_type, domains, channel, subject, message, success, error, attempt, latency_ms, created_at
+FROM notification_deliveries`+where+` ORDER BY id DESC LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询投递日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []NotificationDelivery
+	for rows.Next() {
+		var d NotificationDelivery
+		var domains string
+		if err := rows.Scan(&d.ID, &d.OutboxID, &d.EventType, &domains, &d.Channel, &d.Subject, &d.Message,
+			&d.Success, &d.Error, &d.Attempt, &d.LatencyMs, &d.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("读取投递日志失败: %w", err)
+		}
+		d.Domains = splitList(domains)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotificationRule 通知路由规则（条件为空表示不限制）
+type NotificationRule struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Position        int       `json:"position"`         // 排序，越小越先匹配
+	Enabled         bool      `json:"enabled"`          // 是否启用
+	EventType       string    `json:"event_type"`       // 事件类型，如 status_change、registrar_change
+	DomainPattern   string    `json:"domain_pattern"`   // 域名通配符，如 *.example.com
+	TLD             string    `json:"tld"`              // 顶级域，如 com、co.uk
+	FromStatus      string    `json:"from_status"`      // 原状态
+	ToStatus        string    `json:"to_status"`        // 新状态
+	ErrorClass      string    `json:"error_class"`      // 查询错误分类，如 timeout、rate_limited（仅用于连续查询失败告警）
+	Targets         []string  `json:"targets"`          // 接收的通知器标识，"*" 表示全部，为空表示丢弃
+	ThrottleSeconds int       `json:"throttle_seconds"` // 同一域名的最小通知间隔（秒），0 表示不限制
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// notificationRuleColumns 查询列
+const notificationRuleColumns = `id, name, position, enabled, event_type, domain_pattern, tld, from_status, to_status, error_class, targets, throttle_seconds, created_at`
+
+// scanNotificationRule 读取一行规则
+func scanNotificationRule(scanner interface{ Scan(...interface{}) error }) (NotificationRule, error) {
+	var rule NotificationRule
+	var targets string
+	err := scanner.Scan(&rule.ID, &rule.Name, &rule.Position, &rule.Enabled, &rule.EventType, &rule.DomainPattern,
+		&rule.TLD, &rule.FromStatus, &rule.ToStatus, &rule.ErrorClass, &targets, &rule.ThrottleSeconds, &rule.CreatedAt)
+	rule.Targets = splitList(targets)
+	return rule, err
+}
+
+// ListNotificationRules 按匹配顺序列出全部规则
+func ListNotificationRules() ([]NotificationRule, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT ` + notificationRuleColumns + ` FROM notification_rules ORDER BY position ASC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []NotificationRule
+	for rows.Next() {
+		rule, err := scanNotificationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取路由规则失败: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetNotificationRule 获取单条规则，不存在时返回 nil
+func GetNotificationRule(id int64) (*NotificationRule, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := scanNotificationRule(db.QueryRow(`SELECT `+notificationRuleColumns+` FROM notification_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateNotificationRule 新增规则，成功后回填 ID
+func CreateNotificationRule(rule *NotificationRule) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`INSERT INTO notification_rules(name, position, enabled, event_type, domain_pattern, tld, from_status, to_status, error_class, targets, throttle_seconds)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.Name, rule.Position, rule.Enabled, rule.EventType, rule.DomainPattern, rule.TLD,
+		rule.FromStatus, rule.ToStatus, rule.ErrorClass, strings.Join(rule.Targets, ","), rule.ThrottleSeconds)
+	if err != nil {
+		return fmt.Errorf("保存路由规则失败: %w", err)
+	}
+	rule.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// UpdateNotificationRule 更新规则
+func UpdateNotificationRule(rule *NotificationRule) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE notification_rules SET name = ?, position = ?, enabled = ?, event_type = ?, domain_pattern = ?, tld = ?,
+from_status = ?, to_status = ?, error_class = ?, targets = ?, throttle_seconds = ? WHERE id = ?`,
+		rule.Name, rule.Position, rule.Enabled, rule.EventType, rule.DomainPattern, rule.TLD,
+		rule.FromStatus, rule.ToStatus, rule.ErrorClass, strings.Join(rule.Targets, ","), rule.ThrottleSeconds, rule.ID)
+	if err != nil {
+		return fmt.Errorf("更新路由规则失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("路由规则不存在: %d", rule.ID)
+	}
+	return nil
+}
+
+// DeleteNotificationRule 删除规则
+func DeleteNotificationRule(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM notification_rules WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除路由规则失败: %w", err)
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
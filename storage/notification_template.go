@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// 通知模板类型
+const (
+	TemplateSingle = "single" // 单条通知
+	TemplateBatch  = "batch"  // 聚合通知
+)
+
+// NotificationTemplate 通知模板（按渠道与类型唯一，主题或正文为空时使用内置排版）
+type NotificationTemplate struct {
+	ID        int64     `json:"id"`
+	Channel   string    `json:"channel"` // 通知器类型（如 telegram）或通知器标识（如 telegram:2）
+	Kind      string    `json:"kind"`    // single 或 batch
+	Subject   string    `json:"subject"` // 主题模板
+	Body      string    `json:"body"`    // 正文模板
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// notificationTemplateColumns 查询列
+const notificationTemplateColumns = `id, channel, kind, subject, body, enabled, updated_at`
+
+// scanNotificationTemplate 读取一行模板
+func scanNotificationTemplate(scanner interface{ Scan(...interface{}) error }) (NotificationTemplate, error) {
+	var tpl NotificationTemplate
+	err := scanner.Scan(&tpl.ID, &tpl.Channel, &tpl.Kind, &tpl.Subject, &tpl.Body, &tpl.Enabled, &tpl.UpdatedAt)
+	return tpl, err
+}
+
+// ListNotificationTemplates 列出全部模板
+func ListNotificationTemplates() ([]NotificationTemplate, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT ` + notificationTemplateColumns + ` FROM notification_templates ORDER BY channel ASC, kind ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知模板失败: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []NotificationTemplate
+	for rows.Next() {
+		tpl, err := scanNotificationTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取通知模板失败: %w", err)
+		}
+		templates = append(templates, tpl)
+	}
+	return templates, rows.Err()
+}
+
+// GetNotificationTemplate 获取单个模板，不存在时返回 nil
+func GetNotificationTemplate(id int64) (*NotificationTemplate, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, err := scanNotificationTemplate(db.QueryRow(`SELECT `+notificationTemplateColumns+` FROM notification_templates WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询通知模板失败: %w", err)
+	}
+	return &tpl, nil
+}
+
+// CreateNotificationTemplate 新增模板，成功后回填 ID
+func CreateNotificationTemplate(tpl *NotificationTemplate) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`INSERT INTO notification_templates(channel, kind, subject, body, enabled) VALUES(?, ?, ?, ?, ?)`,
+		tpl.Channel, tpl.Kind, tpl.Subject, tpl.Body, tpl.Enabled)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("渠道 %s 已存在 %s 模板", tpl.Channel, tpl.Kind)
+		}
+		return fmt.Errorf("保存通知模板失败: %w", err)
+	}
+	tpl.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// UpdateNotificationTemplate 更新模板
+func UpdateNotificationTemplate(tpl *NotificationTemplate) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE notification_templates SET channel = ?, kind = ?, subject = ?, body = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		tpl.Channel, tpl.Kind, tpl.Subject, tpl.Body, tpl.Enabled, tpl.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("渠道 %s 已存在 %s 模板", tpl.Channel, tpl.Kind)
+		}
+		return fmt.Errorf("更新通知模板失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("通知模板不存在: %d", tpl.ID)
+	}
+	return nil
+}
+
+// DeleteNotificationTemplate 删除模板
+func DeleteNotificationTemplate(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM notification_templates WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除通知模板失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotifierInstance 通知渠道实例（同一类型可配置多个，如多个 Telegram 聊天、多个 SMTP 账户）
+type NotifierInstance struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`    // 通知器类型：email、telegram
+	Name      string    `json:"name"`    // 显示名称，同类型内唯一
+	Config    string    `json:"config"`  // 类型相关配置（JSON）
+	Enabled   bool      `json:"enabled"` // 是否启用
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListNotifierInstances 列出通知渠道实例，notifierType 为空时返回全部
+func ListNotifierInstances(notifierType string) ([]NotifierInstance, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, type, name, config, enabled, created_at, updated_at FROM notifier_instances`
+	var args []interface{}
+	if notifierType != "" {
+		query += ` WHERE type = ?`
+		args = append(args, notifierType)
+	}
+	query += ` ORDER BY type ASC, id ASC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知渠道失败: %w", err)
+	}
+	defer rows.Close()
+
+	var instances []NotifierInstance
+	for rows.Next() {
+		var inst NotifierInstance
+		if err := rows.Scan(&inst.ID, &inst.Type, &inst.Name, &inst.Config, &inst.Enabled, &inst.CreatedAt, &inst.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("读取通知渠道失败: %w", err)
+		}
+		instances = append(instances, inst)
+	}
+	return instances, rows.Err()
+}
+
+// GetNotifierInstance 获取单个通知渠道实例，不存在时返回 nil
+func GetNotifierInstance(id int64) (*NotifierInstance, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var inst NotifierInstance
+	err = db.QueryRow(`SELECT id, type, name, config, enabled, created_at, updated_at FROM notifier_instances WHERE id = ?`, id).
+		Scan(&inst.ID, &inst.Type, &inst.Name, &inst.Config, &inst.Enabled, &inst.CreatedAt, &inst.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询通知渠道失败: %w", err)
+	}
+	return &inst, nil
+}
+
+// CreateNotifierInstance 新增通知渠道实例，成功后回填 ID
+func CreateNotifierInstance(inst *NotifierInstance) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+	return insertNotifierInstance(db, inst)
+}
+
+// UpdateNotifierInstance 更新通知渠道实例的名称、配置与启用状态
+func UpdateNotifierInstance(inst *NotifierInstance) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE notifier_instances SET name = ?, config = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		strings.TrimSpace(inst.Name), inst.Config, inst.Enabled, inst.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("同类型下已存在名为 %s 的通知渠道", inst.Name)
+		}
+		return fmt.Errorf("更新通知渠道失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("通知渠道不存在: %d", inst.ID)
+	}
+	return nil
+}
+
+// DeleteNotifierInstance 删除通知渠道实例
+func DeleteNotifierInstance(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM notifier_instances WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除通知渠道失败: %w", err)
+	}
+	return nil
+}
+
+// MigrateSettingsToNotifiers 在同一事务中写入迁移得到的通知渠道实例、删除旧设置键并写入迁移标记
+func MigrateSettingsToNotifiers(instances []NotifierInstance, legacyKeys []string, markerKey string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range instances {
+		if err := insertNotifierInstance(tx, &instances[i]); err != nil {
+			return err
+		}
+	}
+	for _, key := range legacyKeys {
+		if _, err := tx.Exec(`DELETE FROM app_settings WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("删除旧设置失败(%s): %w", key, err)
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO app_settings(key, value) VALUES(?, 'true')
+ON CONFLICT(key) DO UPDATE SET value=excluded.value`, markerKey); err != nil {
+		return fmt.Errorf("写入迁移标记失败: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// execer 同时适用于 *sql.DB 与 *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertNotifierInstance 插入通知渠道实例
+func insertNotifierInstance(db execer, inst *NotifierInstance) error {
+	inst.Name = strings.TrimSpace(inst.Name)
+	if inst.Type == "" || inst.Name == "" {
+		return fmt.Errorf("通知渠道类型和名称不能为空")
+	}
+	if inst.Config == "" {
+		inst.Config = "{}"
+	}
+
+	res, err := db.Exec(`INSERT INTO notifier_instances(type, name, config, enabled) VALUES(?, ?, ?, ?)`,
+		inst.Type, inst.Name, inst.Config, inst.Enabled)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("同类型下已存在名为 %s 的通知渠道", inst.Name)
+		}
+		return fmt.Errorf("保存通知渠道失败: %w", err)
+	}
+	inst.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// isUniqueViolation 判断是否为唯一约束冲突
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
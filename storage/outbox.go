@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 发件箱投递状态
+const (
+	OutboxPending = "pending" // 待投递（含等待重试）
+	OutboxSent    = "sent"    // 已投递
+	OutboxDead    = "dead"    // 超过最大重试次数，进入死信
+)
+
+// OutboxEntry 通知发件箱记录（每个接收通知器一条）
+type OutboxEntry struct {
+	ID            int64      `json:"id"`
+	NotifierKey   string     `json:"notifier_key"` // 通知器标识（见 notification.NotifierKey）
+	Subject       string     `json:"subject"`
+	Message       string     `json:"message"`
+	Events        string     `json:"events"`   // 结构化事件（JSON），供 Webhook 等通知器使用
+	Rendered      bool       `json:"rendered"` // 正文已由自定义模板渲染，发送时跳过内置排版
+	Progress      int        `json:"progress"` // 已成功发送的分段数（如超长 Telegram 消息），重试时从此继续
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// outboxColumns 查询列
+const outboxColumns = `id, notifier_key, subject, message, events, rendered, progress, status, attempts, last_error, next_attempt_at, created_at, sent_at`
+
+// scanOutboxEntry 读取一行发件箱记录
+func scanOutboxEntry(scanner interface{ Scan(...interface{}) error }) (OutboxEntry, error) {
+	var e OutboxEntry
+	var nextAttempt int64
+	var lastError sql.NullString
+	var sentAt sql.NullTime
+	if err := scanner.Scan(&e.ID, &e.NotifierKey, &e.Subject, &e.Message, &e.Events, &e.Rendered, &e.Progress, &e.Status, &e.Attempts,
+		&lastError, &nextAttempt, &e.CreatedAt, &sentAt); err != nil {
+		return e, err
+	}
+	e.LastError = lastError.String
+	e.NextAttemptAt = time.Unix(nextAttempt, 0)
+	if sentAt.Valid {
+		e.SentAt = &sentAt.Time
+	}
+	return e, nil
+}
+
+// EnqueueOutbox 批量写入待投递记录
+func EnqueueOutbox(entries []OutboxEntry) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	for i := range entries {
+		res, err := tx.Exec(`INSERT INTO notification_outbox(notifier_key, subject, message, events, rendered, status, next_attempt_at) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+			entries[i].NotifierKey, entries[i].Subject, entries[i].Message, entries[i].Events, entries[i].Rendered, OutboxPending, now)
+		if err != nil {
+			return fmt.Errorf("写入通知发件箱失败: %w", err)
+		}
+		entries[i].ID, _ = res.LastInsertId()
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDueOutbox 领取到期的待投递记录，并将其下次投递时间推迟 lease，避免重复领取
+func ClaimDueOutbox(now time.Time, lease time.Duration, limit int) ([]OutboxEntry, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+outboxColumns+` FROM notification_outbox
+WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at ASC, id ASC LIMIT ?`, OutboxPending, now.Unix(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知发件箱失败: %w", err)
+	}
+	var entries []OutboxEntry
+	for rows.Next() {
+		e, err := scanOutboxEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取通知发件箱失败: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leaseUntil := now.Add(lease).Unix()
+	for _, e := range entries {
+		if _, err := tx.Exec(`UPDATE notification_outbox SET next_attempt_at = ? WHERE id = ?`, leaseUntil, e.ID); err != nil {
+			return nil, fmt.Errorf("领取通知发件箱失败: %w", err)
+		}
+	}
+
+	return entries, tx.Commit()
+}
+
+// MarkOutboxSent 标记投递成功
+func MarkOutboxSent(id int64, attempts int) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`UPDATE notification_outbox SET status = ?, attempts = ?, last_error = NULL, sent_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		OutboxSent, attempts, id); err != nil {
+		return fmt.Errorf("更新通知发件箱失败: %w", err)
+	}
+	return nil
+}
+
+// SetOutboxProgress 记录已成功发送的分段数
+func SetOutboxProgress(id int64, progress int) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`UPDATE notification_outbox SET progress = ? WHERE id = ?`, progress, id); err != nil {
+		return fmt.Errorf("更新通知发件箱失败: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxFailed 记录投递失败；nextAttempt 为零值时进入死信
+func MarkOutboxFailed(id int64, attempts int, lastError string, nextAttempt time.Time) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	status := OutboxPending
+	if nextAttempt.IsZero() {
+		status = OutboxDead
+	}
+	if _, err := db.Exec(`UPDATE notification_outbox SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?`,
+		status, attempts, lastError, nextAttempt.Unix(), id); err != nil {
+		return fmt.Errorf("更新通知发件箱失败: %w", err)
+	}
+	return nil
+}
+
+// ListOutbox 分页列出发件箱记录（按时间倒序），status 为空时返回全部
+func ListOutbox(status string, limit, offset int) ([]OutboxEntry, int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := ""
+	var args []interface{}
+	if status != "" {
+		where = ` WHERE status = ?`
+		args = append(args, status)
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM notification_outbox`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计通知发件箱失败: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT `+outboxColumns+` FROM notification_outbox`+where+` ORDER BY id DESC LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询通知发件箱失败: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		e, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("读取通知发件箱失败: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// CountOutboxByStatus 按状态统计发件箱记录数
+func CountOutboxByStatus() (map[string]int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT status, COUNT(*) FROM notification_outbox GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("统计通知发件箱失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{OutboxPending: 0, OutboxSent: 0, OutboxDead: 0}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// ReplayOutbox 将死信记录重新置为待投递并清零重试次数；ids 为空时重放全部死信，返回重放条数
+func ReplayOutbox(ids []int64) (int64, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE notification_outbox SET status = ?, attempts = 0, next_attempt_at = ? WHERE status = ?`
+	args := []interface{}{OutboxPending, time.Now().Unix(), OutboxDead}
+	if len(ids) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		query += ` AND id IN (` + placeholders + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("重放通知失败: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// PurgeSentOutbox 清理早于指定时间的已投递记录
+func PurgeSentOutbox(before time.Time) (int64, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.Exec(`DELETE FROM notification_outbox WHERE status = ? AND created_at < ?`, OutboxSent, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("清理通知发件箱失败: %w", err)
+	}
+	return res.RowsAffected()
+}
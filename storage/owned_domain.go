@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OwnedDomain 自有域名（需要续费提醒）
+type OwnedDomain struct {
+	Domain       string    `json:"domain"`
+	ReminderDays []int     `json:"reminder_days"` // 到期前提醒天数，按降序排列
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SetDomainOwned 标记域名为自有域名并设置提醒天数
+func SetDomainOwned(domain string, reminderDays []int) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	_, err = db.Exec(`INSERT INTO owned_domains(domain, reminder_days) VALUES(?, ?)
+ON CONFLICT(domain) DO UPDATE SET reminder_days=excluded.reminder_days`,
+		domain, joinDays(reminderDays))
+	if err != nil {
+		return fmt.Errorf("保存自有域名失败: %w", err)
+	}
+	return nil
+}
+
+// UnsetDomainOwned 取消自有域名标记，并清除提醒记录
+func UnsetDomainOwned(domain string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM owned_domains WHERE domain = ?`, domain); err != nil {
+		return fmt.Errorf("删除自有域名失败: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM expiry_reminders WHERE domain = ?`, domain); err != nil {
+		return fmt.Errorf("删除提醒记录失败: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetOwnedDomain 获取自有域名设置，未标记时返回 nil
+func GetOwnedDomain(domain string) (*OwnedDomain, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var od OwnedDomain
+	var days sql.NullString
+	err = db.QueryRow(`SELECT domain, reminder_days, created_at FROM owned_domains WHERE domain = ?`,
+		strings.ToLower(strings.TrimSpace(domain))).Scan(&od.Domain, &days, &od.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询自有域名失败: %w", err)
+	}
+	od.ReminderDays = splitDays(days.String)
+
+	return &od, nil
+}
+
+// ListOwnedDomains 列出所有自有域名
+func ListOwnedDomains() ([]OwnedDomain, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT domain, reminder_days, created_at FROM owned_domains ORDER BY domain ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询自有域名失败: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []OwnedDomain
+	for rows.Next() {
+		var od OwnedDomain
+		var days sql.NullString
+		if err := rows.Scan(&od.Domain, &days, &od.CreatedAt); err != nil {
+			return nil, err
+		}
+		od.ReminderDays = splitDays(days.String)
+		domains = append(domains, od)
+	}
+
+	return domains, rows.Err()
+}
+
+// ListSentReminders 返回域名在指定过期日期下已发送的提醒天数
+func ListSentReminders(domain, expiryDate string) (map[int]bool, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT offset_days FROM expiry_reminders WHERE domain = ? AND expiry_date = ?`,
+		domain, expiryDate)
+	if err != nil {
+		return nil, fmt.Errorf("查询提醒记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	sent := make(map[int]bool)
+	for rows.Next() {
+		var offset int
+		if err := rows.Scan(&offset); err != nil {
+			return nil, err
+		}
+		sent[offset] = true
+	}
+
+	return sent, rows.Err()
+}
+
+// MarkRemindersSent 记录已发送的提醒
+func MarkRemindersSent(domain, expiryDate string, offsets []int) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, offset := range offsets {
+		if _, err := tx.Exec(`INSERT INTO expiry_reminders(domain, expiry_date, offset_days) VALUES(?, ?, ?)
+ON CONFLICT(domain, expiry_date, offset_days) DO NOTHING`, domain, expiryDate, offset); err != nil {
+			return fmt.Errorf("保存提醒记录失败: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ResetReminders 删除其他过期日期下的提醒记录（过期日期后移表示已续费），返回删除条数
+func ResetReminders(domain, expiryDate string) (int64, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(`DELETE FROM expiry_reminders WHERE domain = ? AND expiry_date <> ?`, domain, expiryDate)
+	if err != nil {
+		return 0, fmt.Errorf("重置提醒记录失败: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// joinDays 将提醒天数去重并降序拼接
+func joinDays(days []int) string {
+	seen := make(map[int]bool)
+	var unique []int
+	for _, d := range days {
+		if !seen[d] {
+			seen[d] = true
+			unique = append(unique, d)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(unique)))
+
+	parts := make([]string, len(unique))
+	for i, d := range unique {
+		parts[i] = strconv.Itoa(d)
+	}
+	return strings.Join(parts, ",")
+}
+
+// splitDays 解析逗号分隔的提醒天数
+func splitDays(value string) []int {
+	var days []int
+	for _, part := range strings.Split(value, ",") {
+		if d, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			days = append(days, d)
+		}
+	}
+	return days
+}
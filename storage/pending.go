@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PendingEvent 已接收但尚未发出的状态变化事件（聚合器重启后继续处理）
+type PendingEvent struct {
+	ID        int64     `json:"id"`
+	Domain    string    `json:"domain"`
+	Event     string    `json:"event"`     // 结构化事件（JSON）
+	Grouped   bool      `json:"grouped"`   // 已加入合并发送的通知组
+	Receivers []string  `json:"receivers"` // 合并发送的接收通知器标识（加入通知组时确定）
+	AlertID   int64     `json:"alert_id"`  // 关联的告警，0 表示无
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddPendingEvent 写入新接收的事件，返回记录 ID
+func AddPendingEvent(domain, event string) (int64, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.Exec(`INSERT INTO notification_pending(domain, event) VALUES(?, ?)`, domain, event)
+	if err != nil {
+		return 0, fmt.Errorf("写入待处理通知失败: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListPendingEvents 按写入顺序列出待处理事件，grouped 区分已加入通知组与尚未处理的事件
+func ListPendingEvents(grouped bool) ([]PendingEvent, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id, domain, event, grouped, receivers, alert_id, created_at FROM notification_pending WHERE grouped = ? ORDER BY id ASC`, grouped)
+	if err != nil {
+		return nil, fmt.Errorf("查询待处理通知失败: %w", err)
+	}
+	defer rows.Close()
+
+	var events []PendingEvent
+	for rows.Next() {
+		var e PendingEvent
+		var receivers string
+		if err := rows.Scan(&e.ID, &e.Domain, &e.Event, &e.Grouped, &receivers, &e.AlertID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取待处理通知失败: %w", err)
+		}
+		if receivers != "" {
+			e.Receivers = strings.Split(receivers, ",")
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// MarkPendingGrouped 记录事件已加入通知组及其接收者
+func MarkPendingGrouped(id int64, receivers []string, alertID int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`UPDATE notification_pending SET grouped = 1, receivers = ?, alert_id = ? WHERE id = ?`,
+		strings.Join(receivers, ","), alertID, id); err != nil {
+		return fmt.Errorf("更新待处理通知失败: %w", err)
+	}
+	return nil
+}
+
+// DeletePendingEvents 删除已处理的事件
+func DeletePendingEvents(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM notification_pending WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("删除待处理通知失败: %w", err)
+		}
+	}
+	return tx.Commit()
+}
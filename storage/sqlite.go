@@ -20,6 +20,20 @@ var (
 	dbErr  error
 )
 
+// domainScopedTables 以 domain 列关联域名的附属表，删除域名或清理孤立数据时一并处理
+// domain 为 GlobalScope 的行表示全局配置，不属于任何域名
+var domainScopedTables = []string{
+	"status_history",
+	"whois_snapshots",
+	"change_subscriptions",
+	"owned_domains",
+	"expiry_reminders",
+	"escalation_policies",
+	"alerts",
+	"notification_deferred",
+	"notification_pending",
+}
+
 // DomainEntry 表示存储在数据库中的域名记录
 type DomainEntry struct {
 	ID        int64     `json:"id"`
@@ -86,6 +100,7 @@ CREATE TABLE IF NOT EXISTS domain_results (
 	name_servers TEXT,
 	whois_raw TEXT,
 	error_message TEXT,
+	status_codes TEXT,
 	created_at_record DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -98,6 +113,169 @@ CREATE TABLE IF NOT EXISTS notification_history (
 	notification_type TEXT DEFAULT 'status_change',
 	UNIQUE(domain, status)
 );
+
+CREATE TABLE IF NOT EXISTS status_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL,
+	status TEXT NOT NULL,
+	old_status TEXT,
+	changed INTEGER NOT NULL DEFAULT 1,
+	query_method TEXT,
+	error_code TEXT,
+	error_message TEXT,
+	checked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_status_history_domain ON status_history(domain, checked_at);
+
+CREATE TABLE IF NOT EXISTS whois_snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	query_method TEXT,
+	raw TEXT NOT NULL,
+	captured_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_whois_snapshots_domain ON whois_snapshots(domain, id);
+
+CREATE TABLE IF NOT EXISTS owned_domains (
+	domain TEXT PRIMARY KEY,
+	reminder_days TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS expiry_reminders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL,
+	expiry_date TEXT NOT NULL,
+	offset_days INTEGER NOT NULL,
+	sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(domain, expiry_date, offset_days)
+);
+
+CREATE TABLE IF NOT EXISTS change_subscriptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL,
+	change_type TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(domain, change_type)
+);
+
+CREATE TABLE IF NOT EXISTS notifier_instances (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL,
+	name TEXT NOT NULL,
+	config TEXT NOT NULL DEFAULT '{}',
+	enabled INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(type, name)
+);
+
+CREATE TABLE IF NOT EXISTS notification_rules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	event_type TEXT NOT NULL DEFAULT '',
+	domain_pattern TEXT NOT NULL DEFAULT '',
+	tld TEXT NOT NULL DEFAULT '',
+	from_status TEXT NOT NULL DEFAULT '',
+	to_status TEXT NOT NULL DEFAULT '',
+	error_class TEXT NOT NULL DEFAULT '',
+	targets TEXT NOT NULL DEFAULT '',
+	throttle_seconds INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS notification_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	notifier_key TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	message TEXT NOT NULL,
+	events TEXT NOT NULL DEFAULT '[]',
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at INTEGER NOT NULL DEFAULT 0,
+	rendered INTEGER NOT NULL DEFAULT 0,
+	progress INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	sent_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox(status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS notification_templates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	channel TEXT NOT NULL,
+	kind TEXT NOT NULL DEFAULT 'single',
+	subject TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL DEFAULT '',
+	enabled INTEGER NOT NULL DEFAULT 1,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(channel, kind)
+);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	outbox_id INTEGER NOT NULL DEFAULT 0,
+	event_type TEXT NOT NULL DEFAULT '',
+	domains TEXT NOT NULL DEFAULT '',
+	channel TEXT NOT NULL,
+	subject TEXT NOT NULL DEFAULT '',
+	message TEXT NOT NULL DEFAULT '',
+	success INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	attempt INTEGER NOT NULL DEFAULT 1,
+	latency_ms INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel ON notification_deliveries(channel);
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL DEFAULT '*',
+	status TEXT NOT NULL,
+	interval_minutes INTEGER NOT NULL DEFAULT 30,
+	max_repeats INTEGER NOT NULL DEFAULT 0,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(domain, status)
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL,
+	status TEXT NOT NULL,
+	old_status TEXT NOT NULL DEFAULT '',
+	policy_id INTEGER NOT NULL DEFAULT 0,
+	repeats INTEGER NOT NULL DEFAULT 0,
+	next_at INTEGER NOT NULL DEFAULT 0,
+	snoozed_until INTEGER NOT NULL DEFAULT 0,
+	acked_at DATETIME,
+	acked_by TEXT NOT NULL DEFAULT '',
+	resolved_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_domain ON alerts(domain, status);
+
+CREATE TABLE IF NOT EXISTS notification_deferred (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	notifier_key TEXT NOT NULL,
+	domain TEXT NOT NULL DEFAULT '',
+	event TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notification_deferred_key ON notification_deferred(notifier_key);
+
+CREATE TABLE IF NOT EXISTS notification_pending (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain TEXT NOT NULL DEFAULT '',
+	event TEXT NOT NULL,
+	grouped INTEGER NOT NULL DEFAULT 0,
+	receivers TEXT NOT NULL DEFAULT '',
+	alert_id INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
 `
 	if _, err := db.Exec(schema); err != nil {
 		return fmt.Errorf("初始化数据库表失败: %w", err)
@@ -105,22 +283,44 @@ CREATE TABLE IF NOT EXISTS notification_history (
 	if err := ensureDomainResultColumns(db); err != nil {
 		return err
 	}
+	if err := ensureColumns(db, "notification_outbox", map[string]string{
+		"rendered": "ALTER TABLE notification_outbox ADD COLUMN rendered INTEGER NOT NULL DEFAULT 0",
+		"progress": "ALTER TABLE notification_outbox ADD COLUMN progress INTEGER NOT NULL DEFAULT 0",
+	}); err != nil {
+		return err
+	}
+	if err := ensureColumns(db, "notification_rules", map[string]string{
+		"error_class": "ALTER TABLE notification_rules ADD COLUMN error_class TEXT NOT NULL DEFAULT ''",
+	}); err != nil {
+		return err
+	}
+	if err := ensureColumns(db, "alerts", map[string]string{
+		"old_status": "ALTER TABLE alerts ADD COLUMN old_status TEXT NOT NULL DEFAULT ''",
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
 // ensureDomainResultColumns 确保 domain_results 拥有新增列（迁移兼容）
 func ensureDomainResultColumns(db *sql.DB) error {
-	required := map[string]string{
+	return ensureColumns(db, "domain_results", map[string]string{
 		"created_at":        "ALTER TABLE domain_results ADD COLUMN created_at DATETIME",
 		"expiry_at":         "ALTER TABLE domain_results ADD COLUMN expiry_at DATETIME",
 		"updated_at":        "ALTER TABLE domain_results ADD COLUMN updated_at DATETIME",
 		"name_servers":      "ALTER TABLE domain_results ADD COLUMN name_servers TEXT",
 		"whois_raw":         "ALTER TABLE domain_results ADD COLUMN whois_raw TEXT",
 		"error_message":     "ALTER TABLE domain_results ADD COLUMN error_message TEXT",
+		"status_codes":      "ALTER TABLE domain_results ADD COLUMN status_codes TEXT",
 		"created_at_record": "ALTER TABLE domain_results ADD COLUMN created_at_record DATETIME DEFAULT CURRENT_TIMESTAMP",
-	}
+		"failure_count":     "ALTER TABLE domain_results ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0",
+	})
+}
+
+// ensureColumns 为已有表补齐缺失的列
+func ensureColumns(db *sql.DB, table string, required map[string]string) error {
 	existing := make(map[string]bool)
-	rows, err := db.Query(`PRAGMA table_info(domain_results)`)
+	rows, err := db.Query(`PRAGMA table_info(` + table + `)`)
 	if err != nil {
 		return err
 	}
@@ -314,6 +514,16 @@ func RemoveDomain(name string) error {
 	rowsAffected, _ = result.RowsAffected()
 	logger.Debug("删除域名 %s: notification_history表删除了 %d 行", name, rowsAffected)
 
+	// 4. 删除其他按域名关联的数据（状态历史、快照等）
+	for _, table := range domainScopedTables {
+		result, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE domain = ?`, table), name)
+		if err != nil {
+			return fmt.Errorf("删除%s数据失败: %w", table, err)
+		}
+		rowsAffected, _ = result.RowsAffected()
+		logger.Debug("删除域名 %s: %s表删除了 %d 行", name, table, rowsAffected)
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("提交事务失败: %w", err)
@@ -387,6 +597,14 @@ func RemoveDomains(names []string) error {
 	}
 	historyDeleted, _ := result.RowsAffected()
 
+	// 4. 批量删除其他按域名关联的数据
+	for _, table := range domainScopedTables {
+		query = fmt.Sprintf(`DELETE FROM %s WHERE domain IN (%s)`, table, inClause)
+		if _, err = tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("批量删除%s数据失败: %w", table, err)
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("提交事务失败: %w", err)
@@ -431,6 +649,13 @@ func CleanOrphanedData() error {
 	}
 	historyDeleted, _ := result.RowsAffected()
 
+	// 3. 清理其他按域名关联表中的孤立数据
+	for _, table := range domainScopedTables {
+		if _, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE domain <> ? AND domain NOT IN (SELECT name FROM domains)`, table), GlobalScope); err != nil {
+			return fmt.Errorf("清理%s孤立数据失败: %w", table, err)
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("提交事务失败: %w", err)
@@ -467,6 +692,8 @@ type DomainResult struct {
 	NameServers  []string
 	WhoisRaw     string
 	ErrorMessage string
+	StatusCodes  []string // EPP状态码
+	FailureCount int      // 连续查询失败次数（由 SaveDomainResult 维护）
 }
 
 // SaveDomainResult 保存单个域名查询结果
@@ -475,10 +702,16 @@ func SaveDomainResult(res DomainResult) error {
 	if err != nil {
 		return err
 	}
-	_, err = db.Exec(`INSERT INTO domain_results(domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, whois_raw, error_message)
-VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT(domain) DO UPDATE SET status=excluded.status, registrar=excluded.registrar, last_checked=excluded.last_checked, query_method=excluded.query_method, created_at=excluded.created_at, expiry_at=excluded.expiry_at, updated_at=excluded.updated_at, name_servers=excluded.name_servers, whois_raw=excluded.whois_raw, error_message=excluded.error_message`,
-		res.Domain, res.Status, res.Registrar, res.LastChecked, res.QueryMethod, res.CreatedAt, res.ExpiryAt, res.UpdatedAt, strings.Join(res.NameServers, ","), res.WhoisRaw, res.ErrorMessage)
+	failures := 0
+	if res.Status == "error" {
+		failures = 1
+	}
+	// 连续失败次数：查询失败时累加，成功时清零
+	_, err = db.Exec(`INSERT INTO domain_results(domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, whois_raw, error_message, status_codes, failure_count)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(domain) DO UPDATE SET status=excluded.status, registrar=excluded.registrar, last_checked=excluded.last_checked, query_method=excluded.query_method, created_at=excluded.created_at, expiry_at=excluded.expiry_at, updated_at=excluded.updated_at, name_servers=excluded.name_servers, whois_raw=excluded.whois_raw, error_message=excluded.error_message, status_codes=excluded.status_codes,
+failure_count=CASE WHEN excluded.failure_count > 0 THEN domain_results.failure_count + 1 ELSE 0 END`,
+		res.Domain, res.Status, res.Registrar, res.LastChecked, res.QueryMethod, res.CreatedAt, res.ExpiryAt, res.UpdatedAt, strings.Join(res.NameServers, ","), res.WhoisRaw, res.ErrorMessage, strings.Join(res.StatusCodes, ","), failures)
 	return err
 }
 
@@ -488,7 +721,7 @@ func LoadDomainResults() (map[string]DomainResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	rows, err := db.Query(`SELECT domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, COALESCE(whois_raw, ''), COALESCE(error_message, '') FROM domain_results ORDER BY domain ASC`)
+	rows, err := db.Query(`SELECT domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, COALESCE(whois_raw, ''), COALESCE(error_message, ''), COALESCE(status_codes, ''), failure_count FROM domain_results ORDER BY domain ASC`)
 	if err != nil {
 		return nil, err
 	}
@@ -497,9 +730,9 @@ func LoadDomainResults() (map[string]DomainResult, error) {
 	result := make(map[string]DomainResult)
 	for rows.Next() {
 		var r DomainResult
-		var ns string
+		var ns, codes string
 		var c, e, u sql.NullTime
-		if err := rows.Scan(&r.Domain, &r.Status, &r.Registrar, &r.LastChecked, &r.QueryMethod, &c, &e, &u, &ns, &r.WhoisRaw, &r.ErrorMessage); err != nil {
+		if err := rows.Scan(&r.Domain, &r.Status, &r.Registrar, &r.LastChecked, &r.QueryMethod, &c, &e, &u, &ns, &r.WhoisRaw, &r.ErrorMessage, &codes, &r.FailureCount); err != nil {
 			return nil, err
 		}
 		if c.Valid {
@@ -514,6 +747,9 @@ func LoadDomainResults() (map[string]DomainResult, error) {
 		if strings.TrimSpace(ns) != "" {
 			r.NameServers = strings.Split(ns, ",")
 		}
+		if strings.TrimSpace(codes) != "" {
+			r.StatusCodes = strings.Split(codes, ",")
+		}
 		result[r.Domain] = r
 	}
 	return result, rows.Err()
@@ -529,11 +765,11 @@ func GetDomainResult(domain string) (*DomainResult, error) {
 	domain = strings.ToLower(strings.TrimSpace(domain))
 	
 	var r DomainResult
-	var ns string
+	var ns, codes string
 	var c, e, u sql.NullTime
 	
-	err = db.QueryRow(`SELECT domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, COALESCE(whois_raw, ''), COALESCE(error_message, '') FROM domain_results WHERE domain = ?`, domain).Scan(
-		&r.Domain, &r.Status, &r.Registrar, &r.LastChecked, &r.QueryMethod, &c, &e, &u, &ns, &r.WhoisRaw, &r.ErrorMessage,
+	err = db.QueryRow(`SELECT domain, status, registrar, last_checked, query_method, created_at, expiry_at, updated_at, name_servers, COALESCE(whois_raw, ''), COALESCE(error_message, ''), COALESCE(status_codes, ''), failure_count FROM domain_results WHERE domain = ?`, domain).Scan(
+		&r.Domain, &r.Status, &r.Registrar, &r.LastChecked, &r.QueryMethod, &c, &e, &u, &ns, &r.WhoisRaw, &r.ErrorMessage, &codes, &r.FailureCount,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -555,6 +791,9 @@ func GetDomainResult(domain string) (*DomainResult, error) {
 	if strings.TrimSpace(ns) != "" {
 		r.NameServers = strings.Split(ns, ",")
 	}
+	if strings.TrimSpace(codes) != "" {
+		r.StatusCodes = strings.Split(codes, ",")
+	}
 	
 	return &r, nil
 }
@@ -589,6 +828,31 @@ type NotificationRecord struct {
 	NotificationType string
 }
 
+// ListNotificationsSince 列出指定时间之后的状态变化通知记录（按时间倒序）
+func ListNotificationsSince(since time.Time) ([]NotificationRecord, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id, domain, status, COALESCE(old_status, ''), sent_at, notification_type
+		FROM notification_history WHERE sent_at >= ? ORDER BY sent_at DESC`, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("查询通知记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var records []NotificationRecord
+	for rows.Next() {
+		var record NotificationRecord
+		if err := rows.Scan(&record.ID, &record.Domain, &record.Status, &record.OldStatus, &record.SentAt, &record.NotificationType); err != nil {
+			return nil, fmt.Errorf("读取通知记录失败: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
 // GetLastNotification 获取域名的最后一次通知记录
 func GetLastNotification(domain string) (*NotificationRecord, error) {
 	db, err := GetDB()
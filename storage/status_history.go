@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatusHistoryEntry 域名状态历史记录
+type StatusHistoryEntry struct {
+	ID           int64
+	Domain       string
+	Status       string
+	OldStatus    string
+	Changed      bool // 本次查询是否发生了状态变化
+	QueryMethod  string
+	ErrorCode    string
+	ErrorMessage string
+	CheckedAt    time.Time
+}
+
+// SaveStatusHistory 追加一条状态历史记录
+func SaveStatusHistory(entry StatusHistoryEntry) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	domain := strings.ToLower(strings.TrimSpace(entry.Domain))
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	if entry.CheckedAt.IsZero() {
+		entry.CheckedAt = time.Now()
+	}
+
+	_, err = db.Exec(`INSERT INTO status_history(domain, status, old_status, changed, query_method, error_code, error_message, checked_at)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		domain, entry.Status, entry.OldStatus, boolToInt(entry.Changed), entry.QueryMethod, entry.ErrorCode, entry.ErrorMessage, entry.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("保存状态历史失败: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatusHistory 读取域名的状态历史（按时间升序）
+// changesOnly 为 true 时仅返回状态变化记录；limit <= 0 表示不限制，否则返回最近的 limit 条
+func ListStatusHistory(domain string, changesOnly bool, limit int) ([]StatusHistoryEntry, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	query := `SELECT id, domain, status, COALESCE(old_status, ''), changed, COALESCE(query_method, ''), COALESCE(error_code, ''), COALESCE(error_message, ''), checked_at
+FROM status_history WHERE domain = ?`
+	if changesOnly {
+		query += ` AND changed = 1`
+	}
+	query += ` ORDER BY checked_at DESC, id DESC`
+	args := []interface{}{domain}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询状态历史失败: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []StatusHistoryEntry
+	for rows.Next() {
+		var e StatusHistoryEntry
+		var changedInt int
+		if err := rows.Scan(&e.ID, &e.Domain, &e.Status, &e.OldStatus, &changedInt, &e.QueryMethod, &e.ErrorCode, &e.ErrorMessage, &e.CheckedAt); err != nil {
+			return nil, err
+		}
+		e.Changed = changedInt == 1
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 反转为时间升序
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
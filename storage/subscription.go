@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GlobalScope 表示对所有域名生效的全局配置
+const GlobalScope = "*"
+
+// ChangeSubscription 字段变化订阅
+type ChangeSubscription struct {
+	ID         int64     `json:"id"`
+	Domain     string    `json:"domain"`      // 域名，"*" 表示全局
+	ChangeType string    `json:"change_type"` // 变化类型，"*" 表示全部类型
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AddChangeSubscription 新增订阅（已存在时忽略）
+func AddChangeSubscription(domain, changeType string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		domain = GlobalScope
+	}
+	changeType = strings.TrimSpace(changeType)
+	if changeType == "" {
+		return fmt.Errorf("变化类型不能为空")
+	}
+
+	_, err = db.Exec(`INSERT INTO change_subscriptions(domain, change_type) VALUES(?, ?)
+ON CONFLICT(domain, change_type) DO NOTHING`, domain, changeType)
+	if err != nil {
+		return fmt.Errorf("保存订阅失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveChangeSubscription 删除订阅
+func RemoveChangeSubscription(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM change_subscriptions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除订阅失败: %w", err)
+	}
+	return nil
+}
+
+// ListChangeSubscriptions 列出订阅，domain 为空时返回全部
+func ListChangeSubscriptions(domain string) ([]ChangeSubscription, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, domain, change_type, created_at FROM change_subscriptions`
+	var args []interface{}
+	if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+		query += ` WHERE domain = ?`
+		args = append(args, domain)
+	}
+	query += ` ORDER BY domain ASC, change_type ASC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询订阅失败: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []ChangeSubscription
+	for rows.Next() {
+		var sub ChangeSubscription
+		if err := rows.Scan(&sub.ID, &sub.Domain, &sub.ChangeType, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// IsChangeSubscribed 检查域名是否订阅了指定变化类型（域名订阅或全局订阅均可）
+func IsChangeSubscribed(domain, changeType string) (bool, error) {
+	db, err := GetDB()
+	if err != nil {
+		return false, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM change_subscriptions
+WHERE domain IN (?, ?) AND change_type IN (?, ?)`, domain, GlobalScope, changeType, GlobalScope).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("查询订阅失败: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WhoisSnapshot WHOIS/RDAP 原始响应快照
+type WhoisSnapshot struct {
+	ID          int64     `json:"id"`
+	Domain      string    `json:"domain"`
+	ContentHash string    `json:"content_hash"`
+	QueryMethod string    `json:"query_method"`
+	Size        int       `json:"size"`
+	Raw         string    `json:"raw,omitempty"`
+	CapturedAt  time.Time `json:"captured_at"`
+}
+
+// SaveWhoisSnapshot 保存快照（仅当内容哈希与该域名最新快照不同时写入）
+// 返回是否写入了新快照
+func SaveWhoisSnapshot(domain, queryMethod, contentHash, raw string) (bool, error) {
+	db, err := GetDB()
+	if err != nil {
+		return false, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return false, fmt.Errorf("域名不能为空")
+	}
+
+	var latestHash string
+	err = db.QueryRow(`SELECT content_hash FROM whois_snapshots WHERE domain = ? ORDER BY id DESC LIMIT 1`, domain).Scan(&latestHash)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("查询最新快照失败: %w", err)
+	}
+	if latestHash == contentHash {
+		return false, nil
+	}
+
+	_, err = db.Exec(`INSERT INTO whois_snapshots(domain, content_hash, query_method, raw, captured_at) VALUES(?, ?, ?, ?, ?)`,
+		domain, contentHash, queryMethod, raw, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("保存快照失败: %w", err)
+	}
+
+	return true, nil
+}
+
+// ListWhoisSnapshots 列出域名的所有快照（按时间倒序，不含原始内容）
+func ListWhoisSnapshots(domain string) ([]WhoisSnapshot, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	rows, err := db.Query(`SELECT id, domain, content_hash, COALESCE(query_method, ''), LENGTH(raw), captured_at
+FROM whois_snapshots WHERE domain = ? ORDER BY id DESC`, domain)
+	if err != nil {
+		return nil, fmt.Errorf("查询快照列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []WhoisSnapshot
+	for rows.Next() {
+		var s WhoisSnapshot
+		if err := rows.Scan(&s.ID, &s.Domain, &s.ContentHash, &s.QueryMethod, &s.Size, &s.CapturedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// GetWhoisSnapshot 读取单个快照（含原始内容），不存在时返回 nil
+func GetWhoisSnapshot(domain string, id int64) (*WhoisSnapshot, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	var s WhoisSnapshot
+	err = db.QueryRow(`SELECT id, domain, content_hash, COALESCE(query_method, ''), raw, captured_at
+FROM whois_snapshots WHERE domain = ? AND id = ?`, domain, id).Scan(
+		&s.ID, &s.Domain, &s.ContentHash, &s.QueryMethod, &s.Raw, &s.CapturedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询快照失败: %w", err)
+	}
+	s.Size = len(s.Raw)
+
+	return &s, nil
+}
@@ -0,0 +1,370 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// maxSnoozeMinutes 单次暂停提醒的最长时间（7天）
+const maxSnoozeMinutes = 7 * 24 * 60
+
+// errSnoozeRange 暂停时长超出范围
+var errSnoozeRange = fmt.Errorf("暂停时长必须在1-%d分钟之间", maxSnoozeMinutes)
+
+// handleAlerts 分页查看告警
+// GET /api/alerts?state=open|all&page=1&limit=20
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	page := 1
+	limit := 20
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	alerts, total, err := storage.ListAlerts(r.URL.Query().Get("state") != "all", limit, (page-1)*limit)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "alert_list_failed", err.Error())
+		return
+	}
+	if alerts == nil {
+		alerts = []storage.Alert{}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"alerts":      alerts,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + limit - 1) / limit,
+	})
+}
+
+// handleAlertAction 确认或暂停告警
+// POST /api/alerts/{id}/ack
+// POST /api/alerts/{id}/snooze {"minutes": 60}
+func (s *Server) handleAlertAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/alerts/"), "/")
+	idText, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "alert_id_invalid")
+		return
+	}
+
+	switch action {
+	case "ack":
+		if err := storage.AckAlert(id, "web"); err != nil {
+			status, code, args := alertErrorCode(err)
+			s.writeErrorCode(w, r, status, code, args...)
+			return
+		}
+		logger.Info("告警 #%d 已在Web界面确认", id)
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.alert_acked"),
+		})
+	case "snooze":
+		var req struct {
+			Minutes int `json:"minutes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		until, err := snoozeAlert(id, req.Minutes)
+		if err != nil {
+			status, code, args := alertErrorCode(err)
+			s.writeErrorCode(w, r, status, code, args...)
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"status":        "success",
+			"message":       s.text(r, "msg.alert_snoozed", until.Format("2006-01-02 15:04")),
+			"snoozed_until": until,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusNotFound, "action_unknown", action)
+	}
+}
+
+// alertErrorCode 告警操作错误对应的 HTTP 状态码与错误码
+func alertErrorCode(err error) (int, string, []interface{}) {
+	switch {
+	case errors.Is(err, storage.ErrAlertClosed):
+		return http.StatusBadRequest, "alert_closed", nil
+	case errors.Is(err, errSnoozeRange):
+		return http.StatusBadRequest, "snooze_out_of_range", []interface{}{maxSnoozeMinutes}
+	default:
+		return http.StatusInternalServerError, "operation_failed", []interface{}{err.Error()}
+	}
+}
+
+// snoozeAlert 暂停告警提醒 minutes 分钟
+func snoozeAlert(id int64, minutes int) (time.Time, error) {
+	if minutes < 1 || minutes > maxSnoozeMinutes {
+		return time.Time{}, errSnoozeRange
+	}
+	until := time.Now().Add(time.Duration(minutes) * time.Minute)
+	if err := storage.SnoozeAlert(id, until); err != nil {
+		return time.Time{}, err
+	}
+	logger.Info("告警 #%d 已暂停提醒至 %s", id, until.Format("2006-01-02 15:04"))
+	return until, nil
+}
+
+// alertLinkPage 签名链接的确认页面（GET 只展示，POST 才执行，避免链接预览误触发），
+// 文案函数 t 在渲染时按请求语言替换
+var alertLinkPage = template.Must(template.New("alert").Funcs(template.FuncMap{"t": i18n.T}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>{{t "alert.title"}}</title></head>
+<body style="font-family:ui-monospace,SFMono-Regular,Menlo,monospace;background:#fafafa;padding:40px 20px;">
+<div style="max-width:480px;margin:0 auto;background:#fff;border:2px solid #000;box-shadow:4px 4px 0 0 #000;padding:24px;">
+<h2 style="margin-top:0;">{{t "alert.heading" .ID}}</h2>
+{{if .Domain}}<p>{{t "alert.domain"}}: <b>{{.Domain}}</b><br>{{t "alert.status"}}: {{.Status}}</p>{{end}}
+{{if .Result}}<p>{{.Result}}</p>{{else}}<form method="post">
+<input type="hidden" name="id" value="{{.ID}}"><input type="hidden" name="token" value="{{.Token}}">
+<button type="submit" style="padding:8px 16px;border:2px solid #000;background:#000;color:#fff;font-weight:700;cursor:pointer;">{{.Action}}</button>
+</form>{{end}}
+</div></body></html>`))
+
+// handleAlertLink 通知中的签名链接（无需登录）
+// GET/POST /alerts/ack?id=1&token=...
+// GET/POST /alerts/snooze?id=1&token=...（暂停时长固定，签名不覆盖其他参数）
+func (s *Server) handleAlertLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, s.text(r, "error.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.generalLimiter.Allow(r.RemoteAddr) {
+		http.Error(w, s.text(r, "error.rate_limited"), http.StatusTooManyRequests)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, s.text(r, "error.alert_link_invalid"), http.StatusForbidden)
+		return
+	}
+	if err := s.notification.VerifyAlertToken(id, r.FormValue("token"), time.Now()); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrAlertLinkExpired):
+			http.Error(w, s.text(r, "error.alert_link_expired"), http.StatusGone)
+		case errors.Is(err, notification.ErrAlertLinkInvalid):
+			http.Error(w, s.text(r, "error.alert_link_invalid"), http.StatusForbidden)
+		default:
+			http.Error(w, s.text(r, "error.operation_failed", err.Error()), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	alert, err := storage.GetAlert(id)
+	if err != nil {
+		http.Error(w, s.text(r, "error.operation_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if alert == nil {
+		http.Error(w, s.text(r, "error.alert_not_found"), http.StatusNotFound)
+		return
+	}
+
+	locale := s.locale(r)
+
+	data := map[string]interface{}{
+		"ID":     id,
+		"Token":  r.FormValue("token"),
+		"Domain": alert.Domain,
+		"Status": i18n.T(locale, "status."+alert.Status),
+	}
+
+	snooze := strings.TrimSuffix(r.URL.Path, "/") == "/alerts/snooze"
+	minutes := notification.AlertLinkSnoozeMinutes
+	if snooze {
+		data["Action"] = i18n.T(locale, "alert.snooze", minutes)
+	} else {
+		data["Action"] = i18n.T(locale, "alert.ack")
+	}
+
+	if alert.AckedAt != nil || alert.ResolvedAt != nil {
+		data["Result"] = i18n.T(locale, "msg.alert_handled")
+	} else if r.Method == http.MethodPost {
+		if snooze {
+			until, err := snoozeAlert(id, minutes)
+			if err != nil {
+				status, code, args := alertErrorCode(err)
+				http.Error(w, i18n.T(locale, "error."+code, args...), status)
+				return
+			}
+			data["Result"] = i18n.T(locale, "msg.alert_snoozed", until.Format("2006-01-02 15:04"))
+		} else {
+			if err := storage.AckAlert(id, "link"); err != nil {
+				status, code, args := alertErrorCode(err)
+				http.Error(w, i18n.T(locale, "error."+code, args...), status)
+				return
+			}
+			logger.Info("告警 #%d 已通过链接确认", id)
+			data["Result"] = i18n.T(locale, "msg.alert_acked")
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(alertLinkPage.Clone()).Funcs(template.FuncMap{
+		"t": func(key string, args ...interface{}) string { return i18n.T(locale, key, args...) },
+	})
+	if err := tmpl.Execute(w, data); err != nil {
+		logger.Error("渲染告警页面失败: %v", err)
+	}
+}
+
+// handleEscalationPolicies 重复提醒策略列表与新增
+// GET  /api/escalations
+// POST /api/escalations {"domain": "example.com", "status": "available", "interval_minutes": 15, "max_repeats": 0}
+func (s *Server) handleEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		policies, err := storage.ListEscalationPolicies()
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "escalation_list_failed", err.Error())
+			return
+		}
+		if policies == nil {
+			policies = []storage.EscalationPolicy{}
+		}
+		s.writeJSON(w, map[string]interface{}{"policies": policies})
+	case http.MethodPost:
+		var policy storage.EscalationPolicy
+		if !s.decodeEscalationPolicy(w, r, &policy) {
+			return
+		}
+		if err := storage.CreateEscalationPolicy(&policy); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadEscalationPolicies()
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.escalation_added"),
+			"id":      policy.ID,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleEscalationPolicy 单条提醒策略的更新与删除
+// PUT    /api/escalations/{id}
+// DELETE /api/escalations/{id}
+func (s *Server) handleEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/escalations/"), "/"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "escalation_id_invalid")
+		return
+	}
+
+	existing, err := storage.GetEscalationPolicy(id)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+	if existing == nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "escalation_not_found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		policy := storage.EscalationPolicy{ID: id}
+		if !s.decodeEscalationPolicy(w, r, &policy) {
+			return
+		}
+		if err := storage.UpdateEscalationPolicy(&policy); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadEscalationPolicies()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.escalation_updated"),
+		})
+	case http.MethodDelete:
+		if err := storage.DeleteEscalationPolicy(id); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadEscalationPolicies()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.escalation_deleted"),
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// decodeEscalationPolicy 解析并校验提醒策略请求体
+func (s *Server) decodeEscalationPolicy(w http.ResponseWriter, r *http.Request, policy *storage.EscalationPolicy) bool {
+	var req struct {
+		Domain          string `json:"domain"`
+		Status          string `json:"status"`
+		IntervalMinutes int    `json:"interval_minutes"`
+		MaxRepeats      int    `json:"max_repeats"`
+		Enabled         *bool  `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return false
+	}
+
+	policy.Domain = strings.ToLower(strings.TrimSpace(req.Domain))
+	if policy.Domain == "" {
+		policy.Domain = storage.GlobalScope
+	}
+	policy.Status = strings.TrimSpace(req.Status)
+	policy.IntervalMinutes = req.IntervalMinutes
+	policy.MaxRepeats = req.MaxRepeats
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+
+	if _, ok := core.GetAllStatusInfo()[core.DomainStatus(policy.Status)]; !ok {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "status_invalid", policy.Status)
+		return false
+	}
+	if policy.IntervalMinutes < 1 || policy.IntervalMinutes > 24*60 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "escalation_interval_out_of_range")
+		return false
+	}
+	if policy.MaxRepeats < 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "escalation_repeats_negative")
+		return false
+	}
+	return true
+}
+
+// reloadEscalationPolicies 策略变更后重新加载到通知管理器
+func (s *Server) reloadEscalationPolicies() {
+	if err := s.notification.LoadEscalationPolicies(); err != nil {
+		logger.Error("重新加载重复提醒策略失败: %v", err)
+	}
+}
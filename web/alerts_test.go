@@ -0,0 +1,74 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/i18n"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+func TestHandleAlertLinkToken(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	s.generalLimiter = NewRateLimiter(100, time.Minute)
+
+	expired := s.notification.AlertToken(5, time.Now().Add(-time.Minute))
+	valid := s.notification.AlertToken(5, time.Now().Add(time.Hour))
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		key    string
+	}{
+		{"已过期", "id=5&token=" + url.QueryEscape(expired), http.StatusGone, "error.alert_link_expired"},
+		{"其他告警的签名", "id=6&token=" + url.QueryEscape(valid), http.StatusForbidden, "error.alert_link_invalid"},
+		{"旧版无过期时间的签名", "id=5&token=" + strings.Repeat("ab", 32), http.StatusForbidden, "error.alert_link_invalid"},
+		{"无效 ID", "id=x&token=" + url.QueryEscape(valid), http.StatusForbidden, "error.alert_link_invalid"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/alerts/ack?"+tt.query, nil)
+		r.AddCookie(&http.Cookie{Name: localeCookie, Value: "en"})
+		w := httptest.NewRecorder()
+		s.handleAlertLink(w, r)
+		if w.Code != tt.status || strings.TrimSpace(w.Body.String()) != i18n.T(i18n.En, tt.key) {
+			t.Errorf("%s: %d %q，期望 %d %q", tt.name, w.Code, w.Body.String(), tt.status, i18n.T(i18n.En, tt.key))
+		}
+	}
+}
+
+func TestHandleAlertLinkSnoozeIgnoresMinutes(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	s.generalLimiter = NewRateLimiter(100, time.Minute)
+	alert, err := storage.OpenAlert("link-snooze.com", "available", "registered", 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.RemoveDomain("link-snooze.com") })
+
+	// 链接中的 minutes 不在签名内，应忽略并使用固定时长
+	token := s.notification.AlertToken(alert.ID, time.Now().Add(time.Hour))
+	form := url.Values{"id": {strconv.FormatInt(alert.ID, 10)}, "token": {token}, "minutes": {"10080"}}
+	r := httptest.NewRequest(http.MethodPost, "/alerts/snooze", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	s.handleAlertLink(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("暂停失败: %d %s", w.Code, w.Body.String())
+	}
+
+	got, err := storage.GetAlert(alert.ID)
+	if err != nil || got == nil || got.SnoozedUntil == nil {
+		t.Fatalf("告警应已暂停: %+v %v", got, err)
+	}
+	if until := time.Until(*got.SnoozedUntil); until > time.Duration(notification.AlertLinkSnoozeMinutes+1)*time.Minute {
+		t.Errorf("暂停时长应固定为 %d 分钟，实际剩余 %v", notification.AlertLinkSnoozeMinutes, until)
+	}
+}
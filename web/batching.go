@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// batchingSettings 通知合并设置
+type batchingSettings struct {
+	AggregateWindow  int    `json:"aggregate_window"`
+	QuietTimeout     int    `json:"quiet_timeout"`
+	MaxBatchSize     int    `json:"max_batch_size"`
+	CriticalStatuses string `json:"critical_statuses"`
+	ChannelModes     string `json:"channel_modes"`
+}
+
+// handleBatchingSettings 获取或更新通知合并设置
+// GET  /api/notification-batching
+// POST /api/notification-batching {"aggregate_window": 10, "quiet_timeout": 8, "max_batch_size": 50, "critical_statuses": "available", "channel_modes": "bark=immediate,email=hourly"}
+func (s *Server) handleBatchingSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		deferred, err := storage.CountDeferredEvents()
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "batch_count_failed", err.Error())
+			return
+		}
+		cfg := s.config.Notification
+		s.writeJSON(w, map[string]interface{}{
+			"aggregate_window":  cfg.AggregateWindow,
+			"quiet_timeout":     cfg.QuietTimeout,
+			"max_batch_size":    cfg.MaxBatchSize,
+			"critical_statuses": cfg.CriticalStatuses,
+			"channel_modes":     cfg.ChannelModes,
+			"deferred":          deferred,
+		})
+		return
+	case http.MethodPost:
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var req batchingSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	if req.AggregateWindow < 1 || req.AggregateWindow > 3600 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "batch_window_out_of_range")
+		return
+	}
+	if req.QuietTimeout < 0 || req.QuietTimeout > req.AggregateWindow {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "batch_lead_out_of_range")
+		return
+	}
+	if req.MaxBatchSize < 1 || req.MaxBatchSize > 1000 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "batch_limit_out_of_range")
+		return
+	}
+	req.CriticalStatuses = strings.TrimSpace(req.CriticalStatuses)
+	req.ChannelModes = strings.TrimSpace(req.ChannelModes)
+	if _, err := notification.ParseChannelModes(req.ChannelModes); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return
+	}
+
+	if err := storage.UpsertSettings(map[string]string{
+		"notification_aggregate_window":  fmt.Sprintf("%d", req.AggregateWindow),
+		"notification_quiet_timeout":     fmt.Sprintf("%d", req.QuietTimeout),
+		"notification_max_batch_size":    fmt.Sprintf("%d", req.MaxBatchSize),
+		"notification_critical_statuses": req.CriticalStatuses,
+		"notification_channel_modes":     req.ChannelModes,
+	}); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+		return
+	}
+
+	s.config.Notification.AggregateWindow = req.AggregateWindow
+	s.config.Notification.QuietTimeout = req.QuietTimeout
+	s.config.Notification.MaxBatchSize = req.MaxBatchSize
+	s.config.Notification.CriticalStatuses = req.CriticalStatuses
+	s.config.Notification.ChannelModes = req.ChannelModes
+	s.notification.SetAggregation(s.config.Notification)
+	logger.Info("通知合并设置已更新: 窗口=%ds 提前发送=%ds 上限=%d", req.AggregateWindow, req.QuietTimeout, req.MaxBatchSize)
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":   "success",
+		"message":  s.text(r, "msg.batching_saved"),
+		"batching": req,
+	})
+}
@@ -0,0 +1,123 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Puff/core"
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+// calendarTokenKey 日历订阅令牌的设置键
+const calendarTokenKey = "calendar_token"
+
+// getCalendarToken 读取日历订阅令牌，尚未生成时返回空
+func getCalendarToken() (string, error) {
+	token, _, err := storage.GetSetting(calendarTokenKey)
+	return token, err
+}
+
+// rotateCalendarToken 生成新的日历订阅令牌（旧令牌随之失效）
+func rotateCalendarToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("生成日历令牌失败: %w", err)
+	}
+	token := hex.EncodeToString(bytes)
+
+	if err := storage.UpsertSettings(map[string]string{calendarTokenKey: token}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// handleCalendarSettings 获取或重置日历订阅地址
+// GET  /api/calendar（只读，未生成令牌时 token 与 path 为空）
+// POST /api/calendar（生成或重新生成令牌，旧地址失效）
+func (s *Server) handleCalendarSettings(w http.ResponseWriter, r *http.Request) {
+	var token string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		token, err = getCalendarToken()
+	case http.MethodPost:
+		token, err = rotateCalendarToken()
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "calendar_token_failed", err.Error())
+		return
+	}
+
+	path := ""
+	if token != "" {
+		path = "/calendar/" + token + ".ics"
+	}
+	s.writeJSON(w, map[string]interface{}{
+		"token": token,
+		"path":  path,
+	})
+}
+
+// handleCalendarFeed 只读 iCalendar 订阅（令牌鉴权，无需登录）
+// GET /calendar/{token}.ics?tld=com,net&status=registered,grace&lang=en（lang 为空时按请求语言）
+func (s *Server) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, s.text(r, "error.method_not_allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.generalLimiter.Allow(r.RemoteAddr) {
+		http.Error(w, s.text(r, "error.rate_limited"), http.StatusTooManyRequests)
+		return
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/calendar/"), ".ics")
+	expected, ok, err := storage.GetSetting(calendarTokenKey)
+	if err != nil {
+		http.Error(w, s.text(r, "error.internal_error"), http.StatusInternalServerError)
+		return
+	}
+	if !ok || expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	filter := core.CalendarFilter{TLDs: splitQueryList(r.URL.Query().Get("tld"))}
+	for _, status := range splitQueryList(r.URL.Query().Get("status")) {
+		filter.Statuses = append(filter.Statuses, core.DomainStatus(status))
+	}
+
+	results, err := storage.LoadDomainResults()
+	if err != nil {
+		http.Error(w, s.text(r, "error.internal_error"), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="puff.ics"`)
+	locale := i18n.Normalize(r.URL.Query().Get("lang"))
+	if locale == "" {
+		locale = s.locale(r)
+	}
+	w.Write([]byte(core.BuildCalendar(locale, results, filter, time.Now())))
+}
+
+// splitQueryList 解析逗号分隔的查询参数
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
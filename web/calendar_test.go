@@ -0,0 +1,128 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/i18n"
+	"Puff/storage"
+)
+
+func TestHandleCalendarSettings(t *testing.T) {
+	s := testServer()
+	if err := storage.UpsertSettings(map[string]string{calendarTokenKey: ""}); err != nil {
+		t.Fatal(err)
+	}
+	token := func(method string) string {
+		t.Helper()
+		r := httptest.NewRequest(method, "/api/calendar", nil)
+		w := httptest.NewRecorder()
+		s.handleCalendarSettings(w, r)
+		var resp struct{ Token, Path string }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s /api/calendar: %d %s", method, w.Code, w.Body.String())
+		}
+		if (resp.Token == "" && resp.Path != "") || (resp.Token != "" && resp.Path != "/calendar/"+resp.Token+".ics") {
+			t.Fatalf("订阅地址错误: %+v", resp)
+		}
+		return resp.Token
+	}
+
+	// 读取不生成令牌，生成后读取返回同一令牌，重新生成后旧令牌失效
+	if got := token(http.MethodGet); got != "" {
+		t.Fatalf("未生成时应返回空令牌: %s", got)
+	}
+	if got, _ := getCalendarToken(); got != "" {
+		t.Fatalf("读取时不应生成令牌: %s", got)
+	}
+	first := token(http.MethodPost)
+	if first == "" {
+		t.Fatal("POST 应生成令牌")
+	}
+	if again := token(http.MethodGet); again != first {
+		t.Errorf("读取时不应更换令牌: %s %s", first, again)
+	}
+	if rotated := token(http.MethodPost); rotated == first {
+		t.Error("重新生成后令牌应变化")
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/calendar", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	s.handleCalendarSettings(w, r)
+	if w.Header().Get("X-Error-Code") != "method_not_allowed" {
+		t.Errorf("错误码 = %q", w.Header().Get("X-Error-Code"))
+	}
+}
+
+func TestHandleCalendarFeed(t *testing.T) {
+	s := testServer()
+	s.generalLimiter = NewRateLimiter(100, time.Minute)
+	expiry := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, domain := range []string{"cal-feed.com", "cal-feed.net"} {
+		if err := storage.SaveDomainResult(storage.DomainResult{Domain: domain, Status: "registered", ExpiryAt: &expiry, LastChecked: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { storage.RemoveDomain(domain) })
+	}
+	token, err := rotateCalendarToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := func(method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		s.handleCalendarFeed(w, r)
+		return w
+	}
+
+	w := feed(http.MethodGet, "/calendar/"+token+".ics?tld=COM&status=registered,grace&lang=en")
+	body := w.Body.String()
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("订阅响应错误: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "UID:cal-feed.com-expiry@puff") || strings.Contains(body, "cal-feed.net") {
+		t.Errorf("应只包含筛选后缀的域名:\n%s", body)
+	}
+	if !strings.Contains(body, "X-WR-CALNAME:"+i18n.T(i18n.En, "calendar.name")) {
+		t.Errorf("lang 参数应决定日历语言:\n%s", body)
+	}
+
+	// 状态不匹配时不包含该域名
+	if body = feed(http.MethodGet, "/calendar/"+token+".ics?status=grace").Body.String(); strings.Contains(body, "cal-feed.com") {
+		t.Errorf("状态筛选无效:\n%s", body)
+	}
+
+	if w = feed(http.MethodHead, "/calendar/"+token+".ics"); w.Code != http.StatusOK {
+		t.Errorf("HEAD 请求应允许: %d", w.Code)
+	}
+	if w = feed(http.MethodGet, "/calendar/wrong.ics"); w.Code != http.StatusNotFound {
+		t.Errorf("错误令牌应返回 404: %d", w.Code)
+	}
+	if w = feed(http.MethodPost, "/calendar/"+token+".ics"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST 应返回 405: %d", w.Code)
+	}
+
+	// 重新生成后旧地址失效
+	if _, err := rotateCalendarToken(); err != nil {
+		t.Fatal(err)
+	}
+	if w = feed(http.MethodGet, "/calendar/"+token+".ics"); w.Code != http.StatusNotFound {
+		t.Errorf("旧令牌应失效: %d", w.Code)
+	}
+}
+
+func TestSplitQueryList(t *testing.T) {
+	if got := splitQueryList(" COM, ,net,"); !reflect.DeepEqual(got, []string{"com", "net"}) {
+		t.Errorf("splitQueryList = %v", got)
+	}
+	if got := splitQueryList(""); got != nil {
+		t.Errorf("空参数应返回 nil: %v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Puff/storage"
+)
+
+// handleNotificationDeliveries 分页查询通知投递日志
+// GET /api/notifications?domain=example.com&channel=telegram&event_type=status_change&success=false&since=2024-01-01&until=2024-02-01&page=1&limit=20
+func (s *Server) handleNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := storage.DeliveryFilter{
+		Domain:    strings.ToLower(strings.TrimSpace(query.Get("domain"))),
+		Channel:   strings.TrimSpace(query.Get("channel")),
+		EventType: strings.TrimSpace(query.Get("event_type")),
+	}
+
+	if v := strings.TrimSpace(query.Get("success")); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "success_param_invalid")
+			return
+		}
+		filter.Success = &success
+	}
+
+	var err error
+	if filter.Since, err = parseQueryTime(query.Get("since")); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "since_invalid", err.Error())
+		return
+	}
+	if filter.Until, err = parseQueryTime(query.Get("until")); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "until_invalid", err.Error())
+		return
+	}
+
+	page := 1
+	limit := 20
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	deliveries, total, err := storage.ListDeliveries(filter, limit, (page-1)*limit)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "delivery_list_failed", err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []storage.NotificationDelivery{}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"deliveries":  deliveries,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + limit - 1) / limit,
+		"has_next":    page*limit < total,
+		"has_prev":    page > 1,
+	})
+}
+
+// parseQueryTime 解析查询参数中的时间，支持 RFC3339 与 2006-01-02（本地时间），为空时返回零值
+func parseQueryTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Puff/storage"
+)
+
+func TestHandleNotificationDeliveries(t *testing.T) {
+	s := testServer()
+	for _, d := range []storage.NotificationDelivery{
+		{OutboxID: 1, EventType: "status_change", Domains: []string{"a.dlv", "b.dlv"}, Channel: "dlv:1", Subject: "主题", Success: true, Attempt: 1},
+		{OutboxID: 2, EventType: "status_change", Domains: []string{"ba.dlv"}, Channel: "dlv:2", Success: false, Error: "HTTP 502", Attempt: 1},
+		{OutboxID: 2, EventType: "status_change", Domains: []string{"ba.dlv"}, Channel: "dlv:2", Success: true, Attempt: 2},
+		{OutboxID: 3, EventType: "expiry_reminder", Domains: []string{"a.dlv"}, Channel: "dlv", Success: true, Attempt: 1},
+		{OutboxID: 4, EventType: "status_change", Domains: []string{"a.dlv"}, Channel: "dlvx", Success: false, Attempt: 1},
+	} {
+		if err := storage.RecordDelivery(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/notifications?"+query, nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.handleNotificationDeliveries(w, r)
+		return w
+	}
+	type page struct {
+		Deliveries []storage.NotificationDelivery `json:"deliveries"`
+		Total      int                            `json:"total"`
+		TotalPages int                            `json:"total_pages"`
+		HasNext    bool                           `json:"has_next"`
+		HasPrev    bool                           `json:"has_prev"`
+	}
+	list := func(query string) page {
+		t.Helper()
+		w := get(query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("查询 %q 失败: %d %s", query, w.Code, w.Body.String())
+		}
+		var p page
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		query string
+		total int
+	}{
+		{"channel=dlv", 4},   // 类型匹配所有实例，但不匹配 dlvx
+		{"channel=dlv:2", 2}, // 实例标识精确匹配
+		{"domain=A.DLV", 3},  // 域名不区分大小写，且不匹配 ba.dlv
+		{"domain=ba.dlv&success=false", 1},
+		{"channel=dlv&event_type=expiry_reminder", 1},
+		{"domain=a.dlv&since=" + time.Now().Add(time.Hour).Format(time.RFC3339), 0},
+		{"domain=a.dlv&until=" + time.Now().Add(time.Hour).Format(time.RFC3339), 3},
+	}
+	for _, tt := range tests {
+		if got := list(tt.query).Total; got != tt.total {
+			t.Errorf("%s 总数 = %d, 期望 %d", tt.query, got, tt.total)
+		}
+	}
+
+	// 按时间倒序分页
+	p := list("channel=dlv&limit=3&page=1")
+	if len(p.Deliveries) != 3 || p.TotalPages != 2 || !p.HasNext || p.HasPrev {
+		t.Fatalf("第一页错误: %+v", p)
+	}
+	if p.Deliveries[0].OutboxID != 3 || p.Deliveries[1].Attempt != 2 {
+		t.Errorf("应按时间倒序: %+v", p.Deliveries)
+	}
+	p = list("channel=dlv&limit=3&page=2")
+	if len(p.Deliveries) != 1 || p.HasNext || !p.HasPrev {
+		t.Errorf("第二页错误: %+v", p)
+	}
+	if d := p.Deliveries[0]; d.Subject != "主题" || len(d.Domains) != 2 || d.Domains[1] != "b.dlv" {
+		t.Errorf("记录内容错误: %+v", d)
+	}
+	if p := list("channel=nothing"); p.Deliveries == nil || len(p.Deliveries) != 0 {
+		t.Errorf("无记录时应返回空数组: %+v", p)
+	}
+
+	for query, code := range map[string]string{
+		"success=maybe":    "success_param_invalid",
+		"since=yesterday":  "since_invalid",
+		"until=2024-13-01": "until_invalid",
+	} {
+		if got := get(query).Header().Get("X-Error-Code"); got != code {
+			t.Errorf("%s 错误码 = %q, 期望 %q", query, got, code)
+		}
+	}
+}
+
+func TestParseQueryTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024/01/02", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseQueryTime(tt.value)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseQueryTime(%q) = %v, %v, 期望 %v", tt.value, got, err, tt.want)
+		}
+	}
+}
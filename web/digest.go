@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// handleDigestSettings 获取或更新定期摘要设置
+// GET  /api/digest
+// POST /api/digest {"enabled": true, "frequency": "weekly", "hour": 9, "weekday": 1, "expiry_days": 30, "targets": "email,telegram:2"}
+func (s *Server) handleDigestSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.config.Digest)
+		return
+	case http.MethodPost:
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var req config.DigestConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	req.Targets = strings.TrimSpace(req.Targets)
+	if req.Frequency != "daily" && req.Frequency != "weekly" {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "digest_frequency_invalid")
+		return
+	}
+	if req.Hour < 0 || req.Hour > 23 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "digest_hour_out_of_range")
+		return
+	}
+	if req.Weekday < 0 || req.Weekday > 6 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "digest_weekday_out_of_range")
+		return
+	}
+	if req.ExpiryDays < 1 || req.ExpiryDays > 365 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "digest_expiry_out_of_range")
+		return
+	}
+
+	if err := storage.UpsertSettings(map[string]string{
+		"digest_enabled":     fmt.Sprintf("%t", req.Enabled),
+		"digest_frequency":   req.Frequency,
+		"digest_hour":        fmt.Sprintf("%d", req.Hour),
+		"digest_weekday":     fmt.Sprintf("%d", req.Weekday),
+		"digest_expiry_days": fmt.Sprintf("%d", req.ExpiryDays),
+		"digest_targets":     req.Targets,
+	}); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+		return
+	}
+
+	s.config.Digest = req
+	s.notification.SetDigestConfig(req)
+	logger.Info("定期摘要设置已更新: 启用=%t 频率=%s", req.Enabled, req.Frequency)
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.digest_saved"),
+		"digest":  req,
+	})
+}
+
+// handleDigestPreview 预览最近一个周期的摘要
+// GET /api/digest/preview?format=json|text|html
+func (s *Server) handleDigestPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	digest, err := s.notification.PreviewDigest(time.Now())
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "digest_build_failed", err.Error())
+		return
+	}
+
+	locale := s.locale(r)
+	digest.Localize(locale)
+	switch r.URL.Query().Get("format") {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(digest.Text(locale)))
+	case "html":
+		html, err := digest.HTML(locale)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(html))
+	default:
+		s.writeJSON(w, map[string]interface{}{
+			"subject": digest.Subject(locale),
+			"text":    digest.Text(locale),
+			"digest":  digest,
+		})
+	}
+}
+
+// handleDigestSend 立即发送最近一个周期的摘要
+// POST /api/digest/send
+func (s *Server) handleDigestSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	now := time.Now()
+	if err := s.notification.SendDigest(now.Add(-notification.DigestPeriod(s.config.Digest.Frequency)), now); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "digest_send_failed", err.Error())
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.digest_queued"),
+	})
+}
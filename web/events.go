@@ -0,0 +1,223 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Puff/config"
+	"Puff/eventsink"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// SetEventSink 设置事件流导出（用于记录管理操作与更新导出设置）
+func (s *Server) SetEventSink(sink *eventsink.Sink) {
+	s.events = sink
+}
+
+// statusRecorder 记录处理器写出的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// audited 记录会修改数据的请求（GET、HEAD、OPTIONS 除外）为管理操作事件
+func (s *Server) audited(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.events.Enabled() || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			handler(w, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		handler(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		s.auditEvent(r, s.config.Server.Username, r.Method+" "+r.URL.Path, rec.status)
+	}
+}
+
+// auditEvent 发布一条 Web 管理操作事件；状态码 >= 400 视为失败
+func (s *Server) auditEvent(r *http.Request, actor, action string, status int) {
+	result := "success"
+	if status >= http.StatusBadRequest {
+		result = "failure"
+	}
+	s.events.Publish(eventsink.Event{
+		Type:       eventsink.TypeAdminAction,
+		Source:     "web",
+		Actor:      actor,
+		RemoteAddr: r.RemoteAddr,
+		Action:     action,
+		Result:     result,
+		StatusCode: status,
+	})
+}
+
+// eventsSettings 事件流导出设置
+type eventsSettings struct {
+	SyslogEnabled    bool   `json:"syslog_enabled"`
+	SyslogNetwork    string `json:"syslog_network"`
+	SyslogAddress    string `json:"syslog_address"`
+	SyslogFacility   string `json:"syslog_facility"`
+	SyslogAppName    string `json:"syslog_app_name"`
+	SyslogSkipVerify bool   `json:"syslog_skip_verify"`
+	SyslogCACert     string `json:"syslog_ca_cert"`
+	FileEnabled      bool   `json:"file_enabled"`
+	FilePath         string `json:"file_path"`
+	FileMaxSize      int    `json:"file_max_size"`
+	FileMaxBackups   int    `json:"file_max_backups"`
+}
+
+// handleEventsSettings 获取或更新事件流导出设置
+// GET  /api/events
+// POST /api/events {"syslog_enabled": true, "syslog_network": "tls", "syslog_address": "siem.example.com:6514", "file_enabled": true, "file_path": "data/events.jsonl", "file_max_size": 100, "file_max_backups": 5}
+func (s *Server) handleEventsSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.config.Events)
+		return
+	case http.MethodPost:
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var req eventsSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return
+	}
+	cfg := config.EventsConfig{
+		SyslogEnabled:    req.SyslogEnabled,
+		SyslogNetwork:    strings.ToLower(strings.TrimSpace(req.SyslogNetwork)),
+		SyslogAddress:    strings.TrimSpace(req.SyslogAddress),
+		SyslogFacility:   strings.ToLower(strings.TrimSpace(req.SyslogFacility)),
+		SyslogAppName:    strings.TrimSpace(req.SyslogAppName),
+		SyslogSkipVerify: req.SyslogSkipVerify,
+		SyslogCACert:     strings.TrimSpace(req.SyslogCACert),
+		FileEnabled:      req.FileEnabled,
+		FilePath:         strings.TrimSpace(req.FilePath),
+		FileMaxSize:      req.FileMaxSize,
+		FileMaxBackups:   req.FileMaxBackups,
+	}
+	if cfg.SyslogNetwork == "" {
+		cfg.SyslogNetwork = s.config.Events.SyslogNetwork
+	}
+	if cfg.SyslogFacility == "" {
+		cfg.SyslogFacility = s.config.Events.SyslogFacility
+	}
+	if cfg.SyslogAppName == "" {
+		cfg.SyslogAppName = s.config.Events.SyslogAppName
+	}
+	if cfg.FilePath == "" {
+		cfg.FilePath = s.config.Events.FilePath
+	}
+	if cfg.FileMaxSize == 0 {
+		cfg.FileMaxSize = s.config.Events.FileMaxSize
+	}
+	if cfg.FileMaxBackups == 0 {
+		cfg.FileMaxBackups = s.config.Events.FileMaxBackups
+	}
+	if cfg.FileMaxSize < 1 || cfg.FileMaxSize > 10240 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "events_file_size_out_of_range")
+		return
+	}
+	if cfg.FileMaxBackups < 1 || cfg.FileMaxBackups > 100 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "events_file_backups_out_of_range")
+		return
+	}
+	if err := eventsink.Validate(cfg); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return
+	}
+
+	if err := storage.UpsertSettings(map[string]string{
+		"events_syslog_enabled":     fmt.Sprintf("%t", cfg.SyslogEnabled),
+		"events_syslog_network":     cfg.SyslogNetwork,
+		"events_syslog_address":     cfg.SyslogAddress,
+		"events_syslog_facility":    cfg.SyslogFacility,
+		"events_syslog_app_name":    cfg.SyslogAppName,
+		"events_syslog_skip_verify": fmt.Sprintf("%t", cfg.SyslogSkipVerify),
+		"events_syslog_ca_cert":     cfg.SyslogCACert,
+		"events_file_enabled":       fmt.Sprintf("%t", cfg.FileEnabled),
+		"events_file_path":          cfg.FilePath,
+		"events_file_max_size":      fmt.Sprintf("%d", cfg.FileMaxSize),
+		"events_file_max_backups":   fmt.Sprintf("%d", cfg.FileMaxBackups),
+	}); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+		return
+	}
+
+	s.config.Events = cfg
+	if err := s.events.Configure(cfg); err != nil {
+		logger.Warn("应用事件流导出设置失败: %v", err)
+	}
+	logger.Info("事件流导出设置已更新: syslog=%t(%s %s) 文件=%t(%s)",
+		cfg.SyslogEnabled, cfg.SyslogNetwork, cfg.SyslogAddress, cfg.FileEnabled, cfg.FilePath)
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.events_saved"),
+		"events":  cfg,
+	})
+}
+
+// handleEventsTest 向已启用的各输出写入一条测试事件
+// POST /api/events/test
+func (s *Server) handleEventsTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+	if !s.events.Enabled() {
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "error",
+			"message": s.text(r, "msg.events_disabled"),
+		})
+		return
+	}
+
+	results := map[string]string{}
+	failed := false
+	for name, err := range s.events.Test() {
+		if err != nil {
+			results[name] = s.text(r, "msg.send_failed", err.Error())
+			failed = true
+		} else {
+			results[name] = s.text(r, "msg.send_ok")
+		}
+	}
+	status := "success"
+	if failed {
+		status = "error"
+	}
+	s.writeJSON(w, map[string]interface{}{
+		"status":  status,
+		"message": s.text(r, "msg.events_test_done"),
+		"results": results,
+	})
+}
@@ -3,10 +3,12 @@ package web
 import (
 	"Puff/config"
 	"Puff/core"
+	"Puff/i18n"
 	"Puff/logger"
 	"Puff/notification"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"log"
 	"net/http"
 	"strconv"
@@ -42,7 +44,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	case "POST":
 		s.processLogin(w, r)
 	default:
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 	}
 }
 
@@ -50,9 +52,9 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 func (s *Server) serveLoginPage(w http.ResponseWriter, r *http.Request) {
 	loginHTML := `
 <!DOCTYPE html>
-<html lang="zh-CN" data-theme="lofi">
+<html lang="{{.Lang}}" data-theme="lofi">
 <head>
-    <title>Puff - 登录</title>
+    <title>Puff - {{.Title}}</title>
 	<link rel="icon" href="data:image/svg+xml;base64,PHN2ZyB0PSIxNzY1OTA3NjEzNzgzIiBjbGFzcz0iaWNvbiIgdmlld0JveD0iMCAwIDE0NDggMTAyNCIgdmVyc2lvbj0iMS4xIiB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHAtaWQ9IjYzMDIiIHdpZHRoPSIxMjgiIGhlaWdodD0iMTI4Ij48cGF0aCBkPSJNMTAzNi45MDEwNTggNzc5LjU2Nzk0OWMzNS40MzQ5MDctMTIuOTAxMDU4IDYzLjQ3MzIwNy00MC43NjczNDQgNzYuMDMwMjM3LTc2LjM3NDI2NSAxMi4wNDA5ODgtMzMuODg2NzggMTguNzQ5NTM4LTcwLjE4MTc1NyAxOC43NDk1MzgtMTA3LjY4MDgzMyAwLTE5Mi42NTU4MDQtMTcxLjQ5ODA2OC0zNDguNjcyNjAyLTM4Mi45MDM0MS0zNDguNjcyNjAycy0zODIuOTAzNDEgMTU2LjE4ODgxMi0zODIuOTAzNDEgMzQ4LjY3MjYwMmMwIDMzLjg4Njc4IDUuMzMyNDM3IDY2Ljc0MTQ3NSAxNS4zMDkyNTYgOTcuODc2MDI5IDExLjY5Njk2IDM2LjQ2Njk5MSA0MC4wNzkyODggNjUuMzY1MzYyIDc2LjIwMjI1MSA3OC4yNjY0MiAxMzQuMzQzMDIgNDcuOTkxOTM3IDM0OC4zMjg1NzQgOTEuMzM5NDkzIDU3OS41MTU1MzggNy45MTI2NDl6IiBmaWxsPSIjRkJERUIyIiBwLWlkPSI2MzAzIj48L3BhdGg+PHBhdGggZD0iTTk2NC44MjcxNDYgNzYwLjk5MDQyNWMyNi42NjIxODctOS42MzI3OSA0Ny42NDc5MDktMzAuNjE4NTEyIDU3LjEwODY4NS01Ny4yODA2OTkgOS4xMTY3NDgtMjUuNDU4MDg4IDEzLjkzMzE0My01Mi42MzYzMTggMTMuOTMzMTQzLTgwLjg0NjYzMiAwLTE0NC40OTE4NTMtMTI4LjQ5NDU0MS0yNjEuNjMzNDYyLTI4Ny4yNjM1NjUtMjYxLjYzMzQ2MnMtMjg3LjI2MzU2NSAxMTcuMTQxNjA5LTI4Ny4yNjM1NjUgMjYxLjYzMzQ2MmMwIDI1LjQ1ODA4OCAzLjk1NjMyNSA1MC4wNTYxMDYgMTEuNTI0OTQ2IDczLjI3ODAxMSA4Ljc3MjcyIDI3LjM1MDI0NCAzMC4xMDI0NjkgNDkuMDI0MDIyIDU3LjEwODY4NSA1OC42NTY4MTIgMTAwLjk3MjI4MyAzNi4yOTQ5NzcgMjYxLjQ2MTQ0OCA2OC44MDU2NDQgNDM0Ljg1MTY3MSA2LjE5MjUwOHoiIGZpbGw9IiNGREZCRTQiIHAtaWQ9IjYzMDQiPjwvcGF0aD48cGF0aCBkPSJNOTY0LjgyNzE0NiA3NjAuOTkwNDI1YzE1Ljk5NzMxMi01Ljg0ODQ4IDMwLjI3NDQ4My0xNS45OTczMTIgNDAuOTM5MzU4LTI5LjI0MjM5OSAxMS4wMDg5MDMtMTMuMDczMDcyIDE3LjAyOTM5Ny0yOS40MTQ0MTMgMjEuMzI5NzUtNDUuNzU1NzUzIDQuNDcyMzY3LTE2LjM0MTM0MSA3LjA1MjU3OS0zMy4xOTg3MjMgNy45MTI2NDktNTAuMjI4MTIgMC4xNzIwMTQtNC4zMDAzNTMgMC4zNDQwMjgtOC40Mjg2OTEgMC4zNDQwMjgtMTIuNzI5MDQ1bC0wLjY4ODA1Ni0xMi43MjkwNDQtMC42ODgwNTctMTIuNzI5MDQ0Yy0wLjM0NDAyOC00LjMwMDM1My0xLjM3NjExMy04LjQyODY5MS0xLjg5MjE1NS0xMi41NTcwMy0xMC42NjQ4NzUtNjcuMjU3NTE3LTUxLjk0ODI2MS0xMjcuMTE4NDI4LTEwNi45OTI3NzctMTY1LjY0OTU4OS0yNy41MjIyNTgtMTkuNDM3NTk0LTU4LjMxMjc4My0zNC4wNTg3OTQtOTAuODIzNDUtNDMuMDAzNTI3LTMyLjUxMDY2Ny04Ljk0NDczNC02Ni4yMjU0MzMtMTIuMDQwOTg4LTk5Ljc2ODE4NC0xMC40OTI4NjEtNjYuOTEzNDg5IDIuOTI0MjQtMTMzLjEzODkyMiAyOC4yMTAzMTQtMTgyLjY3ODk4NSA3My4xMDU5OTctMjQuOTQyMDQ2IDIyLjE4OTgyLTQ1LjkyNzc2OCA0OC44NTIwMDctNjAuNzIwOTgxIDc4Ljc4MjQ2My0xNC43OTMyMTQgMjkuOTMwNDU1LTIzLjM5MzkxOSA2Mi43ODUxNS0yNC41OTgwMTggOTYuMTU1ODg3LTEuMjA0MDk5IDE2LjY4NTM2OSAwLjE3MjAxNCAzMy4zNzA3MzcgMi43NTIyMjYgNDkuODg0MDkzIDEuMzc2MTEzIDguMjU2Njc3IDMuMjY4MjY4IDE2LjM0MTM0MSA1LjUwNDQ1MSAyNC40MjYwMDMgMi4yMzYxODMgOC4yNTY2NzcgNC45ODg0MDkgMTUuNjUzMjg0IDguOTQ0NzM0IDIyLjg3Nzg3NyA3LjkxMjY0OSAxNC4yNzcxNzEgMTkuOTUzNjM3IDI2LjMxODE1OSAzNC4yMzA4MDggMzQuNDAyODIyIDcuMDUyNTc5IDQuMTI4MzM5IDE0Ljk2NTIyOCA2Ljg4MDU2NCAyMy4wNDk4OTEgOS42MzI3OSA3LjkxMjY0OSAyLjkyNDI0IDE1Ljk5NzMxMiA1LjUwNDQ1MiAyNC4wODE5NzUgOC4wODQ2NjMgNjQuNTA1MjkxIDIwLjI5NzY2NSAxMzIuMjc4ODUxIDMxLjgyMjYxIDIwMC4wNTI0MTEgMzEuNjUwNTk3IDMzLjg4Njc4LTAuMTcyMDE0IDY3Ljk0NTU3NC0yLjc1MjIyNiAxMDEuMzE2MzExLTguNjAwNzA2IDMzLjM3MDczNy01LjUwNDQ1MiA2Ni4yMjU0MzMtMTQuMTA1MTU3IDk4LjM5MjA3MS0yNS4yODYwNzR6IG0wIDBjLTMxLjk5NDYyNSAxMS4zNTI5MzEtNjQuODQ5MzIgMjAuMjk3NjY1LTk4LjM5MjA3MSAyNi40OTAxNzMtMzMuNTQyNzUyIDYuMDIwNDk0LTY3LjQyOTUzMSA4Ljk0NDczNC0xMDEuNDg4MzI1IDkuMjg4NzYyLTY4LjExNzU4OCAxLjAzMjA4NS0xMzYuMjM1MTc2LTkuMTE2NzQ4LTIwMS42MDA1MzgtMjguMzgyMzI4LTguMjU2Njc3LTIuNDA4MTk4LTE2LjM0MTM0MS00LjgxNjM5NS0yNC40MjYwMDQtNy41Njg2MjEtOC4wODQ2NjMtMi41ODAyMTItMTYuMzQxMzQxLTUuMzMyNDM3LTI0LjA4MTk3NS05LjYzMjc5LTE1LjMwOTI1Ni04LjQyODY5MS0yOC41NTQzNDItMjAuOTg1NzIxLTM3LjMyNzA2Mi0zNi4yOTQ5NzgtNC40NzIzNjctNy41Njg2MjEtNy43NDA2MzUtMTUuOTk3MzEyLTkuOTc2ODE5LTI0LjI1Mzk4OS0yLjU4MDIxMi04LjI1NjY3Ny00LjQ3MjM2Ny0xNi42ODUzNjktNi4xOTI1MDgtMjUuMTE0MDYtMi45MjQyNC0xNy4wMjkzOTctNC44MTYzOTUtMzQuNDAyODIyLTMuNjEyMjk2LTUxLjYwNDIzNCAxLjAzMjA4NS0zNC41NzQ4MzYgOS42MzI3OS02OC44MDU2NDQgMjQuNzcwMDMyLTk5Ljk0MDE5OHMzNi42MzkwMDYtNTkuMDAwODQgNjIuNDQxMTIyLTgyLjA1MDczYzEwNC4wNjg1MzctOTMuMjMxNjQ4IDI3Mi4xMjYzMjMtOTkuMjUyMTQyIDM4Mi43MzEzOTYtMTYuMzQxMzQxIDI3LjY5NDI3MiAyMC4yOTc2NjUgNTEuNjA0MjMzIDQ1LjQxMTcyNSA2OS44Mzc3MjkgNzQuMzEwMDk2IDE4LjIzMzQ5NiAyOC44OTgzNzEgMzAuNjE4NTEyIDYxLjQwOTAzNyAzNS43Nzg5MzUgOTUuMTIzODAzIDAuNjg4MDU2IDQuMTI4MzM5IDEuNTQ4MTI3IDguNDI4NjkxIDEuODkyMTU1IDEyLjU1NzAzbDAuNjg4MDU3IDEyLjcyOTA0NCAwLjY4ODA1NiAxMi43MjkwNDRjMCA0LjMwMDM1My0wLjE3MjAxNCA4LjYwMDcwNi0wLjM0NDAyOCAxMi43MjkwNDUtMS4wMzIwODUgMTcuMDI5Mzk3LTMuNzg0MzEgMzMuODg2NzgtOC4yNTY2NzcgNTAuMjI4MTItNC40NzIzNjcgMTYuMzQxMzQxLTEwLjQ5Mjg2MSAzMi42ODI2ODEtMjEuNjczNzc4IDQ1Ljc1NTc1My0xMS4wMDg5MDMgMTMuNDE3MTAxLTI1LjQ1ODA4OCAyMy41NjU5MzMtNDEuNDU1NDAxIDI5LjI0MjM5OXoiIGZpbGw9IiNGOUQ1OTIiIHAtaWQ9IjYzMDUiPjwvcGF0aD48cGF0aCBkPSJNNzM2LjIyMDM5MyA4MTcuMjM5MDM5Yy0xMjMuMzM0MTE3IDAtMjMyLjIxOTA0OS0yNi44MzQyMDEtMzE0Ljk1NzgzNi01Ni40MjA2MjgtNDEuOTcxNDQzLTE0Ljk2NTIyOC03NC45OTgxNTItNDkuMDI0MDIyLTg4LjQxNTI1My05MC44MjM0NS0xMC44MzY4ODktMzMuNzE0NzY2LTE2LjM0MTM0MS02OC42MzM2My0xNi4zNDEzNDEtMTAzLjg5NjUyMyAwLTIwMy4zMjA2NzkgMTgwLjYxNDgxNi0zNjguNjI2MjM5IDQwMi42ODUwMzMtMzY4LjYyNjIzOSAxOS45NTM2MzcgMCAzOS45MDcyNzQgMS4zNzYxMTMgNTkuMzQ0ODY4IDMuOTU2MzI0IDEwLjgzNjg4OSAxLjU0ODEyNyAxOC41Nzc1MjQgMTEuNTI0OTQ1IDE3LjAyOTM5NyAyMi4zNjE4MzUtMS41NDgxMjcgMTAuODM2ODg5LTExLjUyNDk0NSAxOC40MDU1MS0yMi4zNjE4MzQgMTcuMDI5Mzk3LTE3LjcxNzQ1My0yLjQwODE5OC0zNS45NTA5NDktMy42MTIyOTYtNTQuMDEyNDMxLTMuNjEyMjk3LTIwMC4yMjQ0MjUgMC0zNjIuOTQ5NzczIDE0Ny41ODgxMDctMzYyLjk0OTc3MyAzMjguODkwOTggMCAzMS4xMzQ1NTQgNC44MTYzOTUgNjEuOTI1MDggMTQuNDQ5MTg1IDkxLjY4MzUyMSA5LjYzMjc5IDMwLjI3NDQ4MyAzMy41NDI3NTIgNTQuNzAwNDg3IDYzLjk4OTI0OSA2NS41MzczNzYgMTM0LjY4NzA0OSA0OC4zMzU5NjUgMzQyLjMwODA4IDg4LjkzMTI5NSA1NjUuOTI2NDI0IDguMDg0NjYzIDMwLjEwMjQ2OS0xMC44MzY4ODkgNTMuNDk2Mzg4LTM0LjIzMDgwOCA2NC4xNjEyNjMtNjQuMzMzMjc3IDExLjY5Njk2LTMyLjUxMDY2NyAxNy41NDU0MzktNjYuNTY5NDYxIDE3LjU0NTQzOS0xMDEuMTQ0Mjk3IDAtODguOTMxMjk1LTM4LjUzMTE2MS0xNzIuMzU4MTM5LTEwOC43MTI5MTctMjM0LjYyNzI0Ny04LjI1NjY3Ny03LjIyNDU5My04Ljk0NDczNC0xOS43ODE2MjMtMS43MjAxNDEtMjguMDM4MyA3LjIyNDU5My04LjI1NjY3NyAxOS43ODE2MjMtOC45NDQ3MzQgMjguMDM4My0xLjcyMDE0MSA3OC43ODI0NjMgNzAuMDA5NzQzIDEyMS45NTgwMDQgMTYzLjkyOTQ0NyAxMjEuOTU4MDA0IDI2NC4zODU2ODggMCAzOS4wNDcyMDMtNi43MDg1NSA3Ny41NzgzNjQtMTkuNzgxNjIzIDExNC4zODkzODMtMTQuNzkzMjE0IDQxLjI4MzM4Ny00Ni43ODc4MzggNzMuNDUwMDI1LTg4LjA3MTIyNCA4OC40MTUyNTMtOTYuODQzOTQ0IDM1LjA5MDg3OS0xOTAuOTM1NjYzIDQ4LjUwNzk3OS0yNzcuODAyNzg5IDQ4LjUwNzk3OXoiIGZpbGw9IiIgcC1pZD0iNjMwNiI+PC9wYXRoPjxwYXRoIGQ9Ik04OTYuNzA5NTU4IDI3Ni43NzA3MDRjLTIuOTI0MjQgMC01Ljg0ODQ4LTAuNjg4MDU2LTguNjAwNzA1LTEuODkyMTU1LTEzLjU4OTExNS02LjUzNjUzNi0yNy44NjYyODYtMTIuMjEzMDAyLTQyLjMxNTQ3Mi0xNy4yMDE0MTEtMTAuMzIwODQ3LTMuNDQwMjgyLTE1Ljk5NzMxMi0xNC43OTMyMTQtMTIuNTU3MDMtMjUuMTE0MDYxIDMuNDQwMjgyLTEwLjQ5Mjg2MSAxNC43OTMyMTQtMTUuOTk3MzEyIDI1LjExNDA2MS0xMi41NTcwMyAxNS44MjUyOTggNS4zMzI0MzcgMzEuNjUwNTk2IDExLjY5Njk2IDQ2LjYxNTgyNCAxOC45MjE1NTMgOS45NzY4MTggNC42NDQzODEgMTQuMTA1MTU3IDE2LjUxMzM1NSA5LjQ2MDc3NiAyNi40OTAxNzMtMy4yNjgyNjggNy4wNTI1NzktMTAuMzIwODQ3IDExLjM1MjkzMS0xNy43MTc0NTQgMTEuMzUyOTMxeiIgZmlsbD0iIiBwLWlkPSI2MzA3Ij48L3BhdGg+PC9zdmc+" type="image/x-icon">
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -67,14 +69,14 @@ func (s *Server) serveLoginPage(w http.ResponseWriter, r *http.Request) {
             <form id="loginForm" method="POST" class="space-y-4">
                 <div class="form-control w-full">
                     <label class="label">
-                        <span class="label-text">用户名</span>
+                        <span class="label-text">{{.Username}}</span>
                     </label>
                     <input type="text" name="username" class="input input-bordered w-full" required />
                 </div>
                 
                 <div class="form-control w-full">
                     <label class="label">
-                        <span class="label-text">密码</span>
+                        <span class="label-text">{{.Password}}</span>
                     </label>
                     <input type="password" name="password" class="input input-bordered w-full" required />
                 </div>
@@ -82,7 +84,7 @@ func (s *Server) serveLoginPage(w http.ResponseWriter, r *http.Request) {
                 <div class="form-control w-full mt-6">
                     <button type="submit" class="btn btn-primary" id="loginBtn">
                         <span class="loading loading-spinner loading-sm hidden" id="loginSpinner"></span>
-                        登录
+                        {{.Submit}}
                     </button>
                 </div>
                 
@@ -123,11 +125,11 @@ func (s *Server) serveLoginPage(w http.ResponseWriter, r *http.Request) {
                 } else {
                     // 登录失败，显示错误信息
                     const result = await response.json();
-                    errorMessage.textContent = result.error || '登录失败';
+                    errorMessage.textContent = result.error || {{.Failed}};
                     errorAlert.classList.remove('hidden');
                 }
             } catch (error) {
-                errorMessage.textContent = '网络错误，请重试';
+                errorMessage.textContent = {{.NetworkError}};
                 errorAlert.classList.remove('hidden');
             } finally {
                 // 恢复按钮状态
@@ -139,8 +141,22 @@ func (s *Server) serveLoginPage(w http.ResponseWriter, r *http.Request) {
 </body>
 </html>`
 
+	locale := s.locale(r)
+	tmpl, err := template.New("login").Parse(loginHTML)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(loginHTML))
+	tmpl.Execute(w, map[string]string{
+		"Lang":         locale,
+		"Title":        i18n.T(locale, "login.title"),
+		"Username":     i18n.T(locale, "login.username"),
+		"Password":     i18n.T(locale, "login.password"),
+		"Submit":       i18n.T(locale, "login.submit"),
+		"Failed":       i18n.T(locale, "login.failed"),
+		"NetworkError": i18n.T(locale, "login.network_error"),
+	})
 }
 
 // processLogin 处理登录
@@ -149,9 +165,11 @@ func (s *Server) processLogin(w http.ResponseWriter, r *http.Request) {
 	clientIP := r.RemoteAddr
 	if !s.loginLimiter.Allow(clientIP) {
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Error-Code", "login_rate_limited")
 		w.WriteHeader(http.StatusTooManyRequests)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "登录尝试过于频繁，请5分钟后再试",
+			"code":  "login_rate_limited",
+			"error": s.text(r, "error.login_rate_limited"),
 		})
 		return
 	}
@@ -161,15 +179,20 @@ func (s *Server) processLogin(w http.ResponseWriter, r *http.Request) {
 
 	session, err := s.auth.Login(username, password)
 	if err != nil {
+		s.auditEvent(r, username, "login", http.StatusUnauthorized)
 		// 返回JSON错误响应
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Error-Code", "login_failed")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "用户名或密码错误",
+			"code":  "login_failed",
+			"error": s.text(r, "error.login_failed"),
 		})
 		return
 	}
 
+	s.auditEvent(r, username, "login", http.StatusOK)
+
 	// 设置会话Cookie
 	cookie := &http.Cookie{
 		Name:     "session_id",
@@ -209,6 +232,7 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	// 获取会话ID
 	if cookie, err := r.Cookie("session_id"); err == nil {
 		s.auth.Logout(cookie.Value)
+		s.auditEvent(r, s.config.Server.Username, "logout", http.StatusOK)
 	}
 
 	// 清除Cookie
@@ -262,7 +286,7 @@ func (s *Server) handleDomains(w http.ResponseWriter, r *http.Request) {
 		// 从数据库获取域名列表（按添加时间排序）
 		domainEntries, err := storage.ListDomains(false)
 		if err != nil {
-			s.writeError(w, "获取域名列表失败: "+err.Error(), http.StatusInternalServerError)
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_list_failed", err.Error())
 			return
 		}
 
@@ -300,6 +324,7 @@ func (s *Server) handleDomains(w http.ResponseWriter, r *http.Request) {
 					ExpiryDate:   result.ExpiryAt,
 					UpdatedDate:  result.UpdatedAt,
 					NameServers:  result.NameServers,
+					StatusCodes:  result.StatusCodes,
 					WhoisRaw:     result.WhoisRaw,
 					ErrorMessage: result.ErrorMessage,
 					AddedAt:      &entry.CreatedAt,
@@ -378,7 +403,7 @@ func (s *Server) handleDomains(w http.ResponseWriter, r *http.Request) {
 
 		s.writeJSON(w, response)
 	default:
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 	}
 }
 
@@ -389,13 +414,19 @@ func (s *Server) handleDomainDetail(w http.ResponseWriter, r *http.Request) {
 	domain := strings.TrimSuffix(path, "/")
 
 	if domain == "" {
-		s.writeError(w, "域名不能为空", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
+		return
+	}
+
+	// 子资源：/api/domain/{name}/history、/api/domain/{name}/snapshots...
+	if name, sub, ok := strings.Cut(domain, "/"); ok {
+		s.handleDomainSubresource(w, r, name, sub)
 		return
 	}
 
 	info, err := s.monitor.GetDomainInfo(domain)
 	if err != nil {
-		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_info_failed", err.Error())
 		return
 	}
 
@@ -405,7 +436,7 @@ func (s *Server) handleDomainDetail(w http.ResponseWriter, r *http.Request) {
 // handleDomainCheck 域名检查处理器
 func (s *Server) handleDomainCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -414,13 +445,13 @@ func (s *Server) handleDomainCheck(w http.ResponseWriter, r *http.Request) {
 	domain := strings.TrimSuffix(path, "/")
 
 	if domain == "" {
-		s.writeError(w, "域名不能为空", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
 		return
 	}
 
 	info, err := s.monitor.ForceCheck(domain)
 	if err != nil {
-		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_query_failed", err.Error())
 		return
 	}
 
@@ -430,7 +461,7 @@ func (s *Server) handleDomainCheck(w http.ResponseWriter, r *http.Request) {
 // handleStats 统计信息处理器
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -451,12 +482,12 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 // handleMonitorStart 启动监控处理器
 func (s *Server) handleMonitorStart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
 	if err := s.monitor.Start(); err != nil {
-		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "monitor_start_failed", err.Error())
 		return
 	}
 
@@ -466,7 +497,7 @@ func (s *Server) handleMonitorStart(w http.ResponseWriter, r *http.Request) {
 // handleMonitorStop 停止监控处理器
 func (s *Server) handleMonitorStop(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -477,126 +508,78 @@ func (s *Server) handleMonitorStop(w http.ResponseWriter, r *http.Request) {
 // handleMonitorReload 重新加载监控处理器
 func (s *Server) handleMonitorReload(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
 	// 重新加载域名列表
 	if err := s.monitor.LoadDomains(); err != nil {
-		s.writeError(w, fmt.Sprintf("重新加载域名列表失败: %v", err), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "monitor_reload_failed", err.Error())
 		return
 	}
 
 	s.writeJSON(w, map[string]string{"status": "reloaded"})
 }
 
-// handleNotificationTest 通知测试处理器
+// handleNotificationTest 通知测试处理器（测试所有已启用的通知器）
 func (s *Server) handleNotificationTest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
-	// 构建详细的响应信息
-	emailStatus := "未配置"
-	telegramStatus := "未配置"
-	hasAnyEnabled := false
+	results := make(map[string]string)
+	failed := 0
 
-	// 只测试已启用的通知器
-	if s.config.SMTP.Enabled {
-		hasAnyEnabled = true
-		// 找到邮件通知器并测试
-		var emailNotifier Notifier
-		for _, notifier := range s.notification.GetNotifiers() {
-			if notifier.GetType() == "email" && notifier.IsEnabled() {
-				emailNotifier = notifier
-				break
-			}
-		}
-
-		if emailNotifier != nil {
-			if err := emailNotifier.Test(); err != nil {
-				emailStatus = fmt.Sprintf("发送失败: %v", err)
-				logger.Error("邮件测试通知发送失败: %v", err)
-			} else {
-				emailStatus = "发送成功"
-				logger.Info("邮件测试通知发送成功")
-			}
-		} else {
-			emailStatus = "通知器未初始化"
-		}
-	} else {
-		emailStatus = "未启用"
-	}
-
-	if s.config.Telegram.Enabled {
-		hasAnyEnabled = true
-		// 找到Telegram通知器并测试
-		var telegramNotifier Notifier
-		for _, notifier := range s.notification.GetNotifiers() {
-			if notifier.GetType() == "telegram" && notifier.IsEnabled() {
-				telegramNotifier = notifier
-				break
-			}
+	for _, notifier := range s.notification.GetNotifiers() {
+		if !notifier.IsEnabled() {
+			continue
 		}
-
-		if telegramNotifier != nil {
-			if err := telegramNotifier.Test(); err != nil {
-				telegramStatus = fmt.Sprintf("发送失败: %v", err)
-				logger.Error("Telegram测试通知发送失败: %v", err)
-			} else {
-				telegramStatus = "发送成功"
-				logger.Info("Telegram测试通知发送成功")
-			}
+		label := notifierDisplayName(s.locale(r), notifier)
+		if _, err := s.notification.TestNotifier(notifier); err != nil {
+			results[label] = s.text(r, "msg.send_failed", err.Error())
+			failed++
+			logger.Error("%s测试通知发送失败: %v", label, err)
 		} else {
-			telegramStatus = "通知器未初始化"
+			results[label] = s.text(r, "msg.send_ok")
+			logger.Info("%s测试通知发送成功", label)
 		}
-	} else {
-		telegramStatus = "未启用"
 	}
 
 	// 如果没有启用任何通知方式
-	if !hasAnyEnabled {
+	if len(results) == 0 {
 		s.writeJSON(w, map[string]interface{}{
-			"status":          "warning",
-			"message":         "未启用任何通知方式，请先在设置中配置并启用邮件或Telegram通知",
-			"email_status":    emailStatus,
-			"telegram_status": telegramStatus,
-			"timestamp":       time.Now(),
+			"status":    "warning",
+			"message":   s.text(r, "msg.no_notifiers"),
+			"results":   results,
+			"timestamp": time.Now(),
 		})
 		return
 	}
 
 	// 判断整体状态
 	overallStatus := "success"
-	message := "测试通知发送完成"
-
-	emailFailed := s.config.SMTP.Enabled && emailStatus != "发送成功"
-	telegramFailed := s.config.Telegram.Enabled && telegramStatus != "发送成功"
-
-	if emailFailed && telegramFailed {
+	message := s.text(r, "msg.test_done")
+	if failed == len(results) {
 		overallStatus = "error"
-		message = "所有通知方式发送失败"
-	} else if emailFailed || telegramFailed {
+		message = s.text(r, "msg.test_all_failed")
+	} else if failed > 0 {
 		overallStatus = "warning"
-		message = "部分通知方式发送失败"
+		message = s.text(r, "msg.test_partial_failed")
 	}
 
-	response := map[string]interface{}{
-		"status":          overallStatus,
-		"message":         message,
-		"email_status":    emailStatus,
-		"telegram_status": telegramStatus,
-		"timestamp":       time.Now(),
-	}
-
-	s.writeJSON(w, response)
+	s.writeJSON(w, map[string]interface{}{
+		"status":    overallStatus,
+		"message":   message,
+		"results":   results,
+		"timestamp": time.Now(),
+	})
 }
 
 // handleDomainAdd 添加单个域名
 func (s *Server) handleDomainAdd(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -605,7 +588,7 @@ func (s *Server) handleDomainAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		s.writeError(w, "Invalid JSON", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
@@ -613,64 +596,46 @@ func (s *Server) handleDomainAdd(w http.ResponseWriter, r *http.Request) {
 	domain = strings.ToLower(domain)
 
 	if domain == "" {
-		s.writeError(w, "域名不能为空", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
 		return
 	}
 
 	// 验证域名格式
 	if err := s.monitor.GetChecker().ValidateDomain(domain); err != nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "域名格式无效: " + err.Error(),
-		})
+		s.writeJSON(w, s.apiError(r, "domain_invalid", err.Error()))
 		return
 	}
 
 	// 检查后缀是否支持
 	tld := config.FindBestTLD(domain)
 	if tld == "" {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "该后缀目前不支持进行监控",
-		})
+		s.writeJSON(w, s.apiError(r, "tld_unsupported"))
 		return
 	}
 
 	// 检查域名是否已存在
 	existingDomains, err := storage.ListDomains(false)
 	if err != nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "检查域名失败: " + err.Error(),
-		})
+		s.writeJSON(w, s.apiError(r, "domain_check_failed", err.Error()))
 		return
 	}
 
 	for _, existing := range existingDomains {
 		if strings.EqualFold(existing.Name, domain) {
-			s.writeJSON(w, map[string]interface{}{
-				"status":  "error",
-				"message": "域名已存在",
-			})
+			s.writeJSON(w, s.apiError(r, "domain_exists"))
 			return
 		}
 	}
 
 	// 添加到监控列表
 	if err := s.addDomainToConfig(domain); err != nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "添加域名失败: " + err.Error(),
-		})
+		s.writeJSON(w, s.apiError(r, "domain_add_failed", err.Error()))
 		return
 	}
 
 	// 重新加载域名列表
 	if err := s.monitor.LoadDomains(); err != nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "重新加载域名失败: " + err.Error(),
-		})
+		s.writeJSON(w, s.apiError(r, "domain_reload_failed", err.Error()))
 		return
 	}
 
@@ -701,7 +666,7 @@ func (s *Server) handleDomainAdd(w http.ResponseWriter, r *http.Request) {
 	// 返回占位信息，让前端能立即显示
 	s.writeJSON(w, map[string]interface{}{
 		"status":  "success",
-		"message": "Domain added successfully",
+		"message": s.text(r, "msg.domain_added"),
 		"domain":  domain,
 		"info":    placeholderInfo,
 	})
@@ -710,14 +675,14 @@ func (s *Server) handleDomainAdd(w http.ResponseWriter, r *http.Request) {
 // handleDomainBatchAdd 批量添加域名
 func (s *Server) handleDomainBatchAdd(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
 	// 速率限制检查
 	clientIP := r.RemoteAddr
 	if !s.batchLimiter.Allow(clientIP) {
-		s.writeError(w, "批量添加过于频繁，请1分钟后再试", http.StatusTooManyRequests)
+		s.writeErrorCode(w, r, http.StatusTooManyRequests, "batch_rate_limited")
 		return
 	}
 
@@ -726,18 +691,18 @@ func (s *Server) handleDomainBatchAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		s.writeError(w, "Invalid JSON", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
 	if len(request.Domains) == 0 {
-		s.writeError(w, "No domains provided", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "batch_empty")
 		return
 	}
 
 	// 限制批量添加数量
 	if len(request.Domains) > 1000 {
-		s.writeError(w, "一次最多添加1000个域名", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "batch_too_many", 1000)
 		return
 	}
 
@@ -748,7 +713,7 @@ func (s *Server) handleDomainBatchAdd(w http.ResponseWriter, r *http.Request) {
 	// 获取已有域名列表
 	existingDomainList, err := storage.ListDomains(false)
 	if err != nil {
-		s.writeError(w, "获取已有域名列表失败: "+err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_list_failed", err.Error())
 		return
 	}
 
@@ -834,7 +799,7 @@ func (s *Server) handleDomainBatchAdd(w http.ResponseWriter, r *http.Request) {
 // handleDomainRemove 删除域名
 func (s *Server) handleDomainRemove(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -843,13 +808,13 @@ func (s *Server) handleDomainRemove(w http.ResponseWriter, r *http.Request) {
 	domain := strings.TrimSuffix(path, "/")
 
 	if domain == "" {
-		s.writeError(w, "域名不能为空", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
 		return
 	}
 
 	// 从配置中删除域名（包括domain_results）
 	if err := s.removeDomainFromConfig(domain); err != nil {
-		s.writeError(w, "删除域名失败: "+err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_remove_failed", err.Error())
 		return
 	}
 
@@ -859,7 +824,7 @@ func (s *Server) handleDomainRemove(w http.ResponseWriter, r *http.Request) {
 
 	s.writeJSON(w, map[string]string{
 		"status":  "success",
-		"message": "Domain removed successfully",
+		"message": s.text(r, "msg.domain_removed"),
 		"domain":  domain,
 	})
 }
@@ -868,7 +833,7 @@ func (s *Server) handleDomainRemove(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	// 检查请求方法
 	if r.Method != http.MethodPost {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -879,7 +844,7 @@ func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !s.auth.IsValidSession(sessionToken) {
-		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		s.writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -890,26 +855,26 @@ func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
 	// 验证当前密码
 	if !s.auth.ValidatePassword(req.CurrentPassword) {
-		s.writeError(w, "当前密码错误", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "password_incorrect")
 		return
 	}
 
 	// 验证新密码
 	if len(req.NewPassword) < 6 {
-		s.writeError(w, "新密码长度至少6位", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "password_too_short", 6)
 		return
 	}
 
 	// 更新密码
 	if err := s.auth.UpdatePassword(req.NewPassword); err != nil {
 		log.Printf("更新密码失败: %v", err)
-		s.writeError(w, "更新密码失败", http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "password_update_failed")
 		return
 	}
 
@@ -926,7 +891,7 @@ func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	// 返回成功响应
 	s.writeJSON(w, map[string]string{
 		"status":  "success",
-		"message": "密码修改成功",
+		"message": s.text(r, "msg.password_changed"),
 	})
 }
 
@@ -934,7 +899,7 @@ func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleUpdateUsername(w http.ResponseWriter, r *http.Request) {
 	// 检查请求方法
 	if r.Method != http.MethodPost {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -945,7 +910,7 @@ func (s *Server) handleUpdateUsername(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !s.auth.IsValidSession(sessionToken) {
-		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		s.writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -955,20 +920,20 @@ func (s *Server) handleUpdateUsername(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
 	// 验证用户名
 	if len(req.Username) < 3 {
-		s.writeError(w, "用户名长度至少3位", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "username_too_short", 3)
 		return
 	}
 
 	// 更新用户名
 	if err := s.auth.UpdateUsername(req.Username); err != nil {
 		log.Printf("更新用户名失败: %v", err)
-		s.writeError(w, "更新用户名失败", http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "username_update_failed")
 		return
 	}
 
@@ -985,254 +950,130 @@ func (s *Server) handleUpdateUsername(w http.ResponseWriter, r *http.Request) {
 	// 返回成功响应
 	s.writeJSON(w, map[string]string{
 		"status":  "success",
-		"message": "用户名更新成功",
+		"message": s.text(r, "msg.username_updated"),
 	})
 }
 
-// handleSmtpSettings 处理SMTP设置
-func (s *Server) handleSmtpSettings(w http.ResponseWriter, r *http.Request) {
+// handleWebhookSettings 处理Webhook设置
+func (s *Server) handleWebhookSettings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
 	var req struct {
-		Host     string `json:"host"`
-		Port     int    `json:"port"`
-		User     string `json:"user"`
-		Password string `json:"password"`
-		From     string `json:"from"`
-		To       string `json:"to"`
-		Enabled  bool   `json:"enabled"`
+		URL        string `json:"url"`
+		Secret     string `json:"secret"`
+		Headers    string `json:"headers"`
+		Template   string `json:"template"`
+		MaxRetries *int   `json:"max_retries"`
+		Enabled    bool   `json:"enabled"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
-	// 将设置保存到数据库
-	if err := storage.UpsertSettings(map[string]string{
-		"smtp_host":    req.Host,
-		"smtp_port":    fmt.Sprintf("%d", req.Port),
-		"smtp_user":    req.User,
-		"smtp_pass":    req.Password,
-		"smtp_from":    req.From,
-		"smtp_to":      req.To,
-		"smtp_enabled": fmt.Sprintf("%t", req.Enabled),
-	}); err != nil {
-		log.Printf("保存SMTP设置到数据库失败: %v", err)
-		s.writeError(w, "保存设置失败: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// 更新当前配置
-	s.config.SMTP.Host = req.Host
-	s.config.SMTP.Port = req.Port
-	s.config.SMTP.User = req.User
-	s.config.SMTP.Password = req.Password
-	s.config.SMTP.From = req.From
-	s.config.SMTP.To = req.To
-	s.config.SMTP.Enabled = req.Enabled
-
-	// 更新通知器配置
-	if err := s.notification.UpdateEmailConfig(s.config.SMTP); err != nil {
-		logger.Error("更新邮件通知器配置失败: %v", err)
-	} else {
-		logger.Info("已更新邮件通知器配置，启用状态: %v", req.Enabled)
-	}
-
-	s.writeJSON(w, map[string]string{
-		"status":  "success",
-		"message": "SMTP设置保存成功",
-	})
-}
-
-// handleTelegramSettings 处理Telegram设置
-func (s *Server) handleTelegramSettings(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost && r.Method != http.MethodPut {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
-		return
+	cfg := config.WebhookConfig{
+		URL:        strings.TrimSpace(req.URL),
+		Secret:     req.Secret,
+		Headers:    req.Headers,
+		Template:   req.Template,
+		MaxRetries: s.config.Webhook.MaxRetries,
+		Enabled:    req.Enabled,
 	}
-
-	var req struct {
-		BotToken string `json:"bot_token"`
-		ChatID   string `json:"chat_id"`
-		Enabled  bool   `json:"enabled"`
+	if req.MaxRetries != nil {
+		if *req.MaxRetries < 0 || *req.MaxRetries > 10 {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "retries_out_of_range")
+			return
+		}
+		cfg.MaxRetries = *req.MaxRetries
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, "Invalid request body", http.StatusBadRequest)
-		return
+	// 启用时校验地址、请求头与模板
+	if cfg.Enabled {
+		if err := notification.ValidateWebhookConfig(cfg); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
 	}
 
 	// 将设置保存到数据库
 	if err := storage.UpsertSettings(map[string]string{
-		"telegram_bot_token": req.BotToken,
-		"telegram_chat_id":   req.ChatID,
-		"telegram_enabled":   fmt.Sprintf("%t", req.Enabled),
+		"webhook_url":         cfg.URL,
+		"webhook_secret":      cfg.Secret,
+		"webhook_headers":     cfg.Headers,
+		"webhook_template":    cfg.Template,
+		"webhook_max_retries": fmt.Sprintf("%d", cfg.MaxRetries),
+		"webhook_enabled":     fmt.Sprintf("%t", cfg.Enabled),
 	}); err != nil {
-		log.Printf("保存Telegram设置到数据库失败: %v", err)
-		s.writeError(w, "保存设置失败: "+err.Error(), http.StatusInternalServerError)
+		log.Printf("保存Webhook设置到数据库失败: %v", err)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
 		return
 	}
 
 	// 更新当前配置
-	s.config.Telegram.BotToken = req.BotToken
-	s.config.Telegram.ChatID = req.ChatID
-	s.config.Telegram.Enabled = req.Enabled
+	s.config.Webhook = cfg
 
 	// 更新通知器配置
-	if err := s.notification.UpdateTelegramConfig(s.config.Telegram); err != nil {
-		logger.Error("更新Telegram通知器配置失败: %v", err)
+	if err := s.notification.UpdateWebhookConfig(s.config.Webhook); err != nil {
+		logger.Error("更新Webhook通知器配置失败: %v", err)
 	} else {
-		logger.Info("已更新Telegram通知器配置，启用状态: %v", req.Enabled)
+		logger.Info("已更新Webhook通知器配置，启用状态: %v", req.Enabled)
 	}
 
 	s.writeJSON(w, map[string]string{
 		"status":  "success",
-		"message": "Telegram设置保存成功",
-	})
-}
-
-// handleTestEmail 测试邮件发送
-func (s *Server) handleTestEmail(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "不允许的请求方法",
-		})
-		return
-	}
-
-	// 检查会话
-	sessionToken := ""
-	if cookie, err := r.Cookie("session_id"); err == nil {
-		sessionToken = cookie.Value
-	}
-
-	if !s.auth.IsValidSession(sessionToken) {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "未授权",
-		})
-		return
-	}
-
-	// 检查是否启用
-	if !s.config.SMTP.Enabled {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "邮件通知未启用，请先在设置中启用并配置SMTP",
-		})
-		return
-	}
-
-	// 直接测试邮件通知器
-	var emailNotifier Notifier
-	for _, notifier := range s.notification.GetNotifiers() {
-		if notifier.GetType() == "email" {
-			emailNotifier = notifier
-			break
-		}
-	}
-
-	if emailNotifier == nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "未找到邮件通知器",
-		})
-		return
-	}
-
-	// 执行测试
-	if err := emailNotifier.Test(); err != nil {
-		logger.Error("测试邮件发送失败: %v", err)
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": fmt.Sprintf("测试邮件发送失败: %v", err),
-		})
-		return
-	}
-
-	logger.Info("测试邮件发送成功")
-	s.writeJSON(w, map[string]interface{}{
-		"status":  "success",
-		"message": "测试邮件发送成功，请检查您的邮箱",
+		"message": s.text(r, "msg.webhook_saved"),
 	})
 }
 
-// handleTestTelegram 测试Telegram发送
-func (s *Server) handleTestTelegram(w http.ResponseWriter, r *http.Request) {
+// handleTestWebhook 测试Webhook发送
+func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "不允许的请求方法",
-		})
-		return
-	}
-
-	// 检查会话
-	sessionToken := ""
-	if cookie, err := r.Cookie("session_id"); err == nil {
-		sessionToken = cookie.Value
-	}
-
-	if !s.auth.IsValidSession(sessionToken) {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "未授权",
-		})
+		s.writeJSON(w, s.apiError(r, "method_not_allowed"))
 		return
 	}
 
 	// 检查是否启用
-	if !s.config.Telegram.Enabled {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "Telegram通知未启用，请先在设置中启用并配置Telegram Bot",
-		})
+	if !s.config.Webhook.Enabled {
+		s.writeJSON(w, s.apiError(r, "webhook_disabled"))
 		return
 	}
 
-	// 直接测试Telegram通知器
-	var telegramNotifier Notifier
+	// 直接测试Webhook通知器
+	var webhookNotifier Notifier
 	for _, notifier := range s.notification.GetNotifiers() {
-		if notifier.GetType() == "telegram" {
-			telegramNotifier = notifier
+		if notifier.GetType() == "webhook" {
+			webhookNotifier = notifier
 			break
 		}
 	}
 
-	if telegramNotifier == nil {
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": "未找到Telegram通知器",
-		})
+	if webhookNotifier == nil {
+		s.writeJSON(w, s.apiError(r, "webhook_not_found"))
 		return
 	}
 
 	// 执行测试
-	if err := telegramNotifier.Test(); err != nil {
-		logger.Error("测试Telegram发送失败: %v", err)
-		s.writeJSON(w, map[string]interface{}{
-			"status":  "error",
-			"message": fmt.Sprintf("测试Telegram发送失败: %v", err),
-		})
+	if err := webhookNotifier.Test(); err != nil {
+		logger.Error("测试Webhook发送失败: %v", err)
+		s.writeJSON(w, s.apiError(r, "webhook_test_failed", err.Error()))
 		return
 	}
 
-	logger.Info("测试Telegram发送成功")
+	logger.Info("测试Webhook发送成功")
 	s.writeJSON(w, map[string]interface{}{
 		"status":  "success",
-		"message": "测试Telegram消息发送成功，请检查您的Telegram",
+		"message": s.text(r, "msg.webhook_test_ok"),
 	})
 }
 
 // handleGetSettings 获取当前设置
 func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -1243,30 +1084,34 @@ func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !s.auth.IsValidSession(sessionToken) {
-		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		s.writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	// 返回当前设置（包含所有配置信息）
 	settings := map[string]interface{}{
-		"smtp": map[string]interface{}{
-			"host":     s.config.SMTP.Host,
-			"port":     s.config.SMTP.Port,
-			"user":     s.config.SMTP.User,
-			"password": s.config.SMTP.Password, // 返回密码
-			"from":     s.config.SMTP.From,
-			"to":       s.config.SMTP.To,
-			"enabled":  s.config.SMTP.Enabled,
-		},
-		"telegram": map[string]interface{}{
-			"bot_token": s.config.Telegram.BotToken, // 返回bot token
-			"chat_id":   s.config.Telegram.ChatID,
-			"enabled":   s.config.Telegram.Enabled,
+		"webhook": map[string]interface{}{
+			"url":         s.config.Webhook.URL,
+			"secret":      s.config.Webhook.Secret,
+			"headers":     s.config.Webhook.Headers,
+			"template":    s.config.Webhook.Template,
+			"max_retries": s.config.Webhook.MaxRetries,
+			"enabled":     s.config.Webhook.Enabled,
 		},
+		"dingtalk":   s.robotSettings(s.config.DingTalk),
+		"feishu":     s.robotSettings(s.config.Feishu),
+		"wecom":      s.robotSettings(s.config.WeCom),
+		"bark":       s.pushSettings(s.config.Bark),
+		"ntfy":       s.pushSettings(s.config.Ntfy),
+		"gotify":     s.pushSettings(s.config.Gotify),
+		"serverchan": s.pushSettings(s.config.ServerChan),
 		"monitor": map[string]interface{}{
-			"check_interval":   int(s.config.Monitor.CheckInterval.Seconds()),
-			"concurrent_limit": s.config.Monitor.ConcurrentLimit,
-			"timeout":          int(s.config.Monitor.Timeout.Seconds()),
+			"check_interval":    int(s.config.Monitor.CheckInterval.Seconds()),
+			"concurrent_limit":  s.config.Monitor.ConcurrentLimit,
+			"timeout":           int(s.config.Monitor.Timeout.Seconds()),
+			"record_all_checks": s.config.Monitor.RecordAllChecks,
+			"max_attempts":      s.config.Notification.MaxAttempts,
+			"public_url":        s.config.Notification.PublicURL,
 		},
 		"username": s.config.Server.Username,
 	}
@@ -1277,43 +1122,68 @@ func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 // handleMonitorSettings 处理监控参数设置
 func (s *Server) handleMonitorSettings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
 	var req struct {
-		CheckInterval   int `json:"check_interval"`   // 检查间隔（秒）
-		ConcurrentLimit int `json:"concurrent_limit"` // 并发限制
-		Timeout         int `json:"timeout"`          // 超时时间（秒）
+		CheckInterval   int     `json:"check_interval"`    // 检查间隔（秒）
+		ConcurrentLimit int     `json:"concurrent_limit"`  // 并发限制
+		Timeout         int     `json:"timeout"`           // 超时时间（秒）
+		RecordAllChecks *bool   `json:"record_all_checks"` // 是否记录每次查询（可选）
+		MaxAttempts     int     `json:"max_attempts"`      // 通知最大投递次数（可选）
+		PublicURL       *string `json:"public_url"`        // 对外访问地址，用于告警确认链接（可选）
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
 		return
 	}
 
 	// 验证参数
 	if req.CheckInterval < 5 {
-		s.writeError(w, "检查间隔不能小于5秒", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "interval_too_small", 5)
 		return
 	}
 	if req.ConcurrentLimit <= 0 || req.ConcurrentLimit > 1000 {
-		s.writeError(w, "并发限制必须在1-1000之间", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "concurrency_out_of_range")
 		return
 	}
 	if req.Timeout <= 0 || req.Timeout > 120 {
-		s.writeError(w, "超时时间必须在1-120秒之间", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "timeout_out_of_range")
 		return
 	}
+	if req.MaxAttempts < 0 || req.MaxAttempts > 50 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "max_attempts_out_of_range")
+		return
+	}
+	if req.PublicURL != nil {
+		url := strings.TrimRight(strings.TrimSpace(*req.PublicURL), "/")
+		if url != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "public_url_invalid")
+			return
+		}
+		req.PublicURL = &url
+	}
 
 	// 将设置保存到数据库
-	if err := storage.UpsertSettings(map[string]string{
+	monitorSettings := map[string]string{
 		"monitor_check_interval":   fmt.Sprintf("%d", req.CheckInterval),
 		"monitor_concurrent_limit": fmt.Sprintf("%d", req.ConcurrentLimit),
 		"monitor_timeout":          fmt.Sprintf("%d", req.Timeout),
-	}); err != nil {
+	}
+	if req.RecordAllChecks != nil {
+		monitorSettings["monitor_record_all_checks"] = fmt.Sprintf("%t", *req.RecordAllChecks)
+	}
+	if req.MaxAttempts > 0 {
+		monitorSettings["notification_max_attempts"] = fmt.Sprintf("%d", req.MaxAttempts)
+	}
+	if req.PublicURL != nil {
+		monitorSettings["notification_public_url"] = *req.PublicURL
+	}
+	if err := storage.UpsertSettings(monitorSettings); err != nil {
 		log.Printf("保存监控设置到数据库失败: %v", err)
-		s.writeError(w, "保存设置失败: "+err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
 		return
 	}
 
@@ -1321,6 +1191,17 @@ func (s *Server) handleMonitorSettings(w http.ResponseWriter, r *http.Request) {
 	s.config.Monitor.CheckInterval = time.Duration(req.CheckInterval) * time.Second
 	s.config.Monitor.ConcurrentLimit = req.ConcurrentLimit
 	s.config.Monitor.Timeout = time.Duration(req.Timeout) * time.Second
+	if req.RecordAllChecks != nil {
+		s.config.Monitor.RecordAllChecks = *req.RecordAllChecks
+	}
+	if req.MaxAttempts > 0 {
+		s.config.Notification.MaxAttempts = req.MaxAttempts
+		s.notification.SetMaxAttempts(req.MaxAttempts)
+	}
+	if req.PublicURL != nil {
+		s.config.Notification.PublicURL = *req.PublicURL
+		s.notification.SetPublicURL(*req.PublicURL)
+	}
 
 	// 热重载：更新checker的配置
 	if s.monitor.GetChecker() != nil {
@@ -1335,14 +1216,14 @@ func (s *Server) handleMonitorSettings(w http.ResponseWriter, r *http.Request) {
 
 	s.writeJSON(w, map[string]string{
 		"status":  "success",
-		"message": "监控参数保存成功并已热重载",
+		"message": s.text(r, "msg.monitor_saved"),
 	})
 }
 
 // handleDomainWhoisRaw 获取域名的原始WHOIS数据
 func (s *Server) handleDomainWhoisRaw(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -1351,7 +1232,7 @@ func (s *Server) handleDomainWhoisRaw(w http.ResponseWriter, r *http.Request) {
 	domain := strings.TrimSuffix(path, "/")
 
 	if domain == "" {
-		s.writeError(w, "域名不能为空", http.StatusBadRequest)
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
 		return
 	}
 
@@ -1360,12 +1241,12 @@ func (s *Server) handleDomainWhoisRaw(w http.ResponseWriter, r *http.Request) {
 	// 从数据库读取WHOIS原始数据
 	result, err := storage.GetDomainResult(domain)
 	if err != nil {
-		s.writeError(w, "获取域名信息失败: "+err.Error(), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "domain_info_failed", err.Error())
 		return
 	}
 
 	if result == nil || result.WhoisRaw == "" {
-		s.writeError(w, "WHOIS data not found, please wait for the query to complete", http.StatusNotFound)
+		s.writeErrorCode(w, r, http.StatusNotFound, "whois_not_found")
 		return
 	}
 
@@ -1408,7 +1289,7 @@ func (s *Server) filterDomains(domains []*core.DomainInfo, searchTerm, statusFil
 // handleCleanOrphanedData 清理孤立数据处理器
 func (s *Server) handleCleanOrphanedData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -1417,20 +1298,20 @@ func (s *Server) handleCleanOrphanedData(w http.ResponseWriter, r *http.Request)
 	// 执行清理
 	if err := storage.CleanOrphanedData(); err != nil {
 		logger.Error("清理孤立数据失败: %v", err)
-		s.writeError(w, fmt.Sprintf("清理失败: %v", err), http.StatusInternalServerError)
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "cleanup_failed", err.Error())
 		return
 	}
 
 	s.writeJSON(w, map[string]interface{}{
 		"status":  "success",
-		"message": "孤立数据清理完成",
+		"message": s.text(r, "msg.cleanup_done"),
 	})
 }
 
 // handleCheckUpdate 检查更新处理器
 func (s *Server) handleCheckUpdate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
-		s.writeError(w, "不允许的请求方法", http.StatusMethodNotAllowed)
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
 		return
 	}
 
@@ -1441,7 +1322,8 @@ func (s *Server) handleCheckUpdate(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.Warn("检查更新失败: %v", err)
 		s.writeJSON(w, map[string]interface{}{
-			"error":          "无法检查更新",
+			"code":           "update_check_failed",
+			"error":          s.text(r, "error.update_check_failed"),
 			"currentVersion": currentVersion,
 		})
 		return
@@ -1452,7 +1334,8 @@ func (s *Server) handleCheckUpdate(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		logger.Warn("解析更新信息失败: %v", err)
 		s.writeJSON(w, map[string]interface{}{
-			"error":          "解析更新信息失败",
+			"code":           "update_parse_failed",
+			"error":          s.text(r, "error.update_parse_failed"),
 			"currentVersion": currentVersion,
 		})
 		return
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// handleHealthSettings 获取或更新查询健康告警设置
+// GET  /api/health-alerts
+// POST /api/health-alerts {"enabled": true, "failure_threshold": 5, "server_window": 360, "server_failure_pct": 80, "server_min_domains": 3, "targets": "telegram:1"}
+func (s *Server) handleHealthSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.config.Health)
+		return
+	case http.MethodPost:
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var req config.HealthConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	req.Targets = strings.TrimSpace(req.Targets)
+	if req.FailureThreshold < 1 || req.FailureThreshold > 100 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "health_failures_out_of_range")
+		return
+	}
+	if req.ServerWindow < 10 || req.ServerWindow > 7*24*60 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "health_window_out_of_range")
+		return
+	}
+	if req.ServerFailurePct < 1 || req.ServerFailurePct > 100 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "health_percent_out_of_range")
+		return
+	}
+	if req.ServerMinDomains < 1 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "health_min_domains_invalid")
+		return
+	}
+
+	if err := storage.UpsertSettings(map[string]string{
+		"health_enabled":            fmt.Sprintf("%t", req.Enabled),
+		"health_failure_threshold":  fmt.Sprintf("%d", req.FailureThreshold),
+		"health_server_window":      fmt.Sprintf("%d", req.ServerWindow),
+		"health_server_failure_pct": fmt.Sprintf("%d", req.ServerFailurePct),
+		"health_server_min_domains": fmt.Sprintf("%d", req.ServerMinDomains),
+		"health_targets":            req.Targets,
+	}); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+		return
+	}
+
+	s.config.Health = req
+	s.notification.SetHealthTargets(req.Targets)
+	logger.Info("健康告警设置已更新: 启用=%t 连续失败阈值=%d", req.Enabled, req.FailureThreshold)
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.health_saved"),
+		"health":  req,
+	})
+}
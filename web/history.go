@@ -0,0 +1,149 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Puff/storage"
+)
+
+// handleDomainSubresource 分发域名子资源请求
+func (s *Server) handleDomainSubresource(w http.ResponseWriter, r *http.Request, domain, sub string) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_required")
+		return
+	}
+
+	switch {
+	case sub == "history":
+		s.handleDomainHistory(w, r, domain)
+	case sub == "owned":
+		s.handleDomainOwned(w, r, domain)
+	case sub == "snapshots":
+		s.handleDomainSnapshots(w, r, domain)
+	case sub == "snapshots/diff":
+		s.handleDomainSnapshotDiff(w, r, domain)
+	case strings.HasPrefix(sub, "snapshots/"):
+		s.handleDomainSnapshot(w, r, domain, strings.TrimPrefix(sub, "snapshots/"))
+	default:
+		s.writeErrorCode(w, r, http.StatusNotFound, "not_found")
+	}
+}
+
+// handleDomainHistory 获取域名状态历史与生命周期时间线
+// GET /api/domain/{name}/history?limit=100&all=true
+func (s *Server) handleDomainHistory(w http.ResponseWriter, r *http.Request, domain string) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+	includeChecks := r.URL.Query().Get("all") == "true"
+
+	timeline, err := s.monitor.GetDomainHistory(domain, limit, includeChecks)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "history_list_failed", err.Error())
+		return
+	}
+
+	s.writeJSON(w, timeline)
+}
+
+// handleDomainSnapshots 列出域名的WHOIS/RDAP快照
+// GET /api/domain/{name}/snapshots
+func (s *Server) handleDomainSnapshots(w http.ResponseWriter, r *http.Request, domain string) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	snapshots, err := storage.ListWhoisSnapshots(domain)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "snapshot_list_failed", err.Error())
+		return
+	}
+	if snapshots == nil {
+		snapshots = []storage.WhoisSnapshot{}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"domain":    domain,
+		"snapshots": snapshots,
+		"total":     len(snapshots),
+	})
+}
+
+// handleDomainSnapshot 获取单个快照的原始内容
+// GET /api/domain/{name}/snapshots/{id}
+func (s *Server) handleDomainSnapshot(w http.ResponseWriter, r *http.Request, domain, idStr string) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSuffix(idStr, "/"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "snapshot_id_invalid")
+		return
+	}
+
+	snapshot, err := storage.GetWhoisSnapshot(domain, id)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "snapshot_load_failed", err.Error())
+		return
+	}
+	if snapshot == nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "snapshot_not_found")
+		return
+	}
+
+	s.writeJSON(w, snapshot)
+}
+
+// handleDomainSnapshotDiff 比较两个快照，返回统一格式差异
+// GET /api/domain/{name}/snapshots/diff?from=1&to=2（缺省时比较最近两个快照）
+func (s *Server) handleDomainSnapshotDiff(w http.ResponseWriter, r *http.Request, domain string) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	parseID := func(key string) (int64, bool) {
+		v := r.URL.Query().Get(key)
+		if v == "" {
+			return 0, true
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		return id, err == nil && id > 0
+	}
+
+	fromID, okFrom := parseID("from")
+	toID, okTo := parseID("to")
+	if !okFrom || !okTo {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "snapshot_id_invalid")
+		return
+	}
+
+	diff, err := s.monitor.GetSnapshotDiff(domain, fromID, toID)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "snapshot_diff_failed", err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == "text" {
+		s.enableCORS(w)
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		w.Write([]byte(diff.Diff))
+		return
+	}
+
+	s.writeJSON(w, diff)
+}
@@ -0,0 +1,190 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// localeCookie 界面语言 Cookie
+const localeCookie = "puff_locale"
+
+// statusErrorCodes HTTP 状态码对应的通用错误码（未指定错误码时使用）
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:       "bad_request",
+	http.StatusUnauthorized:     "unauthorized",
+	http.StatusForbidden:        "forbidden",
+	http.StatusNotFound:         "not_found",
+	http.StatusMethodNotAllowed: "method_not_allowed",
+	http.StatusConflict:         "conflict",
+	http.StatusTooManyRequests:  "rate_limited",
+}
+
+// locale 请求的界面语言：用户选择的语言（Cookie）优先，其次为配置的默认语言与浏览器语言
+func (s *Server) locale(r *http.Request) string {
+	if cookie, err := r.Cookie(localeCookie); err == nil {
+		if locale := i18n.Normalize(cookie.Value); locale != "" {
+			return locale
+		}
+	}
+	return i18n.Resolve(s.config.Server.Locale, i18n.FromAcceptLanguage(r.Header.Get("Accept-Language")))
+}
+
+// wantsJSON 客户端是否要求 JSON 格式的错误响应
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// statusErrorCode HTTP 状态码对应的通用错误码
+func statusErrorCode(status int) string {
+	if code, ok := statusErrorCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return "internal_error"
+	}
+	return "bad_request"
+}
+
+// writeErrorCode 写入带错误码的本地化错误响应；错误码通过 X-Error-Code 头返回，
+// 客户端接受 JSON 时响应体为 {"status":"error","code":...,"message":...}
+func (s *Server) writeErrorCode(w http.ResponseWriter, r *http.Request, status int, code string, args ...interface{}) {
+	message := i18n.T(s.locale(r), "error."+code, args...)
+	w.Header().Set("X-Error-Code", code)
+	if !wantsJSON(r) {
+		s.writeError(w, message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.enableCORS(w)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "error",
+		"code":    code,
+		"message": message,
+	})
+}
+
+// apiError 以 200 状态返回的错误结果（{"status":"error"} 形式的接口）
+func (s *Server) apiError(r *http.Request, code string, args ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"status":  "error",
+		"code":    code,
+		"message": i18n.T(s.locale(r), "error."+code, args...),
+	}
+}
+
+// text 按请求的界面语言返回消息
+func (s *Server) text(r *http.Request, key string, args ...interface{}) string {
+	return i18n.T(s.locale(r), key, args...)
+}
+
+// localeOptions 支持的语言列表
+func localeOptions() []map[string]string {
+	options := make([]map[string]string, 0, len(i18n.Supported()))
+	for _, locale := range i18n.Supported() {
+		options = append(options, map[string]string{"code": locale, "name": i18n.Names[locale]})
+	}
+	return options
+}
+
+// handleI18n 获取或设置当前用户的界面语言（保存在 Cookie 中，不影响其他用户）
+// GET  /api/i18n
+// POST /api/i18n {"locale": "en"}
+func (s *Server) handleI18n(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, map[string]interface{}{
+			"locale":    s.locale(r),
+			"supported": localeOptions(),
+		})
+	case http.MethodPost:
+		var req struct {
+			Locale string `json:"locale"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		locale := i18n.Normalize(req.Locale)
+		if locale == "" {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "locale_unsupported", req.Locale)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     localeCookie,
+			Value:    locale,
+			Path:     "/",
+			MaxAge:   365 * 24 * 3600,
+			SameSite: http.SameSiteLaxMode,
+		})
+		s.writeJSON(w, map[string]interface{}{
+			"status":    "success",
+			"locale":    locale,
+			"supported": localeOptions(),
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleNotificationLocales 获取或更新通知语言
+// GET  /api/notification-locales
+// POST /api/notification-locales {"locale": "zh-CN", "locales": "email=en,telegram:3=en"}
+func (s *Server) handleNotificationLocales(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg := s.config.Notification
+		s.writeJSON(w, map[string]interface{}{
+			"locale":    i18n.Resolve(cfg.Locale),
+			"locales":   cfg.Locales,
+			"supported": localeOptions(),
+		})
+	case http.MethodPost:
+		var req struct {
+			Locale  string `json:"locale"`
+			Locales string `json:"locales"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		locale := i18n.Normalize(req.Locale)
+		if locale == "" {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "locale_unsupported", req.Locale)
+			return
+		}
+		req.Locales = strings.TrimSpace(req.Locales)
+		if _, err := notification.ParseNotifierLocales(req.Locales); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
+
+		if err := storage.UpsertSettings(map[string]string{
+			"notification_locale":  locale,
+			"notification_locales": req.Locales,
+		}); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+			return
+		}
+
+		s.config.Notification.Locale = locale
+		s.config.Notification.Locales = req.Locales
+		s.notification.SetLocales(s.config.Notification)
+		logger.Info("通知语言已更新: 默认=%s 通知器=%s", locale, req.Locales)
+
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.locales_saved"),
+			"locale":  locale,
+			"locales": req.Locales,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Puff/config"
+	"Puff/i18n"
+)
+
+func testServer() *Server {
+	return &Server{config: &config.Config{}}
+}
+
+func TestLocalePrecedence(t *testing.T) {
+	s := testServer()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	if got := s.locale(r); got != i18n.En {
+		t.Errorf("应按浏览器语言选择，实际 %q", got)
+	}
+
+	s.config.Server.Locale = i18n.ZhCN
+	if got := s.locale(r); got != i18n.ZhCN {
+		t.Errorf("配置的默认语言应优先于浏览器语言，实际 %q", got)
+	}
+
+	r.AddCookie(&http.Cookie{Name: localeCookie, Value: "en"})
+	if got := s.locale(r); got != i18n.En {
+		t.Errorf("用户选择的语言应优先，实际 %q", got)
+	}
+}
+
+func TestWriteErrorCode(t *testing.T) {
+	s := testServer()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/domains", nil)
+	r.Header.Set("Accept", "application/json")
+	r.AddCookie(&http.Cookie{Name: localeCookie, Value: "en"})
+	w := httptest.NewRecorder()
+	s.writeErrorCode(w, r, http.StatusNotFound, "notifier_missing", "ops")
+
+	if w.Code != http.StatusNotFound || w.Header().Get("X-Error-Code") != "notifier_missing" {
+		t.Fatalf("状态码或错误码头错误: %d %q", w.Code, w.Header().Get("X-Error-Code"))
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("响应不是 JSON: %v", err)
+	}
+	if body["code"] != "notifier_missing" || body["message"] != "Notification channel ops not found" {
+		t.Errorf("JSON 错误响应错误: %v", body)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/domains", nil)
+	r.Header.Set("Accept-Language", "zh-CN")
+	w = httptest.NewRecorder()
+	s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") || strings.TrimSpace(w.Body.String()) != i18n.T(i18n.ZhCN, "error.invalid_json") {
+		t.Errorf("纯文本错误响应错误: %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestHandleI18nSetsCookieOnly(t *testing.T) {
+	s := testServer()
+	r := httptest.NewRequest(http.MethodPost, "/api/i18n", strings.NewReader(`{"locale":"en-US"}`))
+	w := httptest.NewRecorder()
+	s.handleI18n(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("设置语言失败: %d %s", w.Code, w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != localeCookie || cookies[0].Value != i18n.En {
+		t.Errorf("应通过 Cookie 保存语言: %v", cookies)
+	}
+	if s.config.Server.Locale != "" {
+		t.Errorf("设置语言不应修改全局默认语言: %q", s.config.Server.Locale)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/i18n", strings.NewReader(`{"locale":"fr"}`))
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	s.handleI18n(w, r)
+	if w.Code != http.StatusBadRequest || w.Header().Get("X-Error-Code") != "locale_unsupported" {
+		t.Errorf("不支持的语言应返回 locale_unsupported: %d %q", w.Code, w.Header().Get("X-Error-Code"))
+	}
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"Puff/storage"
+)
+
+// legacyNotifier 旧版单一邮件/Telegram设置接口对应的通知渠道
+type legacyNotifier struct {
+	instanceType string   // 通知渠道类型
+	defaultName  string   // 尚无该类型渠道时新建的渠道名称（与升级迁移一致）
+	fields       []string // 旧版请求体中写入渠道配置的字段
+}
+
+// legacyNotifiers 旧版接口路径中的名称 -> 通知渠道
+var legacyNotifiers = map[string]legacyNotifier{
+	"smtp":     {"email", "默认邮箱", []string{"host", "port", "user", "password", "from", "to"}},
+	"email":    {"email", "默认邮箱", []string{"host", "port", "user", "password", "from", "to"}},
+	"telegram": {"telegram", "默认Telegram", []string{"bot_token", "chat_id"}},
+}
+
+// legacyInstance 返回该类型的第一个通知渠道，不存在时返回 nil
+func legacyInstance(notifierType string) (*storage.NotifierInstance, error) {
+	instances, err := storage.ListNotifierInstances(notifierType)
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+	return &instances[0], nil
+}
+
+// handleLegacyNotifierSettings 旧版邮件/Telegram设置接口，写入该类型的第一个通知渠道（不存在时新建）
+// POST /api/settings/smtp     {"host": "...", "port": 587, "user": "...", "password": "...", "from": "...", "to": "...", "enabled": true}
+// POST /api/settings/telegram {"bot_token": "...", "chat_id": "...", "enabled": true}
+func (s *Server) handleLegacyNotifierSettings(name string) http.HandlerFunc {
+	legacy := legacyNotifiers[name]
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+
+		var req map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+		var enabled bool
+		if raw, ok := req["enabled"]; ok {
+			if err := json.Unmarshal(raw, &enabled); err != nil {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+				return
+			}
+		}
+
+		inst, err := legacyInstance(legacy.instanceType)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		create := inst == nil
+		if create {
+			inst = &storage.NotifierInstance{Type: legacy.instanceType, Name: legacy.defaultName, Config: "{}"}
+		}
+
+		// 只覆盖旧版接口提供的字段，保留在新界面中设置的其他配置（如连接安全、认证方式）
+		cfg := make(map[string]json.RawMessage)
+		if err := json.Unmarshal([]byte(inst.Config), &cfg); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		for _, field := range legacy.fields {
+			if raw, ok := req[field]; ok {
+				cfg[field] = raw
+			}
+		}
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		inst.Config = string(data)
+		inst.Enabled = enabled
+
+		if !s.applyNotifierInstance(w, r, inst, create) {
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.settings_saved", notifierTypeName(s.locale(r), legacy.instanceType)),
+			"id":      inst.ID,
+		})
+	}
+}
+
+// handleLegacyNotifierTest 旧版邮件/Telegram测试接口，测试该类型的第一个通知渠道
+// POST /api/test/email
+// POST /api/test/telegram
+func (s *Server) handleLegacyNotifierTest(name string) http.HandlerFunc {
+	legacy := legacyNotifiers[name]
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+
+		inst, err := legacyInstance(legacy.instanceType)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		if inst == nil {
+			s.writeJSON(w, s.apiError(r, "notifier_missing", notifierTypeName(s.locale(r), legacy.instanceType)))
+			return
+		}
+		s.handleTestNotifierInstance(w, r, inst)
+	}
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Puff/notification"
+	"Puff/storage"
+)
+
+func TestLegacyTelegramSettings(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	post := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/settings/telegram", strings.NewReader(body))
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.handleLegacyNotifierSettings("telegram")(w, r)
+		return w
+	}
+
+	if w := post(`{"bot_token":"123:abc","chat_id":"42","enabled":true}`); w.Code != http.StatusOK {
+		t.Fatalf("保存旧版 Telegram 设置失败: %d %s", w.Code, w.Body.String())
+	}
+	instances, err := storage.ListNotifierInstances("telegram")
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("应新建一个 Telegram 渠道: %v %v", instances, err)
+	}
+	inst := instances[0]
+	if inst.Name != "默认Telegram" || !inst.Enabled {
+		t.Errorf("新建的渠道错误: %+v", inst)
+	}
+	if s.notification.GetInstance(inst.ID) == nil {
+		t.Error("保存后应更新运行中的通知器")
+	}
+
+	// 在新界面设置的字段不被旧版接口覆盖
+	inst.Config = `{"bot_token":"123:abc","chat_id":"42","parse_mode":"HTML"}`
+	if err := storage.UpdateNotifierInstance(&inst); err != nil {
+		t.Fatal(err)
+	}
+	if w := post(`{"chat_id":"-100","enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("更新旧版 Telegram 设置失败: %d %s", w.Code, w.Body.String())
+	}
+	instances, _ = storage.ListNotifierInstances("telegram")
+	if len(instances) != 1 || instances[0].Enabled {
+		t.Fatalf("应更新已有渠道: %+v", instances)
+	}
+	var cfg map[string]string
+	if err := json.Unmarshal([]byte(instances[0].Config), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["chat_id"] != "-100" || cfg["bot_token"] != "123:abc" || cfg["parse_mode"] != "HTML" {
+		t.Errorf("合并后的配置错误: %v", cfg)
+	}
+
+	if w := post(`{"chat_id":`); w.Header().Get("X-Error-Code") != "invalid_json" {
+		t.Errorf("无效请求体应返回 invalid_json: %d %q", w.Code, w.Header().Get("X-Error-Code"))
+	}
+}
+
+func TestLegacyEmailTestWithoutInstance(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	r := httptest.NewRequest(http.MethodPost, "/api/test/email", nil)
+	r.AddCookie(&http.Cookie{Name: localeCookie, Value: "en"})
+	w := httptest.NewRecorder()
+	s.handleLegacyNotifierTest("email")(w, r)
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("响应不是 JSON: %v", err)
+	}
+	if body["status"] != "error" || body["code"] != "notifier_missing" {
+		t.Errorf("没有邮件渠道时应返回 notifier_missing: %v", body)
+	}
+}
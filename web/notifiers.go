@@ -0,0 +1,236 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Puff/i18n"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// notifierInstanceView 通知渠道实例（config 以 JSON 对象返回）
+type notifierInstanceView struct {
+	storage.NotifierInstance
+	Config json.RawMessage `json:"config"`
+}
+
+// notifierInstanceRequest 新增/更新通知渠道请求
+type notifierInstanceRequest struct {
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Config  json.RawMessage `json:"config"`
+	Enabled bool            `json:"enabled"`
+}
+
+// notifierDisplayName 通知器显示名称（通知渠道实例附带实例名）
+func notifierDisplayName(locale string, n Notifier) string {
+	if inst, ok := n.(*notification.InstanceNotifier); ok {
+		return fmt.Sprintf("%s·%s", notifierTypeName(locale, inst.GetType()), inst.Name)
+	}
+	return notifierTypeName(locale, n.GetType())
+}
+
+// notifierTypeName 通知器类型的显示名称（通知URL创建的实例可为任意类型）
+func notifierTypeName(locale, notifierType string) string {
+	if i18n.Has("channel." + notifierType) {
+		return i18n.T(locale, "channel."+notifierType)
+	}
+	if name, ok := notification.InstanceTypes[notifierType]; ok {
+		return name
+	}
+	if name, ok := robotNames[notifierType]; ok {
+		return name
+	}
+	if name, ok := pushNames[notifierType]; ok {
+		return name
+	}
+	if notifierType == "webhook" {
+		return "Webhook"
+	}
+	return notifierType
+}
+
+// handleNotifiers 通知渠道列表与新增
+// GET  /api/notifiers
+// POST /api/notifiers {"type": "telegram", "name": "团队群", "config": {...}, "enabled": true}
+func (s *Server) handleNotifiers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		instances, err := storage.ListNotifierInstances(r.URL.Query().Get("type"))
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "notifier_list_failed", err.Error())
+			return
+		}
+		views := make([]notifierInstanceView, 0, len(instances))
+		for _, inst := range instances {
+			views = append(views, notifierInstanceView{NotifierInstance: inst, Config: json.RawMessage(inst.Config)})
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"instances": views,
+			"types":     notification.InstanceTypes,
+		})
+	case http.MethodPost:
+		req, ok := s.decodeNotifierRequest(w, r)
+		if !ok {
+			return
+		}
+		if _, supported := notification.InstanceTypes[req.Type]; !supported {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "notifier_type_unsupported", req.Type)
+			return
+		}
+
+		inst := storage.NotifierInstance{Type: req.Type, Name: req.Name, Config: string(req.Config), Enabled: req.Enabled}
+		if !s.applyNotifierInstance(w, r, &inst, true) {
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.notifier_added"),
+			"id":      inst.ID,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleNotifier 单个通知渠道的更新、删除与测试
+// PUT    /api/notifiers/{id}
+// DELETE /api/notifiers/{id}
+// POST   /api/notifiers/{id}/test
+func (s *Server) handleNotifier(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/notifiers/"), "/")
+	idPart, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "notifier_id_invalid")
+		return
+	}
+
+	existing, err := storage.GetNotifierInstance(id)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+	if existing == nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "notifier_not_found")
+		return
+	}
+
+	switch {
+	case action == "test" && r.Method == http.MethodPost:
+		s.handleTestNotifierInstance(w, r, existing)
+	case action == "" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		req, ok := s.decodeNotifierRequest(w, r)
+		if !ok {
+			return
+		}
+		existing.Name = req.Name
+		existing.Config = string(req.Config)
+		existing.Enabled = req.Enabled
+		if !s.applyNotifierInstance(w, r, existing, false) {
+			return
+		}
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.notifier_updated"),
+		})
+	case action == "" && r.Method == http.MethodDelete:
+		if err := storage.DeleteNotifierInstance(id); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.notification.RemoveInstance(id)
+		logger.Info("已删除通知渠道 %s", existing.Name)
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.notifier_deleted"),
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// decodeNotifierRequest 解析通知渠道请求体
+func (s *Server) decodeNotifierRequest(w http.ResponseWriter, r *http.Request) (notifierInstanceRequest, bool) {
+	var req notifierInstanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return req, false
+	}
+	req.Type = strings.TrimSpace(req.Type)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "notifier_name_required")
+		return req, false
+	}
+	if len(req.Config) == 0 || string(req.Config) == "null" {
+		req.Config = json.RawMessage("{}")
+	}
+	return req, true
+}
+
+// applyNotifierInstance 校验并保存通知渠道，随后更新运行中的通知器
+func (s *Server) applyNotifierInstance(w http.ResponseWriter, r *http.Request, inst *storage.NotifierInstance, create bool) bool {
+	if err := notification.ValidateInstanceConfig(inst.Type, inst.Config, inst.Enabled); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return false
+	}
+
+	// 先构建通知器，确认配置可以解析
+	notifier, err := notification.NewInstanceNotifier(inst.ID, inst.Type, inst.Name, inst.Config, inst.Enabled)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return false
+	}
+
+	if create {
+		err = storage.CreateNotifierInstance(inst)
+		notifier.ID = inst.ID
+	} else {
+		err = storage.UpdateNotifierInstance(inst)
+	}
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return false
+	}
+
+	s.notification.SetInstance(notifier)
+	logger.Info("已保存通知渠道 %s，启用状态: %v", notifierDisplayName(i18n.Default, notifier), inst.Enabled)
+	return true
+}
+
+// handleTestNotifierInstance 测试单个通知渠道
+func (s *Server) handleTestNotifierInstance(w http.ResponseWriter, r *http.Request, inst *storage.NotifierInstance) {
+	notifier := s.notification.GetInstance(inst.ID)
+	if notifier == nil {
+		s.writeJSON(w, s.apiError(r, "notifier_missing", inst.Name))
+		return
+	}
+
+	name := notifierDisplayName(s.locale(r), notifier)
+	if !notifier.IsEnabled() {
+		s.writeJSON(w, s.apiError(r, "notifier_disabled", name))
+		return
+	}
+
+	output, err := s.notification.TestNotifier(notifier)
+	if err != nil {
+		logger.Error("测试%s发送失败: %v", name, err)
+		result := s.apiError(r, "test_failed", name, err.Error())
+		result["output"] = output
+		s.writeJSON(w, result)
+		return
+	}
+
+	logger.Info("测试%s发送成功", name)
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.test_sent", name),
+		"output":  output,
+	})
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Puff/notification"
+)
+
+func TestNotifierInstancesAPI(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	do := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		if path == "/api/notifiers" || strings.HasPrefix(path, "/api/notifiers?") {
+			s.handleNotifiers(w, r)
+		} else {
+			s.handleNotifier(w, r)
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	// 同一类型可创建多个渠道
+	var ids []int64
+	for _, name := range []string{"脚本A", "脚本B"} {
+		w, resp := do(http.MethodPost, "/api/notifiers", fmt.Sprintf(`{"type":"exec","name":%q,"config":{"command":"/bin/echo","args":[%q]},"enabled":true}`, name, name))
+		if w.Code != http.StatusOK {
+			t.Fatalf("新增渠道失败: %d %s", w.Code, w.Body.String())
+		}
+		ids = append(ids, int64(resp["id"].(float64)))
+	}
+	t.Cleanup(func() {
+		for _, id := range ids {
+			do(http.MethodDelete, fmt.Sprintf("/api/notifiers/%d", id), "")
+		}
+	})
+
+	_, resp := do(http.MethodGet, "/api/notifiers?type=exec", "")
+	if instances, _ := resp["instances"].([]interface{}); len(instances) != 2 {
+		t.Fatalf("应列出 2 个外部命令渠道: %v", resp["instances"])
+	}
+	if s.notification.GetInstance(ids[0]) == nil || s.notification.GetInstance(ids[1]) == nil {
+		t.Fatal("新增后应加入运行中的通知器")
+	}
+
+	// 测试单个渠道，返回命令输出
+	_, resp = do(http.MethodPost, fmt.Sprintf("/api/notifiers/%d/test", ids[1]), "")
+	if resp["status"] != "success" || strings.TrimSpace(fmt.Sprint(resp["output"])) != "脚本B" {
+		t.Errorf("测试结果错误: %v", resp)
+	}
+
+	// 禁用后测试返回提示
+	if w, _ := do(http.MethodPut, fmt.Sprintf("/api/notifiers/%d", ids[1]), `{"name":"脚本B","config":{"command":"/bin/echo"},"enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("更新渠道失败: %d %s", w.Code, w.Body.String())
+	}
+	if s.notification.GetInstance(ids[1]).IsEnabled() {
+		t.Error("更新后运行中的通知器应被禁用")
+	}
+	if _, resp = do(http.MethodPost, fmt.Sprintf("/api/notifiers/%d/test", ids[1]), ""); resp["code"] != "notifier_disabled" {
+		t.Errorf("禁用的渠道测试应返回 notifier_disabled: %v", resp)
+	}
+
+	// 删除后从运行中的通知器移除
+	if w, _ := do(http.MethodDelete, fmt.Sprintf("/api/notifiers/%d", ids[0]), ""); w.Code != http.StatusOK {
+		t.Fatalf("删除渠道失败: %d", w.Code)
+	}
+	if s.notification.GetInstance(ids[0]) != nil {
+		t.Error("删除后不应保留运行中的通知器")
+	}
+
+	tests := []struct {
+		method, path, body, code string
+	}{
+		{http.MethodPost, "/api/notifiers", `{"type":"exec","name":" "}`, "notifier_name_required"},
+		{http.MethodPost, "/api/notifiers", `{"type":"pager","name":"x"}`, "notifier_type_unsupported"},
+		{http.MethodPost, "/api/notifiers", `{"type":"exec","name":"x","config":{"command":""},"enabled":true}`, "invalid_config"},
+		{http.MethodPost, "/api/notifiers", `{"type":`, "invalid_json"},
+		{http.MethodGet, "/api/notifiers/abc", "", "notifier_id_invalid"},
+		{http.MethodPut, fmt.Sprintf("/api/notifiers/%d", ids[0]), `{"name":"x"}`, "notifier_not_found"},
+		{http.MethodPatch, fmt.Sprintf("/api/notifiers/%d", ids[1]), "", "method_not_allowed"},
+	}
+	for _, tt := range tests {
+		if w, _ := do(tt.method, tt.path, tt.body); w.Header().Get("X-Error-Code") != tt.code {
+			t.Errorf("%s %s %s 错误码 = %q, 期望 %q", tt.method, tt.path, tt.body, w.Header().Get("X-Error-Code"), tt.code)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// handleOutbox 分页查看通知发件箱
+// GET /api/outbox?status=dead&page=1&limit=20
+func (s *Server) handleOutbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	switch status {
+	case "", storage.OutboxPending, storage.OutboxSent, storage.OutboxDead:
+	default:
+		s.writeErrorCode(w, r, http.StatusBadRequest, "status_invalid", status)
+		return
+	}
+
+	page := 1
+	limit := 20
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	entries, total, err := storage.ListOutbox(status, limit, (page-1)*limit)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "outbox_list_failed", err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []storage.OutboxEntry{}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"entries":      entries,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+		"total_pages":  (total + limit - 1) / limit,
+		"counts":       s.notification.OutboxStats(),
+		"max_attempts": s.config.Notification.MaxAttempts,
+	})
+}
+
+// handleOutboxReplay 重新投递死信记录
+// POST /api/outbox/replay {"ids": [1, 2]}（ids 为空时重放全部死信）
+// POST /api/outbox/{id}/replay
+func (s *Server) handleOutboxReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var ids []int64
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/outbox/"), "/")
+	if rest == "replay" {
+		var req struct {
+			IDs []int64 `json:"ids"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+				return
+			}
+		}
+		ids = req.IDs
+	} else {
+		id, err := strconv.ParseInt(strings.TrimSuffix(rest, "/replay"), 10, 64)
+		if err != nil || id <= 0 || !strings.HasSuffix(rest, "/replay") {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "record_id_invalid")
+			return
+		}
+		ids = []int64{id}
+	}
+
+	count, err := storage.ReplayOutbox(ids)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+	if count > 0 {
+		s.notification.WakeOutbox()
+		logger.Info("已重新投递 %d 条死信通知", count)
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":   "success",
+		"message":  s.text(r, "msg.outbox_replayed", count),
+		"replayed": count,
+	})
+}
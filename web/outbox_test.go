@@ -0,0 +1,98 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Puff/notification"
+	"Puff/storage"
+)
+
+func TestHandleOutboxReplay(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+
+	entries := []storage.OutboxEntry{{NotifierKey: "webhook", Subject: "主题", Message: "正文", Events: "[]"}}
+	if err := storage.EnqueueOutbox(entries); err != nil {
+		t.Fatal(err)
+	}
+	id := entries[0].ID
+	if err := storage.MarkOutboxFailed(id, 5, "HTTP 502", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		if method == http.MethodGet {
+			s.handleOutbox(w, r)
+		} else {
+			s.handleOutboxReplay(w, r)
+		}
+		return w
+	}
+	list := func(status string) (total int) {
+		w := do(http.MethodGet, "/api/outbox?status="+status, "")
+		var resp struct {
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("解析列表失败: %v %s", err, w.Body.String())
+		}
+		return resp.Total
+	}
+
+	if list(storage.OutboxDead) != 1 {
+		t.Fatal("应列出 1 条死信")
+	}
+
+	replay := func(path, body string) int64 {
+		w := do(http.MethodPost, path, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("重放失败: %d %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Replayed int64 `json:"replayed"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Replayed
+	}
+	if n := replay(fmt.Sprintf("/api/outbox/%d/replay", id), ""); n != 1 {
+		t.Errorf("按 ID 重放应返回 1，实际 %d", n)
+	}
+	if list(storage.OutboxDead) != 0 || list(storage.OutboxPending) != 1 {
+		t.Error("重放后记录应变为待投递")
+	}
+	// 非死信记录不会被重复重放
+	if n := replay("/api/outbox/replay", fmt.Sprintf(`{"ids":[%d]}`, id)); n != 0 {
+		t.Errorf("待投递记录不应被重放，实际 %d", n)
+	}
+
+	tests := []struct {
+		method, path, body, code string
+	}{
+		{http.MethodGet, "/api/outbox?status=failed", "", "status_invalid"},
+		{http.MethodPost, "/api/outbox/abc/replay", "", "record_id_invalid"},
+		{http.MethodPost, "/api/outbox/1", "", "record_id_invalid"},
+		{http.MethodPost, "/api/outbox/replay", `{"ids":`, "invalid_json"},
+		{http.MethodGet, "/api/outbox/replay", "", "method_not_allowed"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		if strings.HasPrefix(tt.path, "/api/outbox?") {
+			s.handleOutbox(w, r)
+		} else {
+			s.handleOutboxReplay(w, r)
+		}
+		if got := w.Header().Get("X-Error-Code"); got != tt.code {
+			t.Errorf("%s %s 错误码 = %q, 期望 %q", tt.method, tt.path, got, tt.code)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"Puff/core"
+	"Puff/storage"
+)
+
+// handleOwnedDomains 列出自有域名及剩余天数
+// GET /api/owned
+func (s *Server) handleOwnedDomains(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	domains, err := s.monitor.GetOwnedDomains()
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "owned_list_failed", err.Error())
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"domains":               domains,
+		"total":                 len(domains),
+		"default_reminder_days": core.DefaultReminderDays,
+	})
+}
+
+// handleDomainOwned 设置域名的自有标记与提醒天数
+// GET    /api/domain/{name}/owned
+// PUT    /api/domain/{name}/owned {"reminder_days": [90, 30, 7, 1]}
+// DELETE /api/domain/{name}/owned
+func (s *Server) handleDomainOwned(w http.ResponseWriter, r *http.Request, domain string) {
+	switch r.Method {
+	case http.MethodGet:
+		owned, err := storage.GetOwnedDomain(domain)
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		if owned != nil && len(owned.ReminderDays) == 0 {
+			owned.ReminderDays = core.DefaultReminderDays
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"domain": domain,
+			"owned":  owned != nil,
+			"detail": owned,
+		})
+
+	case http.MethodPut, http.MethodPost:
+		var request struct {
+			ReminderDays []int `json:"reminder_days"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+				return
+			}
+		}
+		for _, d := range request.ReminderDays {
+			if d < 1 || d > 3650 {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "reminder_days_out_of_range")
+				return
+			}
+		}
+
+		if err := storage.SetDomainOwned(domain, request.ReminderDays); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+
+		// 立即检查一次，已到达提醒档位的域名无需等待下一次每日任务
+		go s.monitor.CheckExpiryReminders(time.Now())
+
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.owned_set"),
+		})
+
+	case http.MethodDelete:
+		if err := storage.UnsetDomainOwned(domain); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.owned_unset"),
+		})
+
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// pushNames 手机推送通知器类型及显示名称
+var pushNames = map[string]string{
+	"bark":       "Bark",
+	"ntfy":       "ntfy",
+	"gotify":     "Gotify",
+	"serverchan": "Server酱",
+}
+
+// pushSettings 手机推送设置（用于 /api/settings 返回）
+func (s *Server) pushSettings(cfg config.PushConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"server":       cfg.Server,
+		"token":        cfg.Token,
+		"access_token": cfg.AccessToken,
+		"priorities":   cfg.Priorities,
+		"enabled":      cfg.Enabled,
+	}
+}
+
+// handlePushSettings 处理手机推送设置（Bark、ntfy、Gotify、Server酱）
+func (s *Server) handlePushSettings(notifierType string) http.HandlerFunc {
+	name := pushNames[notifierType]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		label := notifierTypeName(s.locale(r), notifierType)
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+
+		var req struct {
+			Server      string `json:"server"`
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+			Priorities  string `json:"priorities"`
+			Enabled     bool   `json:"enabled"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+
+		req.Server = strings.TrimSpace(req.Server)
+		req.Token = strings.TrimSpace(req.Token)
+		req.AccessToken = strings.TrimSpace(req.AccessToken)
+		req.Priorities = strings.TrimSpace(req.Priorities)
+
+		if req.Server != "" && !strings.HasPrefix(req.Server, "https://") && !strings.HasPrefix(req.Server, "http://") {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "push_url_invalid", label)
+			return
+		}
+		if _, err := notification.ParsePriorityMap(req.Priorities); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
+		if req.Enabled {
+			if req.Token == "" {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "push_key_required", label)
+				return
+			}
+			if notifierType == "gotify" && req.Server == "" {
+				s.writeErrorCode(w, r, http.StatusBadRequest, "gotify_url_required")
+				return
+			}
+		}
+
+		// 将设置保存到数据库
+		if err := storage.UpsertSettings(map[string]string{
+			notifierType + "_server":       req.Server,
+			notifierType + "_token":        req.Token,
+			notifierType + "_access_token": req.AccessToken,
+			notifierType + "_priorities":   req.Priorities,
+			notifierType + "_enabled":      fmt.Sprintf("%t", req.Enabled),
+		}); err != nil {
+			log.Printf("保存%s设置到数据库失败: %v", name, err)
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+			return
+		}
+
+		// 更新当前配置
+		push := s.config.PushServices()[notifierType]
+		*push = config.PushConfig{
+			Server:      req.Server,
+			Token:       req.Token,
+			AccessToken: req.AccessToken,
+			Priorities:  req.Priorities,
+			Enabled:     req.Enabled,
+		}
+
+		// 更新通知器配置
+		if err := s.notification.UpdatePushConfig(notifierType, *push); err != nil {
+			logger.Error("更新%s通知器配置失败: %v", name, err)
+		} else {
+			logger.Info("已更新%s通知器配置，启用状态: %v", name, req.Enabled)
+		}
+
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.settings_saved", label),
+		})
+	}
+}
+
+// handleTestPush 测试手机推送发送
+func (s *Server) handleTestPush(notifierType string) http.HandlerFunc {
+	name := pushNames[notifierType]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		label := notifierTypeName(s.locale(r), notifierType)
+		if r.Method != http.MethodPost {
+			s.writeJSON(w, s.apiError(r, "method_not_allowed"))
+			return
+		}
+
+		// 检查是否启用
+		if !s.config.PushServices()[notifierType].Enabled {
+			s.writeJSON(w, s.apiError(r, "push_disabled", label))
+			return
+		}
+
+		var pushNotifier Notifier
+		for _, notifier := range s.notification.GetNotifiers() {
+			if notifier.GetType() == notifierType {
+				pushNotifier = notifier
+				break
+			}
+		}
+
+		if pushNotifier == nil {
+			s.writeJSON(w, s.apiError(r, "notifier_missing", label))
+			return
+		}
+
+		// 执行测试
+		if err := pushNotifier.Test(); err != nil {
+			logger.Error("测试%s推送失败: %v", name, err)
+			s.writeJSON(w, s.apiError(r, "test_failed", label, err.Error()))
+			return
+		}
+
+		logger.Info("测试%s推送成功", name)
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.push_test_sent", label),
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"Puff/config"
+	"Puff/logger"
+	"Puff/storage"
+)
+
+// robotNames 群机器人通知器类型及显示名称
+var robotNames = map[string]string{
+	"dingtalk": "钉钉",
+	"feishu":   "飞书",
+	"wecom":    "企业微信",
+}
+
+// robotSettings 群机器人设置（用于 /api/settings 返回）
+func (s *Server) robotSettings(cfg config.RobotConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"webhook": cfg.Webhook,
+		"secret":  cfg.Secret,
+		"enabled": cfg.Enabled,
+	}
+}
+
+// handleRobotSettings 处理群机器人设置（钉钉、飞书、企业微信）
+func (s *Server) handleRobotSettings(notifierType string) http.HandlerFunc {
+	name := robotNames[notifierType]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		label := notifierTypeName(s.locale(r), notifierType)
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+
+		var req struct {
+			Webhook string `json:"webhook"`
+			Secret  string `json:"secret"`
+			Enabled bool   `json:"enabled"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+			return
+		}
+
+		req.Webhook = strings.TrimSpace(req.Webhook)
+		req.Secret = strings.TrimSpace(req.Secret)
+		if req.Enabled && !strings.HasPrefix(req.Webhook, "https://") && !strings.HasPrefix(req.Webhook, "http://") {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "robot_url_invalid", label)
+			return
+		}
+
+		// 将设置保存到数据库
+		if err := storage.UpsertSettings(map[string]string{
+			notifierType + "_webhook": req.Webhook,
+			notifierType + "_secret":  req.Secret,
+			notifierType + "_enabled": fmt.Sprintf("%t", req.Enabled),
+		}); err != nil {
+			log.Printf("保存%s设置到数据库失败: %v", name, err)
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "settings_save_failed", err.Error())
+			return
+		}
+
+		// 更新当前配置
+		robot := s.config.Robots()[notifierType]
+		*robot = config.RobotConfig{Webhook: req.Webhook, Secret: req.Secret, Enabled: req.Enabled}
+
+		// 更新通知器配置
+		if err := s.notification.UpdateRobotConfig(notifierType, *robot); err != nil {
+			logger.Error("更新%s通知器配置失败: %v", name, err)
+		} else {
+			logger.Info("已更新%s通知器配置，启用状态: %v", name, req.Enabled)
+		}
+
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.settings_saved", label),
+		})
+	}
+}
+
+// handleTestRobot 测试群机器人发送
+func (s *Server) handleTestRobot(notifierType string) http.HandlerFunc {
+	name := robotNames[notifierType]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		label := notifierTypeName(s.locale(r), notifierType)
+		if r.Method != http.MethodPost {
+			s.writeJSON(w, s.apiError(r, "method_not_allowed"))
+			return
+		}
+
+		// 检查是否启用
+		if !s.config.Robots()[notifierType].Enabled {
+			s.writeJSON(w, s.apiError(r, "robot_disabled", label))
+			return
+		}
+
+		var robotNotifier Notifier
+		for _, notifier := range s.notification.GetNotifiers() {
+			if notifier.GetType() == notifierType {
+				robotNotifier = notifier
+				break
+			}
+		}
+
+		if robotNotifier == nil {
+			s.writeJSON(w, s.apiError(r, "notifier_missing", label))
+			return
+		}
+
+		// 执行测试
+		if err := robotNotifier.Test(); err != nil {
+			logger.Error("测试%s发送失败: %v", name, err)
+			s.writeJSON(w, s.apiError(r, "test_failed", label, err.Error()))
+			return
+		}
+
+		logger.Info("测试%s发送成功", name)
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.robot_test_sent", label),
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"Puff/core"
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// routableEventTypes 可用于路由规则的事件类型
+func routableEventTypes() []string {
+	types := append([]string{"status_change", "expiry_reminder", "alert_repeat"}, core.FieldChangeTypes...)
+	return append(types, core.HealthEventTypes...)
+}
+
+// handleRules 通知路由规则列表与新增
+// GET  /api/rules
+// POST /api/rules {"name": "...", "tld": "com", "to_status": "available", "targets": ["telegram:1"], "throttle_seconds": 3600}
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := storage.ListNotificationRules()
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "rule_list_failed", err.Error())
+			return
+		}
+		if rules == nil {
+			rules = []storage.NotificationRule{}
+		}
+
+		// 可选的通知器目标
+		targets := []map[string]string{{"key": notification.AllTargets, "name": s.text(r, "msg.all_targets")}}
+		for _, n := range s.notification.GetNotifiers() {
+			targets = append(targets, map[string]string{"key": notification.NotifierKey(n), "name": notifierDisplayName(s.locale(r), n)})
+		}
+
+		s.writeJSON(w, map[string]interface{}{
+			"rules":       rules,
+			"targets":     targets,
+			"event_types": routableEventTypes(),
+		})
+	case http.MethodPost:
+		var rule storage.NotificationRule
+		if !s.decodeRule(w, r, &rule) {
+			return
+		}
+		if err := storage.CreateNotificationRule(&rule); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadRules()
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.rule_added"),
+			"id":      rule.ID,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleRule 单条路由规则的更新与删除
+// PUT    /api/rules/{id}
+// DELETE /api/rules/{id}
+func (s *Server) handleRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/rules/"), "/"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "rule_id_invalid")
+		return
+	}
+
+	existing, err := storage.GetNotificationRule(id)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+	if existing == nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "rule_not_found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		rule := storage.NotificationRule{ID: id}
+		if !s.decodeRule(w, r, &rule) {
+			return
+		}
+		if err := storage.UpdateNotificationRule(&rule); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadRules()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.rule_updated"),
+		})
+	case http.MethodDelete:
+		if err := storage.DeleteNotificationRule(id); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadRules()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.rule_deleted"),
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// decodeRule 解析并校验路由规则请求体
+func (s *Server) decodeRule(w http.ResponseWriter, r *http.Request, rule *storage.NotificationRule) bool {
+	var req struct {
+		Name            string   `json:"name"`
+		Position        int      `json:"position"`
+		Enabled         *bool    `json:"enabled"`
+		EventType       string   `json:"event_type"`
+		DomainPattern   string   `json:"domain_pattern"`
+		TLD             string   `json:"tld"`
+		FromStatus      string   `json:"from_status"`
+		ToStatus        string   `json:"to_status"`
+		ErrorClass      string   `json:"error_class"`
+		Targets         []string `json:"targets"`
+		ThrottleSeconds int      `json:"throttle_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return false
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Position = req.Position
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.EventType = strings.TrimSpace(req.EventType)
+	rule.DomainPattern = strings.ToLower(strings.TrimSpace(req.DomainPattern))
+	rule.TLD = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.TLD), "."))
+	rule.FromStatus = strings.TrimSpace(req.FromStatus)
+	rule.ToStatus = strings.TrimSpace(req.ToStatus)
+	rule.ErrorClass = strings.TrimSpace(req.ErrorClass)
+	rule.ThrottleSeconds = req.ThrottleSeconds
+	rule.Targets = nil
+	for _, target := range req.Targets {
+		if target = strings.TrimSpace(target); target != "" {
+			rule.Targets = append(rule.Targets, target)
+		}
+	}
+
+	if rule.Name == "" {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "rule_name_required")
+		return false
+	}
+	if rule.EventType != "" && !containsString(routableEventTypes(), rule.EventType) {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "event_type_invalid", rule.EventType)
+		return false
+	}
+	// 查询错误只通过健康告警（连续查询失败）进入路由，错误分类条件需配合该事件类型
+	if rule.ErrorClass != "" {
+		if !containsString(core.ErrorClasses, rule.ErrorClass) {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "error_class_invalid", rule.ErrorClass)
+			return false
+		}
+		if rule.EventType != core.HealthLookupFailure {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "error_class_event_type", core.HealthLookupFailure)
+			return false
+		}
+	}
+	if _, err := path.Match(rule.DomainPattern, ""); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "domain_pattern_invalid", rule.DomainPattern)
+		return false
+	}
+	if rule.ThrottleSeconds < 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "throttle_negative")
+		return false
+	}
+
+	// 目标必须是 "*"、通知器类型或已存在的通知器标识
+	valid := map[string]bool{notification.AllTargets: true}
+	for _, n := range s.notification.GetNotifiers() {
+		valid[notification.NotifierKey(n)] = true
+		valid[n.GetType()] = true
+	}
+	for _, target := range rule.Targets {
+		if !valid[target] || strings.Contains(target, ",") {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "target_unknown", target)
+			return false
+		}
+	}
+	return true
+}
+
+// reloadRules 规则变更后重新加载到通知管理器
+func (s *Server) reloadRules() {
+	if err := s.notification.LoadRoutingRules(); err != nil {
+		logger.Error("重新加载通知路由规则失败: %v", err)
+	}
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
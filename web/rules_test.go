@@ -0,0 +1,35 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Puff/storage"
+)
+
+func TestDecodeRuleErrorClass(t *testing.T) {
+	tests := []struct {
+		body string
+		code string
+	}{
+		{`{"name":"r","event_type":"health_lookup_failure","error_class":"nope"}`, "error_class_invalid"},
+		{`{"name":"r","error_class":"timeout"}`, "error_class_event_type"},
+		{`{"name":"r","event_type":"status_change","error_class":"timeout"}`, "error_class_event_type"},
+	}
+	s := testServer()
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/api/rules", strings.NewReader(tt.body))
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		var rule storage.NotificationRule
+		if s.decodeRule(w, r, &rule) {
+			t.Errorf("%s 应校验失败", tt.body)
+			continue
+		}
+		if w.Code != http.StatusBadRequest || w.Header().Get("X-Error-Code") != tt.code {
+			t.Errorf("%s: %d %q，期望 %s", tt.body, w.Code, w.Header().Get("X-Error-Code"), tt.code)
+		}
+	}
+}
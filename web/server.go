@@ -1,6 +1,7 @@
 package web
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"Puff/auth"
 	"Puff/config"
 	"Puff/core"
+	"Puff/eventsink"
 	"Puff/notification"
 	"Puff/storage"
 )
@@ -25,6 +27,7 @@ type Server struct {
 	loginLimiter   *RateLimiter
 	batchLimiter   *RateLimiter
 	generalLimiter *RateLimiter
+	events         *eventsink.Sink
 }
 
 // NewServer 创建新的Web服务器
@@ -108,12 +111,55 @@ func (s *Server) setupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/notification/test", s.withAuth(s.handleNotificationTest))
 	mux.HandleFunc("/api/change-password", s.withAuth(s.handleChangePassword))
 	mux.HandleFunc("/api/update-username", s.withAuth(s.handleUpdateUsername))
-	mux.HandleFunc("/api/settings/smtp", s.withAuth(s.handleSmtpSettings))
-	mux.HandleFunc("/api/settings/telegram", s.withAuth(s.handleTelegramSettings))
+	mux.HandleFunc("/api/settings/webhook", s.withAuth(s.handleWebhookSettings))
 	mux.HandleFunc("/api/settings/monitor", s.withAuth(s.handleMonitorSettings))
 	mux.HandleFunc("/api/settings", s.withAuth(s.handleGetSettings))
-	mux.HandleFunc("/api/test/email", s.withAuth(s.handleTestEmail))
-	mux.HandleFunc("/api/test/telegram", s.withAuth(s.handleTestTelegram))
+	mux.HandleFunc("/api/test/webhook", s.withAuth(s.handleTestWebhook))
+	for notifierType := range robotNames {
+		mux.HandleFunc("/api/settings/"+notifierType, s.withAuth(s.handleRobotSettings(notifierType)))
+		mux.HandleFunc("/api/test/"+notifierType, s.withAuth(s.handleTestRobot(notifierType)))
+	}
+	for notifierType := range pushNames {
+		mux.HandleFunc("/api/settings/"+notifierType, s.withAuth(s.handlePushSettings(notifierType)))
+		mux.HandleFunc("/api/test/"+notifierType, s.withAuth(s.handleTestPush(notifierType)))
+	}
+	mux.HandleFunc("/api/settings/smtp", s.withAuth(s.handleLegacyNotifierSettings("smtp")))
+	mux.HandleFunc("/api/settings/telegram", s.withAuth(s.handleLegacyNotifierSettings("telegram")))
+	mux.HandleFunc("/api/test/email", s.withAuth(s.handleLegacyNotifierTest("email")))
+	mux.HandleFunc("/api/test/telegram", s.withAuth(s.handleLegacyNotifierTest("telegram")))
+	mux.HandleFunc("/api/notifiers", s.withAuth(s.handleNotifiers))
+	mux.HandleFunc("/api/notifiers/", s.withAuth(s.handleNotifier))
+	mux.HandleFunc("/api/rules", s.withAuth(s.handleRules))
+	mux.HandleFunc("/api/rules/", s.withAuth(s.handleRule))
+	mux.HandleFunc("/api/templates", s.withAuth(s.handleTemplates))
+	mux.HandleFunc("/api/templates/preview", s.withAuth(s.handleTemplatePreview))
+	mux.HandleFunc("/api/templates/", s.withAuth(s.handleTemplate))
+	mux.HandleFunc("/api/notifications", s.withAuth(s.handleNotificationDeliveries))
+	mux.HandleFunc("/api/digest", s.withAuth(s.handleDigestSettings))
+	mux.HandleFunc("/api/digest/preview", s.withAuth(s.handleDigestPreview))
+	mux.HandleFunc("/api/digest/send", s.withAuth(s.handleDigestSend))
+	mux.HandleFunc("/api/health-alerts", s.withAuth(s.handleHealthSettings))
+	mux.HandleFunc("/api/notification-batching", s.withAuth(s.handleBatchingSettings))
+	mux.HandleFunc("/api/notification-locales", s.withAuth(s.handleNotificationLocales))
+	mux.HandleFunc("/api/i18n", s.withAuth(s.handleI18n))
+	mux.HandleFunc("/api/events", s.withAuth(s.handleEventsSettings))
+	mux.HandleFunc("/api/events/test", s.withAuth(s.handleEventsTest))
+	mux.HandleFunc("/api/alerts", s.withAuth(s.handleAlerts))
+	mux.HandleFunc("/api/alerts/", s.withAuth(s.handleAlertAction))
+	mux.HandleFunc("/api/escalations", s.withAuth(s.handleEscalationPolicies))
+	mux.HandleFunc("/api/escalations/", s.withAuth(s.handleEscalationPolicy))
+	mux.HandleFunc("/api/outbox", s.withAuth(s.handleOutbox))
+	mux.HandleFunc("/api/outbox/", s.withAuth(s.handleOutboxReplay))
+	mux.HandleFunc("/api/subscriptions", s.withAuth(s.handleSubscriptions))
+	mux.HandleFunc("/api/owned", s.withAuth(s.handleOwnedDomains))
+	mux.HandleFunc("/api/calendar", s.withAuth(s.handleCalendarSettings))
+
+	// 日历订阅（令牌鉴权）
+	mux.HandleFunc("/calendar/", s.handleCalendarFeed)
+
+	// 告警确认与暂停（签名链接鉴权）
+	mux.HandleFunc("/alerts/ack", s.handleAlertLink)
+	mux.HandleFunc("/alerts/snooze", s.handleAlertLink)
 
 	// 数据库维护
 	mux.HandleFunc("/api/database/clean-orphaned", s.withAuth(s.handleCleanOrphanedData))
@@ -130,6 +176,7 @@ func (s *Server) setupRoutes(mux *http.ServeMux) {
 
 // withAuth 认证中间件
 func (s *Server) withAuth(handler http.HandlerFunc) http.HandlerFunc {
+	handler = s.audited(handler)
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 检查是否需要认证
 		if !s.auth.RequireAuth() {
@@ -164,7 +211,7 @@ func (s *Server) withAuth(handler http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		s.writeErrorCode(w, r, http.StatusUnauthorized, "unauthorized")
 	}
 }
 
@@ -173,6 +220,7 @@ func (s *Server) enableCORS(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Error-Code")
 }
 
 // writeJSON 写入JSON响应
@@ -185,8 +233,11 @@ func (s *Server) writeJSON(w http.ResponseWriter, data interface{}) {
 	}
 }
 
-// writeError 写入错误响应
+// writeError 写入错误响应（未指定错误码时按 HTTP 状态码附带通用错误码）
 func (s *Server) writeError(w http.ResponseWriter, message string, code int) {
+	if w.Header().Get("X-Error-Code") == "" {
+		w.Header().Set("X-Error-Code", statusErrorCode(code))
+	}
 	s.enableCORS(w)
 	http.Error(w, message, code)
 }
@@ -223,6 +274,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Page not found", http.StatusNotFound)
 		return
 	}
+	// 页面以简体中文编写，其他界面语言由 static/i18n.js 翻译
+	indexFile = bytes.Replace(indexFile, []byte(`<html lang="zh-CN"`), []byte(`<html lang="`+s.locale(r)+`"`), 1)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write(indexFile)
 }
@@ -0,0 +1,180 @@
+// 界面多语言：页面以简体中文编写，其他语言按 /static/locales/<语言>.json 中的对照表翻译
+// 对照表的键为中文原文，{0}、{1} 为占位符；未收录的文本保持原样
+(function () {
+    const SOURCE_LOCALE = 'zh-CN';
+    const CJK = /[一-鿿]/;
+    const ATTRIBUTES = ['placeholder', 'title'];
+
+    const locale = document.documentElement.lang || SOURCE_LOCALE;
+    let exact = {};
+    let patterns = [];
+    let fragments = [];
+
+    // escapeRegExp 转义正则特殊字符
+    function escapeRegExp(text) {
+        return text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+    }
+
+    // compile 将对照表拆分为完整匹配、占位符模式与片段替换
+    function compile(dict) {
+        exact = dict;
+        patterns = [];
+        fragments = [];
+        Object.keys(dict).forEach(key => {
+            if (/\{\d\}/.test(key)) {
+                const order = [];
+                const source = escapeRegExp(key).replace(/\\\{(\d)\\\}/g, (_, n) => {
+                    order.push(Number(n));
+                    return '(.+?)';
+                });
+                patterns.push({ regex: new RegExp('^' + source + '$'), order, target: dict[key] });
+            } else if (key.length >= 2 && CJK.test(key)) {
+                fragments.push(key);
+            }
+        });
+        fragments.sort((a, b) => b.length - a.length);
+    }
+
+    // translate 翻译一段文本：完整匹配优先，其次占位符模式，最后按片段替换
+    function translate(text) {
+        if (locale === SOURCE_LOCALE || !text || !CJK.test(text)) {
+            return text;
+        }
+        const trimmed = text.trim();
+        if (exact[trimmed] !== undefined) {
+            return text.replace(trimmed, exact[trimmed]);
+        }
+        for (const p of patterns) {
+            const m = trimmed.match(p.regex);
+            if (m) {
+                const values = [];
+                p.order.forEach((n, i) => { values[n] = translate(m[i + 1]); });
+                const result = p.target.replace(/\{(\d)\}/g, (_, n) => values[Number(n)] ?? '');
+                return text.replace(trimmed, result);
+            }
+        }
+        let result = text;
+        for (const fragment of fragments) {
+            if (result.includes(fragment)) {
+                result = result.split(fragment).join(exact[fragment]);
+                if (!CJK.test(result)) {
+                    break;
+                }
+            }
+        }
+        return result;
+    }
+
+    // translateNode 翻译节点及其子节点的文本与属性
+    function translateNode(root) {
+        if (root.nodeType === Node.TEXT_NODE) {
+            const translated = translate(root.nodeValue);
+            if (translated !== root.nodeValue) {
+                root.nodeValue = translated;
+            }
+            return;
+        }
+        if (root.nodeType !== Node.ELEMENT_NODE || root.tagName === 'SCRIPT' || root.tagName === 'STYLE') {
+            return;
+        }
+        ATTRIBUTES.forEach(name => {
+            const value = root.getAttribute(name);
+            if (value && CJK.test(value)) {
+                root.setAttribute(name, translate(value));
+            }
+        });
+        root.childNodes.forEach(translateNode);
+    }
+
+    // observe 翻译动态插入或修改的内容
+    function observe() {
+        new MutationObserver(mutations => {
+            mutations.forEach(m => {
+                if (m.type === 'characterData') {
+                    translateNode(m.target);
+                } else if (m.type === 'attributes') {
+                    translateNode(m.target);
+                } else {
+                    m.addedNodes.forEach(translateNode);
+                }
+            });
+        }).observe(document.body, {
+            childList: true,
+            subtree: true,
+            characterData: true,
+            attributes: true,
+            attributeFilter: ATTRIBUTES,
+        });
+    }
+
+    // setLocale 保存界面语言并刷新页面
+    async function setLocale(code) {
+        const response = await fetch('/api/i18n', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json', 'Accept': 'application/json' },
+            body: JSON.stringify({ locale: code }),
+        });
+        if (!response.ok) {
+            const result = await response.json().catch(() => ({}));
+            throw new Error(result.message || response.statusText);
+        }
+        window.location.reload();
+    }
+
+    // initSelector 初始化导航栏的语言选择
+    async function initSelector() {
+        const select = document.getElementById('locale-select');
+        if (!select) {
+            return;
+        }
+        try {
+            const response = await fetch('/api/i18n');
+            const data = await response.json();
+            select.innerHTML = '';
+            data.supported.forEach(option => {
+                const el = document.createElement('option');
+                el.value = option.code;
+                el.textContent = option.name;
+                el.selected = option.code === data.locale;
+                select.appendChild(el);
+            });
+        } catch (error) {
+            console.error('加载语言列表失败:', error);
+        }
+        select.addEventListener('change', () => {
+            setLocale(select.value).catch(error => console.error('切换语言失败:', error));
+        });
+    }
+
+    // 对话框不在 DOM 中，单独翻译
+    const nativeConfirm = window.confirm.bind(window);
+    const nativeAlert = window.alert.bind(window);
+    window.confirm = message => nativeConfirm(translate(String(message)));
+    window.alert = message => nativeAlert(translate(String(message)));
+
+    window.t = translate;
+    window.i18n = { locale, translate, setLocale };
+
+    async function init() {
+        initSelector();
+        if (locale === SOURCE_LOCALE) {
+            return;
+        }
+        try {
+            const response = await fetch(`/static/locales/${locale}.json`);
+            compile(await response.json());
+        } catch (error) {
+            console.error('加载语言包失败:', error);
+            return;
+        }
+        document.title = translate(document.title);
+        translateNode(document.body);
+        observe();
+    }
+
+    if (document.readyState === 'loading') {
+        document.addEventListener('DOMContentLoaded', init);
+    } else {
+        init();
+    }
+})();
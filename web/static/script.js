@@ -159,10 +159,23 @@ function initializeEventListeners() {
     
     // 设置页面事件
     document.getElementById('saveSystemSettingsBtn')?.addEventListener('click', saveMonitorSettings);
-    document.getElementById('saveSmtpBtn')?.addEventListener('click', saveSmtpSettings);
-    document.getElementById('saveTelegramBtn')?.addEventListener('click', saveTelegramSettings);
-    document.getElementById('testEmailBtn')?.addEventListener('click', testEmailSettings);
-    document.getElementById('testTelegramBtn')?.addEventListener('click', testTelegramSettings);
+    document.getElementById('saveNotifierBtn')?.addEventListener('click', saveNotifierInstance);
+    document.getElementById('resetNotifierBtn')?.addEventListener('click', resetNotifierForm);
+    document.getElementById('notifierType')?.addEventListener('change', updateNotifierFields);
+    document.getElementById('saveWebhookBtn')?.addEventListener('click', saveWebhookSettings);
+    document.getElementById('testWebhookBtn')?.addEventListener('click', testWebhookSettings);
+    document.querySelectorAll('[data-robot-save]').forEach(btn => {
+        btn.addEventListener('click', () => saveRobotSettings(btn.dataset.robotSave));
+    });
+    document.querySelectorAll('[data-robot-test]').forEach(btn => {
+        btn.addEventListener('click', () => testRobotSettings(btn.dataset.robotTest));
+    });
+    document.querySelectorAll('[data-push-save]').forEach(btn => {
+        btn.addEventListener('click', () => savePushSettings(btn.dataset.pushSave));
+    });
+    document.querySelectorAll('[data-push-test]').forEach(btn => {
+        btn.addEventListener('click', () => testPushSettings(btn.dataset.pushTest));
+    });
     
     // 分页事件
     bindPaginationEvents();
@@ -263,6 +276,7 @@ async function loadData() {
         updateStatistics(statsData.monitor.status_counts);
         updateMonitorStatus(statsData.monitor.is_running);
         applyFilters();
+        loadAlerts();
         
     } catch (error) {
         console.error('加载数据失败:', error);
@@ -564,6 +578,79 @@ function showNotification(message, type = 'info', duration = 5000) {
 }
 
 // 监控控制功能
+// 加载未确认告警（无告警时隐藏卡片）
+async function loadAlerts() {
+    const card = document.getElementById('alertsCard');
+    const list = document.getElementById('alertsList');
+    if (!card || !list) return;
+
+    try {
+        const response = await fetch('/api/alerts?state=open&limit=50');
+        if (!response.ok) throw new Error('加载告警失败');
+        const result = await response.json();
+        const alerts = result.alerts || [];
+
+        card.classList.toggle('hidden', alerts.length === 0);
+        document.getElementById('alertsCount').textContent = result.total || 0;
+        list.innerHTML = alerts.map(alert => {
+            let state = `已提醒 ${alert.repeats + 1} 次`;
+            if (alert.state === 'snoozed') {
+                state += `，暂停至 ${formatDateTime(alert.snoozed_until)}`;
+            } else if (alert.state === 'exhausted') {
+                state += '，已达最大提醒次数';
+            } else if (alert.next_at) {
+                state += `，下次 ${formatDateTime(alert.next_at)}`;
+            }
+            return `
+                <div class="flex flex-wrap items-center justify-between gap-2 p-3 rounded-lg bg-base-200">
+                    <div>
+                        <span class="font-semibold">${escapeHtml(alert.domain)}</span>
+                        <span class="badge ${getStatusColor(alert.status)} ml-2">${getStatusText(alert.status)}</span>
+                        <span class="text-sm opacity-70 ml-2">${escapeHtml(state)}</span>
+                    </div>
+                    <div class="flex gap-2">
+                        <button class="btn btn-xs btn-primary" onclick="ackAlert(${alert.id})">确认</button>
+                        <button class="btn btn-xs" onclick="snoozeAlert(${alert.id}, 60)">暂停1小时</button>
+                        <button class="btn btn-xs" onclick="snoozeAlert(${alert.id}, 1440)">暂停1天</button>
+                    </div>
+                </div>
+            `;
+        }).join('');
+    } catch (error) {
+        console.error('加载告警失败:', error);
+    }
+}
+
+// 确认告警
+async function ackAlert(id) {
+    try {
+        const response = await fetch(`/api/alerts/${id}/ack`, { method: 'POST' });
+        if (!response.ok) throw new Error(await response.text());
+        const result = await response.json();
+        showNotification(result.message || '告警已确认', 'success');
+        loadAlerts();
+    } catch (error) {
+        showNotification('确认告警失败: ' + error.message, 'error');
+    }
+}
+
+// 暂停告警提醒
+async function snoozeAlert(id, minutes) {
+    try {
+        const response = await fetch(`/api/alerts/${id}/snooze`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ minutes })
+        });
+        if (!response.ok) throw new Error(await response.text());
+        const result = await response.json();
+        showNotification(result.message || '已暂停提醒', 'success');
+        loadAlerts();
+    } catch (error) {
+        showNotification('暂停提醒失败: ' + error.message, 'error');
+    }
+}
+
 async function startMonitor() {
     try {
         const response = await fetch('/api/monitor/start', { method: 'POST' });
@@ -613,8 +700,8 @@ async function testNotification() {
         } else {
             // 构建详细的状态消息
             let message = '';
-            if (result.email_status && result.telegram_status) {
-                message = `邮箱: ${result.email_status}, Telegram: ${result.telegram_status}`;
+            if (result.results && Object.keys(result.results).length > 0) {
+                message = Object.entries(result.results).map(([name, status]) => `${name}: ${status}`).join(', ');
             } else {
                 message = result.message;
             }
@@ -985,7 +1072,7 @@ async function addSingleDomain() {
         // 检查返回的status字段
         if (result.status === 'error') {
             // 如果是不支持的后缀，显示模态框
-            if (result.message && result.message.includes('不支持进行监控')) {
+            if (result.code === 'tld_unsupported') {
                 showUnsupportedDomainsModal([domain]);
             } else {
                 showNotification(result.message || '添加域名失败', 'error');
@@ -1092,6 +1179,7 @@ function loadSettings() {
     // 加载系统设置和配置
     loadSystemSettings();
     loadConfigSettings();
+    loadNotifierInstances();
 }
 
 async function loadSystemSettings() {
@@ -1153,25 +1241,43 @@ async function loadConfigSettings() {
             if (timeoutInput) {
                 timeoutInput.value = settings.monitor.timeout;
             }
+            const publicUrlInput = document.getElementById('publicUrlInput');
+            if (publicUrlInput) {
+                publicUrlInput.value = settings.monitor.public_url || '';
+            }
         }
         
-        // 填充SMTP设置
-        if (settings.smtp) {
-            document.getElementById('smtpHost').value = settings.smtp.host || '';
-            document.getElementById('smtpPort').value = settings.smtp.port || 587;
-            document.getElementById('smtpUser').value = settings.smtp.user || '';
-            document.getElementById('smtpPass').value = settings.smtp.password || ''; // 填充密码
-            document.getElementById('smtpFrom').value = settings.smtp.from || '';
-            document.getElementById('smtpTo').value = settings.smtp.to || '';
-            document.getElementById('emailNotificationToggle').checked = settings.smtp.enabled || false;
+        // 填充Webhook设置
+        if (settings.webhook) {
+            document.getElementById('webhookUrl').value = settings.webhook.url || '';
+            document.getElementById('webhookSecret').value = settings.webhook.secret || '';
+            document.getElementById('webhookHeaders').value = settings.webhook.headers || '';
+            document.getElementById('webhookTemplate').value = settings.webhook.template || '';
+            document.getElementById('webhookMaxRetries').value = settings.webhook.max_retries ?? 3;
+            document.getElementById('webhookNotificationToggle').checked = settings.webhook.enabled || false;
         }
         
-        // 填充Telegram设置
-        if (settings.telegram) {
-            document.getElementById('telegramBotToken').value = settings.telegram.bot_token || ''; // 填充bot token
-            document.getElementById('telegramChatId').value = settings.telegram.chat_id || '';
-            document.getElementById('telegramNotificationToggle').checked = settings.telegram.enabled || false;
-        }
+        // 填充群机器人设置
+        Object.keys(ROBOT_NAMES).forEach(type => {
+            const robot = settings[type];
+            if (!robot) return;
+            document.getElementById(`${type}Webhook`).value = robot.webhook || '';
+            const secretInput = document.getElementById(`${type}Secret`);
+            if (secretInput) secretInput.value = robot.secret || '';
+            document.getElementById(`${type}NotificationToggle`).checked = robot.enabled || false;
+        });
+        
+        // 填充手机推送设置
+        Object.keys(PUSH_NAMES).forEach(type => {
+            const push = settings[type];
+            if (!push) return;
+            document.getElementById(`${type}Server`).value = push.server || '';
+            document.getElementById(`${type}Token`).value = push.token || '';
+            const accessInput = document.getElementById(`${type}AccessToken`);
+            if (accessInput) accessInput.value = push.access_token || '';
+            document.getElementById(`${type}Priorities`).value = push.priorities || '';
+            document.getElementById(`${type}NotificationToggle`).checked = push.enabled || false;
+        });
         
         // 填充用户名
         if (settings.username) {
@@ -1434,73 +1540,117 @@ async function handleUpdateUsername() {
     }
 }
 
-// 保存SMTP设置
-async function saveSmtpSettings() {
-    const smtpData = {
-        host: document.getElementById('smtpHost').value.trim(),
-        port: parseInt(document.getElementById('smtpPort').value) || 587,
-        user: document.getElementById('smtpUser').value.trim(),
-        password: document.getElementById('smtpPass').value.trim(),
-        from: document.getElementById('smtpFrom').value.trim(),
-        to: document.getElementById('smtpTo').value.trim(),
-        enabled: document.getElementById('emailNotificationToggle').checked
+// 保存Webhook设置
+async function saveWebhookSettings() {
+    const webhookData = {
+        url: document.getElementById('webhookUrl').value.trim(),
+        secret: document.getElementById('webhookSecret').value.trim(),
+        headers: document.getElementById('webhookHeaders').value.trim(),
+        template: document.getElementById('webhookTemplate').value.trim(),
+        max_retries: parseInt(document.getElementById('webhookMaxRetries').value) || 0,
+        enabled: document.getElementById('webhookNotificationToggle').checked
     };
     
-    if (smtpData.enabled && (!smtpData.host || !smtpData.user || !smtpData.from || !smtpData.to)) {
-        showNotification('请填写完整的SMTP信息', 'error');
+    if (webhookData.enabled && !webhookData.url) {
+        showNotification('请填写Webhook地址', 'error');
         return;
     }
     
     try {
-        const response = await fetch('/api/settings/smtp', {
+        const response = await fetch('/api/settings/webhook', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify(smtpData)
+            body: JSON.stringify(webhookData)
         });
         
         if (response.ok) {
-            showNotification('SMTP设置保存成功', 'success');
+            showNotification('Webhook设置保存成功', 'success');
         } else {
-            const result = await response.json();
-            showNotification(result.error || '保存SMTP设置失败', 'error');
+            const text = await response.text();
+            showNotification(text.trim() || '保存Webhook设置失败', 'error');
         }
     } catch (error) {
-        showNotification('保存SMTP设置失败: ' + error.message, 'error');
+        showNotification('保存Webhook设置失败: ' + error.message, 'error');
     }
 }
 
-// 保存Telegram设置
-async function saveTelegramSettings() {
-    const telegramData = {
-        bot_token: document.getElementById('telegramBotToken').value.trim(),
-        chat_id: document.getElementById('telegramChatId').value.trim(),
-        enabled: document.getElementById('telegramNotificationToggle').checked
+// 群机器人类型及名称
+const ROBOT_NAMES = { dingtalk: '钉钉', feishu: '飞书', wecom: '企业微信' };
+
+// 保存群机器人设置
+async function saveRobotSettings(type) {
+    const name = ROBOT_NAMES[type];
+    const secretInput = document.getElementById(`${type}Secret`);
+    const robotData = {
+        webhook: document.getElementById(`${type}Webhook`).value.trim(),
+        secret: secretInput ? secretInput.value.trim() : '',
+        enabled: document.getElementById(`${type}NotificationToggle`).checked
     };
     
-    if (telegramData.enabled && (!telegramData.bot_token || !telegramData.chat_id)) {
-        showNotification('请填写完整的Telegram信息', 'error');
+    if (robotData.enabled && !robotData.webhook) {
+        showNotification(`请填写${name}机器人Webhook地址`, 'error');
         return;
     }
     
     try {
-        const response = await fetch('/api/settings/telegram', {
+        const response = await fetch(`/api/settings/${type}`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify(telegramData)
+            body: JSON.stringify(robotData)
         });
         
         if (response.ok) {
-            showNotification('Telegram设置保存成功', 'success');
+            showNotification(`${name}设置保存成功`, 'success');
         } else {
-            const result = await response.json();
-            showNotification(result.error || '保存Telegram设置失败', 'error');
+            const text = await response.text();
+            showNotification(text.trim() || `保存${name}设置失败`, 'error');
+        }
+    } catch (error) {
+        showNotification(`保存${name}设置失败: ` + error.message, 'error');
+    }
+}
+
+// 手机推送类型及名称
+const PUSH_NAMES = { bark: 'Bark', ntfy: 'ntfy', gotify: 'Gotify', serverchan: 'Server酱' };
+
+// 保存手机推送设置
+async function savePushSettings(type) {
+    const name = PUSH_NAMES[type];
+    const accessInput = document.getElementById(`${type}AccessToken`);
+    const pushData = {
+        server: document.getElementById(`${type}Server`).value.trim(),
+        token: document.getElementById(`${type}Token`).value.trim(),
+        access_token: accessInput ? accessInput.value.trim() : '',
+        priorities: document.getElementById(`${type}Priorities`).value.trim(),
+        enabled: document.getElementById(`${type}NotificationToggle`).checked
+    };
+    
+    if (pushData.enabled && !pushData.token) {
+        showNotification(`请填写${name}密钥`, 'error');
+        return;
+    }
+    
+    try {
+        const response = await fetch(`/api/settings/${type}`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(pushData)
+        });
+        
+        if (response.ok) {
+            showNotification(`${name}设置保存成功`, 'success');
+        } else {
+            const text = await response.text();
+            showNotification(text.trim() || `保存${name}设置失败`, 'error');
         }
     } catch (error) {
-        showNotification('保存Telegram设置失败: ' + error.message, 'error');
+        showNotification(`保存${name}设置失败: ` + error.message, 'error');
     }
 }
 
@@ -1544,7 +1694,8 @@ async function saveMonitorSettings() {
             body: JSON.stringify({
                 check_interval: checkInterval, // 直接发送秒数
                 concurrent_limit: concurrentLimit,
-                timeout: timeout
+                timeout: timeout,
+                public_url: document.getElementById('publicUrlInput')?.value.trim() || ''
             })
         });
         
@@ -1563,11 +1714,273 @@ async function saveMonitorSettings() {
     }
 }
 
-// 测试邮件设置
-async function testEmailSettings() {
+// 通知渠道类型名称
+const NOTIFIER_TYPE_NAMES = { email: '邮件', telegram: 'Telegram', exec: '外部命令', mqtt: 'MQTT', url: '通知URL' };
+
+// 已加载的通知渠道
+let notifierInstances = [];
+
+// 转义HTML
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text ?? '';
+    return div.innerHTML;
+}
+
+// 加载通知渠道列表
+async function loadNotifierInstances() {
+    const list = document.getElementById('notifierInstanceList');
+    if (!list) return;
+    
+    try {
+        const response = await fetch('/api/notifiers');
+        if (!response.ok) throw new Error('加载通知渠道失败');
+        const result = await response.json();
+        notifierInstances = result.instances || [];
+        renderNotifierInstances();
+    } catch (error) {
+        list.innerHTML = `<div class="text-error text-sm">${escapeHtml(error.message)}</div>`;
+    }
+}
+
+// 渲染通知渠道列表
+function renderNotifierInstances() {
+    const list = document.getElementById('notifierInstanceList');
+    if (!list) return;
+    
+    if (notifierInstances.length === 0) {
+        list.innerHTML = '<div class="text-sm opacity-70">暂无通知渠道，请在下方添加</div>';
+        return;
+    }
+    
+    list.innerHTML = notifierInstances.map(inst => {
+        const target = inst.type === 'email' ? (inst.config.to || '')
+            : inst.type === 'exec' ? (inst.config.command || '')
+            : inst.type === 'mqtt' ? (inst.config.broker || '')
+            : inst.type === 'url' ? (inst.config.url || '').split('://')[0] + '://…' : (inst.config.chat_id || '');
+        return `
+            <div class="flex flex-wrap items-center justify-between gap-2 p-3 rounded-lg bg-base-200">
+                <div>
+                    <span class="badge badge-outline">${NOTIFIER_TYPE_NAMES[inst.type] || escapeHtml(inst.type)}</span>
+                    <span class="font-semibold ml-2">${escapeHtml(inst.name)}</span>
+                    <span class="text-sm opacity-70 ml-2">${escapeHtml(target)}</span>
+                    <span class="badge ${inst.enabled ? 'badge-success' : 'badge-ghost'} ml-2">${inst.enabled ? '已启用' : '未启用'}</span>
+                </div>
+                <div class="flex gap-2">
+                    <button class="btn btn-xs" onclick="editNotifierInstance(${inst.id})">编辑</button>
+                    <button class="btn btn-xs btn-secondary" onclick="testNotifierInstance(${inst.id})">测试</button>
+                    <button class="btn btn-xs btn-error" onclick="deleteNotifierInstance(${inst.id})">删除</button>
+                </div>
+            </div>
+        `;
+    }).join('');
+}
+
+// 根据类型切换表单字段
+function updateNotifierFields() {
+    const type = document.getElementById('notifierType').value;
+    document.getElementById('notifierEmailFields').classList.toggle('hidden', type !== 'email');
+    document.getElementById('notifierTelegramFields').classList.toggle('hidden', type !== 'telegram');
+    document.getElementById('notifierExecFields').classList.toggle('hidden', type !== 'exec');
+    document.getElementById('notifierMQTTFields').classList.toggle('hidden', type !== 'mqtt');
+    document.getElementById('notifierURLFields').classList.toggle('hidden', type !== 'url');
+}
+
+// 清空通知渠道表单
+function resetNotifierForm() {
+    document.getElementById('notifierInstanceId').value = '';
+    document.getElementById('notifierType').disabled = false;
+    document.getElementById('notifierName').value = '';
+    document.getElementById('notifierEnabledToggle').checked = true;
+    ['smtpHost', 'smtpPort', 'smtpUser', 'smtpPass', 'smtpFrom', 'smtpTo', 'smtpSecurity', 'smtpAuth', 'smtpCACert', 'smtpHeloName', 'smtpTimeout',
+        'telegramBotToken', 'telegramChatId', 'telegramApiBase', 'telegramParseMode', 'telegramCSVThreshold',
+        'execCommand', 'execArgs', 'execWorkDir', 'execTimeout',
+        'mqttBroker', 'mqttUsername', 'mqttPassword', 'mqttTopicPrefix', 'mqttClientId', 'mqttTimeout', 'mqttDiscoveryPrefix', 'mqttCACert', 'notifierURL']
+        .forEach(id => { document.getElementById(id).value = ''; });
+    document.getElementById('mqttQoS').value = '0';
+    document.getElementById('mqttDiscoveryToggle').checked = false;
+    document.getElementById('mqttSkipVerify').checked = false;
+    document.getElementById('smtpSkipVerify').checked = false;
+    document.getElementById('telegramCommandsToggle').checked = false;
+    document.getElementById('execPerEventToggle').checked = false;
+    document.getElementById('notifierFormTitle').textContent = '添加通知渠道';
+    updateNotifierFields();
+}
+
+// 编辑通知渠道
+function editNotifierInstance(id) {
+    const inst = notifierInstances.find(item => item.id === id);
+    if (!inst) return;
+    
+    resetNotifierForm();
+    document.getElementById('notifierInstanceId').value = inst.id;
+    document.getElementById('notifierType').value = inst.type;
+    document.getElementById('notifierType').disabled = true;
+    document.getElementById('notifierName').value = inst.name;
+    document.getElementById('notifierEnabledToggle').checked = inst.enabled;
+    if (inst.type === 'email') {
+        document.getElementById('smtpHost').value = inst.config.host || '';
+        document.getElementById('smtpPort').value = inst.config.port || 587;
+        document.getElementById('smtpUser').value = inst.config.user || '';
+        document.getElementById('smtpPass').value = inst.config.password || '';
+        document.getElementById('smtpFrom').value = inst.config.from || '';
+        document.getElementById('smtpTo').value = inst.config.to || '';
+        document.getElementById('smtpSecurity').value = inst.config.security || '';
+        document.getElementById('smtpAuth').value = inst.config.auth || '';
+        document.getElementById('smtpSkipVerify').checked = !!inst.config.skip_verify;
+        document.getElementById('smtpCACert').value = inst.config.ca_cert || '';
+        document.getElementById('smtpHeloName').value = inst.config.helo_name || '';
+        document.getElementById('smtpTimeout').value = inst.config.timeout || '';
+    } else if (inst.type === 'telegram') {
+        document.getElementById('telegramBotToken').value = inst.config.bot_token || '';
+        document.getElementById('telegramChatId').value = inst.config.chat_id || '';
+        document.getElementById('telegramCommandsToggle').checked = !!inst.config.commands;
+        document.getElementById('telegramApiBase').value = inst.config.api_base || '';
+        document.getElementById('telegramParseMode').value = inst.config.parse_mode || '';
+        document.getElementById('telegramCSVThreshold').value = inst.config.csv_threshold || '';
+    } else if (inst.type === 'exec') {
+        document.getElementById('execCommand').value = inst.config.command || '';
+        document.getElementById('execArgs').value = (inst.config.args || []).join('\n');
+        document.getElementById('execWorkDir').value = inst.config.work_dir || '';
+        document.getElementById('execTimeout').value = inst.config.timeout || '';
+        document.getElementById('execPerEventToggle').checked = !!inst.config.per_event;
+    } else if (inst.type === 'mqtt') {
+        document.getElementById('mqttBroker').value = inst.config.broker || '';
+        document.getElementById('mqttUsername').value = inst.config.username || '';
+        document.getElementById('mqttPassword').value = inst.config.password || '';
+        document.getElementById('mqttTopicPrefix').value = inst.config.topic_prefix || '';
+        document.getElementById('mqttQoS').value = String(inst.config.qos || 0);
+        document.getElementById('mqttClientId').value = inst.config.client_id || '';
+        document.getElementById('mqttTimeout').value = inst.config.timeout || '';
+        document.getElementById('mqttDiscoveryToggle').checked = !!inst.config.discovery;
+        document.getElementById('mqttDiscoveryPrefix').value = inst.config.discovery_prefix || '';
+        document.getElementById('mqttSkipVerify').checked = !!inst.config.skip_verify;
+        document.getElementById('mqttCACert').value = inst.config.ca_cert || '';
+    } else if (inst.type === 'url') {
+        document.getElementById('notifierURL').value = inst.config.url || '';
+    }
+    document.getElementById('notifierFormTitle').textContent = `编辑通知渠道：${inst.name}`;
+    updateNotifierFields();
+}
+
+// 保存通知渠道（新增或更新）
+async function saveNotifierInstance() {
+    const id = document.getElementById('notifierInstanceId').value;
+    const type = document.getElementById('notifierType').value;
+    const data = {
+        type: type,
+        name: document.getElementById('notifierName').value.trim(),
+        enabled: document.getElementById('notifierEnabledToggle').checked,
+        config: type === 'email' ? {
+            host: document.getElementById('smtpHost').value.trim(),
+            port: parseInt(document.getElementById('smtpPort').value) || 587,
+            user: document.getElementById('smtpUser').value.trim(),
+            password: document.getElementById('smtpPass').value.trim(),
+            from: document.getElementById('smtpFrom').value.trim(),
+            to: document.getElementById('smtpTo').value.trim(),
+            security: document.getElementById('smtpSecurity').value,
+            auth: document.getElementById('smtpAuth').value,
+            skip_verify: document.getElementById('smtpSkipVerify').checked,
+            ca_cert: document.getElementById('smtpCACert').value.trim(),
+            helo_name: document.getElementById('smtpHeloName').value.trim(),
+            timeout: parseInt(document.getElementById('smtpTimeout').value) || 0
+        } : type === 'exec' ? {
+            command: document.getElementById('execCommand').value.trim(),
+            args: document.getElementById('execArgs').value.split('\n').map(arg => arg.trim()).filter(arg => arg !== ''),
+            work_dir: document.getElementById('execWorkDir').value.trim(),
+            timeout: parseInt(document.getElementById('execTimeout').value) || 0,
+            per_event: document.getElementById('execPerEventToggle').checked
+        } : type === 'mqtt' ? {
+            broker: document.getElementById('mqttBroker').value.trim(),
+            username: document.getElementById('mqttUsername').value.trim(),
+            password: document.getElementById('mqttPassword').value,
+            topic_prefix: document.getElementById('mqttTopicPrefix').value.trim(),
+            qos: parseInt(document.getElementById('mqttQoS').value) || 0,
+            client_id: document.getElementById('mqttClientId').value.trim(),
+            timeout: parseInt(document.getElementById('mqttTimeout').value) || 0,
+            discovery: document.getElementById('mqttDiscoveryToggle').checked,
+            discovery_prefix: document.getElementById('mqttDiscoveryPrefix').value.trim(),
+            skip_verify: document.getElementById('mqttSkipVerify').checked,
+            ca_cert: document.getElementById('mqttCACert').value.trim()
+        } : type === 'url' ? {
+            url: document.getElementById('notifierURL').value.trim()
+        } : {
+            bot_token: document.getElementById('telegramBotToken').value.trim(),
+            chat_id: document.getElementById('telegramChatId').value.trim(),
+            commands: document.getElementById('telegramCommandsToggle').checked,
+            api_base: document.getElementById('telegramApiBase').value.trim(),
+            parse_mode: document.getElementById('telegramParseMode').value,
+            csv_threshold: parseInt(document.getElementById('telegramCSVThreshold').value) || 0
+        }
+    };
+    
+    if (!data.name) {
+        showNotification('请填写通知渠道名称', 'error');
+        return;
+    }
+    
     try {
-        showNotification('正在发送测试邮件...', 'info');
-        const response = await fetch('/api/test/email', { method: 'POST' });
+        const response = await fetch(id ? `/api/notifiers/${id}` : '/api/notifiers', {
+            method: id ? 'PUT' : 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(data)
+        });
+        
+        if (response.ok) {
+            showNotification(`通知渠道 ${data.name} 保存成功`, 'success');
+            resetNotifierForm();
+            loadNotifierInstances();
+        } else {
+            const text = await response.text();
+            showNotification(text.trim() || '保存通知渠道失败', 'error');
+        }
+    } catch (error) {
+        showNotification('保存通知渠道失败: ' + error.message, 'error');
+    }
+}
+
+// 测试通知渠道
+async function testNotifierInstance(id) {
+    try {
+        showNotification('正在发送测试消息...', 'info');
+        const response = await fetch(`/api/notifiers/${id}/test`, { method: 'POST' });
+        const result = await response.json();
+        // 外部命令的输出来自脚本，需转义后显示
+        const message = result.output
+            ? `${escapeHtml(result.message)}<pre class="whitespace-pre-wrap text-xs mt-1">${escapeHtml(result.output)}</pre>`
+            : result.message;
+        showNotification(message, result.status === 'success' ? 'success' : 'error', result.output ? 15000 : 5000);
+    } catch (error) {
+        showNotification('测试消息发送失败: ' + error.message, 'error');
+    }
+}
+
+// 删除通知渠道
+async function deleteNotifierInstance(id) {
+    const inst = notifierInstances.find(item => item.id === id);
+    if (!inst || !confirm(`确定删除通知渠道 ${inst.name} 吗？`)) return;
+    
+    try {
+        const response = await fetch(`/api/notifiers/${id}`, { method: 'DELETE' });
+        if (response.ok) {
+            showNotification('通知渠道已删除', 'success');
+            loadNotifierInstances();
+        } else {
+            const text = await response.text();
+            showNotification(text.trim() || '删除通知渠道失败', 'error');
+        }
+    } catch (error) {
+        showNotification('删除通知渠道失败: ' + error.message, 'error');
+    }
+}
+
+// 测试Webhook设置
+async function testWebhookSettings() {
+    try {
+        showNotification('正在发送测试Webhook请求...', 'info');
+        const response = await fetch('/api/test/webhook', { method: 'POST' });
         const result = await response.json();
         
         // 判断是否成功
@@ -1577,15 +1990,16 @@ async function testEmailSettings() {
             showNotification(result.message, 'error');
         }
     } catch (error) {
-        showNotification('测试邮件发送失败: ' + error.message, 'error');
+        showNotification('测试Webhook请求发送失败: ' + error.message, 'error');
     }
 }
 
-// 测试Telegram设置
-async function testTelegramSettings() {
+// 测试群机器人设置
+async function testRobotSettings(type) {
+    const name = ROBOT_NAMES[type];
     try {
-        showNotification('正在发送测试Telegram消息...', 'info');
-        const response = await fetch('/api/test/telegram', { method: 'POST' });
+        showNotification(`正在发送测试${name}消息...`, 'info');
+        const response = await fetch(`/api/test/${type}`, { method: 'POST' });
         const result = await response.json();
         
         // 判断是否成功
@@ -1595,7 +2009,25 @@ async function testTelegramSettings() {
             showNotification(result.message, 'error');
         }
     } catch (error) {
-        showNotification('测试Telegram消息发送失败: ' + error.message, 'error');
+        showNotification(`测试${name}消息发送失败: ` + error.message, 'error');
+    }
+}
+
+// 测试手机推送设置
+async function testPushSettings(type) {
+    const name = PUSH_NAMES[type];
+    try {
+        showNotification(`正在发送测试${name}推送...`, 'info');
+        const response = await fetch(`/api/test/${type}`, { method: 'POST' });
+        const result = await response.json();
+        
+        if (result.status === 'success') {
+            showNotification(result.message, 'success');
+        } else {
+            showNotification(result.message, 'error');
+        }
+    } catch (error) {
+        showNotification(`测试${name}推送发送失败: ` + error.message, 'error');
     }
 }
 
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Puff/core"
+	"Puff/storage"
+)
+
+// handleSubscriptions 字段变化订阅管理
+// GET    /api/subscriptions?domain=example.com
+// POST   /api/subscriptions {"domain": "*", "change_types": ["registrar_change"]}
+// DELETE /api/subscriptions?id=1
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleListSubscriptions(w, r)
+	case http.MethodPost:
+		s.handleAddSubscriptions(w, r)
+	case http.MethodDelete:
+		s.handleDeleteSubscription(w, r)
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleListSubscriptions 列出订阅及可订阅的变化类型
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := storage.ListChangeSubscriptions(r.URL.Query().Get("domain"))
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "subscription_list_failed", err.Error())
+		return
+	}
+	if subs == nil {
+		subs = []storage.ChangeSubscription{}
+	}
+
+	changeTypes := make([]map[string]string, 0, len(core.FieldChangeTypes))
+	for _, t := range core.FieldChangeTypes {
+		changeTypes = append(changeTypes, map[string]string{
+			"type":  t,
+			"label": core.GetFieldLabel(t),
+		})
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"subscriptions": subs,
+		"change_types":  changeTypes,
+	})
+}
+
+// handleAddSubscriptions 为域名（或全局 "*"）订阅一种或多种变化类型
+func (s *Server) handleAddSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Domain      string   `json:"domain"`
+		ChangeTypes []string `json:"change_types"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return
+	}
+
+	domain := strings.ToLower(strings.TrimSpace(request.Domain))
+	if domain == "" {
+		domain = storage.GlobalScope
+	}
+	if domain != storage.GlobalScope {
+		if err := s.monitor.GetChecker().ValidateDomain(domain); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "domain_invalid", err.Error())
+			return
+		}
+	}
+
+	if len(request.ChangeTypes) == 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "change_types_required")
+		return
+	}
+	for _, t := range request.ChangeTypes {
+		if t != storage.GlobalScope && !core.IsFieldChangeType(t) {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "change_type_unsupported", t)
+			return
+		}
+	}
+
+	for _, t := range request.ChangeTypes {
+		if err := storage.AddChangeSubscription(domain, t); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.subscription_saved"),
+	})
+}
+
+// handleDeleteSubscription 删除订阅
+func (s *Server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "subscription_id_invalid")
+		return
+	}
+
+	if err := storage.RemoveChangeSubscription(id); err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": s.text(r, "msg.subscription_deleted"),
+	})
+}
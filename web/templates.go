@@ -0,0 +1,191 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Puff/logger"
+	"Puff/notification"
+	"Puff/storage"
+)
+
+// handleTemplates 通知模板列表与新增
+// GET  /api/templates
+// POST /api/templates {"channel": "telegram", "kind": "single", "subject": "...", "body": "..."}
+func (s *Server) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := storage.ListNotificationTemplates()
+		if err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "template_list_failed", err.Error())
+			return
+		}
+		if templates == nil {
+			templates = []storage.NotificationTemplate{}
+		}
+
+		s.writeJSON(w, map[string]interface{}{
+			"templates": templates,
+			"channels":  s.templateChannels(),
+			"kinds":     []string{storage.TemplateSingle, storage.TemplateBatch},
+		})
+	case http.MethodPost:
+		var tpl storage.NotificationTemplate
+		if !s.decodeTemplate(w, r, &tpl) {
+			return
+		}
+		if err := storage.CreateNotificationTemplate(&tpl); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
+		s.reloadTemplates()
+		s.writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": s.text(r, "msg.template_added"),
+			"id":      tpl.ID,
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleTemplate 单个通知模板的更新与删除
+// PUT    /api/templates/{id}
+// DELETE /api/templates/{id}
+func (s *Server) handleTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/templates/"), "/"), 10, 64)
+	if err != nil || id <= 0 {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "template_id_invalid")
+		return
+	}
+
+	existing, err := storage.GetNotificationTemplate(id)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+		return
+	}
+	if existing == nil {
+		s.writeErrorCode(w, r, http.StatusNotFound, "template_not_found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		tpl := storage.NotificationTemplate{ID: id}
+		if !s.decodeTemplate(w, r, &tpl) {
+			return
+		}
+		if err := storage.UpdateNotificationTemplate(&tpl); err != nil {
+			s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
+		s.reloadTemplates()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.template_updated"),
+		})
+	case http.MethodDelete:
+		if err := storage.DeleteNotificationTemplate(id); err != nil {
+			s.writeErrorCode(w, r, http.StatusInternalServerError, "operation_failed", err.Error())
+			return
+		}
+		s.reloadTemplates()
+		s.writeJSON(w, map[string]string{
+			"status":  "success",
+			"message": s.text(r, "msg.template_deleted"),
+		})
+	default:
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+// handleTemplatePreview 使用示例数据渲染模板（不保存）
+// POST /api/templates/preview {"channel": "email", "kind": "single", "subject": "...", "body": "..."}
+func (s *Server) handleTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorCode(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	var tpl storage.NotificationTemplate
+	if !s.decodeTemplate(w, r, &tpl) {
+		return
+	}
+
+	subject, body, err := s.notification.PreviewTemplate(tpl)
+	if err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"subject": subject,
+		"body":    body,
+		"html":    notification.IsHTMLChannel(tpl.Channel) && strings.TrimSpace(tpl.Body) != "",
+	})
+}
+
+// decodeTemplate 解析并校验模板请求体
+func (s *Server) decodeTemplate(w http.ResponseWriter, r *http.Request, tpl *storage.NotificationTemplate) bool {
+	var req struct {
+		Channel string `json:"channel"`
+		Kind    string `json:"kind"`
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+		Enabled *bool  `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_json")
+		return false
+	}
+
+	tpl.Channel = strings.TrimSpace(req.Channel)
+	tpl.Kind = strings.TrimSpace(req.Kind)
+	if tpl.Kind == "" {
+		tpl.Kind = storage.TemplateSingle
+	}
+	tpl.Subject = req.Subject
+	tpl.Body = req.Body
+	tpl.Enabled = req.Enabled == nil || *req.Enabled
+
+	if !containsString(s.templateChannels(), tpl.Channel) {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "channel_unknown", tpl.Channel)
+		return false
+	}
+	if tpl.Kind != storage.TemplateSingle && tpl.Kind != storage.TemplateBatch {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "template_kind_invalid", tpl.Kind)
+		return false
+	}
+	if strings.TrimSpace(tpl.Subject) == "" && strings.TrimSpace(tpl.Body) == "" {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "template_empty")
+		return false
+	}
+	if err := notification.ValidateTemplate(*tpl); err != nil {
+		s.writeErrorCode(w, r, http.StatusBadRequest, "invalid_config", err.Error())
+		return false
+	}
+	return true
+}
+
+// templateChannels 可配置模板的渠道：通知器类型与各通知器标识
+func (s *Server) templateChannels() []string {
+	var channels []string
+	for _, n := range s.notification.GetNotifiers() {
+		if !containsString(channels, n.GetType()) {
+			channels = append(channels, n.GetType())
+		}
+		if key := notification.NotifierKey(n); !containsString(channels, key) {
+			channels = append(channels, key)
+		}
+	}
+	return channels
+}
+
+// reloadTemplates 模板变更后重新加载到通知管理器
+func (s *Server) reloadTemplates() {
+	if err := s.notification.LoadTemplates(); err != nil {
+		logger.Error("重新加载通知模板失败: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Puff/config"
+	"Puff/notification"
+)
+
+func TestHandleTemplatePreview(t *testing.T) {
+	s := testServer()
+	s.notification = notification.NewNotificationManager()
+	s.notification.AddNotifier(notification.NewEmailNotifier(config.SMTPConfig{}))
+	s.notification.AddNotifier(notification.NewWebhookNotifier(config.WebhookConfig{}))
+
+	preview := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/templates/preview", strings.NewReader(body))
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.handleTemplatePreview(w, r)
+		return w
+	}
+
+	w := preview(`{"channel":"email","subject":"{{.Event.Domain}} {{.Info.Registrar}}","body":"<b>{{.Event.Domain}}</b>"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("预览失败: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+		HTML    bool   `json:"html"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Subject != "example.com Example Registrar, Inc." || resp.Body != "<b>example.com</b>" || !resp.HTML {
+		t.Errorf("预览结果错误: %+v", resp)
+	}
+
+	// 只有主题模板时正文为内置排版，不按 HTML 显示
+	w = preview(`{"channel":"webhook","kind":"batch","subject":"{{.Count}} 个域名"}`)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Subject != "2 个域名" || resp.Body == "" || resp.HTML {
+		t.Errorf("批量预览结果错误: %+v", resp)
+	}
+
+	tests := []struct {
+		body, code string
+	}{
+		{`{"channel":`, "invalid_json"},
+		{`{"channel":"telegram","body":"x"}`, "channel_unknown"},
+		{`{"channel":"email","kind":"daily","body":"x"}`, "template_kind_invalid"},
+		{`{"channel":"email","subject":" ","body":""}`, "template_empty"},
+		{`{"channel":"email","body":"{{.Event"}`, "invalid_config"},
+	}
+	for _, tt := range tests {
+		if got := preview(tt.body).Header().Get("X-Error-Code"); got != tt.code {
+			t.Errorf("%s 错误码 = %q, 期望 %q", tt.body, got, tt.code)
+		}
+	}
+}